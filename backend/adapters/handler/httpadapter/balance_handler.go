@@ -0,0 +1,44 @@
+package httpadapter
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/fim-lab/expense-tracker/internal/core/ports"
+)
+
+type BalanceHandler struct {
+	service ports.BalanceService
+}
+
+func NewBalanceHandler(service ports.BalanceService) *BalanceHandler {
+	return &BalanceHandler{service: service}
+}
+
+// CheckBalances reports the wallet and budget balances of all users that
+// drifted from their ledgers without touching them.
+func (h *BalanceHandler) CheckBalances(w http.ResponseWriter, r *http.Request) {
+	h.checkBalances(w, false)
+}
+
+// RepairBalances sets every drifted balance to its ledger and reports what
+// it changed.
+func (h *BalanceHandler) RepairBalances(w http.ResponseWriter, r *http.Request) {
+	h.checkBalances(w, true)
+}
+
+func (h *BalanceHandler) checkBalances(w http.ResponseWriter, repair bool) {
+	report, err := h.service.CheckBalances(repair)
+	if err != nil {
+		log.Printf("Error checking balances (repair: %t): %v", repair, err)
+		http.Error(w, "Could not check balances", http.StatusInternalServerError)
+		return
+	}
+	if repair && len(report.Drifts) > 0 {
+		log.Printf("Repaired %d drifted balances", len(report.Drifts))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
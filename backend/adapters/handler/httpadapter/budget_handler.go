@@ -2,9 +2,11 @@ package httpadapter
 
 import (
 	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/fim-lab/expense-tracker/internal/core/domain"
 	"github.com/fim-lab/expense-tracker/internal/core/ports"
@@ -59,6 +61,11 @@ func (h *BudgetHandler) CreateBudget(w http.ResponseWriter, r *http.Request) {
 	err = h.service.CreateBudget(userID, budget)
 	if err != nil {
 		log.Printf("Error creating budget: %v", err)
+		if errors.Is(err, domain.ErrInvalidBudgetPeriod) || errors.Is(err, domain.ErrInvalidAlertThreshold) ||
+			errors.Is(err, domain.ErrBudgetNotFound) || errors.Is(err, domain.ErrInvalidBudgetParent) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 		http.Error(w, "Error creating budget", http.StatusInternalServerError)
 		return
 	}
@@ -166,14 +173,197 @@ func (h *BudgetHandler) UpdateBudget(w http.ResponseWriter, r *http.Request) {
 	err = h.service.UpdateBudget(userID, budget)
 	if err != nil {
 		log.Printf("Error updating budget %d for user %d: %v", id, userID, err)
-		switch err {
-		case domain.ErrBudgetNotFound, domain.ErrMissingBudget, domain.ErrInvalidAmount:
-			http.Error(w, err.Error(), http.StatusBadRequest)
-		case domain.ErrUnauthorized:
-			http.Error(w, "Unauthorized", http.StatusUnauthorized)
-		default:
-			http.Error(w, "Could not update budget", http.StatusInternalServerError)
+		writeBudgetError(w, err, "Could not update budget")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeBudgetError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, domain.ErrBudgetNotFound), errors.Is(err, domain.ErrMissingBudget),
+		errors.Is(err, domain.ErrInvalidAmount), errors.Is(err, domain.ErrInvalidBudgetPeriod),
+		errors.Is(err, domain.ErrSameBudgetTransfer), errors.Is(err, domain.ErrInvalidAlertThreshold),
+		errors.Is(err, domain.ErrInvalidBudgetParent):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, domain.ErrOverAssigned):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, domain.ErrUnauthorized):
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+	default:
+		http.Error(w, fallback, http.StatusInternalServerError)
+	}
+}
+
+// GetBudgetHistory returns the budget's periods up to today, or up to the
+// period holding the "until" date.
+func (h *BudgetHandler) GetBudgetHistory(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(w, r)
+	if !ok {
+		return
+	}
+	id, ok := idFromURL(w, r, "id")
+	if !ok {
+		return
+	}
+
+	until := time.Now()
+	if untilStr := r.URL.Query().Get("until"); untilStr != "" {
+		parsed, err := time.Parse("2006-01-02", untilStr)
+		if err != nil {
+			http.Error(w, "until must be a date like 2006-01-02", http.StatusBadRequest)
+			return
 		}
+		until = parsed
+	}
+
+	history, err := h.service.GetBudgetHistory(userID, id, until)
+	if err != nil {
+		log.Printf("Error fetching history of budget %d for user %d: %v", id, userID, err)
+		writeBudgetError(w, err, "Could not fetch budget history")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(history)
+}
+
+func (h *BudgetHandler) SetAllocation(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(w, r)
+	if !ok {
+		return
+	}
+	id, ok := idFromURL(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var allocation domain.BudgetAllocation
+	if err := json.NewDecoder(r.Body).Decode(&allocation); err != nil {
+		log.Printf("JSON decode error: %v", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	allocation.BudgetID = id
+
+	if err := h.service.SetAllocation(userID, allocation); err != nil {
+		log.Printf("Error allocating budget %d for user %d: %v", id, userID, err)
+		writeBudgetError(w, err, "Could not set allocation")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *BudgetHandler) GetToBeAssigned(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(w, r)
+	if !ok {
+		return
+	}
+
+	toBeAssigned, err := h.service.GetToBeAssigned(userID)
+	if err != nil {
+		log.Printf("Error fetching the amount to be assigned for user %d: %v", userID, err)
+		http.Error(w, "Could not fetch the amount to be assigned", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]int{"toBeAssignedCents": toBeAssigned})
+}
+
+// AssignToBudgets distributes income to be assigned across budgets in one go.
+func (h *BudgetHandler) AssignToBudgets(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(w, r)
+	if !ok {
+		return
+	}
+
+	var assignments []domain.BudgetAssignment
+	if err := json.NewDecoder(r.Body).Decode(&assignments); err != nil {
+		log.Printf("JSON decode error: %v", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	toBeAssigned, err := h.service.AssignToBudgets(userID, assignments)
+	if err != nil {
+		log.Printf("Error assigning to budgets for user %d: %v", userID, err)
+		writeBudgetError(w, err, "Could not assign to budgets")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]int{"toBeAssignedCents": toBeAssigned})
+}
+
+func (h *BudgetHandler) Transfer(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(w, r)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		FromBudgetID int    `json:"fromBudgetId"`
+		ToBudgetID   int    `json:"toBudgetId"`
+		Amount       int    `json:"amount"`
+		Note         string `json:"note"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.TransferBetweenBudgets(userID, req.FromBudgetID, req.ToBudgetID, req.Amount, req.Note); err != nil {
+		log.Printf("Error transferring between budgets for user %d: %v", userID, err)
+		writeBudgetError(w, err, "Error creating transfer")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *BudgetHandler) GetMovements(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(w, r)
+	if !ok {
+		return
+	}
+
+	movements, err := h.service.GetMovements(userID)
+	if err != nil {
+		log.Printf("Error fetching budget movements for user %d: %v", userID, err)
+		http.Error(w, "Could not fetch budget movements", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(movements)
+}
+
+// MoveBudget nests the budget under {"parentId": ...}, or makes it a top
+// level budget with a null parentId.
+func (h *BudgetHandler) MoveBudget(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(w, r)
+	if !ok {
+		return
+	}
+	id, ok := idFromURL(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var req struct {
+		ParentID *int `json:"parentId"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.MoveBudget(userID, id, req.ParentID); err != nil {
+		log.Printf("Error moving budget %d: %v", id, err)
+		writeBudgetError(w, err, "Could not move budget")
 		return
 	}
 
@@ -0,0 +1,34 @@
+package httpadapter
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/fim-lab/expense-tracker/internal/core/ports"
+)
+
+type DashboardHandler struct {
+	service ports.DashboardService
+}
+
+func NewDashboardHandler(service ports.DashboardService) *DashboardHandler {
+	return &DashboardHandler{service: service}
+}
+
+func (h *DashboardHandler) GetDashboard(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(w, r)
+	if !ok {
+		return
+	}
+
+	dashboard, err := h.service.GetDashboard(userID)
+	if err != nil {
+		log.Printf("Error fetching dashboard for user %d: %v", userID, err)
+		http.Error(w, "Could not fetch dashboard", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(dashboard)
+}
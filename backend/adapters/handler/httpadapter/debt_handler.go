@@ -0,0 +1,216 @@
+package httpadapter
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/fim-lab/expense-tracker/internal/core/domain"
+	"github.com/fim-lab/expense-tracker/internal/core/ports"
+)
+
+type DebtHandler struct {
+	service ports.DebtService
+}
+
+func NewDebtHandler(service ports.DebtService) *DebtHandler {
+	return &DebtHandler{service: service}
+}
+
+func writeDebtError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, domain.ErrCounterpartyNotFound),
+		errors.Is(err, domain.ErrDebtNotFound),
+		errors.Is(err, domain.ErrWalletNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, domain.ErrCounterpartyExists),
+		errors.Is(err, domain.ErrNotEmpty):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, domain.ErrMissingCounterparty),
+		errors.Is(err, domain.ErrInvalidAmount),
+		errors.Is(err, domain.ErrOverRepaid):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, fallback, http.StatusInternalServerError)
+	}
+}
+
+func (h *DebtHandler) GetCounterparties(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(w, r)
+	if !ok {
+		return
+	}
+
+	counterparties, err := h.service.GetCounterparties(userID)
+	if err != nil {
+		log.Printf("Error fetching counterparties: %v", err)
+		writeDebtError(w, err, "Could not fetch counterparties")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(counterparties)
+}
+
+func (h *DebtHandler) CreateCounterparty(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(w, r)
+	if !ok {
+		return
+	}
+
+	var c domain.Counterparty
+	if err := json.NewDecoder(r.Body).Decode(&c); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	created, err := h.service.CreateCounterparty(userID, c)
+	if err != nil {
+		log.Printf("Error creating counterparty: %v", err)
+		writeDebtError(w, err, "Could not create counterparty")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(created)
+}
+
+func (h *DebtHandler) DeleteCounterparty(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(w, r)
+	if !ok {
+		return
+	}
+	id, ok := idFromURL(w, r, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteCounterparty(userID, id); err != nil {
+		log.Printf("Error deleting counterparty %d: %v", id, err)
+		writeDebtError(w, err, "Could not delete counterparty")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetDebts returns the balance with every counterparty, positive if they owe
+// the user, along with their debts and repayments.
+func (h *DebtHandler) GetDebts(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(w, r)
+	if !ok {
+		return
+	}
+
+	debts, err := h.service.GetDebts(userID)
+	if err != nil {
+		log.Printf("Error fetching debts: %v", err)
+		writeDebtError(w, err, "Could not fetch debts")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(debts)
+}
+
+// CreateDebt books a debt with a counterparty. An expense lends the money,
+// an income borrows it.
+func (h *DebtHandler) CreateDebt(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(w, r)
+	if !ok {
+		return
+	}
+
+	var t domain.Transaction
+	if err := json.NewDecoder(r.Body).Decode(&t); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if t.CounterpartyID == nil {
+		http.Error(w, "Missing counterparty", http.StatusBadRequest)
+		return
+	}
+
+	debt, err := h.service.CreateDebt(userID, *t.CounterpartyID, t)
+	if err != nil {
+		log.Printf("Error creating debt: %v", err)
+		writeDebtError(w, err, "Could not create debt")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(debt)
+}
+
+// AssignCounterparty names the counterparty of a debt transaction booked
+// without one.
+func (h *DebtHandler) AssignCounterparty(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(w, r)
+	if !ok {
+		return
+	}
+	id, ok := idFromURL(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var req struct {
+		CounterpartyID int `json:"counterpartyId"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.AssignCounterparty(userID, id, req.CounterpartyID); err != nil {
+		log.Printf("Error assigning counterparty to debt %d: %v", id, err)
+		writeDebtError(w, err, "Could not assign counterparty")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RepayDebt books a repayment of part or all of what is open of the debt. It
+// goes into the debt's wallet and on today unless the request says otherwise.
+func (h *DebtHandler) RepayDebt(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(w, r)
+	if !ok {
+		return
+	}
+	id, ok := idFromURL(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var req struct {
+		AmountInCents int       `json:"amountInCents"`
+		WalletID      int       `json:"walletId"`
+		Date          time.Time `json:"date"`
+		Description   string    `json:"description"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	repayment, err := h.service.RepayDebt(userID, id, domain.Transaction{
+		AmountInCents: req.AmountInCents,
+		WalletID:      req.WalletID,
+		Date:          req.Date,
+		Description:   req.Description,
+	})
+	if err != nil {
+		log.Printf("Error repaying debt %d: %v", id, err)
+		writeDebtError(w, err, "Could not repay debt")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(repayment)
+}
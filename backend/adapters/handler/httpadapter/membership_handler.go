@@ -0,0 +1,135 @@
+package httpadapter
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/fim-lab/expense-tracker/internal/core/domain"
+	"github.com/fim-lab/expense-tracker/internal/core/ports"
+)
+
+type MembershipHandler struct {
+	service ports.MembershipService
+}
+
+func NewMembershipHandler(service ports.MembershipService) *MembershipHandler {
+	return &MembershipHandler{service: service}
+}
+
+func writeMembershipError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, domain.ErrUnauthorized):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, domain.ErrMembershipNotFound),
+		errors.Is(err, domain.ErrUserNotFound),
+		errors.Is(err, domain.ErrWalletNotFound),
+		errors.Is(err, domain.ErrBudgetNotFound),
+		errors.Is(err, domain.ErrDepotNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, domain.ErrInvalidResourceType),
+		errors.Is(err, domain.ErrInvalidPermission):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, fallback, http.StatusInternalServerError)
+	}
+}
+
+// GetMembers lists who the wallet, budget or depot of the given type is
+// shared with.
+func (h *MembershipHandler) GetMembers(resourceType domain.ResourceType) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := userIDFromContext(w, r)
+		if !ok {
+			return
+		}
+		id, ok := idFromURL(w, r, "id")
+		if !ok {
+			return
+		}
+
+		members, err := h.service.GetMembers(userID, resourceType, id)
+		if err != nil {
+			log.Printf("Error fetching members of %s %d: %v", resourceType, id, err)
+			writeMembershipError(w, err, "Could not fetch members")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(members)
+	}
+}
+
+// Invite shares the wallet, budget or depot of the given type with the user
+// named in the request, or changes their permission if it already is.
+func (h *MembershipHandler) Invite(resourceType domain.ResourceType) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := userIDFromContext(w, r)
+		if !ok {
+			return
+		}
+		id, ok := idFromURL(w, r, "id")
+		if !ok {
+			return
+		}
+
+		var req struct {
+			Username   string            `json:"username"`
+			Permission domain.Permission `json:"permission"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		m, err := h.service.Invite(userID, domain.Membership{ResourceType: resourceType, ResourceID: id, Username: req.Username, Permission: req.Permission})
+		if err != nil {
+			log.Printf("Error inviting %q to %s %d: %v", req.Username, resourceType, id, err)
+			writeMembershipError(w, err, "Could not invite member")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(m)
+	}
+}
+
+// GetSharedWithMe returns the memberships others granted the user.
+func (h *MembershipHandler) GetSharedWithMe(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(w, r)
+	if !ok {
+		return
+	}
+
+	memberships, err := h.service.GetSharedWithMe(userID)
+	if err != nil {
+		log.Printf("Error fetching memberships: %v", err)
+		writeMembershipError(w, err, "Could not fetch memberships")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(memberships)
+}
+
+// RemoveMember ends a membership, by the owner or by the member leaving.
+func (h *MembershipHandler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(w, r)
+	if !ok {
+		return
+	}
+	id, ok := idFromURL(w, r, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.service.RemoveMember(userID, id); err != nil {
+		log.Printf("Error removing membership %d: %v", id, err)
+		writeMembershipError(w, err, "Could not remove member")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
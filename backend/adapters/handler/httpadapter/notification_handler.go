@@ -0,0 +1,61 @@
+package httpadapter
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/fim-lab/expense-tracker/internal/core/domain"
+	"github.com/fim-lab/expense-tracker/internal/core/ports"
+)
+
+type NotificationHandler struct {
+	service ports.NotificationService
+}
+
+func NewNotificationHandler(service ports.NotificationService) *NotificationHandler {
+	return &NotificationHandler{service: service}
+}
+
+// GetNotifications returns the inbox, newest first, or only the unread
+// notifications with "unread=true".
+func (h *NotificationHandler) GetNotifications(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(w, r)
+	if !ok {
+		return
+	}
+
+	notifications, err := h.service.GetNotifications(userID, r.URL.Query().Get("unread") == "true")
+	if err != nil {
+		log.Printf("Error fetching notifications for user %d: %v", userID, err)
+		http.Error(w, "Could not fetch notifications", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(notifications)
+}
+
+func (h *NotificationHandler) MarkRead(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(w, r)
+	if !ok {
+		return
+	}
+	id, ok := idFromURL(w, r, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.service.MarkRead(userID, id); err != nil {
+		if errors.Is(err, domain.ErrNotificationNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		log.Printf("Error marking notification %d read: %v", id, err)
+		http.Error(w, "Could not mark notification read", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,186 @@
+package httpadapter
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/fim-lab/expense-tracker/internal/core/domain"
+	"github.com/fim-lab/expense-tracker/internal/core/ports"
+)
+
+type ReconciliationHandler struct {
+	service ports.ReconciliationService
+}
+
+func NewReconciliationHandler(service ports.ReconciliationService) *ReconciliationHandler {
+	return &ReconciliationHandler{service: service}
+}
+
+func writeReconciliationError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, domain.ErrReconciliationNotFound),
+		errors.Is(err, domain.ErrWalletNotFound),
+		errors.Is(err, domain.ErrTransactionNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, domain.ErrReconciliationOpen),
+		errors.Is(err, domain.ErrReconciliationCompleted),
+		errors.Is(err, domain.ErrTransactionReconciled),
+		errors.Is(err, domain.ErrReconciliationUnbalanced):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, domain.ErrNotOnStatement),
+		errors.Is(err, domain.ErrInvalidDateRange):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, fallback, http.StatusInternalServerError)
+	}
+}
+
+func (h *ReconciliationHandler) GetReconciliations(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(w, r)
+	if !ok {
+		return
+	}
+	walletID, ok := idFromURL(w, r, "id")
+	if !ok {
+		return
+	}
+
+	reconciliations, err := h.service.GetReconciliations(userID, walletID)
+	if err != nil {
+		log.Printf("Error fetching reconciliations of wallet %d: %v", walletID, err)
+		writeReconciliationError(w, err, "Could not fetch reconciliations")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(reconciliations)
+}
+
+// StartReconciliation opens a reconciliation of the wallet against the
+// statement's date and ending balance.
+func (h *ReconciliationHandler) StartReconciliation(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(w, r)
+	if !ok {
+		return
+	}
+	walletID, ok := idFromURL(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var req struct {
+		StatementDate         time.Time `json:"statementDate"`
+		StatementBalanceCents int       `json:"statementBalanceCents"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	rec, err := h.service.StartReconciliation(userID, walletID, req.StatementDate, req.StatementBalanceCents)
+	if err != nil {
+		log.Printf("Error starting reconciliation of wallet %d: %v", walletID, err)
+		writeReconciliationError(w, err, "Could not start reconciliation")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(rec)
+}
+
+func (h *ReconciliationHandler) GetReconciliation(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(w, r)
+	if !ok {
+		return
+	}
+	id, ok := idFromURL(w, r, "id")
+	if !ok {
+		return
+	}
+
+	rec, err := h.service.GetReconciliation(userID, id)
+	if err != nil {
+		log.Printf("Error fetching reconciliation %d: %v", id, err)
+		writeReconciliationError(w, err, "Could not fetch reconciliation")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rec)
+}
+
+// SetCleared ticks the transactions, or unticks them with "cleared": false,
+// and returns the reconciliation with its new difference.
+func (h *ReconciliationHandler) SetCleared(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(w, r)
+	if !ok {
+		return
+	}
+	id, ok := idFromURL(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var req struct {
+		TransactionIDs []int `json:"transactionIds"`
+		Cleared        bool  `json:"cleared"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	rec, err := h.service.SetCleared(userID, id, req.TransactionIDs, req.Cleared)
+	if err != nil {
+		log.Printf("Error clearing transactions in reconciliation %d: %v", id, err)
+		writeReconciliationError(w, err, "Could not clear transactions")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rec)
+}
+
+func (h *ReconciliationHandler) CompleteReconciliation(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(w, r)
+	if !ok {
+		return
+	}
+	id, ok := idFromURL(w, r, "id")
+	if !ok {
+		return
+	}
+
+	rec, err := h.service.CompleteReconciliation(userID, id)
+	if err != nil {
+		log.Printf("Error completing reconciliation %d: %v", id, err)
+		writeReconciliationError(w, err, "Could not complete reconciliation")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rec)
+}
+
+func (h *ReconciliationHandler) DeleteReconciliation(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(w, r)
+	if !ok {
+		return
+	}
+	id, ok := idFromURL(w, r, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteReconciliation(userID, id); err != nil {
+		log.Printf("Error deleting reconciliation %d: %v", id, err)
+		writeReconciliationError(w, err, "Could not delete reconciliation")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,141 @@
+package httpadapter
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/fim-lab/expense-tracker/internal/core/domain"
+	"github.com/fim-lab/expense-tracker/internal/core/ports"
+)
+
+type SavingsGoalHandler struct {
+	service ports.SavingsGoalService
+}
+
+func NewSavingsGoalHandler(service ports.SavingsGoalService) *SavingsGoalHandler {
+	return &SavingsGoalHandler{service: service}
+}
+
+func writeSavingsGoalError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, domain.ErrSavingsGoalNotFound),
+		errors.Is(err, domain.ErrWalletNotFound),
+		errors.Is(err, domain.ErrBudgetNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, domain.ErrInvalidSavingsGoal),
+		errors.Is(err, domain.ErrInvalidAmount),
+		errors.Is(err, domain.ErrSameWalletTransfer):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, fallback, http.StatusInternalServerError)
+	}
+}
+
+func (h *SavingsGoalHandler) GetSavingsGoals(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(w, r)
+	if !ok {
+		return
+	}
+
+	goals, err := h.service.GetSavingsGoals(userID)
+	if err != nil {
+		log.Printf("Error fetching savings goals for user %d: %v", userID, err)
+		http.Error(w, "Could not fetch savings goals", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(goals)
+}
+
+func (h *SavingsGoalHandler) GetSavingsGoal(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(w, r)
+	if !ok {
+		return
+	}
+	id, ok := idFromURL(w, r, "id")
+	if !ok {
+		return
+	}
+
+	goal, err := h.service.GetSavingsGoal(userID, id)
+	if err != nil {
+		log.Printf("Error fetching savings goal %d: %v", id, err)
+		writeSavingsGoalError(w, err, "Could not fetch savings goal")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(goal)
+}
+
+func (h *SavingsGoalHandler) CreateSavingsGoal(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(w, r)
+	if !ok {
+		return
+	}
+
+	var goal domain.SavingsGoal
+	if err := json.NewDecoder(r.Body).Decode(&goal); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	created, err := h.service.CreateSavingsGoal(userID, goal)
+	if err != nil {
+		log.Printf("Error creating savings goal %q: %v", goal.Name, err)
+		writeSavingsGoalError(w, err, "Could not create savings goal")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(created)
+}
+
+func (h *SavingsGoalHandler) UpdateSavingsGoal(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(w, r)
+	if !ok {
+		return
+	}
+	id, ok := idFromURL(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var goal domain.SavingsGoal
+	if err := json.NewDecoder(r.Body).Decode(&goal); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	goal.ID = id
+
+	if err := h.service.UpdateSavingsGoal(userID, goal); err != nil {
+		log.Printf("Error updating savings goal %d: %v", id, err)
+		writeSavingsGoalError(w, err, "Could not update savings goal")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *SavingsGoalHandler) DeleteSavingsGoal(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(w, r)
+	if !ok {
+		return
+	}
+	id, ok := idFromURL(w, r, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteSavingsGoal(userID, id); err != nil {
+		log.Printf("Error deleting savings goal %d: %v", id, err)
+		writeSavingsGoalError(w, err, "Could not delete savings goal")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,183 @@
+package httpadapter
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/fim-lab/expense-tracker/internal/core/domain"
+	"github.com/fim-lab/expense-tracker/internal/core/ports"
+)
+
+type SharedExpenseHandler struct {
+	service ports.SharedExpenseService
+}
+
+func NewSharedExpenseHandler(service ports.SharedExpenseService) *SharedExpenseHandler {
+	return &SharedExpenseHandler{service: service}
+}
+
+func writeSharedExpenseError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, domain.ErrTransactionNotFound),
+		errors.Is(err, domain.ErrSharedExpenseNotFound),
+		errors.Is(err, domain.ErrSettlementNotFound),
+		errors.Is(err, domain.ErrUserNotFound),
+		errors.Is(err, domain.ErrWalletNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, domain.ErrInvalidShare),
+		errors.Is(err, domain.ErrInvalidAmount),
+		errors.Is(err, domain.ErrOverRepaid):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, fallback, http.StatusInternalServerError)
+	}
+}
+
+// ShareExpense splits one of the user's expenses with other users, named by
+// ID or username, replacing the split it had.
+func (h *SharedExpenseHandler) ShareExpense(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(w, r)
+	if !ok {
+		return
+	}
+	id, ok := idFromURL(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var e domain.SharedExpense
+	if err := json.NewDecoder(r.Body).Decode(&e); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	shared, err := h.service.ShareExpense(userID, id, e)
+	if err != nil {
+		log.Printf("Error sharing transaction %d: %v", id, err)
+		writeSharedExpenseError(w, err, "Could not share expense")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(shared)
+}
+
+func (h *SharedExpenseHandler) UnshareExpense(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(w, r)
+	if !ok {
+		return
+	}
+	id, ok := idFromURL(w, r, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.service.UnshareExpense(userID, id); err != nil {
+		log.Printf("Error unsharing transaction %d: %v", id, err)
+		writeSharedExpenseError(w, err, "Could not unshare expense")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetSharedExpenses returns the expenses the user paid for others and those
+// others paid for them.
+func (h *SharedExpenseHandler) GetSharedExpenses(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(w, r)
+	if !ok {
+		return
+	}
+
+	expenses, err := h.service.GetSharedExpenses(userID)
+	if err != nil {
+		log.Printf("Error fetching shared expenses: %v", err)
+		writeSharedExpenseError(w, err, "Could not fetch shared expenses")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(expenses)
+}
+
+// GetLedger returns who owes whom: the balance with every other user,
+// positive if they owe the user.
+func (h *SharedExpenseHandler) GetLedger(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(w, r)
+	if !ok {
+		return
+	}
+
+	balances, err := h.service.GetLedger(userID)
+	if err != nil {
+		log.Printf("Error fetching shared ledger: %v", err)
+		writeSharedExpenseError(w, err, "Could not fetch ledger")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(balances)
+}
+
+// SettleUp pays off the balance with another user, in full unless the
+// request names an amount.
+func (h *SharedExpenseHandler) SettleUp(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(w, r)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		UserID        int `json:"userId"`
+		WalletID      int `json:"walletId"`
+		AmountInCents int `json:"amountInCents"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	settlement, err := h.service.SettleUp(userID, req.UserID, req.WalletID, req.AmountInCents)
+	if err != nil {
+		log.Printf("Error settling up with user %d: %v", req.UserID, err)
+		writeSharedExpenseError(w, err, "Could not settle up")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(settlement)
+}
+
+// ConfirmSettlement books the user's side of a settlement another user
+// started on the wallet named in the request.
+func (h *SharedExpenseHandler) ConfirmSettlement(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(w, r)
+	if !ok {
+		return
+	}
+	id, ok := idFromURL(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var req struct {
+		WalletID int `json:"walletId"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	settlement, err := h.service.ConfirmSettlement(userID, id, req.WalletID)
+	if err != nil {
+		log.Printf("Error confirming settlement %d: %v", id, err)
+		writeSharedExpenseError(w, err, "Could not confirm settlement")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(settlement)
+}
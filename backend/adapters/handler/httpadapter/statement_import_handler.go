@@ -0,0 +1,201 @@
+package httpadapter
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/fim-lab/expense-tracker/internal/core/domain"
+	"github.com/fim-lab/expense-tracker/internal/core/ports"
+)
+
+type StatementImportHandler struct {
+	service ports.StatementImportService
+}
+
+func NewStatementImportHandler(service ports.StatementImportService) *StatementImportHandler {
+	return &StatementImportHandler{service: service}
+}
+
+func writeStatementImportError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, domain.ErrWalletNotFound), errors.Is(err, domain.ErrCSVProfileNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, domain.ErrUnauthorized):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, domain.ErrInvalidStatement), errors.Is(err, domain.ErrInvalidCSVProfile):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, fallback, http.StatusInternalServerError)
+	}
+}
+
+// readStatementFile reads the uploaded "file" together with the "walletId" of
+// the wallet its rows are booked on.
+func readStatementFile(w http.ResponseWriter, r *http.Request) ([]byte, int, bool) {
+	if err := r.ParseMultipartForm(10 << 20); err != nil { // 10 MB
+		http.Error(w, "Unable to parse form", http.StatusBadRequest)
+		return nil, 0, false
+	}
+
+	walletID, err := strconv.Atoi(r.FormValue("walletId"))
+	if err != nil {
+		http.Error(w, "walletId is not valid", http.StatusBadRequest)
+		return nil, 0, false
+	}
+
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		http.Error(w, "Unable to get file from form", http.StatusBadRequest)
+		return nil, 0, false
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		http.Error(w, "Unable to read file", http.StatusBadRequest)
+		return nil, 0, false
+	}
+	return data, walletID, true
+}
+
+func (h *StatementImportHandler) ImportCSV(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(w, r)
+	if !ok {
+		return
+	}
+	data, walletID, ok := readStatementFile(w, r)
+	if !ok {
+		return
+	}
+
+	result, err := h.service.ImportCSV(userID, walletID, r.FormValue("profile"), data)
+	writeStatementImportResult(w, userID, result, err)
+}
+
+func (h *StatementImportHandler) ImportCAMT053(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(w, r)
+	if !ok {
+		return
+	}
+	data, walletID, ok := readStatementFile(w, r)
+	if !ok {
+		return
+	}
+
+	result, err := h.service.ImportCAMT053(userID, walletID, data)
+	writeStatementImportResult(w, userID, result, err)
+}
+
+func (h *StatementImportHandler) ImportMT940(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(w, r)
+	if !ok {
+		return
+	}
+	data, walletID, ok := readStatementFile(w, r)
+	if !ok {
+		return
+	}
+
+	result, err := h.service.ImportMT940(userID, walletID, data)
+	writeStatementImportResult(w, userID, result, err)
+}
+
+func writeStatementImportResult(w http.ResponseWriter, userID int, result domain.StatementImportResult, err error) {
+	if err != nil {
+		log.Printf("Error importing statement for user %d: %v", userID, err)
+		writeStatementImportError(w, err, "Could not import statement")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+func (h *StatementImportHandler) GetCSVProfiles(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(w, r)
+	if !ok {
+		return
+	}
+
+	profiles, err := h.service.GetCSVProfiles(userID)
+	if err != nil {
+		log.Printf("Error fetching csv profiles: %v", err)
+		http.Error(w, "Could not fetch csv profiles", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(profiles)
+}
+
+func (h *StatementImportHandler) CreateCSVProfile(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(w, r)
+	if !ok {
+		return
+	}
+
+	var profile domain.CSVProfile
+	if err := json.NewDecoder(r.Body).Decode(&profile); err != nil {
+		log.Printf("JSON decode error: %v", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.CreateCSVProfile(userID, profile); err != nil {
+		log.Printf("Error creating csv profile: %v", err)
+		writeStatementImportError(w, err, "Could not create csv profile")
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (h *StatementImportHandler) UpdateCSVProfile(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(w, r)
+	if !ok {
+		return
+	}
+	id, ok := idFromURL(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var profile domain.CSVProfile
+	if err := json.NewDecoder(r.Body).Decode(&profile); err != nil {
+		log.Printf("JSON decode error: %v", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	profile.ID = id
+
+	if err := h.service.UpdateCSVProfile(userID, profile); err != nil {
+		log.Printf("Error updating csv profile %d: %v", id, err)
+		writeStatementImportError(w, err, "Could not update csv profile")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *StatementImportHandler) DeleteCSVProfile(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(w, r)
+	if !ok {
+		return
+	}
+	id, ok := idFromURL(w, r, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteCSVProfile(userID, id); err != nil {
+		log.Printf("Error deleting csv profile %d: %v", id, err)
+		writeStatementImportError(w, err, "Could not delete csv profile")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
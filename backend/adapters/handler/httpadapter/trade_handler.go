@@ -57,6 +57,8 @@ func writeStockError(w http.ResponseWriter, err error, fallback string) {
 		errors.Is(err, domain.ErrTradeDepotChange),
 		errors.Is(err, domain.ErrNotEmpty):
 		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, domain.ErrTransactionReconciled):
+		http.Error(w, err.Error(), http.StatusConflict)
 	default:
 		http.Error(w, fallback, http.StatusInternalServerError)
 	}
@@ -137,3 +139,21 @@ func (h *TradeHandler) DeleteTrade(w http.ResponseWriter, r *http.Request) {
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// SyncCashFlows books the wallet transactions of all users' trades at the
+// trades' cash flows and reports how many it changed. It is meant to be run
+// once after upgrading; running it again changes nothing.
+func (h *TradeHandler) SyncCashFlows(w http.ResponseWriter, r *http.Request) {
+	synced, err := h.service.SyncCashFlows()
+	if err != nil {
+		log.Printf("Error syncing the cash flows of trades after %d: %v", synced, err)
+		http.Error(w, "Could not sync the cash flows of trades", http.StatusInternalServerError)
+		return
+	}
+	if synced > 0 {
+		log.Printf("Booked the fees and taxes of %d trades on their wallets", synced)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]int{"synced": synced})
+}
@@ -2,8 +2,11 @@ package httpadapter
 
 import (
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/fim-lab/expense-tracker/internal/core/domain"
@@ -124,6 +127,15 @@ func (h *TransactionHandler) SearchTransactions(w http.ResponseWriter, r *http.R
 		}
 	}
 
+	if tagsStr := query.Get("tags"); tagsStr != "" {
+		for _, tag := range strings.Split(tagsStr, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				criteria.Tags = append(criteria.Tags, tag)
+			}
+		}
+		criteria.AllTags = query.Get("tag_match") == "all"
+	}
+
 	result, err := h.service.Search(userID, criteria)
 	if err != nil {
 		http.Error(w, "Failed to search transactions", http.StatusInternalServerError)
@@ -148,6 +160,10 @@ func (h *TransactionHandler) CreateTransaction(w http.ResponseWriter, r *http.Re
 
 	_, err = h.service.CreateTransaction(userID, transaction)
 	if err != nil {
+		if errors.Is(err, domain.ErrInvalidSplit) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 		http.Error(w, "Error creating transaction", http.StatusInternalServerError)
 		return
 	}
@@ -169,7 +185,7 @@ func (h *TransactionHandler) Transfer(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err := h.service.CreateTransfer(userID, req.FromWalletID, req.ToWalletID, req.Amount)
+	err := h.service.CreateTransfer(userID, req.FromWalletID, req.ToWalletID, req.Amount, time.Now())
 	if err != nil {
 		if err == domain.ErrSameWalletTransfer || err == domain.ErrInvalidAmount {
 			http.Error(w, err.Error(), http.StatusBadRequest)
@@ -186,6 +202,29 @@ func (h *TransactionHandler) Transfer(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// SettleTransactions books the pending transactions the user may change among
+// the given ones and reports how many were settled.
+func (h *TransactionHandler) SettleTransactions(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value("userID").(int)
+	var req struct {
+		TransactionIDs []int `json:"transactionIds"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	settled, err := h.service.SettleTransactions(userID, req.TransactionIDs)
+	if err != nil {
+		http.Error(w, "Error settling transactions", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]int{"settled": settled})
+}
+
 func (h *TransactionHandler) UpdateTransaction(w http.ResponseWriter, r *http.Request) {
 	userID := r.Context().Value("userID").(int)
 	transactionIDStr := chi.URLParam(r, "id")
@@ -216,6 +255,14 @@ func (h *TransactionHandler) UpdateTransaction(w http.ResponseWriter, r *http.Re
 			http.Error(w, "Unauthorized", http.StatusForbidden)
 			return
 		}
+		if errors.Is(err, domain.ErrInvalidSplit) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if errors.Is(err, domain.ErrTransactionReconciled) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
 		http.Error(w, "Error updating transaction", http.StatusInternalServerError)
 		return
 	}
@@ -240,6 +287,10 @@ func (h *TransactionHandler) DeleteTransaction(w http.ResponseWriter, r *http.Re
 
 	err = h.service.DeleteTransaction(userID, transactionID)
 	if err != nil {
+		if errors.Is(err, domain.ErrTransactionReconciled) || errors.Is(err, domain.ErrSettlementConfirmed) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
 		http.Error(w, "Error deleting transaction", http.StatusInternalServerError)
 		return
 	}
@@ -275,35 +326,159 @@ func (h *TransactionHandler) GetTransaction(w http.ResponseWriter, r *http.Reque
 	json.NewEncoder(w).Encode(transaction)
 }
 
-func (h *TransactionHandler) ImportTransactions(w http.ResponseWriter, r *http.Request) {
-	userID := r.Context().Value("userID").(int)
-
+// readUploadedFile reads the file uploaded as the "file" form field.
+func readUploadedFile(w http.ResponseWriter, r *http.Request) ([]byte, bool) {
 	if err := r.ParseMultipartForm(10 << 20); err != nil { // 10 MB
 		http.Error(w, "Unable to parse form", http.StatusBadRequest)
-		return
+		return nil, false
 	}
 
 	file, _, err := r.FormFile("file")
 	if err != nil {
 		http.Error(w, "Unable to get file from form", http.StatusBadRequest)
-		return
+		return nil, false
 	}
 	defer file.Close()
 
+	content, err := io.ReadAll(file)
+	if err != nil {
+		http.Error(w, "Unable to read file", http.StatusBadRequest)
+		return nil, false
+	}
+	return content, true
+}
+
+// readImportFile decodes the JSON export uploaded as the "file" form field.
+func readImportFile(w http.ResponseWriter, r *http.Request) (domain.FullImportData, bool) {
+	content, ok := readUploadedFile(w, r)
+	if !ok {
+		return domain.FullImportData{}, false
+	}
+
 	var data domain.FullImportData
-	if err := json.NewDecoder(file).Decode(&data); err != nil {
+	if err := json.Unmarshal(content, &data); err != nil {
+		http.Error(w, "Invalid JSON format: "+err.Error(), http.StatusBadRequest)
+		return domain.FullImportData{}, false
+	}
+	return data, true
+}
+
+func writeImportResult(w http.ResponseWriter, data domain.FullImportData, result domain.ImportResult) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(struct {
+		Count int `json:"count"`
+		domain.ImportResult
+	}{len(data.Transactions), result})
+}
+
+// ImportTransactions takes both the account export, recognized by its
+// version, and the older settings and transactions format.
+func (h *TransactionHandler) ImportTransactions(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value("userID").(int)
+
+	content, ok := readUploadedFile(w, r)
+	if !ok {
+		return
+	}
+
+	var probe struct {
+		Version int `json:"version"`
+	}
+	if err := json.Unmarshal(content, &probe); err != nil {
 		http.Error(w, "Invalid JSON format: "+err.Error(), http.StatusBadRequest)
 		return
 	}
+	if probe.Version != 0 {
+		h.importAccount(w, userID, content)
+		return
+	}
 
-	err = h.importService.ImportData(userID, data)
+	var data domain.FullImportData
+	if err := json.Unmarshal(content, &data); err != nil {
+		http.Error(w, "Invalid JSON format: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.importService.ImportData(userID, data)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
+	writeImportResult(w, data, result)
+}
+
+func (h *TransactionHandler) importAccount(w http.ResponseWriter, userID int, content []byte) {
+	var export domain.AccountExport
+	if err := json.Unmarshal(content, &export); err != nil {
+		http.Error(w, "Invalid JSON format: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.importService.ImportAccount(userID, export)
+	switch {
+	case errors.Is(err, domain.ErrUnsupportedExportVersion), errors.Is(err, domain.ErrInvalidAccountExport):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case errors.Is(err, domain.ErrAccountNotEmpty):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]int{"count": len(data.Transactions)})
+	json.NewEncoder(w).Encode(struct {
+		Count int `json:"count"`
+		domain.ImportResult
+	}{len(export.Transactions), result})
+}
+
+// PreviewImport reports what importing the file would do, without writing
+// anything. The returned token confirms exactly this plan.
+func (h *TransactionHandler) PreviewImport(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value("userID").(int)
+
+	data, ok := readImportFile(w, r)
+	if !ok {
+		return
+	}
+
+	preview, err := h.importService.PreviewImport(userID, data)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(preview)
+}
+
+func (h *TransactionHandler) ConfirmImport(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value("userID").(int)
+
+	data, ok := readImportFile(w, r)
+	if !ok {
+		return
+	}
+	token := r.FormValue("token")
+	if token == "" {
+		http.Error(w, "token is required", http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.importService.ConfirmImport(userID, data, token)
+	if errors.Is(err, domain.ErrImportPlanChanged) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeImportResult(w, data, result)
 }
 
 func (h *TransactionHandler) ImportTestData(w http.ResponseWriter, r *http.Request) {
@@ -318,6 +493,23 @@ func (h *TransactionHandler) ImportTestData(w http.ResponseWriter, r *http.Reque
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// ExportAccount downloads everything the user owns in the format
+// ImportTransactions reads back.
+func (h *TransactionHandler) ExportAccount(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value("userID").(int)
+
+	export, err := h.importService.ExportAccount(userID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	filename := "expense-tracker-export-" + export.ExportedAt.Format("2006-01-02") + ".json"
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+	json.NewEncoder(w).Encode(export)
+}
+
 func (h *TransactionHandler) DeleteAllUserData(w http.ResponseWriter, r *http.Request) {
 	userID := r.Context().Value("userID").(int)
 
@@ -2,8 +2,10 @@ package httpadapter
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/fim-lab/expense-tracker/internal/core/domain"
 	"github.com/fim-lab/expense-tracker/internal/core/ports"
@@ -88,6 +90,37 @@ func (h *TransactionTemplateHandler) GetTransactionTemplateByID(w http.ResponseW
 	json.NewEncoder(w).Encode(tt)
 }
 
+func (h *TransactionTemplateHandler) GetUpcomingTransactions(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(int)
+	if !ok {
+		http.Error(w, "invalid user ID", http.StatusUnauthorized)
+		return
+	}
+
+	until := time.Now().AddDate(0, 1, 0)
+	if untilStr := r.URL.Query().Get("until"); untilStr != "" {
+		parsed, err := time.Parse("2006-01-02", untilStr)
+		if err != nil {
+			http.Error(w, "invalid until date, expected YYYY-MM-DD", http.StatusBadRequest)
+			return
+		}
+		until = parsed
+	}
+
+	upcoming, err := h.transactionTemplateService.GetUpcomingTransactions(userID, until)
+	if errors.Is(err, domain.ErrInvalidDateRange) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(upcoming)
+}
+
 func (h *TransactionTemplateHandler) UpdateTransactionTemplate(w http.ResponseWriter, r *http.Request) {
 	userID, ok := r.Context().Value("userID").(int)
 	if !ok {
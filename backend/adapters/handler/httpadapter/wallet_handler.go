@@ -2,9 +2,11 @@ package httpadapter
 
 import (
 	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/fim-lab/expense-tracker/internal/core/domain"
 	"github.com/fim-lab/expense-tracker/internal/core/ports"
@@ -19,6 +21,24 @@ func NewWalletHandler(service *ports.WalletService) *WalletHandler {
 	return &WalletHandler{service: *service}
 }
 
+func writeWalletError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, domain.ErrWalletNotFound),
+		errors.Is(err, domain.ErrMissingWallet),
+		errors.Is(err, domain.ErrInvalidWalletType),
+		errors.Is(err, domain.ErrInvalidStatementCycle),
+		errors.Is(err, domain.ErrSameWalletTransfer),
+		errors.Is(err, domain.ErrInvalidDateRange):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, domain.ErrNothingToSettle):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, domain.ErrUnauthorized):
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+	default:
+		http.Error(w, fallback, http.StatusInternalServerError)
+	}
+}
+
 func (h *WalletHandler) GetWallets(w http.ResponseWriter, r *http.Request) {
 	userID, ok := r.Context().Value("userID").(int)
 	if !ok {
@@ -59,7 +79,7 @@ func (h *WalletHandler) CreateWallet(w http.ResponseWriter, r *http.Request) {
 	err = h.service.CreateWallet(userID, wallet)
 	if err != nil {
 		log.Printf("Error creating wallet: %v", err)
-		http.Error(w, "Error creating wallet", http.StatusInternalServerError)
+		writeWalletError(w, err, "Error creating wallet")
 		return
 	}
 
@@ -166,16 +186,106 @@ func (h *WalletHandler) UpdateWallet(w http.ResponseWriter, r *http.Request) {
 	err = h.service.UpdateWallet(userID, wallet)
 	if err != nil {
 		log.Printf("Error updating wallet %d for user %d: %v", id, userID, err)
-		switch err {
-		case domain.ErrWalletNotFound, domain.ErrMissingWallet:
-			http.Error(w, err.Error(), http.StatusBadRequest)
-		case domain.ErrUnauthorized:
-			http.Error(w, "Unauthorized", http.StatusUnauthorized)
-		default:
-			http.Error(w, "Could not update wallet", http.StatusInternalServerError)
-		}
+		writeWalletError(w, err, "Could not update wallet")
 		return
 	}
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// SettleStatement pays a credit card's last statement from its payment
+// wallet and returns the card.
+func (h *WalletHandler) SettleStatement(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(w, r)
+	if !ok {
+		return
+	}
+	id, ok := idFromURL(w, r, "id")
+	if !ok {
+		return
+	}
+
+	card, err := h.service.SettleStatement(userID, id)
+	if err != nil {
+		log.Printf("Error settling the statement of wallet %d for user %d: %v", id, userID, err)
+		writeWalletError(w, err, "Could not settle statement")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(card)
+}
+
+// dateFromQuery reads a date like 2006-01-02 from the query, or returns the
+// fallback if it is not given.
+func dateFromQuery(w http.ResponseWriter, r *http.Request, name string, fallback time.Time) (time.Time, bool) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return fallback, true
+	}
+	date, err := time.Parse("2006-01-02", raw)
+	if err != nil {
+		http.Error(w, name+" must be a date like 2006-01-02", http.StatusBadRequest)
+		return time.Time{}, false
+	}
+	return date, true
+}
+
+// GetBalanceHistory returns the wallet's balance at the end of every day,
+// week or month ("interval", by default day) between "from" and "until". It
+// covers the last month up to today unless asked otherwise.
+func (h *WalletHandler) GetBalanceHistory(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(w, r)
+	if !ok {
+		return
+	}
+	id, ok := idFromURL(w, r, "id")
+	if !ok {
+		return
+	}
+	until, ok := dateFromQuery(w, r, "until", time.Now())
+	if !ok {
+		return
+	}
+	from, ok := dateFromQuery(w, r, "from", until.AddDate(0, -1, 0))
+	if !ok {
+		return
+	}
+	interval := domain.BalanceInterval(r.URL.Query().Get("interval"))
+	if interval == "" {
+		interval = domain.BalanceDaily
+	}
+
+	history, err := h.service.GetBalanceHistory(userID, id, from, until, interval)
+	if err != nil {
+		log.Printf("Error fetching balance history of wallet %d for user %d: %v", id, userID, err)
+		writeWalletError(w, err, "Could not fetch balance history")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(history)
+}
+
+// GetBalances returns the balances of all wallets at the end of the day
+// given as "at", by default today.
+func (h *WalletHandler) GetBalances(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromContext(w, r)
+	if !ok {
+		return
+	}
+	at, ok := dateFromQuery(w, r, "at", time.Now())
+	if !ok {
+		return
+	}
+
+	balances, err := h.service.GetBalancesAt(userID, at)
+	if err != nil {
+		log.Printf("Error fetching wallet balances for user %d: %v", userID, err)
+		http.Error(w, "Could not fetch wallet balances", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(balances)
+}
@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/fim-lab/expense-tracker/internal/core/ports"
+)
+
+// AdminMiddleware lets only admins through. It has to run after the
+// middleware that puts the user into the context.
+type AdminMiddleware struct {
+	service ports.UserService
+}
+
+func NewAdminMiddleware(service *ports.UserService) *AdminMiddleware {
+	return &AdminMiddleware{
+		service: *service,
+	}
+}
+
+func (am *AdminMiddleware) Handle(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := r.Context().Value("userID").(int)
+		if !ok {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		user, err := am.service.GetUserByID(userID)
+		if err != nil {
+			log.Printf("Could not look up user %d for an admin request: %v", userID, err)
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		if !user.IsAdmin {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,176 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fim-lab/expense-tracker/internal/core/domain"
+)
+
+// The camt.053 elements we read. Tags carry no namespace, so all versions of
+// the schema (camt.053.001.02 to .08) match.
+type camtDocument struct {
+	Statements []camtStatement `xml:"BkToCstmrStmt>Stmt"`
+}
+
+type camtStatement struct {
+	IBAN     string        `xml:"Acct>Id>IBAN"`
+	Balances []camtBalance `xml:"Bal"`
+	Entries  []camtEntry   `xml:"Ntry"`
+}
+
+type camtBalance struct {
+	Code      string `xml:"Tp>CdOrPrtry>Cd"`
+	Amount    string `xml:"Amt"`
+	Indicator string `xml:"CdtDbtInd"`
+}
+
+type camtEntry struct {
+	Reference       string                   `xml:"NtryRef"`
+	Amount          string                   `xml:"Amt"`
+	Indicator       string                   `xml:"CdtDbtInd"`
+	Status          camtStatus               `xml:"Sts"`
+	BookingDate     string                   `xml:"BookgDt>Dt"`
+	BookingDateTime string                   `xml:"BookgDt>DtTm"`
+	ServicerRef     string                   `xml:"AcctSvcrRef"`
+	AdditionalInfo  string                   `xml:"AddtlNtryInf"`
+	Details         []camtTransactionDetails `xml:"NtryDtls>TxDtls"`
+}
+
+// camtStatus is plain text up to camt.053.001.04 and a <Cd> element after.
+type camtStatus struct {
+	Text string `xml:",chardata"`
+	Code string `xml:"Cd"`
+}
+
+type camtTransactionDetails struct {
+	ServicerRef   string   `xml:"Refs>AcctSvcrRef"`
+	Debtor        string   `xml:"RltdPties>Dbtr>Nm"`
+	DebtorParty   string   `xml:"RltdPties>Dbtr>Pty>Nm"`
+	Creditor      string   `xml:"RltdPties>Cdtr>Nm"`
+	CreditorParty string   `xml:"RltdPties>Cdtr>Pty>Nm"`
+	Remittance    []string `xml:"RmtInf>Ustrd"`
+}
+
+// ParseCAMT053 reads an ISO 20022 camt.053 account statement. Only booked
+// entries are returned; pending ones show up booked in a later statement.
+func (p *StatementParser) ParseCAMT053(data []byte) (domain.Statement, error) {
+	var doc camtDocument
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.CharsetReader = charsetReader
+	if err := decoder.Decode(&doc); err != nil {
+		return domain.Statement{}, fmt.Errorf("%w: %v", domain.ErrInvalidStatement, err)
+	}
+	if len(doc.Statements) == 0 {
+		return domain.Statement{}, fmt.Errorf("%w: no camt.053 statement found", domain.ErrInvalidStatement)
+	}
+
+	var statement domain.Statement
+	for i, stmt := range doc.Statements {
+		if stmt.IBAN != doc.Statements[0].IBAN {
+			return domain.Statement{}, fmt.Errorf("%w: file contains statements of several accounts", domain.ErrInvalidStatement)
+		}
+
+		for _, bal := range stmt.Balances {
+			cents, err := camtAmount(bal.Amount, bal.Indicator)
+			if err != nil {
+				return domain.Statement{}, err
+			}
+			switch strings.TrimSpace(bal.Code) {
+			case "OPBD", "PRCD":
+				if i == 0 {
+					statement.OpeningBalanceCents = &cents
+				}
+			case "CLBD":
+				statement.ClosingBalanceCents = &cents
+			}
+		}
+
+		for _, entry := range stmt.Entries {
+			if entry.Status.value() != "BOOK" {
+				continue
+			}
+			row, err := camtTransaction(entry)
+			if err != nil {
+				return domain.Statement{}, err
+			}
+			statement.Transactions = append(statement.Transactions, row)
+		}
+	}
+
+	return statement, nil
+}
+
+func (s camtStatus) value() string {
+	if s.Code != "" {
+		return strings.TrimSpace(s.Code)
+	}
+	return strings.TrimSpace(s.Text)
+}
+
+func camtTransaction(entry camtEntry) (domain.ImportTransaction, error) {
+	cents, err := camtAmount(entry.Amount, entry.Indicator)
+	if err != nil {
+		return domain.ImportTransaction{}, err
+	}
+
+	rawDate := strings.TrimSpace(entry.BookingDate)
+	if rawDate == "" && len(entry.BookingDateTime) >= 10 {
+		rawDate = entry.BookingDateTime[:10]
+	}
+	date, err := time.Parse("2006-01-02", rawDate)
+	if err != nil {
+		return domain.ImportTransaction{}, fmt.Errorf("%w: booking date %q is not valid", domain.ErrInvalidStatement, rawDate)
+	}
+
+	reference := strings.TrimSpace(entry.ServicerRef)
+	description := strings.TrimSpace(entry.AdditionalInfo)
+	if len(entry.Details) == 1 {
+		details := entry.Details[0]
+		if reference == "" {
+			reference = strings.TrimSpace(details.ServicerRef)
+		}
+
+		counterparty := firstNonEmpty(details.Debtor, details.DebtorParty)
+		if cents < 0 {
+			counterparty = firstNonEmpty(details.Creditor, details.CreditorParty)
+		}
+		if text := joinFields(append([]string{counterparty}, details.Remittance...)...); text != "" {
+			description = text
+		}
+	}
+	if reference == "" {
+		reference = strings.TrimSpace(entry.Reference)
+	}
+	if description == "" {
+		description = "camt.053"
+	}
+
+	return domain.ImportTransaction{
+		ID:            reference,
+		Date:          date,
+		Description:   description,
+		AmountInCents: abs(cents),
+		Type:          string(transactionType(cents)),
+	}, nil
+}
+
+// camtAmount reads an unsigned amount like "1234.56" and signs it by its
+// credit/debit indicator.
+func camtAmount(amount, indicator string) (int, error) {
+	cents, err := parseCents(amount, ".")
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", domain.ErrInvalidStatement, err)
+	}
+	switch strings.TrimSpace(indicator) {
+	case "CRDT":
+		return abs(cents), nil
+	case "DBIT":
+		return -abs(cents), nil
+	default:
+		return 0, fmt.Errorf("%w: credit/debit indicator %q is not valid", domain.ErrInvalidStatement, indicator)
+	}
+}
@@ -0,0 +1,99 @@
+package importer
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/fim-lab/expense-tracker/internal/core/domain"
+)
+
+const testCAMT053 = `<?xml version="1.0" encoding="ISO-8859-1"?>
+<Document xmlns="urn:iso:std:iso:20022:tech:xsd:camt.053.001.02">
+  <BkToCstmrStmt>
+    <Stmt>
+      <Acct><Id><IBAN>DE02120300000000202051</IBAN></Id></Acct>
+      <Bal>
+        <Tp><CdOrPrtry><Cd>PRCD</Cd></CdOrPrtry></Tp>
+        <Amt Ccy="EUR">1000.00</Amt><CdtDbtInd>CRDT</CdtDbtInd>
+      </Bal>
+      <Bal>
+        <Tp><CdOrPrtry><Cd>CLBD</Cd></CdOrPrtry></Tp>
+        <Amt Ccy="EUR">2950.00</Amt><CdtDbtInd>CRDT</CdtDbtInd>
+      </Bal>
+      <Ntry>
+        <Amt Ccy="EUR">50.00</Amt>
+        <CdtDbtInd>DBIT</CdtDbtInd>
+        <Sts>BOOK</Sts>
+        <BookgDt><Dt>2026-03-02</Dt></BookgDt>
+        <AcctSvcrRef>2026030200001</AcctSvcrRef>
+        <NtryDtls><TxDtls>
+          <RltdPties><Dbtr><Nm>Max Mustermann</Nm></Dbtr><Cdtr><Nm>Caf` + "\xe9" + ` Bohne</Nm></Cdtr></RltdPties>
+          <RmtInf><Ustrd>Rechnung
+            4711</Ustrd></RmtInf>
+        </TxDtls></NtryDtls>
+      </Ntry>
+      <Ntry>
+        <NtryRef>GEHALT-03</NtryRef>
+        <Amt Ccy="EUR">2000.00</Amt>
+        <CdtDbtInd>CRDT</CdtDbtInd>
+        <Sts>BOOK</Sts>
+        <BookgDt><DtTm>2026-03-27T08:00:00</DtTm></BookgDt>
+        <AddtlNtryInf>Gehalt</AddtlNtryInf>
+      </Ntry>
+      <Ntry>
+        <Amt Ccy="EUR">9.99</Amt>
+        <CdtDbtInd>DBIT</CdtDbtInd>
+        <Sts>PDNG</Sts>
+        <BookgDt><Dt>2026-03-31</Dt></BookgDt>
+      </Ntry>
+    </Stmt>
+  </BkToCstmrStmt>
+</Document>`
+
+func TestParseCAMT053(t *testing.T) {
+	statement, err := NewStatementParser().ParseCAMT053([]byte(testCAMT053))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if statement.OpeningBalanceCents == nil || *statement.OpeningBalanceCents != 100000 {
+		t.Errorf("expected an opening balance of 100000, got %v", statement.OpeningBalanceCents)
+	}
+	if statement.ClosingBalanceCents == nil || *statement.ClosingBalanceCents != 295000 {
+		t.Errorf("expected a closing balance of 295000, got %v", statement.ClosingBalanceCents)
+	}
+
+	rows := statement.Transactions
+	if len(rows) != 2 {
+		t.Fatalf("expected the pending entry to be skipped, got %+v", rows)
+	}
+	want := domain.ImportTransaction{
+		ID:            "2026030200001",
+		Date:          time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC),
+		Description:   "Café Bohne Rechnung 4711",
+		AmountInCents: 5000,
+		Type:          string(domain.Expense),
+	}
+	if rows[0] != want {
+		t.Errorf("expected %+v, got %+v", want, rows[0])
+	}
+	if rows[1].ID != "GEHALT-03" || rows[1].Description != "Gehalt" || rows[1].Type != string(domain.Income) ||
+		!rows[1].Date.Equal(time.Date(2026, 3, 27, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected the salary with its entry reference, got %+v", rows[1])
+	}
+}
+
+func TestParseCAMT053_Invalid(t *testing.T) {
+	for name, data := range map[string]string{
+		"not xml":      "Buchungstag;Betrag",
+		"no statement": `<Document><BkToCstmrStmt></BkToCstmrStmt></Document>`,
+		"bad amount":   `<Document><BkToCstmrStmt><Stmt><Ntry><Amt>x</Amt><CdtDbtInd>DBIT</CdtDbtInd><Sts>BOOK</Sts></Ntry></Stmt></BkToCstmrStmt></Document>`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := NewStatementParser().ParseCAMT053([]byte(data)); !errors.Is(err, domain.ErrInvalidStatement) {
+				t.Errorf("expected ErrInvalidStatement, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package importer
+
+import (
+	"encoding/csv"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/fim-lab/expense-tracker/internal/core/domain"
+)
+
+// ParseCSV reads a bank's CSV export with the given profile. Banks put account
+// details above the actual table, so everything before the first line that
+// names the profile's date and amount columns is ignored. Below it, rows
+// without a booking date (footers, "offen" for not yet booked entries) and
+// rows of zero are skipped.
+func (p *StatementParser) ParseCSV(data []byte, profile domain.CSVProfile) (domain.Statement, error) {
+	profile.ApplyDefaults()
+	if err := profile.Validate(); err != nil {
+		return domain.Statement{}, err
+	}
+	layout, _ := profile.DateLayout()
+
+	text, err := decode(data, profile.Encoding)
+	if err != nil {
+		return domain.Statement{}, err
+	}
+
+	reader := csv.NewReader(strings.NewReader(text))
+	reader.Comma = []rune(profile.Delimiter)[0]
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return domain.Statement{}, fmt.Errorf("%w: %v", domain.ErrInvalidStatement, err)
+	}
+
+	headerLine := -1
+	var columns map[string]int
+	for i, record := range records {
+		columns = columnIndex(record)
+		if _, ok := columns[profile.DateColumn]; !ok {
+			continue
+		}
+		if _, ok := columns[profile.AmountColumn]; ok {
+			headerLine = i
+			break
+		}
+	}
+	if headerLine < 0 {
+		return domain.Statement{}, fmt.Errorf("%w: no header with the columns %q and %q found", domain.ErrInvalidStatement, profile.DateColumn, profile.AmountColumn)
+	}
+
+	wanted := append([]string{profile.DateColumn, profile.AmountColumn}, profile.DescriptionColumns...)
+	if profile.SignConvention == domain.DebitIndicator {
+		wanted = append(wanted, profile.IndicatorColumn)
+	}
+	if profile.ReferenceColumn != "" {
+		wanted = append(wanted, profile.ReferenceColumn)
+	}
+	for _, name := range wanted {
+		if _, ok := columns[name]; !ok {
+			return domain.Statement{}, fmt.Errorf("%w: column %q not found", domain.ErrInvalidStatement, name)
+		}
+	}
+
+	field := func(record []string, name string) string {
+		if i := columns[name]; i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	var rows []domain.ImportTransaction
+	for i, record := range records[headerLine+1:] {
+		line := headerLine + i + 2
+
+		rawDate := field(record, profile.DateColumn)
+		if !strings.ContainsFunc(rawDate, unicode.IsDigit) {
+			continue
+		}
+		date, err := time.Parse(layout, rawDate)
+		if err != nil {
+			return domain.Statement{}, fmt.Errorf("%w: line %d: date %q does not match %s", domain.ErrInvalidStatement, line, rawDate, profile.DateFormat)
+		}
+
+		cents, err := parseCents(field(record, profile.AmountColumn), profile.DecimalSeparator)
+		if err != nil {
+			return domain.Statement{}, fmt.Errorf("%w: line %d: %v", domain.ErrInvalidStatement, line, err)
+		}
+		switch profile.SignConvention {
+		case domain.PositiveIsExpense:
+			cents = -cents
+		case domain.DebitIndicator:
+			cents = abs(cents)
+			if strings.EqualFold(field(record, profile.IndicatorColumn), profile.DebitIndicator) {
+				cents = -cents
+			}
+		}
+		if cents == 0 {
+			continue
+		}
+
+		var parts []string
+		for _, name := range profile.DescriptionColumns {
+			parts = append(parts, field(record, name))
+		}
+		description := joinFields(parts...)
+		if description == "" {
+			description = profile.Name
+		}
+
+		row := domain.ImportTransaction{
+			Date:          date,
+			Description:   description,
+			AmountInCents: abs(cents),
+			Type:          string(transactionType(cents)),
+		}
+		if profile.ReferenceColumn != "" {
+			row.ID = field(record, profile.ReferenceColumn)
+		}
+		rows = append(rows, row)
+	}
+
+	return domain.Statement{Transactions: rows}, nil
+}
+
+// columnIndex maps the names in a header row to their position. Names that
+// occur twice, like ING's two "Währung" columns, keep the first position.
+func columnIndex(header []string) map[string]int {
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.TrimSpace(name)
+		if _, exists := columns[name]; !exists && name != "" {
+			columns[name] = i
+		}
+	}
+	return columns
+}
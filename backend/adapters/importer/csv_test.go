@@ -0,0 +1,153 @@
+package importer
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/fim-lab/expense-tracker/internal/core/domain"
+)
+
+func builtIn(t *testing.T, name string) domain.CSVProfile {
+	t.Helper()
+	for _, p := range NewStatementParser().BuiltInCSVProfiles() {
+		if p.Name == name {
+			return p
+		}
+	}
+	t.Fatalf("no built-in profile %q", name)
+	return domain.CSVProfile{}
+}
+
+func TestParseCSV_DKB(t *testing.T) {
+	data := []byte("\uFEFF\"Girokonto\";\"DE02120300000000202051\"\n" +
+		"\"Kontostand vom 31.03.2026:\";\"1.234,56 €\"\n" +
+		"\"\"\n" +
+		"\"Buchungsdatum\";\"Wertstellung\";\"Status\";\"Zahlungspflichtige*r\";\"Zahlungsempfänger*in\";\"Verwendungszweck\";\"Umsatztyp\";\"IBAN\";\"Betrag (€)\"\n" +
+		"\"31.03.26\";\"31.03.26\";\"Gebucht\";\"Max Mustermann\";\"REWE Markt\";\"Einkauf  vom 30.03.\";\"Ausgang\";\"DE123\";\"-1.234,56 €\"\n" +
+		"\"28.03.26\";\"28.03.26\";\"Gebucht\";\"Arbeitgeber GmbH\";\"Max Mustermann\";\"Gehalt März\";\"Eingang\";\"DE456\";\"2.500\"\n")
+
+	statement, err := NewStatementParser().ParseCSV(data, builtIn(t, "DKB"))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	rows := statement.Transactions
+	if len(rows) != 2 {
+		t.Fatalf("expected 2 rows, got %+v", rows)
+	}
+
+	want := domain.ImportTransaction{
+		Date:          time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC),
+		Description:   "REWE Markt Einkauf vom 30.03.",
+		AmountInCents: 123456,
+		Type:          string(domain.Expense),
+	}
+	if rows[0] != want {
+		t.Errorf("expected %+v, got %+v", want, rows[0])
+	}
+	if rows[1].Type != string(domain.Income) || rows[1].AmountInCents != 250000 {
+		t.Errorf("expected an income of 250000, got %+v", rows[1])
+	}
+}
+
+func TestParseCSV_INGInLatin1(t *testing.T) {
+	// "Empfänger" and "Gebühr" in ISO-8859-1
+	data := []byte("Umsatzanzeige;Datei erstellt am: 01.04.2026\n\n" +
+		"Buchung;Valuta;Auftraggeber/Empf\xe4nger;Buchungstext;Verwendungszweck;Saldo;W\xe4hrung;Betrag;W\xe4hrung\n" +
+		"02.03.2026;02.03.2026;Bank;Entgelt;Kontof\xfchrungsgeb\xfchr;100,00;EUR;-4,90;EUR\n")
+
+	statement, err := NewStatementParser().ParseCSV(data, builtIn(t, "ING"))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	rows := statement.Transactions
+	if len(rows) != 1 || rows[0].Description != "Bank Kontoführungsgebühr" || rows[0].AmountInCents != 490 {
+		t.Errorf("expected the fee to be decoded from ISO-8859-1, got %+v", rows)
+	}
+}
+
+func TestParseCSV_SkipsPendingAndFooterRows(t *testing.T) {
+	data := []byte("\"Umsätze Girokonto\";\"Zeitraum: 30 Tage\";\n\n" +
+		"\"Buchungstag\";\"Wertstellung (Valuta)\";\"Vorgang\";\"Buchungstext\";\"Umsatz in EUR\";\n" +
+		"\"offen\";\"--\";\"Kartenzahlung\";\"Tankstelle\";\"-50,00\";\n" +
+		"\"15.03.2026\";\"15.03.2026\";\"Lastschrift / Belastung\";\"Stadtwerke\";\"-80,00\";\n" +
+		"\n\"Alter Kontostand\";\"1.000,00 EUR\";\n")
+
+	statement, err := NewStatementParser().ParseCSV(data, builtIn(t, "comdirect"))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	rows := statement.Transactions
+	if len(rows) != 1 || rows[0].Description != "Lastschrift / Belastung Stadtwerke" {
+		t.Errorf("expected only the booked row, got %+v", rows)
+	}
+}
+
+func TestParseCSV_DebitIndicator(t *testing.T) {
+	profile := domain.CSVProfile{
+		Name:               "Volksbank",
+		Delimiter:          ",",
+		DateColumn:         "Datum",
+		DateFormat:         "YYYY-MM-DD",
+		DescriptionColumns: []string{"Text"},
+		AmountColumn:       "Betrag",
+		DecimalSeparator:   ".",
+		SignConvention:     domain.DebitIndicator,
+		IndicatorColumn:    "S/H",
+		DebitIndicator:     "S",
+		ReferenceColumn:    "Referenz",
+	}
+	data := []byte("Datum,Text,Betrag,S/H,Referenz\n" +
+		"2026-03-01,Miete,\"1,050.00\",S,REF-1\n" +
+		"2026-03-02,Erstattung,12.5,H,REF-2\n")
+
+	statement, err := NewStatementParser().ParseCSV(data, profile)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	rows := statement.Transactions
+	if len(rows) != 2 {
+		t.Fatalf("expected 2 rows, got %+v", rows)
+	}
+	if rows[0].Type != string(domain.Expense) || rows[0].AmountInCents != 105000 || rows[0].ID != "REF-1" {
+		t.Errorf("expected a debit of 105000 with reference REF-1, got %+v", rows[0])
+	}
+	if rows[1].Type != string(domain.Income) || rows[1].AmountInCents != 1250 {
+		t.Errorf("expected a credit of 1250, got %+v", rows[1])
+	}
+}
+
+func TestParseCSV_InvalidFiles(t *testing.T) {
+	tests := map[string][]byte{
+		"no header":    []byte("foo;bar\n1;2\n"),
+		"bad date":     []byte("Buchung;Auftraggeber/Empf\xe4nger;Verwendungszweck;Betrag\n2026-03-01;A;B;-1,00\n"),
+		"bad amount":   []byte("Buchung;Auftraggeber/Empf\xe4nger;Verwendungszweck;Betrag\n01.03.2026;A;B;zwölf\n"),
+		"missing text": []byte("Buchung;Betrag\n01.03.2026;-1,00\n"),
+	}
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewStatementParser().ParseCSV(data, builtIn(t, "ING"))
+			if !errors.Is(err, domain.ErrInvalidStatement) {
+				t.Errorf("expected ErrInvalidStatement, got %v", err)
+			}
+		})
+	}
+}
+
+func TestParseCents(t *testing.T) {
+	tests := map[string]int{
+		"-1.234,56 €": -123456,
+		"+0,5":        50,
+		"12":          1200,
+		"1 000,00":    100000,
+	}
+	for raw, want := range tests {
+		got, err := parseCents(raw, ",")
+		if err != nil || got != want {
+			t.Errorf("parseCents(%q) = %d, %v; want %d", raw, got, err, want)
+		}
+	}
+	if _, err := parseCents("1,234", ","); err == nil {
+		t.Error("expected three decimal places to be rejected")
+	}
+}
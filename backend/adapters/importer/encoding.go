@@ -0,0 +1,68 @@
+package importer
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/fim-lab/expense-tracker/internal/core/domain"
+)
+
+// windows1252 holds the characters Windows-1252 puts where ISO-8859-1 has
+// control codes. The five unassigned bytes fall back to ISO-8859-1.
+var windows1252 = map[byte]rune{
+	0x80: '€', 0x82: '‚', 0x83: 'ƒ', 0x84: '„', 0x85: '…', 0x86: '†', 0x87: '‡',
+	0x88: 'ˆ', 0x89: '‰', 0x8A: 'Š', 0x8B: '‹', 0x8C: 'Œ', 0x8E: 'Ž',
+	0x91: '‘', 0x92: '’', 0x93: '“', 0x94: '”', 0x95: '•', 0x96: '–', 0x97: '—',
+	0x98: '˜', 0x99: '™', 0x9A: 'š', 0x9B: '›', 0x9C: 'œ', 0x9E: 'ž', 0x9F: 'Ÿ',
+}
+
+// decode turns a file in the given encoding into a UTF-8 string without a
+// byte order mark.
+func decode(data []byte, encoding domain.Encoding) (string, error) {
+	switch encoding {
+	case domain.EncodingUTF8, "":
+		if !utf8.Valid(data) {
+			return "", fmt.Errorf("%w: file is not valid UTF-8, check the encoding", domain.ErrInvalidStatement)
+		}
+		return strings.TrimPrefix(string(data), "\uFEFF"), nil
+	case domain.EncodingLatin1, domain.EncodingWindows1252:
+		var b strings.Builder
+		b.Grow(len(data))
+		for _, c := range data {
+			if r, ok := windows1252[c]; ok && encoding == domain.EncodingWindows1252 {
+				b.WriteRune(r)
+				continue
+			}
+			b.WriteRune(rune(c))
+		}
+		return b.String(), nil
+	default:
+		return "", fmt.Errorf("unsupported encoding %q", encoding)
+	}
+}
+
+// charsetReader lets encoding/xml read documents declared as ISO-8859-1 or
+// Windows-1252.
+func charsetReader(charset string, input io.Reader) (io.Reader, error) {
+	var encoding domain.Encoding
+	switch strings.ToUpper(charset) {
+	case "ISO-8859-1", "ISO_8859-1", "LATIN1":
+		encoding = domain.EncodingLatin1
+	case "WINDOWS-1252", "CP1252":
+		encoding = domain.EncodingWindows1252
+	default:
+		return nil, fmt.Errorf("unsupported charset %q", charset)
+	}
+
+	data, err := io.ReadAll(input)
+	if err != nil {
+		return nil, err
+	}
+	text, err := decode(data, encoding)
+	if err != nil {
+		return nil, err
+	}
+	return strings.NewReader(text), nil
+}
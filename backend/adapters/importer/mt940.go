@@ -0,0 +1,201 @@
+package importer
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/fim-lab/expense-tracker/internal/core/domain"
+)
+
+var (
+	mt940Tag = regexp.MustCompile(`^:(\d{2}[A-Z]?):(.*)$`)
+	// :61: value date, optional booking date (MMDD), debit/credit mark with
+	// optional reversal and funds code, amount, transaction type, customer
+	// reference and, after //, the bank's reference.
+	mt940Transaction = regexp.MustCompile(`^(\d{6})(\d{4})?(R?[CD])[A-Z]?(\d+,\d{0,2})[NFS][A-Z0-9]{3}([^/]*)(?://(.*))?$`)
+	// :60F: and :62F: debit/credit mark, date, currency and amount.
+	mt940Balance = regexp.MustCompile(`^([CD])(\d{6})[A-Z]{3}(\d+,\d{0,2})$`)
+)
+
+type mt940Field struct {
+	tag   string
+	value string
+}
+
+// ParseMT940 reads a SWIFT MT940 statement file, which may hold several
+// consecutive statements of the same account.
+func (p *StatementParser) ParseMT940(data []byte) (domain.Statement, error) {
+	encoding := domain.EncodingUTF8
+	if !utf8.Valid(data) {
+		// German banks write their MT940 files in ISO-8859-1.
+		encoding = domain.EncodingLatin1
+	}
+	text, err := decode(data, encoding)
+	if err != nil {
+		return domain.Statement{}, err
+	}
+
+	fields := mt940Fields(text)
+	if len(fields) == 0 {
+		return domain.Statement{}, fmt.Errorf("%w: no MT940 fields found", domain.ErrInvalidStatement)
+	}
+
+	var statement domain.Statement
+	account := ""
+	described := true
+	for _, field := range fields {
+		firstLine, _, _ := strings.Cut(field.value, "\n")
+		switch field.tag {
+		case "25":
+			if account != "" && account != firstLine {
+				return domain.Statement{}, fmt.Errorf("%w: file contains statements of several accounts", domain.ErrInvalidStatement)
+			}
+			account = firstLine
+		case "60F", "60M":
+			cents, err := mt940BalanceCents(firstLine)
+			if err != nil {
+				return domain.Statement{}, err
+			}
+			if statement.OpeningBalanceCents == nil {
+				statement.OpeningBalanceCents = &cents
+			}
+		case "62F", "62M":
+			cents, err := mt940BalanceCents(firstLine)
+			if err != nil {
+				return domain.Statement{}, err
+			}
+			statement.ClosingBalanceCents = &cents
+		case "61":
+			row, err := mt940Row(firstLine)
+			if err != nil {
+				return domain.Statement{}, err
+			}
+			statement.Transactions = append(statement.Transactions, row)
+			described = false
+		case "86":
+			if described {
+				continue
+			}
+			if description := mt940Description(field.value); description != "" {
+				statement.Transactions[len(statement.Transactions)-1].Description = description
+			}
+			described = true
+		}
+	}
+
+	return statement, nil
+}
+
+// mt940Fields splits the file into its tagged fields. Lines without a tag
+// continue the previous field; the SWIFT block markers around statements are
+// dropped.
+func mt940Fields(text string) []mt940Field {
+	var fields []mt940Field
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		if match := mt940Tag.FindStringSubmatch(line); match != nil {
+			fields = append(fields, mt940Field{tag: match[1], value: match[2]})
+			continue
+		}
+		if len(fields) == 0 || line == "" || line == "-" || strings.HasPrefix(line, "-}") || strings.HasPrefix(line, "{") {
+			continue
+		}
+		fields[len(fields)-1].value += "\n" + line
+	}
+	return fields
+}
+
+func mt940Row(line string) (domain.ImportTransaction, error) {
+	match := mt940Transaction.FindStringSubmatch(line)
+	if match == nil {
+		return domain.ImportTransaction{}, fmt.Errorf("%w: transaction line %q is not valid", domain.ErrInvalidStatement, line)
+	}
+
+	valueDate, err := time.Parse("060102", match[1])
+	if err != nil {
+		return domain.ImportTransaction{}, fmt.Errorf("%w: date %q is not valid", domain.ErrInvalidStatement, match[1])
+	}
+	date := valueDate
+	if match[2] != "" {
+		// The booking date has no year; it is the value date's, unless the
+		// two lie on either side of New Year.
+		bookingDate, err := time.Parse("0102", match[2])
+		if err != nil {
+			return domain.ImportTransaction{}, fmt.Errorf("%w: booking date %q is not valid", domain.ErrInvalidStatement, match[2])
+		}
+		year := valueDate.Year()
+		switch {
+		case bookingDate.Month() == time.December && valueDate.Month() == time.January:
+			year--
+		case bookingDate.Month() == time.January && valueDate.Month() == time.December:
+			year++
+		}
+		date = time.Date(year, bookingDate.Month(), bookingDate.Day(), 0, 0, 0, 0, time.UTC)
+	}
+
+	cents, err := parseCents(match[4], ",")
+	if err != nil {
+		return domain.ImportTransaction{}, fmt.Errorf("%w: %v", domain.ErrInvalidStatement, err)
+	}
+	// A reversed credit (RC) takes money out, a reversed debit (RD) brings it back.
+	if match[3] == "D" || match[3] == "RC" {
+		cents = -cents
+	}
+
+	// Only the bank's reference after "//" identifies the booking. The
+	// customer's reference before it is whatever the payer put there, often
+	// the same on every rent or salary payment, so rows without a bank
+	// reference are left without an ID and told apart by their content.
+	return domain.ImportTransaction{
+		ID:            strings.TrimSpace(match[6]),
+		Date:          date,
+		Description:   "MT940",
+		AmountInCents: abs(cents),
+		Type:          string(transactionType(cents)),
+	}, nil
+}
+
+func mt940BalanceCents(line string) (int, error) {
+	match := mt940Balance.FindStringSubmatch(line)
+	if match == nil {
+		return 0, fmt.Errorf("%w: balance %q is not valid", domain.ErrInvalidStatement, line)
+	}
+	cents, err := parseCents(match[3], ",")
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", domain.ErrInvalidStatement, err)
+	}
+	if match[1] == "D" {
+		cents = -cents
+	}
+	return cents, nil
+}
+
+// mt940Description reads the :86: field. German banks structure it into
+// subfields after a three digit transaction code, e.g.
+// "105?00Lastschrift?20Rechnung 4711?32Stadtwerke": ?20 to ?29 and ?60 to ?63
+// hold the purpose, ?32 and ?33 the counterparty.
+func mt940Description(info string) string {
+	if len(info) < 4 || strings.Trim(info[:3], "0123456789") != "" {
+		return joinFields(info)
+	}
+	// Subfields are wrapped at a fixed width, often mid-word.
+	info = strings.ReplaceAll(info, "\n", "")
+
+	subfields := make(map[string]string)
+	for _, part := range strings.Split(info[4:], info[3:4]) {
+		if len(part) >= 2 {
+			subfields[part[:2]] += part[2:]
+		}
+	}
+
+	var purpose strings.Builder
+	for _, code := range []string{"20", "21", "22", "23", "24", "25", "26", "27", "28", "29", "60", "61", "62", "63"} {
+		purpose.WriteString(subfields[code])
+	}
+	if description := joinFields(subfields["32"]+subfields["33"], purpose.String()); description != "" {
+		return description
+	}
+	return joinFields(subfields["00"])
+}
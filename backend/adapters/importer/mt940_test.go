@@ -0,0 +1,103 @@
+package importer
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/fim-lab/expense-tracker/internal/core/domain"
+)
+
+const testMT940 = "{1:F01BANKDEFFXXXX0000000000}{2:I940BANKDEFFXXXXN}{4:\r\n" +
+	":20:STARTUMSE\r\n" +
+	":25:10020030/1234567\r\n" +
+	":28C:00001/001\r\n" +
+	":60F:C251230EUR1000,00\r\n" +
+	":61:2601021231DR50,00NMSCNONREF//BANKREF-1\r\n" +
+	":86:105?00Lastschrift?20EREF+4711 SVWZ+Strom Dez?21ember?32Stadtwerke M\xfcnchen\r\n" +
+	":61:2601020102CR2000,NTRFGEHALT\r\n" +
+	":86:Gehalt Januar\r\n" +
+	"Arbeitgeber GmbH\r\n" +
+	":62F:C260102EUR2950,00\r\n" +
+	"-}\r\n" +
+	"{1:F01BANKDEFFXXXX0000000000}{2:I940BANKDEFFXXXXN}{4:\r\n" +
+	":20:STARTUMSE\r\n" +
+	":25:10020030/1234567\r\n" +
+	":28C:00002/001\r\n" +
+	":60F:C260102EUR2950,00\r\n" +
+	":61:260105RC10,00NCHGNONREF\r\n" +
+	":62F:C260105EUR2940,00\r\n" +
+	"-}"
+
+func TestParseMT940(t *testing.T) {
+	statement, err := NewStatementParser().ParseMT940([]byte(testMT940))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if statement.OpeningBalanceCents == nil || *statement.OpeningBalanceCents != 100000 {
+		t.Errorf("expected the first statement's opening balance, got %v", statement.OpeningBalanceCents)
+	}
+	if statement.ClosingBalanceCents == nil || *statement.ClosingBalanceCents != 294000 {
+		t.Errorf("expected the last statement's closing balance, got %v", statement.ClosingBalanceCents)
+	}
+
+	rows := statement.Transactions
+	if len(rows) != 3 {
+		t.Fatalf("expected 3 rows, got %+v", rows)
+	}
+	want := domain.ImportTransaction{
+		ID:            "BANKREF-1",
+		Date:          time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC),
+		Description:   "Stadtwerke München EREF+4711 SVWZ+Strom Dezember",
+		AmountInCents: 5000,
+		Type:          string(domain.Expense),
+	}
+	if rows[0] != want {
+		t.Errorf("expected %+v, got %+v", want, rows[0])
+	}
+	if rows[1].ID != "" || rows[1].Description != "Gehalt Januar Arbeitgeber GmbH" || rows[1].AmountInCents != 200000 {
+		t.Errorf("expected the salary without an ID, as it has only a customer reference, got %+v", rows[1])
+	}
+	if rows[2].ID != "" || rows[2].Type != string(domain.Expense) || rows[2].Description != "MT940" {
+		t.Errorf("expected a reversed credit without reference, got %+v", rows[2])
+	}
+}
+
+func TestParseMT940_CustomerReferenceIsNoID(t *testing.T) {
+	data := ":20:STARTUMSE\n" +
+		":25:10020030/1234567\n" +
+		":61:2601010101DR850,00NDDTMIETE\n" +
+		":86:Miete Januar\n" +
+		":61:2602010201DR850,00NDDTMIETE\n" +
+		":86:Miete Februar\n"
+	statement, err := NewStatementParser().ParseMT940([]byte(data))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	rows := statement.Transactions
+	if len(rows) != 2 {
+		t.Fatalf("expected 2 rows, got %+v", rows)
+	}
+	for _, row := range rows {
+		if row.ID != "" {
+			t.Errorf("expected the shared customer reference not to become an ID, got %+v", row)
+		}
+	}
+}
+
+func TestParseMT940_Invalid(t *testing.T) {
+	for name, data := range map[string]string{
+		"no fields":        "Buchungstag;Betrag",
+		"bad transaction":  ":20:X\n:61:garbage\n",
+		"bad balance":      ":20:X\n:60F:X260101EUR1,00\n",
+		"several accounts": ":25:A/1\n:20:X\n:25:B/2\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := NewStatementParser().ParseMT940([]byte(data)); !errors.Is(err, domain.ErrInvalidStatement) {
+				t.Errorf("expected ErrInvalidStatement, got %v", err)
+			}
+		})
+	}
+}
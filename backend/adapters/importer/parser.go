@@ -0,0 +1,93 @@
+// Package importer reads the statement files banks export into the rows the
+// import service saves.
+package importer
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/fim-lab/expense-tracker/internal/core/domain"
+)
+
+type StatementParser struct{}
+
+func NewStatementParser() *StatementParser {
+	return &StatementParser{}
+}
+
+// parseCents reads an amount like "-1.234,56" into cents. decimalSeparator is
+// "," or "."; the other one is taken as the thousands separator.
+func parseCents(raw string, decimalSeparator string) (int, error) {
+	thousandsSeparator := "."
+	if decimalSeparator == "." {
+		thousandsSeparator = ","
+	}
+
+	s := strings.TrimSpace(raw)
+	s = strings.TrimSuffix(s, "€")
+	s = strings.TrimSuffix(s, "EUR")
+	s = strings.Join(strings.Fields(s), "")
+	s = strings.ReplaceAll(s, thousandsSeparator, "")
+
+	negative := false
+	switch {
+	case strings.HasPrefix(s, "-"):
+		negative, s = true, s[1:]
+	case strings.HasPrefix(s, "+"):
+		s = s[1:]
+	}
+
+	whole, fraction, _ := strings.Cut(s, decimalSeparator)
+	if len(fraction) > 2 {
+		return 0, fmt.Errorf("amount %q has more than two decimal places", raw)
+	}
+	fraction += strings.Repeat("0", 2-len(fraction))
+	if whole == "" {
+		whole = "0"
+	}
+
+	cents, err := strconv.Atoi(whole + fraction)
+	if err != nil || strings.ContainsAny(whole+fraction, "+-") {
+		return 0, fmt.Errorf("amount %q is not a number", raw)
+	}
+	if negative {
+		cents = -cents
+	}
+	return cents, nil
+}
+
+func transactionType(cents int) domain.TransactionType {
+	if cents < 0 {
+		return domain.Expense
+	}
+	return domain.Income
+}
+
+func abs(cents int) int {
+	if cents < 0 {
+		return -cents
+	}
+	return cents
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// joinFields joins the non-empty values with single spaces, collapsing the
+// line breaks and padding banks leave in their text fields.
+func joinFields(values ...string) string {
+	var parts []string
+	for _, v := range values {
+		if v = strings.Join(strings.Fields(v), " "); v != "" {
+			parts = append(parts, v)
+		}
+	}
+	return strings.Join(parts, " ")
+}
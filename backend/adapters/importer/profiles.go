@@ -0,0 +1,46 @@
+package importer
+
+import "github.com/fim-lab/expense-tracker/internal/core/domain"
+
+// BuiltInCSVProfiles are the CSV exports of the banks we support out of the box.
+func (p *StatementParser) BuiltInCSVProfiles() []domain.CSVProfile {
+	profiles := []domain.CSVProfile{
+		{
+			Name:               "DKB",
+			Encoding:           domain.EncodingUTF8,
+			DateColumn:         "Buchungsdatum",
+			DateFormat:         "DD.MM.YY",
+			DescriptionColumns: []string{"Zahlungsempfänger*in", "Verwendungszweck"},
+			AmountColumn:       "Betrag (€)",
+		},
+		{
+			Name:               "ING",
+			Encoding:           domain.EncodingLatin1,
+			DateColumn:         "Buchung",
+			DateFormat:         "DD.MM.YYYY",
+			DescriptionColumns: []string{"Auftraggeber/Empfänger", "Verwendungszweck"},
+			AmountColumn:       "Betrag",
+		},
+		{
+			Name:               "Sparkasse",
+			Encoding:           domain.EncodingWindows1252,
+			DateColumn:         "Buchungstag",
+			DateFormat:         "DD.MM.YY",
+			DescriptionColumns: []string{"Beguenstigter/Zahlungspflichtiger", "Verwendungszweck"},
+			AmountColumn:       "Betrag",
+		},
+		{
+			Name:               "comdirect",
+			Encoding:           domain.EncodingWindows1252,
+			DateColumn:         "Buchungstag",
+			DateFormat:         "DD.MM.YYYY",
+			DescriptionColumns: []string{"Vorgang", "Buchungstext"},
+			AmountColumn:       "Umsatz in EUR",
+		},
+	}
+	for i := range profiles {
+		profiles[i].BuiltIn = true
+		profiles[i].ApplyDefaults()
+	}
+	return profiles
+}
@@ -0,0 +1,141 @@
+package notifier
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fim-lab/expense-tracker/internal/core/domain"
+)
+
+var testNotification = domain.Notification{
+	ID:        7,
+	UserID:    1,
+	Type:      domain.NotificationBudgetThreshold,
+	Subject:   "Budget Freizeit reached 80% of its limit",
+	Message:   "80.00 € of 100.00 € spent in the period starting 2026-04-01.",
+	CreatedAt: time.Date(2026, 4, 12, 9, 30, 0, 0, time.UTC),
+}
+
+// fakeSMTPServer accepts one mail and hands over the recipient and data.
+func fakeSMTPServer(t *testing.T) (addr string, mails <-chan [2]string) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("could not listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	received := make(chan [2]string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+
+		reply("220 localhost ESMTP")
+		var rcpt string
+		var data strings.Builder
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			switch command := strings.ToUpper(strings.TrimSpace(line)); {
+			case strings.HasPrefix(command, "EHLO"):
+				reply("250-localhost")
+				reply("250 8BITMIME")
+			case strings.HasPrefix(command, "RCPT TO:"):
+				rcpt = strings.Trim(strings.TrimSpace(line)[len("RCPT TO:"):], "<>")
+				reply("250 OK")
+			case command == "DATA":
+				reply("354 go ahead")
+				for {
+					line, err := r.ReadString('\n')
+					if err != nil || line == ".\r\n" {
+						break
+					}
+					data.WriteString(line)
+				}
+				reply("250 OK")
+				received <- [2]string{rcpt, data.String()}
+			case command == "QUIT":
+				reply("221 bye")
+				return
+			default:
+				reply("250 OK")
+			}
+		}
+	}()
+	return listener.Addr().String(), received
+}
+
+func TestSMTPNotifier_MailsTheUser(t *testing.T) {
+	addr, mails := fakeSMTPServer(t)
+	host, port, _ := net.SplitHostPort(addr)
+	notifier := NewSMTPNotifier(host, port, "", "", "alerts@example.com")
+
+	if err := notifier.Deliver(domain.User{Username: "anna", Email: "anna@example.com"}, testNotification); err != nil {
+		t.Fatalf("delivery failed: %v", err)
+	}
+
+	select {
+	case mail := <-mails:
+		if mail[0] != "anna@example.com" {
+			t.Errorf("expected the mail to go to the user, got %q", mail[0])
+		}
+		if !strings.Contains(mail[1], "Subject: Budget Freizeit reached 80% of its limit\r\n") {
+			t.Errorf("expected the subject in the mail, got %q", mail[1])
+		}
+		if !strings.Contains(mail[1], testNotification.Message) {
+			t.Errorf("expected the message in the mail, got %q", mail[1])
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no mail arrived")
+	}
+}
+
+func TestSMTPNotifier_SkipsUsersWithoutEmail(t *testing.T) {
+	notifier := NewSMTPNotifier("127.0.0.1", "1", "", "", "alerts@example.com")
+	if err := notifier.Deliver(domain.User{Username: "anna"}, testNotification); err != nil {
+		t.Errorf("expected nothing to be sent, got %v", err)
+	}
+}
+
+func TestWebhookNotifier_PostsTheNotification(t *testing.T) {
+	var payload webhookPayload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("expected a JSON post, got %s %s", r.Method, r.Header.Get("Content-Type"))
+		}
+		json.NewDecoder(r.Body).Decode(&payload)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	if err := NewWebhookNotifier(server.URL).Deliver(domain.User{Username: "anna"}, testNotification); err != nil {
+		t.Fatalf("delivery failed: %v", err)
+	}
+	if payload.Username != "anna" || payload.Notification != testNotification {
+		t.Errorf("expected the notification to be posted, got %+v", payload)
+	}
+}
+
+func TestWebhookNotifier_FailsOnErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	if err := NewWebhookNotifier(server.URL).Deliver(domain.User{Username: "anna"}, testNotification); err == nil {
+		t.Error("expected an error for a 502")
+	}
+}
@@ -0,0 +1,97 @@
+package notifier
+
+import (
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"github.com/fim-lab/expense-tracker/internal/core/domain"
+)
+
+const smtpTimeout = 10 * time.Second
+
+// SMTPNotifier mails notifications to users who gave an email address.
+type SMTPNotifier struct {
+	host     string
+	addr     string
+	from     string
+	username string
+	password string
+}
+
+// NewSMTPNotifier sends through the server at host:port, upgrading to TLS if
+// the server offers it. Without a username it sends unauthenticated.
+func NewSMTPNotifier(host, port, username, password, from string) *SMTPNotifier {
+	return &SMTPNotifier{
+		host:     host,
+		addr:     net.JoinHostPort(host, port),
+		from:     from,
+		username: username,
+		password: password,
+	}
+}
+
+func (n *SMTPNotifier) Deliver(user domain.User, notification domain.Notification) error {
+	if user.Email == "" {
+		return nil
+	}
+
+	conn, err := net.DialTimeout("tcp", n.addr, smtpTimeout)
+	if err != nil {
+		return fmt.Errorf("could not reach smtp server: %w", err)
+	}
+	conn.SetDeadline(time.Now().Add(smtpTimeout))
+	client, err := smtp.NewClient(conn, n.host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("could not talk to smtp server: %w", err)
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: n.host}); err != nil {
+			return fmt.Errorf("could not start tls: %w", err)
+		}
+	}
+	if n.username != "" {
+		if err := client.Auth(smtp.PlainAuth("", n.username, n.password, n.host)); err != nil {
+			return fmt.Errorf("smtp authentication failed: %w", err)
+		}
+	}
+	if err := client.Mail(n.from); err != nil {
+		return fmt.Errorf("smtp server refused sender: %w", err)
+	}
+	if err := client.Rcpt(user.Email); err != nil {
+		return fmt.Errorf("smtp server refused recipient: %w", err)
+	}
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("smtp server refused data: %w", err)
+	}
+	if _, err := w.Write(n.message(user, notification)); err != nil {
+		return fmt.Errorf("could not write mail: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("smtp server refused mail: %w", err)
+	}
+	return client.Quit()
+}
+
+func (n *SMTPNotifier) message(user domain.User, notification domain.Notification) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", n.from)
+	fmt.Fprintf(&b, "To: %s\r\n", user.Email)
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", notification.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", notification.CreatedAt.Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(notification.Message, "\n", "\r\n"))
+	b.WriteString("\r\n")
+	return []byte(b.String())
+}
@@ -0,0 +1,44 @@
+package notifier
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/fim-lab/expense-tracker/internal/core/domain"
+)
+
+// WebhookNotifier posts every notification as JSON to one URL.
+type WebhookNotifier struct {
+	url    string
+	client *http.Client
+}
+
+func NewWebhookNotifier(url string) *WebhookNotifier {
+	return &WebhookNotifier{url: url, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+type webhookPayload struct {
+	Username     string              `json:"username"`
+	Notification domain.Notification `json:"notification"`
+}
+
+func (n *WebhookNotifier) Deliver(user domain.User, notification domain.Notification) error {
+	body, err := json.Marshal(webhookPayload{Username: user.Username, Notification: notification})
+	if err != nil {
+		return fmt.Errorf("could not encode notification: %w", err)
+	}
+
+	resp, err := n.client.Post(n.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("could not reach webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook answered %s", resp.Status)
+	}
+	return nil
+}
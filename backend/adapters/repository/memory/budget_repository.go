@@ -2,6 +2,7 @@ package memory
 
 import (
 	"sort"
+	"time"
 
 	"github.com/fim-lab/expense-tracker/internal/core/domain"
 )
@@ -10,13 +11,20 @@ type BudgetRepository struct {
 	repo *inMemoryRepositories
 }
 
+// budgetPeriod keys the allocation of a budget for one period.
+type budgetPeriod struct {
+	budgetID    int
+	periodStart time.Time
+}
+
 func (r *BudgetRepository) SaveBudget(b domain.Budget) error {
 	r.repo.mu.Lock()
 	defer r.repo.mu.Unlock()
 	if b.ID == 0 {
 		b.ID = r.repo.nextID()
 	}
-	r.repo.budgets[b.ID] = b
+	b.ApplyDefaults()
+	setEntry(r.repo, r.repo.budgets, b.ID, b)
 	return nil
 }
 
@@ -45,10 +53,26 @@ func (r *BudgetRepository) FindBudgetsByUser(userID int) ([]domain.Budget, error
 	return res, nil
 }
 
+// DeleteBudget refuses to delete a budget money was moved to or from, as the
+// database does.
 func (r *BudgetRepository) DeleteBudget(id int) error {
 	r.repo.mu.Lock()
 	defer r.repo.mu.Unlock()
-	delete(r.repo.budgets, id)
+	for _, m := range r.repo.budgetMovements {
+		if (m.FromBudgetID != nil && *m.FromBudgetID == id) || (m.ToBudgetID != nil && *m.ToBudgetID == id) {
+			return domain.ErrNotEmpty
+		}
+	}
+	parentID := r.repo.budgets[id].ParentID
+	for childID, child := range r.repo.budgets {
+		if child.ParentID != nil && *child.ParentID == id {
+			child.ParentID = parentID
+			setEntry(r.repo, r.repo.budgets, childID, child)
+		}
+	}
+	deleteEntry(r.repo, r.repo.budgets, id)
+	r.deleteAllocations(id)
+	r.repo.deleteMemberships(domain.ResourceBudget, id)
 	return nil
 }
 
@@ -57,7 +81,14 @@ func (r *BudgetRepository) DeleteAllByUser(userID int) error {
 	defer r.repo.mu.Unlock()
 	for id, b := range r.repo.budgets {
 		if b.UserID == userID {
-			delete(r.repo.budgets, id)
+			deleteEntry(r.repo, r.repo.budgets, id)
+			r.deleteAllocations(id)
+			r.repo.deleteMemberships(domain.ResourceBudget, id)
+		}
+	}
+	for id, m := range r.repo.budgetMovements {
+		if m.UserID == userID {
+			deleteEntry(r.repo, r.repo.budgetMovements, id)
 		}
 	}
 	return nil
@@ -72,6 +103,155 @@ func (r *BudgetRepository) UpdateBudget(b domain.Budget) error {
 	}
 	existingBudget.Name = b.Name
 	existingBudget.LimitCents = b.LimitCents
-	r.repo.budgets[b.ID] = existingBudget
+	existingBudget.Period = b.Period
+	existingBudget.Rollover = b.Rollover
+	existingBudget.AlertThresholds = b.AlertThresholds
+	setEntry(r.repo, r.repo.budgets, b.ID, existingBudget)
+	return nil
+}
+
+func (r *BudgetRepository) UpdateBudgetParent(id int, parentID *int) error {
+	r.repo.mu.Lock()
+	defer r.repo.mu.Unlock()
+	b, ok := r.repo.budgets[id]
+	if !ok {
+		return domain.ErrBudgetNotFound
+	}
+	b.ParentID = parentID
+	setEntry(r.repo, r.repo.budgets, id, b)
 	return nil
 }
+
+func (r *BudgetRepository) SetBudgetBalance(id int, balanceCents int) error {
+	r.repo.mu.Lock()
+	defer r.repo.mu.Unlock()
+	b, ok := r.repo.budgets[id]
+	if !ok {
+		return domain.ErrBudgetNotFound
+	}
+	b.BalanceCents = balanceCents
+	setEntry(r.repo, r.repo.budgets, id, b)
+	return nil
+}
+
+// LockBudgetsByUser has nothing to do, as a unit of work already holds the whole
+// store.
+func (r *BudgetRepository) LockBudgetsByUser(userID int) error {
+	return nil
+}
+
+func (r *BudgetRepository) SaveAllocation(a domain.BudgetAllocation) error {
+	r.repo.mu.Lock()
+	defer r.repo.mu.Unlock()
+	if _, ok := r.repo.budgets[a.BudgetID]; !ok {
+		return domain.ErrBudgetNotFound
+	}
+	setEntry(r.repo, r.repo.budgetAllocations, budgetPeriod{a.BudgetID, a.PeriodStart}, a)
+	return nil
+}
+
+func (r *BudgetRepository) FindAllocationsByBudget(budgetID int) ([]domain.BudgetAllocation, error) {
+	r.repo.mu.RLock()
+	defer r.repo.mu.RUnlock()
+	var res []domain.BudgetAllocation
+	for key, a := range r.repo.budgetAllocations {
+		if key.budgetID == budgetID {
+			res = append(res, a)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].PeriodStart.Before(res[j].PeriodStart)
+	})
+	return res, nil
+}
+
+func (r *BudgetRepository) FindAllocationsByUser(userID int) (map[int][]domain.BudgetAllocation, error) {
+	r.repo.mu.RLock()
+	defer r.repo.mu.RUnlock()
+	res := make(map[int][]domain.BudgetAllocation)
+	for key, a := range r.repo.budgetAllocations {
+		if b, ok := r.repo.budgets[key.budgetID]; ok && b.UserID == userID {
+			res[key.budgetID] = append(res[key.budgetID], a)
+		}
+	}
+	for _, allocations := range res {
+		sort.Slice(allocations, func(i, j int) bool {
+			return allocations[i].PeriodStart.Before(allocations[j].PeriodStart)
+		})
+	}
+	return res, nil
+}
+
+func (r *BudgetRepository) deleteAllocations(budgetID int) {
+	for key := range r.repo.budgetAllocations {
+		if key.budgetID == budgetID {
+			deleteEntry(r.repo, r.repo.budgetAllocations, key)
+		}
+	}
+}
+
+// SaveMovements saves all movements and books them on their budgets, or
+// none of them if one of their budgets is missing.
+func (r *BudgetRepository) SaveMovements(movements []domain.BudgetMovement) error {
+	r.repo.mu.Lock()
+	defer r.repo.mu.Unlock()
+	for _, m := range movements {
+		for _, budgetID := range []*int{m.FromBudgetID, m.ToBudgetID} {
+			if budgetID == nil {
+				continue
+			}
+			if _, ok := r.repo.budgets[*budgetID]; !ok {
+				return domain.ErrBudgetNotFound
+			}
+		}
+	}
+	for _, m := range movements {
+		if m.ID == 0 {
+			m.ID = r.repo.nextID()
+		}
+		setEntry(r.repo, r.repo.budgetMovements, m.ID, m)
+		for _, budgetID := range []*int{m.FromBudgetID, m.ToBudgetID} {
+			if budgetID != nil {
+				budget := r.repo.budgets[*budgetID]
+				budget.BalanceCents += m.AmountOnBudget(*budgetID)
+				setEntry(r.repo, r.repo.budgets, *budgetID, budget)
+			}
+		}
+	}
+	return nil
+}
+
+func (r *BudgetRepository) FindMovementsByUser(userID int) ([]domain.BudgetMovement, error) {
+	r.repo.mu.RLock()
+	defer r.repo.mu.RUnlock()
+	var res []domain.BudgetMovement
+	for _, m := range r.repo.budgetMovements {
+		if m.UserID == userID {
+			res = append(res, m)
+		}
+	}
+	sortMovements(res)
+	return res, nil
+}
+
+func (r *BudgetRepository) FindMovementsByBudget(budgetID int) ([]domain.BudgetMovement, error) {
+	r.repo.mu.RLock()
+	defer r.repo.mu.RUnlock()
+	var res []domain.BudgetMovement
+	for _, m := range r.repo.budgetMovements {
+		if m.AmountOnBudget(budgetID) != 0 {
+			res = append(res, m)
+		}
+	}
+	sortMovements(res)
+	return res, nil
+}
+
+func sortMovements(movements []domain.BudgetMovement) {
+	sort.Slice(movements, func(i, j int) bool {
+		if movements[i].Date.Equal(movements[j].Date) {
+			return movements[i].ID < movements[j].ID
+		}
+		return movements[i].Date.Before(movements[j].Date)
+	})
+}
@@ -0,0 +1,67 @@
+package memory
+
+import (
+	"sort"
+
+	"github.com/fim-lab/expense-tracker/internal/core/domain"
+)
+
+type CounterpartyRepository struct {
+	repo *inMemoryRepositories
+}
+
+func (r *CounterpartyRepository) SaveCounterparty(c domain.Counterparty) (int, error) {
+	r.repo.mu.Lock()
+	defer r.repo.mu.Unlock()
+	if c.ID == 0 {
+		c.ID = r.repo.nextID()
+	}
+	setEntry(r.repo, r.repo.counterparties, c.ID, c)
+	return c.ID, nil
+}
+
+func (r *CounterpartyRepository) GetCounterpartyByID(id int) (domain.Counterparty, error) {
+	r.repo.mu.RLock()
+	defer r.repo.mu.RUnlock()
+	c, ok := r.repo.counterparties[id]
+	if !ok {
+		return domain.Counterparty{}, domain.ErrCounterpartyNotFound
+	}
+	return c, nil
+}
+
+func (r *CounterpartyRepository) FindCounterpartiesByUser(userID int) ([]domain.Counterparty, error) {
+	r.repo.mu.RLock()
+	defer r.repo.mu.RUnlock()
+	var res []domain.Counterparty
+	for _, c := range r.repo.counterparties {
+		if c.UserID == userID {
+			res = append(res, c)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Name != res[j].Name {
+			return res[i].Name < res[j].Name
+		}
+		return res[i].ID < res[j].ID
+	})
+	return res, nil
+}
+
+func (r *CounterpartyRepository) DeleteCounterparty(id int) error {
+	r.repo.mu.Lock()
+	defer r.repo.mu.Unlock()
+	deleteEntry(r.repo, r.repo.counterparties, id)
+	return nil
+}
+
+func (r *CounterpartyRepository) DeleteAllByUser(userID int) error {
+	r.repo.mu.Lock()
+	defer r.repo.mu.Unlock()
+	for id, c := range r.repo.counterparties {
+		if c.UserID == userID {
+			deleteEntry(r.repo, r.repo.counterparties, id)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package memory
+
+import (
+	"sort"
+
+	"github.com/fim-lab/expense-tracker/internal/core/domain"
+)
+
+type CSVProfileRepository struct {
+	repo *inMemoryRepositories
+}
+
+func (r *CSVProfileRepository) SaveCSVProfile(p domain.CSVProfile) error {
+	r.repo.mu.Lock()
+	defer r.repo.mu.Unlock()
+	if p.ID == 0 {
+		p.ID = r.repo.nextID()
+	}
+	setEntry(r.repo, r.repo.csvProfiles, p.ID, p)
+	return nil
+}
+
+func (r *CSVProfileRepository) GetCSVProfileByID(id int) (domain.CSVProfile, error) {
+	r.repo.mu.RLock()
+	defer r.repo.mu.RUnlock()
+	p, ok := r.repo.csvProfiles[id]
+	if !ok {
+		return domain.CSVProfile{}, domain.ErrCSVProfileNotFound
+	}
+	return p, nil
+}
+
+func (r *CSVProfileRepository) FindCSVProfilesByUser(userID int) ([]domain.CSVProfile, error) {
+	r.repo.mu.RLock()
+	defer r.repo.mu.RUnlock()
+	var res []domain.CSVProfile
+	for _, p := range r.repo.csvProfiles {
+		if p.UserID == userID {
+			res = append(res, p)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].ID < res[j].ID
+	})
+	return res, nil
+}
+
+func (r *CSVProfileRepository) UpdateCSVProfile(p domain.CSVProfile) error {
+	r.repo.mu.Lock()
+	defer r.repo.mu.Unlock()
+	if _, ok := r.repo.csvProfiles[p.ID]; !ok {
+		return domain.ErrCSVProfileNotFound
+	}
+	setEntry(r.repo, r.repo.csvProfiles, p.ID, p)
+	return nil
+}
+
+func (r *CSVProfileRepository) DeleteCSVProfile(id int) error {
+	r.repo.mu.Lock()
+	defer r.repo.mu.Unlock()
+	deleteEntry(r.repo, r.repo.csvProfiles, id)
+	return nil
+}
+
+func (r *CSVProfileRepository) DeleteAllByUser(userID int) error {
+	r.repo.mu.Lock()
+	defer r.repo.mu.Unlock()
+	for id, p := range r.repo.csvProfiles {
+		if p.UserID == userID {
+			deleteEntry(r.repo, r.repo.csvProfiles, id)
+		}
+	}
+	return nil
+}
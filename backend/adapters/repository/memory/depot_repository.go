@@ -14,7 +14,7 @@ func (r *DepotRepository) SaveDepot(d domain.Depot) error {
 	if d.ID == 0 {
 		d.ID = r.repo.nextID()
 	}
-	r.repo.depots[d.ID] = d
+	setEntry(r.repo, r.repo.depots, d.ID, d)
 	return nil
 }
 
@@ -31,7 +31,7 @@ func (r *DepotRepository) GetDepotByID(id int) (domain.Depot, error) {
 func (r *DepotRepository) UpdateDepot(d domain.Depot) error {
 	r.repo.mu.Lock()
 	defer r.repo.mu.Unlock()
-	r.repo.depots[d.ID] = d
+	setEntry(r.repo, r.repo.depots, d.ID, d)
 	return nil
 }
 
@@ -50,7 +50,8 @@ func (r *DepotRepository) FindDepotsByUser(userID int) ([]domain.Depot, error) {
 func (r *DepotRepository) DeleteDepot(id int) error {
 	r.repo.mu.Lock()
 	defer r.repo.mu.Unlock()
-	delete(r.repo.depots, id)
+	deleteEntry(r.repo, r.repo.depots, id)
+	r.repo.deleteMemberships(domain.ResourceDepot, id)
 	return nil
 }
 
@@ -59,7 +60,8 @@ func (r *DepotRepository) DeleteAllByUser(userID int) error {
 	defer r.repo.mu.Unlock()
 	for id, d := range r.repo.depots {
 		if d.UserID == userID {
-			delete(r.repo.depots, id)
+			deleteEntry(r.repo, r.repo.depots, id)
+			r.repo.deleteMemberships(domain.ResourceDepot, id)
 		}
 	}
 	return nil
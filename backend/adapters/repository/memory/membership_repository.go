@@ -0,0 +1,93 @@
+package memory
+
+import (
+	"sort"
+
+	"github.com/fim-lab/expense-tracker/internal/core/domain"
+)
+
+type MembershipRepository struct {
+	repo *inMemoryRepositories
+}
+
+func (r *MembershipRepository) SaveMembership(m domain.Membership) (int, error) {
+	r.repo.mu.Lock()
+	defer r.repo.mu.Unlock()
+	if m.ID == 0 {
+		m.ID = r.repo.nextID()
+	}
+	setEntry(r.repo, r.repo.memberships, m.ID, m)
+	return m.ID, nil
+}
+
+func (r *MembershipRepository) GetMembershipByID(id int) (domain.Membership, error) {
+	r.repo.mu.RLock()
+	defer r.repo.mu.RUnlock()
+	m, ok := r.repo.memberships[id]
+	if !ok {
+		return domain.Membership{}, domain.ErrMembershipNotFound
+	}
+	return m, nil
+}
+
+func (r *MembershipRepository) GetMembership(resourceType domain.ResourceType, resourceID int, userID int) (domain.Membership, error) {
+	r.repo.mu.RLock()
+	defer r.repo.mu.RUnlock()
+	for _, m := range r.repo.memberships {
+		if m.ResourceType == resourceType && m.ResourceID == resourceID && m.UserID == userID {
+			return m, nil
+		}
+	}
+	return domain.Membership{}, domain.ErrMembershipNotFound
+}
+
+func (r *MembershipRepository) FindMembersOf(resourceType domain.ResourceType, resourceID int) ([]domain.Membership, error) {
+	return r.find(func(m domain.Membership) bool {
+		return m.ResourceType == resourceType && m.ResourceID == resourceID
+	}), nil
+}
+
+func (r *MembershipRepository) FindMembershipsByUser(userID int) ([]domain.Membership, error) {
+	return r.find(func(m domain.Membership) bool { return m.UserID == userID }), nil
+}
+
+func (r *MembershipRepository) find(match func(domain.Membership) bool) []domain.Membership {
+	r.repo.mu.RLock()
+	defer r.repo.mu.RUnlock()
+	var res []domain.Membership
+	for _, m := range r.repo.memberships {
+		if match(m) {
+			res = append(res, m)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].ID < res[j].ID })
+	return res
+}
+
+func (r *MembershipRepository) DeleteMembership(id int) error {
+	r.repo.mu.Lock()
+	defer r.repo.mu.Unlock()
+	deleteEntry(r.repo, r.repo.memberships, id)
+	return nil
+}
+
+func (r *MembershipRepository) DeleteAllByUser(userID int) error {
+	r.repo.mu.Lock()
+	defer r.repo.mu.Unlock()
+	for id, m := range r.repo.memberships {
+		if m.OwnerID == userID || m.UserID == userID {
+			deleteEntry(r.repo, r.repo.memberships, id)
+		}
+	}
+	return nil
+}
+
+// deleteMemberships drops the memberships in a deleted resource, as the
+// database does by cascading. The caller holds the lock.
+func (r *inMemoryRepositories) deleteMemberships(resourceType domain.ResourceType, resourceID int) {
+	for id, m := range r.memberships {
+		if m.ResourceType == resourceType && m.ResourceID == resourceID {
+			deleteEntry(r, r.memberships, id)
+		}
+	}
+}
@@ -9,10 +9,20 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
+// inMemoryRepositories share one store. Outside of a unit of work every call
+// takes the store's lock; inside one the unit holds it and undo records what
+// the unit wrote.
 type inMemoryRepositories struct {
-	mu                   sync.RWMutex
+	mu   locker
+	undo *undoLog
+	*store
+}
+
+type store struct {
 	transactions         map[int]domain.Transaction
 	budgets              map[int]domain.Budget
+	budgetAllocations    map[budgetPeriod]domain.BudgetAllocation
+	budgetMovements      map[int]domain.BudgetMovement
 	wallets              map[int]domain.Wallet
 	users                map[string]domain.User
 	sessions             map[string]domain.Session
@@ -20,6 +30,14 @@ type inMemoryRepositories struct {
 	trades               map[int]domain.Trade
 	transactionTemplates map[int]domain.TransactionTemplate
 	stocks               map[int]domain.Stock
+	csvProfiles          map[int]domain.CSVProfile
+	notifications        map[int]domain.Notification
+	savingsGoals         map[int]domain.SavingsGoal
+	reconciliations      map[int]domain.Reconciliation
+	counterparties       map[int]domain.Counterparty
+	sharedExpenses       map[int]domain.SharedExpense
+	settlements          map[int]domain.Settlement
+	memberships          map[int]domain.Membership
 	lastID               int
 }
 
@@ -35,16 +53,29 @@ func NewCleanRepositories() ports.Repositories {
 
 func NewInMemoryRepositories() *inMemoryRepositories {
 	return &inMemoryRepositories{
-		transactions:         make(map[int]domain.Transaction),
-		budgets:              make(map[int]domain.Budget),
-		wallets:              make(map[int]domain.Wallet),
-		users:                make(map[string]domain.User),
-		sessions:             make(map[string]domain.Session),
-		depots:               make(map[int]domain.Depot),
-		trades:               make(map[int]domain.Trade),
-		transactionTemplates: make(map[int]domain.TransactionTemplate),
-		stocks:               make(map[int]domain.Stock),
-		lastID:               0,
+		mu: &sync.RWMutex{},
+		store: &store{
+			transactions:         make(map[int]domain.Transaction),
+			budgets:              make(map[int]domain.Budget),
+			budgetAllocations:    make(map[budgetPeriod]domain.BudgetAllocation),
+			budgetMovements:      make(map[int]domain.BudgetMovement),
+			wallets:              make(map[int]domain.Wallet),
+			users:                make(map[string]domain.User),
+			sessions:             make(map[string]domain.Session),
+			depots:               make(map[int]domain.Depot),
+			trades:               make(map[int]domain.Trade),
+			transactionTemplates: make(map[int]domain.TransactionTemplate),
+			stocks:               make(map[int]domain.Stock),
+			csvProfiles:          make(map[int]domain.CSVProfile),
+			notifications:        make(map[int]domain.Notification),
+			savingsGoals:         make(map[int]domain.SavingsGoal),
+			reconciliations:      make(map[int]domain.Reconciliation),
+			counterparties:       make(map[int]domain.Counterparty),
+			sharedExpenses:       make(map[int]domain.SharedExpense),
+			settlements:          make(map[int]domain.Settlement),
+			memberships:          make(map[int]domain.Membership),
+			lastID:               0,
+		},
 	}
 }
 
@@ -53,6 +84,31 @@ func (r *inMemoryRepositories) nextID() int {
 	return r.lastID
 }
 
+// WithinTransaction runs fn holding the store, so that other calls wait for
+// the unit rather than see or lose its writes, and undoes what fn wrote if it
+// fails.
+func (r *inMemoryRepositories) WithinTransaction(fn func(repos ports.Repositories) error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	undo := &undoLog{lastID: r.lastID}
+	if err := fn(unitOfWork{&inMemoryRepositories{mu: noLock{}, undo: undo, store: r.store}}); err != nil {
+		undo.rollback(r.store)
+		return err
+	}
+	return nil
+}
+
+// unitOfWork are the repositories inside WithinTransaction; a unit of work
+// started in there joins the running one.
+type unitOfWork struct {
+	*inMemoryRepositories
+}
+
+func (u unitOfWork) WithinTransaction(fn func(repos ports.Repositories) error) error {
+	return fn(u)
+}
+
 func (r *inMemoryRepositories) seed() {
 	// Username: demo | Password: demo | Salary: 100€
 	demoUsername := "demo"
@@ -106,3 +162,31 @@ func (r *inMemoryRepositories) TransactionTemplateRepository() ports.Transaction
 func (r *inMemoryRepositories) StockRepository() ports.StockRepository {
 	return &StockRepository{repo: r}
 }
+
+func (r *inMemoryRepositories) CSVProfileRepository() ports.CSVProfileRepository {
+	return &CSVProfileRepository{repo: r}
+}
+
+func (r *inMemoryRepositories) NotificationRepository() ports.NotificationRepository {
+	return &NotificationRepository{repo: r}
+}
+
+func (r *inMemoryRepositories) SavingsGoalRepository() ports.SavingsGoalRepository {
+	return &SavingsGoalRepository{repo: r}
+}
+
+func (r *inMemoryRepositories) ReconciliationRepository() ports.ReconciliationRepository {
+	return &ReconciliationRepository{repo: r}
+}
+
+func (r *inMemoryRepositories) CounterpartyRepository() ports.CounterpartyRepository {
+	return &CounterpartyRepository{repo: r}
+}
+
+func (r *inMemoryRepositories) SharedExpenseRepository() ports.SharedExpenseRepository {
+	return &SharedExpenseRepository{repo: r}
+}
+
+func (r *inMemoryRepositories) MembershipRepository() ports.MembershipRepository {
+	return &MembershipRepository{repo: r}
+}
@@ -0,0 +1,72 @@
+package memory
+
+import (
+	"sort"
+
+	"github.com/fim-lab/expense-tracker/internal/core/domain"
+)
+
+type NotificationRepository struct {
+	repo *inMemoryRepositories
+}
+
+func (r *NotificationRepository) SaveNotification(n domain.Notification) (int, error) {
+	r.repo.mu.Lock()
+	defer r.repo.mu.Unlock()
+	if n.ID == 0 {
+		n.ID = r.repo.nextID()
+	}
+	setEntry(r.repo, r.repo.notifications, n.ID, n)
+	return n.ID, nil
+}
+
+func (r *NotificationRepository) GetNotificationByID(id int) (domain.Notification, error) {
+	r.repo.mu.RLock()
+	defer r.repo.mu.RUnlock()
+	n, ok := r.repo.notifications[id]
+	if !ok {
+		return domain.Notification{}, domain.ErrNotificationNotFound
+	}
+	return n, nil
+}
+
+func (r *NotificationRepository) FindNotificationsByUser(userID int) ([]domain.Notification, error) {
+	r.repo.mu.RLock()
+	defer r.repo.mu.RUnlock()
+	var res []domain.Notification
+	for _, n := range r.repo.notifications {
+		if n.UserID == userID {
+			res = append(res, n)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if !res[i].CreatedAt.Equal(res[j].CreatedAt) {
+			return res[i].CreatedAt.After(res[j].CreatedAt)
+		}
+		return res[i].ID > res[j].ID
+	})
+	return res, nil
+}
+
+func (r *NotificationRepository) MarkNotificationRead(id int) error {
+	r.repo.mu.Lock()
+	defer r.repo.mu.Unlock()
+	n, ok := r.repo.notifications[id]
+	if !ok {
+		return domain.ErrNotificationNotFound
+	}
+	n.Read = true
+	setEntry(r.repo, r.repo.notifications, id, n)
+	return nil
+}
+
+func (r *NotificationRepository) DeleteAllByUser(userID int) error {
+	r.repo.mu.Lock()
+	defer r.repo.mu.Unlock()
+	for id, n := range r.repo.notifications {
+		if n.UserID == userID {
+			deleteEntry(r.repo, r.repo.notifications, id)
+		}
+	}
+	return nil
+}
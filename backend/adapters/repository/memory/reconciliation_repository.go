@@ -0,0 +1,88 @@
+package memory
+
+import (
+	"sort"
+	"time"
+
+	"github.com/fim-lab/expense-tracker/internal/core/domain"
+)
+
+type ReconciliationRepository struct {
+	repo *inMemoryRepositories
+}
+
+func (r *ReconciliationRepository) SaveReconciliation(rec domain.Reconciliation) (int, error) {
+	r.repo.mu.Lock()
+	defer r.repo.mu.Unlock()
+	if rec.ID == 0 {
+		rec.ID = r.repo.nextID()
+	}
+	rec.Transactions = nil
+	setEntry(r.repo, r.repo.reconciliations, rec.ID, rec)
+	return rec.ID, nil
+}
+
+func (r *ReconciliationRepository) GetReconciliationByID(id int) (domain.Reconciliation, error) {
+	r.repo.mu.RLock()
+	defer r.repo.mu.RUnlock()
+	rec, ok := r.repo.reconciliations[id]
+	if !ok {
+		return domain.Reconciliation{}, domain.ErrReconciliationNotFound
+	}
+	return rec, nil
+}
+
+func (r *ReconciliationRepository) FindReconciliationsByWallet(walletID int) ([]domain.Reconciliation, error) {
+	r.repo.mu.RLock()
+	defer r.repo.mu.RUnlock()
+	var res []domain.Reconciliation
+	for _, rec := range r.repo.reconciliations {
+		if rec.WalletID == walletID {
+			res = append(res, rec)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if !res[i].StatementDate.Equal(res[j].StatementDate) {
+			return res[i].StatementDate.After(res[j].StatementDate)
+		}
+		return res[i].ID > res[j].ID
+	})
+	return res, nil
+}
+
+func (r *ReconciliationRepository) CompleteReconciliation(id int, completedAt time.Time) error {
+	r.repo.mu.Lock()
+	defer r.repo.mu.Unlock()
+	rec, ok := r.repo.reconciliations[id]
+	if !ok {
+		return domain.ErrReconciliationNotFound
+	}
+	rec.Status = domain.ReconciliationCompleted
+	rec.CompletedAt = &completedAt
+	setEntry(r.repo, r.repo.reconciliations, id, rec)
+	return nil
+}
+
+func (r *ReconciliationRepository) DeleteReconciliation(id int) error {
+	r.repo.mu.Lock()
+	defer r.repo.mu.Unlock()
+	deleteEntry(r.repo, r.repo.reconciliations, id)
+	for txID, t := range r.repo.transactions {
+		if t.ReconciliationID != nil && *t.ReconciliationID == id {
+			t.ReconciliationID = nil
+			setEntry(r.repo, r.repo.transactions, txID, t)
+		}
+	}
+	return nil
+}
+
+func (r *ReconciliationRepository) DeleteAllByUser(userID int) error {
+	r.repo.mu.Lock()
+	defer r.repo.mu.Unlock()
+	for id, rec := range r.repo.reconciliations {
+		if rec.UserID == userID {
+			deleteEntry(r.repo, r.repo.reconciliations, id)
+		}
+	}
+	return nil
+}
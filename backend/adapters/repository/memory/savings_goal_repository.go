@@ -0,0 +1,79 @@
+package memory
+
+import (
+	"sort"
+
+	"github.com/fim-lab/expense-tracker/internal/core/domain"
+)
+
+type SavingsGoalRepository struct {
+	repo *inMemoryRepositories
+}
+
+func (r *SavingsGoalRepository) SaveSavingsGoal(g domain.SavingsGoal) (int, error) {
+	r.repo.mu.Lock()
+	defer r.repo.mu.Unlock()
+	if g.ID == 0 {
+		g.ID = r.repo.nextID()
+	}
+	g.Progress = nil
+	setEntry(r.repo, r.repo.savingsGoals, g.ID, g)
+	return g.ID, nil
+}
+
+func (r *SavingsGoalRepository) GetSavingsGoalByID(id int) (domain.SavingsGoal, error) {
+	r.repo.mu.RLock()
+	defer r.repo.mu.RUnlock()
+	g, ok := r.repo.savingsGoals[id]
+	if !ok {
+		return domain.SavingsGoal{}, domain.ErrSavingsGoalNotFound
+	}
+	return g, nil
+}
+
+func (r *SavingsGoalRepository) FindSavingsGoalsByUser(userID int) ([]domain.SavingsGoal, error) {
+	r.repo.mu.RLock()
+	defer r.repo.mu.RUnlock()
+	var res []domain.SavingsGoal
+	for _, g := range r.repo.savingsGoals {
+		if g.UserID == userID {
+			res = append(res, g)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if !res[i].TargetDate.Equal(res[j].TargetDate) {
+			return res[i].TargetDate.Before(res[j].TargetDate)
+		}
+		return res[i].ID < res[j].ID
+	})
+	return res, nil
+}
+
+func (r *SavingsGoalRepository) UpdateSavingsGoal(g domain.SavingsGoal) error {
+	r.repo.mu.Lock()
+	defer r.repo.mu.Unlock()
+	if _, ok := r.repo.savingsGoals[g.ID]; !ok {
+		return domain.ErrSavingsGoalNotFound
+	}
+	g.Progress = nil
+	setEntry(r.repo, r.repo.savingsGoals, g.ID, g)
+	return nil
+}
+
+func (r *SavingsGoalRepository) DeleteSavingsGoal(id int) error {
+	r.repo.mu.Lock()
+	defer r.repo.mu.Unlock()
+	deleteEntry(r.repo, r.repo.savingsGoals, id)
+	return nil
+}
+
+func (r *SavingsGoalRepository) DeleteAllByUser(userID int) error {
+	r.repo.mu.Lock()
+	defer r.repo.mu.Unlock()
+	for id, g := range r.repo.savingsGoals {
+		if g.UserID == userID {
+			deleteEntry(r.repo, r.repo.savingsGoals, id)
+		}
+	}
+	return nil
+}
@@ -11,7 +11,7 @@ type SessionRepository struct {
 func (r *SessionRepository) SaveSession(session domain.Session) error {
 	r.repo.mu.Lock()
 	defer r.repo.mu.Unlock()
-	r.repo.sessions[session.SessionToken] = session
+	setEntry(r.repo, r.repo.sessions, session.SessionToken, session)
 	return nil
 }
 
@@ -28,6 +28,6 @@ func (r *SessionRepository) GetSessionByToken(token string) (domain.Session, err
 func (r *SessionRepository) DeleteSession(sessionID string) error {
 	r.repo.mu.Lock()
 	defer r.repo.mu.Unlock()
-	delete(r.repo.sessions, sessionID)
+	deleteEntry(r.repo, r.repo.sessions, sessionID)
 	return nil
 }
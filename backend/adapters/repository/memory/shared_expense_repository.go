@@ -0,0 +1,127 @@
+package memory
+
+import (
+	"slices"
+	"sort"
+
+	"github.com/fim-lab/expense-tracker/internal/core/domain"
+)
+
+type SharedExpenseRepository struct {
+	repo *inMemoryRepositories
+}
+
+func (r *SharedExpenseRepository) SaveSharedExpense(e domain.SharedExpense) (int, error) {
+	r.repo.mu.Lock()
+	defer r.repo.mu.Unlock()
+	if e.ID == 0 {
+		e.ID = r.repo.nextID()
+	}
+	e.Shares = slices.Clone(e.Shares)
+	setEntry(r.repo, r.repo.sharedExpenses, e.ID, e)
+	return e.ID, nil
+}
+
+// withTransaction fills in what the shared expense takes from the payer's
+// transaction.
+func (r *SharedExpenseRepository) withTransaction(e domain.SharedExpense) domain.SharedExpense {
+	t := r.repo.transactions[e.TransactionID]
+	e.Date, e.Description, e.AmountInCents = t.Date, t.Description, t.AmountInCents
+	e.Shares = slices.Clone(e.Shares)
+	return e
+}
+
+func (r *SharedExpenseRepository) GetSharedExpenseByTransaction(transactionID int) (domain.SharedExpense, error) {
+	r.repo.mu.RLock()
+	defer r.repo.mu.RUnlock()
+	for _, e := range r.repo.sharedExpenses {
+		if e.TransactionID == transactionID {
+			return r.withTransaction(e), nil
+		}
+	}
+	return domain.SharedExpense{}, domain.ErrSharedExpenseNotFound
+}
+
+func (r *SharedExpenseRepository) FindSharedExpensesByUser(userID int) ([]domain.SharedExpense, error) {
+	r.repo.mu.RLock()
+	defer r.repo.mu.RUnlock()
+	var res []domain.SharedExpense
+	for _, e := range r.repo.sharedExpenses {
+		if e.Involves(userID) {
+			res = append(res, r.withTransaction(e))
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if !res[i].Date.Equal(res[j].Date) {
+			return res[i].Date.Before(res[j].Date)
+		}
+		return res[i].ID < res[j].ID
+	})
+	return res, nil
+}
+
+func (r *SharedExpenseRepository) DeleteSharedExpense(id int) error {
+	r.repo.mu.Lock()
+	defer r.repo.mu.Unlock()
+	deleteEntry(r.repo, r.repo.sharedExpenses, id)
+	return nil
+}
+
+func (r *SharedExpenseRepository) SaveSettlement(s domain.Settlement) (int, error) {
+	r.repo.mu.Lock()
+	defer r.repo.mu.Unlock()
+	if s.ID == 0 {
+		s.ID = r.repo.nextID()
+	}
+	setEntry(r.repo, r.repo.settlements, s.ID, s)
+	return s.ID, nil
+}
+
+func (r *SharedExpenseRepository) GetSettlementByID(id int) (domain.Settlement, error) {
+	r.repo.mu.RLock()
+	defer r.repo.mu.RUnlock()
+	s, ok := r.repo.settlements[id]
+	if !ok {
+		return domain.Settlement{}, domain.ErrSettlementNotFound
+	}
+	return s, nil
+}
+
+func (r *SharedExpenseRepository) UpdateSettlement(s domain.Settlement) error {
+	r.repo.mu.Lock()
+	defer r.repo.mu.Unlock()
+	if _, ok := r.repo.settlements[s.ID]; !ok {
+		return domain.ErrSettlementNotFound
+	}
+	setEntry(r.repo, r.repo.settlements, s.ID, s)
+	return nil
+}
+
+func (r *SharedExpenseRepository) FindSettlementsByUser(userID int) ([]domain.Settlement, error) {
+	r.repo.mu.RLock()
+	defer r.repo.mu.RUnlock()
+	var res []domain.Settlement
+	for _, s := range r.repo.settlements {
+		if s.FromUserID == userID || s.ToUserID == userID {
+			res = append(res, s)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if !res[i].Date.Equal(res[j].Date) {
+			return res[i].Date.Before(res[j].Date)
+		}
+		return res[i].ID < res[j].ID
+	})
+	return res, nil
+}
+
+func (r *SharedExpenseRepository) DeleteAllByUser(userID int) error {
+	r.repo.mu.Lock()
+	defer r.repo.mu.Unlock()
+	for id, e := range r.repo.sharedExpenses {
+		if e.PayerID == userID {
+			deleteEntry(r.repo, r.repo.sharedExpenses, id)
+		}
+	}
+	return nil
+}
@@ -45,7 +45,7 @@ func (r *StockRepository) SaveStock(s domain.Stock) (int, error) {
 	if s.ID == 0 {
 		s.ID = r.repo.nextID()
 	}
-	r.repo.stocks[s.ID] = s
+	setEntry(r.repo, r.repo.stocks, s.ID, s)
 	return s.ID, nil
 }
 
@@ -55,13 +55,13 @@ func (r *StockRepository) UpdateStock(s domain.Stock) error {
 	if _, ok := r.repo.stocks[s.ID]; !ok {
 		return domain.ErrStockNotFound
 	}
-	r.repo.stocks[s.ID] = s
+	setEntry(r.repo, r.repo.stocks, s.ID, s)
 	return nil
 }
 
 func (r *StockRepository) DeleteStock(id int) error {
 	r.repo.mu.Lock()
 	defer r.repo.mu.Unlock()
-	delete(r.repo.stocks, id)
+	deleteEntry(r.repo, r.repo.stocks, id)
 	return nil
 }
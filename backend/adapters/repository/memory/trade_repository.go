@@ -15,7 +15,7 @@ func (r *TradeRepository) SaveTrade(t domain.Trade) (int, error) {
 		t.ID = r.repo.nextID()
 	}
 	t.WKN = ""
-	r.repo.trades[t.ID] = t
+	setEntry(r.repo, r.repo.trades, t.ID, t)
 	return t.ID, nil
 }
 
@@ -72,14 +72,14 @@ func (r *TradeRepository) UpdateTrade(t domain.Trade) error {
 		return domain.ErrTradeNotFound
 	}
 	t.WKN = ""
-	r.repo.trades[t.ID] = t
+	setEntry(r.repo, r.repo.trades, t.ID, t)
 	return nil
 }
 
 func (r *TradeRepository) DeleteTrade(id int) error {
 	r.repo.mu.Lock()
 	defer r.repo.mu.Unlock()
-	delete(r.repo.trades, id)
+	deleteEntry(r.repo, r.repo.trades, id)
 	return nil
 }
 
@@ -88,7 +88,7 @@ func (r *TradeRepository) DeleteAllTradesOfDepot(depotID int) error {
 	defer r.repo.mu.Unlock()
 	for id, t := range r.repo.trades {
 		if t.DepotID == depotID {
-			delete(r.repo.trades, id)
+			deleteEntry(r.repo, r.repo.trades, id)
 		}
 	}
 	return nil
@@ -109,7 +109,7 @@ func (r *TradeRepository) DeleteAllByUser(userID int) error {
 	// Delete all trades belonging to those depots
 	for id, t := range r.repo.trades {
 		if userDepots[t.DepotID] {
-			delete(r.repo.trades, id)
+			deleteEntry(r.repo, r.repo.trades, id)
 		}
 	}
 	return nil
@@ -1,8 +1,10 @@
 package memory
 
 import (
+	"slices"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/fim-lab/expense-tracker/internal/core/domain"
 )
@@ -14,21 +16,18 @@ type TransactionRepository struct {
 func (r *TransactionRepository) SaveTransaction(t domain.Transaction) (int, error) {
 	r.repo.mu.Lock()
 	defer r.repo.mu.Unlock()
+	if t.ExternalID != "" {
+		for _, existing := range r.repo.transactions {
+			if existing.UserID == t.UserID && existing.ExternalID == t.ExternalID {
+				return 0, domain.ErrDuplicateExternalID
+			}
+		}
+	}
 	if t.ID == 0 {
 		t.ID = r.repo.nextID()
 	}
 
-	if t.BudgetID != nil {
-		budget, ok := r.repo.budgets[*t.BudgetID]
-		if ok {
-			adjustment := t.AmountInCents
-			if t.Type == domain.Expense {
-				adjustment = -t.AmountInCents
-			}
-			budget.BalanceCents += adjustment
-			r.repo.budgets[*t.BudgetID] = budget
-		}
-	}
+	r.adjustBudgets(t, 1)
 
 	wallet, ok := r.repo.wallets[t.WalletID]
 	if ok {
@@ -37,10 +36,10 @@ func (r *TransactionRepository) SaveTransaction(t domain.Transaction) (int, erro
 			adjustment = -t.AmountInCents
 		}
 		wallet.BalanceCents += adjustment
-		r.repo.wallets[t.WalletID] = wallet
+		setEntry(r.repo, r.repo.wallets, t.WalletID, wallet)
 	}
 
-	r.repo.transactions[t.ID] = t
+	setEntry(r.repo, r.repo.transactions, t.ID, t)
 	return t.ID, nil
 }
 
@@ -54,6 +53,17 @@ func (r *TransactionRepository) GetTransactionByID(id int) (domain.Transaction,
 	return t, nil
 }
 
+func (r *TransactionRepository) FindTransactionByExternalID(userID int, externalID string) (domain.Transaction, error) {
+	r.repo.mu.RLock()
+	defer r.repo.mu.RUnlock()
+	for _, t := range r.repo.transactions {
+		if t.UserID == userID && t.ExternalID == externalID && externalID != "" {
+			return t, nil
+		}
+	}
+	return domain.Transaction{}, domain.ErrTransactionNotFound
+}
+
 func (r *TransactionRepository) GetTransactionCount(userID int) (int, error) {
 	r.repo.mu.RLock()
 	defer r.repo.mu.RUnlock()
@@ -66,6 +76,153 @@ func (r *TransactionRepository) GetTransactionCount(userID int) (int, error) {
 	return len(res), nil
 }
 
+// FindAllTransactionsByUser returns the user's transactions in the order they
+// were saved.
+func (r *TransactionRepository) FindAllTransactionsByUser(userID int) ([]domain.Transaction, error) {
+	r.repo.mu.RLock()
+	defer r.repo.mu.RUnlock()
+	var res []domain.Transaction
+	for _, t := range r.repo.transactions {
+		if t.UserID == userID {
+			res = append(res, t)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].ID < res[j].ID
+	})
+	return res, nil
+}
+
+func (r *TransactionRepository) FindTransactionsByWallet(walletID int) ([]domain.Transaction, error) {
+	r.repo.mu.RLock()
+	defer r.repo.mu.RUnlock()
+	var res []domain.Transaction
+	for _, t := range r.repo.transactions {
+		if t.WalletID == walletID {
+			res = append(res, t)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if !res[i].Date.Equal(res[j].Date) {
+			return res[i].Date.Before(res[j].Date)
+		}
+		return res[i].ID < res[j].ID
+	})
+	return res, nil
+}
+
+func (r *TransactionRepository) SumWalletBalancesAt(userID int, at time.Time) (map[int]int, error) {
+	r.repo.mu.RLock()
+	defer r.repo.mu.RUnlock()
+	at = domain.DateOf(at)
+	balances := make(map[int]int)
+	for _, t := range r.repo.transactions {
+		if t.UserID == userID && !domain.DateOf(t.Date).After(at) {
+			balances[t.WalletID] += t.SignedAmount()
+		}
+	}
+	return balances, nil
+}
+
+func (r *TransactionRepository) FindWalletBalanceChanges(walletID int, from, until time.Time) ([]domain.BalanceChange, error) {
+	r.repo.mu.RLock()
+	defer r.repo.mu.RUnlock()
+	from, until = domain.DateOf(from), domain.DateOf(until)
+	perDay := make(map[time.Time]int)
+	for _, t := range r.repo.transactions {
+		day := domain.DateOf(t.Date)
+		if t.WalletID == walletID && !day.Before(from) && !day.After(until) {
+			perDay[day] += t.SignedAmount()
+		}
+	}
+	changes := make([]domain.BalanceChange, 0, len(perDay))
+	for day, amount := range perDay {
+		changes = append(changes, domain.BalanceChange{Date: day, AmountInCents: amount})
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Date.Before(changes[j].Date)
+	})
+	return changes, nil
+}
+
+func (r *TransactionRepository) SetTransactionsCleared(ids []int, cleared bool) error {
+	r.repo.mu.Lock()
+	defer r.repo.mu.Unlock()
+	for _, id := range ids {
+		t, ok := r.repo.transactions[id]
+		if !ok {
+			return domain.ErrTransactionNotFound
+		}
+		t.Cleared = cleared
+		setEntry(r.repo, r.repo.transactions, id, t)
+	}
+	return nil
+}
+
+func (r *TransactionRepository) SumPendingAmounts(userID int) (map[int]int, map[int]int, error) {
+	r.repo.mu.RLock()
+	defer r.repo.mu.RUnlock()
+	wallets, budgets := make(map[int]int), make(map[int]int)
+	for _, t := range r.repo.transactions {
+		if t.UserID != userID || t.IsPending == nil || !*t.IsPending {
+			continue
+		}
+		wallets[t.WalletID] += t.SignedAmount()
+		for budgetID, amount := range t.BudgetAmounts() {
+			budgets[budgetID] += amount
+		}
+	}
+	return wallets, budgets, nil
+}
+
+func (r *TransactionRepository) SettleTransactions(ids []int) (int, error) {
+	r.repo.mu.Lock()
+	defer r.repo.mu.Unlock()
+	settled := 0
+	for _, id := range ids {
+		t, ok := r.repo.transactions[id]
+		if !ok || t.IsPending == nil || !*t.IsPending {
+			continue
+		}
+		booked := false
+		t.IsPending, t.PendingOverdue = &booked, false
+		setEntry(r.repo, r.repo.transactions, id, t)
+		settled++
+	}
+	return settled, nil
+}
+
+func (r *TransactionRepository) FlagOverduePending(before time.Time) ([]domain.Transaction, error) {
+	r.repo.mu.Lock()
+	defer r.repo.mu.Unlock()
+	var flagged []domain.Transaction
+	for id, t := range r.repo.transactions {
+		if t.IsPending == nil || !*t.IsPending || t.PendingOverdue || !t.Date.Before(before) {
+			continue
+		}
+		t.PendingOverdue = true
+		setEntry(r.repo, r.repo.transactions, id, t)
+		flagged = append(flagged, t)
+	}
+	sort.Slice(flagged, func(i, j int) bool {
+		return flagged[i].ID < flagged[j].ID
+	})
+	return flagged, nil
+}
+
+func (r *TransactionRepository) MarkReconciled(walletID int, until time.Time, reconciliationID int) error {
+	r.repo.mu.Lock()
+	defer r.repo.mu.Unlock()
+	until = domain.DateOf(until)
+	for id, t := range r.repo.transactions {
+		if t.WalletID == walletID && t.Cleared && t.ReconciliationID == nil && !domain.DateOf(t.Date).After(until) {
+			t.ReconciliationID = &reconciliationID
+			setEntry(r.repo, r.repo.transactions, id, t)
+		}
+	}
+	return nil
+}
+
 func (r *TransactionRepository) FindTransactionsByUser(userID int, limit int, offset int) ([]domain.TransactionDTO, error) {
 	r.repo.mu.RLock()
 	defer r.repo.mu.RUnlock()
@@ -97,24 +254,7 @@ func (r *TransactionRepository) FindTransactionsByUser(userID int, limit int, of
 
 	dtos := make([]domain.TransactionDTO, 0, len(paginatedTxs))
 	for _, t := range paginatedTxs {
-		var budgetName string
-		if t.BudgetID != nil {
-			if budget, ok := r.repo.budgets[*t.BudgetID]; ok {
-				budgetName = budget.Name
-			}
-		}
-		wallet := r.repo.wallets[t.WalletID]
-		dtos = append(dtos, domain.TransactionDTO{
-			ID:            t.ID,
-			Date:          t.Date,
-			Description:   t.Description,
-			AmountInCents: t.AmountInCents,
-			Type:          t.Type,
-			BudgetName:    budgetName,
-			WalletName:    wallet.Name,
-			IsPending:     t.IsPending != nil && *t.IsPending,
-			IsDebt:        t.IsDebt != nil && *t.IsDebt,
-		})
+		dtos = append(dtos, r.transactionDTO(t))
 	}
 
 	return dtos, nil
@@ -144,13 +284,8 @@ func (r *TransactionRepository) SearchTransactions(userID int, criteria domain.T
 			continue
 		}
 
-		if criteria.BudgetID != nil {
-			if t.BudgetID == nil {
-				continue
-			}
-			if *t.BudgetID != *criteria.BudgetID {
-				continue
-			}
+		if criteria.BudgetID != nil && !t.OnBudget(*criteria.BudgetID) {
+			continue
 		}
 
 		if criteria.WalletID != nil && t.WalletID != *criteria.WalletID {
@@ -168,6 +303,10 @@ func (r *TransactionRepository) SearchTransactions(userID int, criteria domain.T
 			}
 		}
 
+		if len(criteria.Tags) > 0 && !t.HasTags(criteria.Tags, criteria.AllTags) {
+			continue
+		}
+
 		filtered = append(filtered, t)
 	}
 
@@ -192,24 +331,7 @@ func (r *TransactionRepository) SearchTransactions(userID int, criteria domain.T
 
 	dtos := make([]domain.TransactionDTO, 0, len(paginatedTxs))
 	for _, t := range paginatedTxs {
-		var budgetName string
-		if t.BudgetID != nil {
-			if budget, ok := r.repo.budgets[*t.BudgetID]; ok {
-				budgetName = budget.Name
-			}
-		}
-		wallet := r.repo.wallets[t.WalletID]
-		dtos = append(dtos, domain.TransactionDTO{
-			ID:            t.ID,
-			Date:          t.Date,
-			Description:   t.Description,
-			AmountInCents: t.AmountInCents,
-			Type:          t.Type,
-			BudgetName:    budgetName,
-			WalletName:    wallet.Name,
-			IsPending:     t.IsPending != nil && *t.IsPending,
-			IsDebt:        t.IsDebt != nil && *t.IsDebt,
-		})
+		dtos = append(dtos, r.transactionDTO(t))
 	}
 
 	return dtos, nil
@@ -239,13 +361,8 @@ func (r *TransactionRepository) CountSearchedTransactions(userID int, criteria d
 			continue
 		}
 
-		if criteria.BudgetID != nil {
-			if t.BudgetID == nil {
-				continue
-			}
-			if *t.BudgetID != *criteria.BudgetID {
-				continue
-			}
+		if criteria.BudgetID != nil && !t.OnBudget(*criteria.BudgetID) {
+			continue
 		}
 
 		if criteria.WalletID != nil && t.WalletID != *criteria.WalletID {
@@ -263,6 +380,10 @@ func (r *TransactionRepository) CountSearchedTransactions(userID int, criteria d
 			}
 		}
 
+		if len(criteria.Tags) > 0 && !t.HasTags(criteria.Tags, criteria.AllTags) {
+			continue
+		}
+
 		count++
 	}
 
@@ -293,13 +414,8 @@ func (r *TransactionRepository) SumSearchedTransactionAmounts(userID int, criter
 			continue
 		}
 
-		if criteria.BudgetID != nil {
-			if t.BudgetID == nil {
-				continue
-			}
-			if *t.BudgetID != *criteria.BudgetID {
-				continue
-			}
+		if criteria.BudgetID != nil && !t.OnBudget(*criteria.BudgetID) {
+			continue
 		}
 
 		if criteria.WalletID != nil && t.WalletID != *criteria.WalletID {
@@ -317,10 +433,18 @@ func (r *TransactionRepository) SumSearchedTransactionAmounts(userID int, criter
 			}
 		}
 
+		if len(criteria.Tags) > 0 && !t.HasTags(criteria.Tags, criteria.AllTags) {
+			continue
+		}
+
+		amount := t.AmountInCents
+		if criteria.BudgetID != nil {
+			amount = t.AmountOnBudget(*criteria.BudgetID)
+		}
 		if t.Type == domain.Expense {
-			sum -= t.AmountInCents
+			sum -= amount
 		} else {
-			sum += t.AmountInCents
+			sum += amount
 		}
 	}
 
@@ -335,27 +459,72 @@ func (r *TransactionRepository) DeleteTransaction(id int) error {
 	if !exists {
 		return domain.ErrTransactionNotFound
 	}
+	for _, s := range r.repo.settlements {
+		if s.BookedBy(id) && !s.Pending() {
+			return domain.ErrSettlementConfirmed
+		}
+	}
 
 	adjustment := -tx.AmountInCents
 	if tx.Type == domain.Expense {
 		adjustment = tx.AmountInCents
 	}
 
-	if tx.BudgetID != nil {
-		budget, ok := r.repo.budgets[*tx.BudgetID]
-		if ok {
-			budget.BalanceCents += adjustment
-			r.repo.budgets[*tx.BudgetID] = budget
-		}
-	}
+	r.adjustBudgets(tx, -1)
 
 	wallet, ok := r.repo.wallets[tx.WalletID]
 	if ok {
 		wallet.BalanceCents += adjustment
-		r.repo.wallets[tx.WalletID] = wallet
+		setEntry(r.repo, r.repo.wallets, tx.WalletID, wallet)
 	}
 
-	delete(r.repo.transactions, id)
+	deleteEntry(r.repo, r.repo.transactions, id)
+	for sharedID, e := range r.repo.sharedExpenses {
+		if e.TransactionID == id {
+			deleteEntry(r.repo, r.repo.sharedExpenses, sharedID)
+		}
+	}
+	for settlementID, s := range r.repo.settlements {
+		if s.BookedBy(id) {
+			deleteEntry(r.repo, r.repo.settlements, settlementID)
+		}
+	}
+	for otherID, t := range r.repo.transactions {
+		if t.RepaymentOfID != nil && *t.RepaymentOfID == id {
+			t.RepaymentOfID = nil
+			setEntry(r.repo, r.repo.transactions, otherID, t)
+		}
+	}
+	return nil
+}
+
+func (r *TransactionRepository) FindDebtTransactions(userID int) ([]domain.Transaction, error) {
+	r.repo.mu.RLock()
+	defer r.repo.mu.RUnlock()
+	var res []domain.Transaction
+	for _, t := range r.repo.transactions {
+		if t.UserID == userID && t.IsDebt != nil && *t.IsDebt && t.CounterpartyID != nil {
+			res = append(res, t)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if !res[i].Date.Equal(res[j].Date) {
+			return res[i].Date.Before(res[j].Date)
+		}
+		return res[i].ID < res[j].ID
+	})
+	return res, nil
+}
+
+func (r *TransactionRepository) SetCounterparty(debtID int, counterpartyID int) error {
+	r.repo.mu.Lock()
+	defer r.repo.mu.Unlock()
+	for id, t := range r.repo.transactions {
+		if id == debtID || (t.RepaymentOfID != nil && *t.RepaymentOfID == debtID) {
+			t.CounterpartyID = &counterpartyID
+			setEntry(r.repo, r.repo.transactions, id, t)
+		}
+	}
 	return nil
 }
 
@@ -363,8 +532,14 @@ func (r *TransactionRepository) DeleteAllByUser(userID int) error {
 	r.repo.mu.Lock()
 	defer r.repo.mu.Unlock()
 	for id, t := range r.repo.transactions {
-		if t.UserID == userID {
-			delete(r.repo.transactions, id)
+		if t.UserID != userID {
+			continue
+		}
+		deleteEntry(r.repo, r.repo.transactions, id)
+		for settlementID, s := range r.repo.settlements {
+			if s.BookedBy(id) {
+				deleteEntry(r.repo, r.repo.settlements, settlementID)
+			}
 		}
 	}
 	return nil
@@ -383,32 +558,27 @@ func (r *TransactionRepository) UpdateTransaction(t domain.Transaction) error {
 	if oldT.Type == domain.Income {
 		oldAdjustment = -oldT.AmountInCents
 	}
-	if oldT.BudgetID != nil {
-		if budget, ok := r.repo.budgets[*oldT.BudgetID]; ok {
-			budget.BalanceCents += oldAdjustment
-			r.repo.budgets[*oldT.BudgetID] = budget
-		}
-	}
+	r.adjustBudgets(oldT, -1)
 	if wallet, ok := r.repo.wallets[oldT.WalletID]; ok {
 		wallet.BalanceCents += oldAdjustment
-		r.repo.wallets[oldT.WalletID] = wallet
+		setEntry(r.repo, r.repo.wallets, oldT.WalletID, wallet)
 	}
 
-	r.repo.transactions[t.ID] = t
+	t.ExternalID = oldT.ExternalID
+	t.Cleared, t.ReconciliationID = oldT.Cleared, oldT.ReconciliationID
+	t.PendingOverdue = oldT.PendingOverdue && t.IsPending != nil && *t.IsPending
+	t.CounterpartyID, t.RepaymentOfID = oldT.CounterpartyID, oldT.RepaymentOfID
+	t.CreatedBy = oldT.CreatedBy
+	setEntry(r.repo, r.repo.transactions, t.ID, t)
 
 	newAdjustment := t.AmountInCents
 	if t.Type == domain.Expense {
 		newAdjustment = -t.AmountInCents
 	}
-	if t.BudgetID != nil {
-		if budget, ok := r.repo.budgets[*t.BudgetID]; ok {
-			budget.BalanceCents += newAdjustment
-			r.repo.budgets[*t.BudgetID] = budget
-		}
-	}
+	r.adjustBudgets(t, 1)
 	if wallet, ok := r.repo.wallets[t.WalletID]; ok {
 		wallet.BalanceCents += newAdjustment
-		r.repo.wallets[t.WalletID] = wallet
+		setEntry(r.repo, r.repo.wallets, t.WalletID, wallet)
 	}
 
 	return nil
@@ -421,40 +591,147 @@ func (r *TransactionRepository) CreateTransfer(from, to domain.Transaction) erro
 	if from.ID == 0 {
 		from.ID = r.repo.nextID()
 	}
-	r.repo.transactions[from.ID] = from
+	setEntry(r.repo, r.repo.transactions, from.ID, from)
 	fromWallet, ok := r.repo.wallets[from.WalletID]
 	if !ok {
 		return domain.ErrWalletNotFound
 	}
 	fromWallet.BalanceCents -= from.AmountInCents
-	r.repo.wallets[from.WalletID] = fromWallet
+	setEntry(r.repo, r.repo.wallets, from.WalletID, fromWallet)
 
 	if to.ID == 0 {
 		to.ID = r.repo.nextID()
 	}
-	r.repo.transactions[to.ID] = to
+	setEntry(r.repo, r.repo.transactions, to.ID, to)
 	toWallet, ok := r.repo.wallets[to.WalletID]
 	if !ok {
 		return domain.ErrWalletNotFound
 	}
 	toWallet.BalanceCents += to.AmountInCents
-	r.repo.wallets[to.WalletID] = toWallet
+	setEntry(r.repo, r.repo.wallets, to.WalletID, toWallet)
 
 	return nil
 }
 
+func (r *TransactionRepository) ReplaceTags(userID int, tags []string, replacement string) (int, error) {
+	r.repo.mu.Lock()
+	defer r.repo.mu.Unlock()
+	changed := 0
+	for id, t := range r.repo.transactions {
+		if t.UserID != userID {
+			continue
+		}
+		var replaced, splitReplaced bool
+		t.Tags, replaced = domain.ReplaceTags(t.Tags, tags, replacement)
+		if len(t.Splits) > 0 {
+			t.Splits = slices.Clone(t.Splits)
+			for i := range t.Splits {
+				var ok bool
+				t.Splits[i].Tags, ok = domain.ReplaceTags(t.Splits[i].Tags, tags, replacement)
+				splitReplaced = splitReplaced || ok
+			}
+		}
+		if replaced || splitReplaced {
+			setEntry(r.repo, r.repo.transactions, id, t)
+			changed++
+		}
+	}
+	return changed, nil
+}
+
+func (r *TransactionRepository) SumTransactionsWithoutBudget(userID int) (int, error) {
+	r.repo.mu.RLock()
+	defer r.repo.mu.RUnlock()
+	sum := 0
+	for _, t := range r.repo.transactions {
+		if t.UserID != userID || t.BudgetID != nil || len(t.Splits) > 0 || (t.IsDebt != nil && *t.IsDebt) {
+			continue
+		}
+		if t.Type == domain.Expense {
+			sum -= t.AmountInCents
+		} else {
+			sum += t.AmountInCents
+		}
+	}
+	return sum, nil
+}
+
+func (r *TransactionRepository) FindBudgetBookings(budgetID int) ([]domain.BudgetBooking, error) {
+	return r.findBudgetBookings(budgetID, func(time.Time) bool { return true })
+}
+
+func (r *TransactionRepository) FindBudgetBookingsBetween(budgetID int, from, until time.Time) ([]domain.BudgetBooking, error) {
+	return r.findBudgetBookings(budgetID, func(date time.Time) bool {
+		return !domain.DateOf(date).Before(from) && domain.DateOf(date).Before(until)
+	})
+}
+
+func (r *TransactionRepository) FindBudgetBookingsByUser(userID int, from time.Time) (map[int][]domain.BudgetBooking, error) {
+	r.repo.mu.RLock()
+	defer r.repo.mu.RUnlock()
+	bookings := make(map[int][]domain.BudgetBooking)
+	for _, t := range r.repo.transactions {
+		if domain.DateOf(t.Date).Before(from) {
+			continue
+		}
+		for budgetID, amount := range t.BudgetAmounts() {
+			if b, ok := r.repo.budgets[budgetID]; ok && b.UserID == userID {
+				bookings[budgetID] = append(bookings[budgetID], domain.BudgetBooking{Date: t.Date, AmountInCents: amount})
+			}
+		}
+	}
+	for _, budgetBookings := range bookings {
+		sort.Slice(budgetBookings, func(i, j int) bool {
+			return budgetBookings[i].Date.Before(budgetBookings[j].Date)
+		})
+	}
+	return bookings, nil
+}
+
+func (r *TransactionRepository) findBudgetBookings(budgetID int, dated func(time.Time) bool) ([]domain.BudgetBooking, error) {
+	r.repo.mu.RLock()
+	defer r.repo.mu.RUnlock()
+	var bookings []domain.BudgetBooking
+	for _, t := range r.repo.transactions {
+		if !dated(t.Date) {
+			continue
+		}
+		if amount, ok := t.BudgetAmounts()[budgetID]; ok {
+			bookings = append(bookings, domain.BudgetBooking{Date: t.Date, AmountInCents: amount})
+		}
+	}
+	sort.Slice(bookings, func(i, j int) bool {
+		return bookings[i].Date.Before(bookings[j].Date)
+	})
+	return bookings, nil
+}
+
 func (r *TransactionRepository) CountTransactionsByBudgetID(budgetID int) (int, error) {
 	r.repo.mu.RLock()
 	defer r.repo.mu.RUnlock()
 	count := 0
 	for _, t := range r.repo.transactions {
-		if t.BudgetID != nil && *t.BudgetID == budgetID {
+		if t.OnBudget(budgetID) {
 			count++
 		}
 	}
 	return count, nil
 }
 
+func (r *TransactionRepository) CountTransactionsByBudget(userID int) (map[int]int, error) {
+	r.repo.mu.RLock()
+	defer r.repo.mu.RUnlock()
+	counts := make(map[int]int)
+	for _, t := range r.repo.transactions {
+		for budgetID := range t.BudgetAmounts() {
+			if b, ok := r.repo.budgets[budgetID]; ok && b.UserID == userID {
+				counts[budgetID]++
+			}
+		}
+	}
+	return counts, nil
+}
+
 func (r *TransactionRepository) CountTransactionsByWalletID(walletID int) (int, error) {
 	r.repo.mu.RLock()
 	defer r.repo.mu.RUnlock()
@@ -466,3 +743,60 @@ func (r *TransactionRepository) CountTransactionsByWalletID(walletID int) (int,
 	}
 	return count, nil
 }
+
+// adjustBudgets books the transaction on its budgets, or with sign -1 takes
+// it back off them.
+func (r *TransactionRepository) adjustBudgets(t domain.Transaction, sign int) {
+	for budgetID, amount := range t.BudgetAmounts() {
+		if budget, ok := r.repo.budgets[budgetID]; ok {
+			budget.BalanceCents += sign * amount
+			setEntry(r.repo, r.repo.budgets, budgetID, budget)
+		}
+	}
+}
+
+func (r *TransactionRepository) transactionDTO(t domain.Transaction) domain.TransactionDTO {
+	var budgetName string
+	if t.BudgetID != nil {
+		if budget, ok := r.repo.budgets[*t.BudgetID]; ok {
+			budgetName = budget.Name
+		}
+	}
+	var splits []domain.SplitDTO
+	for _, s := range t.Splits {
+		splits = append(splits, domain.SplitDTO{
+			BudgetID:      s.BudgetID,
+			BudgetName:    r.repo.budgets[s.BudgetID].Name,
+			AmountInCents: s.AmountInCents,
+			Tags:          s.Tags,
+			Note:          s.Note,
+		})
+	}
+	wallet := r.repo.wallets[t.WalletID]
+	return domain.TransactionDTO{
+		ID:             t.ID,
+		Date:           t.Date,
+		Description:    t.Description,
+		AmountInCents:  t.AmountInCents,
+		Type:           t.Type,
+		BudgetName:     budgetName,
+		WalletName:     wallet.Name,
+		IsPending:      t.IsPending != nil && *t.IsPending,
+		IsDebt:         t.IsDebt != nil && *t.IsDebt,
+		Tags:           t.Tags,
+		Splits:         splits,
+		Cleared:        t.Cleared,
+		Reconciled:     t.ReconciliationID != nil,
+		PendingOverdue: t.PendingOverdue,
+		CreatedBy:      r.username(t.CreatedBy),
+	}
+}
+
+func (r *TransactionRepository) username(userID int) string {
+	for _, u := range r.repo.users {
+		if u.ID == userID {
+			return u.Username
+		}
+	}
+	return ""
+}
@@ -2,6 +2,7 @@ package memory
 
 import (
 	"sort"
+	"time"
 
 	"github.com/fim-lab/expense-tracker/internal/core/domain"
 )
@@ -10,14 +11,17 @@ type TransactionTemplateRepository struct {
 	repo *inMemoryRepositories
 }
 
-func (r *TransactionTemplateRepository) SaveTransactionTemplate(tt domain.TransactionTemplate) error {
+func (r *TransactionTemplateRepository) SaveTransactionTemplate(tt domain.TransactionTemplate) (int, error) {
 	r.repo.mu.Lock()
 	defer r.repo.mu.Unlock()
 	if tt.ID == 0 {
 		tt.ID = r.repo.nextID()
 	}
-	r.repo.transactionTemplates[tt.ID] = tt
-	return nil
+	if tt.CreatedAt.IsZero() {
+		tt.CreatedAt = time.Now()
+	}
+	setEntry(r.repo, r.repo.transactionTemplates, tt.ID, tt)
+	return tt.ID, nil
 }
 
 func (r *TransactionTemplateRepository) GetTransactionTemplateByID(id int) (domain.TransactionTemplate, error) {
@@ -55,21 +59,40 @@ func (r *TransactionTemplateRepository) UpdateTransactionTemplate(tt domain.Tran
 	existingTemplate.Day = tt.Day
 	existingTemplate.BudgetID = tt.BudgetID
 	existingTemplate.WalletID = tt.WalletID
+	existingTemplate.ToWalletID = tt.ToWalletID
 	existingTemplate.Description = tt.Description
 	existingTemplate.AmountInCents = tt.AmountInCents
 	existingTemplate.Type = tt.Type
 	existingTemplate.Tags = tt.Tags
-	r.repo.transactionTemplates[tt.ID] = existingTemplate
+	existingTemplate.Recurrence = tt.Recurrence
+	setEntry(r.repo, r.repo.transactionTemplates, tt.ID, existingTemplate)
 	return nil
 }
 
+func (r *TransactionTemplateRepository) ReplaceTags(userID int, tags []string, replacement string) (int, error) {
+	r.repo.mu.Lock()
+	defer r.repo.mu.Unlock()
+	changed := 0
+	for id, tt := range r.repo.transactionTemplates {
+		if tt.UserID != userID {
+			continue
+		}
+		var replaced bool
+		if tt.Tags, replaced = domain.ReplaceTags(tt.Tags, tags, replacement); replaced {
+			setEntry(r.repo, r.repo.transactionTemplates, id, tt)
+			changed++
+		}
+	}
+	return changed, nil
+}
+
 func (r *TransactionTemplateRepository) DeleteTransactionTemplate(id int) error {
 	r.repo.mu.Lock()
 	defer r.repo.mu.Unlock()
 	if _, ok := r.repo.transactionTemplates[id]; !ok {
 		return domain.ErrTransactionTemplateNotFound
 	}
-	delete(r.repo.transactionTemplates, id)
+	deleteEntry(r.repo, r.repo.transactionTemplates, id)
 	return nil
 }
 
@@ -78,8 +101,43 @@ func (r *TransactionTemplateRepository) DeleteAllByUser(userID int) error {
 	defer r.repo.mu.Unlock()
 	for id, tt := range r.repo.transactionTemplates {
 		if tt.UserID == userID {
-			delete(r.repo.transactionTemplates, id)
+			deleteEntry(r.repo, r.repo.transactionTemplates, id)
 		}
 	}
 	return nil
 }
+
+func (r *TransactionTemplateRepository) FindAllTransactionTemplates() ([]domain.TransactionTemplate, error) {
+	r.repo.mu.RLock()
+	defer r.repo.mu.RUnlock()
+	res := make([]domain.TransactionTemplate, 0, len(r.repo.transactionTemplates))
+	for _, tt := range r.repo.transactionTemplates {
+		res = append(res, tt)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].ID < res[j].ID
+	})
+	return res, nil
+}
+
+func (r *TransactionTemplateRepository) AdvanceLastOccurrence(id int, from *time.Time, to *time.Time) (bool, error) {
+	r.repo.mu.Lock()
+	defer r.repo.mu.Unlock()
+	tt, ok := r.repo.transactionTemplates[id]
+	if !ok {
+		return false, domain.ErrTransactionTemplateNotFound
+	}
+	if !sameDate(tt.LastOccurrence, from) {
+		return false, nil
+	}
+	tt.LastOccurrence = to
+	setEntry(r.repo, r.repo.transactionTemplates, id, tt)
+	return true, nil
+}
+
+func sameDate(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.Equal(*b)
+}
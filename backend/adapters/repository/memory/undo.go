@@ -0,0 +1,60 @@
+package memory
+
+import "sync"
+
+// locker is the store's lock, or no lock at all inside a unit of work, which
+// holds the store's lock for all of its calls.
+type locker interface {
+	Lock()
+	Unlock()
+	RLock()
+	RUnlock()
+}
+
+var _ locker = (*sync.RWMutex)(nil)
+
+type noLock struct{}
+
+func (noLock) Lock()    {}
+func (noLock) Unlock()  {}
+func (noLock) RLock()   {}
+func (noLock) RUnlock() {}
+
+// undoLog holds how to take back each write of a unit of work, so that a
+// failing unit undoes its own writes and nothing else.
+type undoLog struct {
+	lastID int
+	steps  []func()
+}
+
+func (u *undoLog) rollback(s *store) {
+	for i := len(u.steps) - 1; i >= 0; i-- {
+		u.steps[i]()
+	}
+	s.lastID = u.lastID
+}
+
+// setEntry and deleteEntry are how the repositories write to the store.
+func setEntry[K comparable, V any](r *inMemoryRepositories, m map[K]V, k K, v V) {
+	remember(r, m, k)
+	m[k] = v
+}
+
+func deleteEntry[K comparable, V any](r *inMemoryRepositories, m map[K]V, k K) {
+	remember(r, m, k)
+	delete(m, k)
+}
+
+func remember[K comparable, V any](r *inMemoryRepositories, m map[K]V, k K) {
+	if r.undo == nil {
+		return
+	}
+	old, existed := m[k]
+	r.undo.steps = append(r.undo.steps, func() {
+		if existed {
+			m[k] = old
+		} else {
+			delete(m, k)
+		}
+	})
+}
@@ -1,6 +1,8 @@
 package memory
 
 import (
+	"sort"
+
 	"github.com/fim-lab/expense-tracker/internal/core/domain"
 )
 
@@ -36,10 +38,10 @@ func (r *UserRepository) SaveUser(u domain.User) error {
 	}
 
 	if foundExistingUserByID && existingUsername != u.Username {
-		delete(r.repo.users, existingUsername)
+		deleteEntry(r.repo, r.repo.users, existingUsername)
 	}
 
-	r.repo.users[u.Username] = u
+	setEntry(r.repo, r.repo.users, u.Username, u)
 	return nil
 }
 
@@ -60,9 +62,35 @@ func (r *UserRepository) UpdateUserSalary(userID int, salary int) error {
 	for username, user := range r.repo.users {
 		if user.ID == userID {
 			user.SalaryCents = salary
-			r.repo.users[username] = user
+			setEntry(r.repo, r.repo.users, username, user)
 			return nil
 		}
 	}
 	return domain.ErrUserNotFound
 }
+
+func (r *UserRepository) UpdateUserEmail(userID int, email string) error {
+	r.repo.mu.Lock()
+	defer r.repo.mu.Unlock()
+	for username, user := range r.repo.users {
+		if user.ID == userID {
+			user.Email = email
+			setEntry(r.repo, r.repo.users, username, user)
+			return nil
+		}
+	}
+	return domain.ErrUserNotFound
+}
+
+func (r *UserRepository) FindAllUsers() ([]domain.User, error) {
+	r.repo.mu.RLock()
+	defer r.repo.mu.RUnlock()
+	users := make([]domain.User, 0, len(r.repo.users))
+	for _, user := range r.repo.users {
+		users = append(users, user)
+	}
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].ID < users[j].ID
+	})
+	return users, nil
+}
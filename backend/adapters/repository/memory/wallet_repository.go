@@ -16,7 +16,8 @@ func (r *WalletRepository) SaveWallet(w domain.Wallet) error {
 	if w.ID == 0 {
 		w.ID = r.repo.nextID()
 	}
-	r.repo.wallets[w.ID] = w
+	w.CurrentStatement = nil
+	setEntry(r.repo, r.repo.wallets, w.ID, w)
 	return nil
 }
 
@@ -49,7 +50,8 @@ func (r *WalletRepository) FindWalletsByUser(userID int) ([]domain.Wallet, error
 func (r *WalletRepository) DeleteWallet(id int) error {
 	r.repo.mu.Lock()
 	defer r.repo.mu.Unlock()
-	delete(r.repo.wallets, id)
+	deleteEntry(r.repo, r.repo.wallets, id)
+	r.repo.deleteMemberships(domain.ResourceWallet, id)
 	return nil
 }
 
@@ -58,7 +60,8 @@ func (r *WalletRepository) DeleteAllByUser(userID int) error {
 	defer r.repo.mu.Unlock()
 	for id, w := range r.repo.wallets {
 		if w.UserID == userID {
-			delete(r.repo.wallets, id)
+			deleteEntry(r.repo, r.repo.wallets, id)
+			r.repo.deleteMemberships(domain.ResourceWallet, id)
 		}
 	}
 	return nil
@@ -72,6 +75,28 @@ func (r *WalletRepository) UpdateWallet(w domain.Wallet) error {
 		return domain.ErrWalletNotFound
 	}
 	existingWallet.Name = w.Name
-	r.repo.wallets[w.ID] = existingWallet
+	existingWallet.Type = w.Type
+	existingWallet.StatementClosingDay = w.StatementClosingDay
+	existingWallet.PaymentDueDay = w.PaymentDueDay
+	existingWallet.PaymentWalletID = w.PaymentWalletID
+	setEntry(r.repo, r.repo.wallets, w.ID, existingWallet)
+	return nil
+}
+
+func (r *WalletRepository) SetWalletBalance(id int, balanceCents int) error {
+	r.repo.mu.Lock()
+	defer r.repo.mu.Unlock()
+	w, ok := r.repo.wallets[id]
+	if !ok {
+		return domain.ErrWalletNotFound
+	}
+	w.BalanceCents = balanceCents
+	setEntry(r.repo, r.repo.wallets, id, w)
+	return nil
+}
+
+// LockWalletsByUser has nothing to do, as a unit of work already holds the whole
+// store.
+func (r *WalletRepository) LockWalletsByUser(userID int) error {
 	return nil
 }
@@ -2,11 +2,16 @@ package postgres
 
 import (
 	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
 	"github.com/fim-lab/expense-tracker/internal/core/domain"
+	"github.com/lib/pq"
 )
 
 type BudgetRepository struct {
-	db *sql.DB
+	db dbtx
 }
 
 func NewBudgetRepository(db *sql.DB) *BudgetRepository {
@@ -14,25 +19,50 @@ func NewBudgetRepository(db *sql.DB) *BudgetRepository {
 }
 
 func (r *BudgetRepository) SaveBudget(b domain.Budget) error {
-	query := `INSERT INTO budgets (user_id, name, limit_cents) 
-	          VALUES ($1, $2, $3)`
-	_, err := r.db.Exec(query, b.UserID, b.Name, b.LimitCents)
+	query := `INSERT INTO budgets (user_id, parent_id, name, limit_cents, period, rollover, alert_thresholds) 
+	          VALUES ($1, $2, $3, $4, COALESCE(NULLIF($5, ''), 'MONTHLY'), COALESCE(NULLIF($6, ''), 'RESET'), $7)`
+	_, err := r.db.Exec(query, b.UserID, b.ParentID, b.Name, b.LimitCents, b.Period, b.Rollover, alertThresholds(b))
 	return err
 }
 
 func (r *BudgetRepository) UpdateBudget(b domain.Budget) error {
 	query := `
 		UPDATE budgets
-		SET name = $2, limit_cents = $3 
+		SET name = $2, limit_cents = $3, period = $4, rollover = $5, alert_thresholds = $6
 	    WHERE id = $1`
-	_, err := r.db.Exec(query, b.ID, b.Name, b.LimitCents)
+	_, err := r.db.Exec(query, b.ID, b.Name, b.LimitCents, b.Period, b.Rollover, alertThresholds(b))
 	return err
 }
 
-func (r *BudgetRepository) GetBudgetByID(id int) (domain.Budget, error) {
+func alertThresholds(b domain.Budget) pq.Int64Array {
+	thresholds := make(pq.Int64Array, len(b.AlertThresholds))
+	for i, threshold := range b.AlertThresholds {
+		thresholds[i] = int64(threshold)
+	}
+	return thresholds
+}
+
+const budgetColumns = `id, user_id, parent_id, name, limit_cents, balance_cents, period, rollover, alert_thresholds`
+
+func scanBudget(row interface{ Scan(...any) error }) (domain.Budget, error) {
 	var b domain.Budget
-	err := r.db.QueryRow("SELECT id, user_id, name, limit_cents FROM budgets WHERE id = $1", id).
-		Scan(&b.ID, &b.UserID, &b.Name, &b.LimitCents)
+	var parentID sql.NullInt64
+	var thresholds pq.Int64Array
+	if err := row.Scan(&b.ID, &b.UserID, &parentID, &b.Name, &b.LimitCents, &b.BalanceCents, &b.Period, &b.Rollover, &thresholds); err != nil {
+		return b, err
+	}
+	if parentID.Valid {
+		id := int(parentID.Int64)
+		b.ParentID = &id
+	}
+	for _, threshold := range thresholds {
+		b.AlertThresholds = append(b.AlertThresholds, int(threshold))
+	}
+	return b, nil
+}
+
+func (r *BudgetRepository) GetBudgetByID(id int) (domain.Budget, error) {
+	b, err := scanBudget(r.db.QueryRow("SELECT "+budgetColumns+" FROM budgets WHERE id = $1", id))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return domain.Budget{}, domain.ErrMissingBudget
@@ -43,7 +73,7 @@ func (r *BudgetRepository) GetBudgetByID(id int) (domain.Budget, error) {
 }
 
 func (r *BudgetRepository) FindBudgetsByUser(userID int) ([]domain.Budget, error) {
-	rows, err := r.db.Query("SELECT id, user_id, name, limit_cents, balance_cents FROM budgets WHERE user_id = $1 ORDER BY id ASC", userID)
+	rows, err := r.db.Query("SELECT "+budgetColumns+" FROM budgets WHERE user_id = $1 ORDER BY id ASC", userID)
 	if err != nil {
 		return nil, err
 	}
@@ -51,8 +81,8 @@ func (r *BudgetRepository) FindBudgetsByUser(userID int) ([]domain.Budget, error
 
 	var res []domain.Budget
 	for rows.Next() {
-		var b domain.Budget
-		if err := rows.Scan(&b.ID, &b.UserID, &b.Name, &b.LimitCents, &b.BalanceCents); err != nil {
+		b, err := scanBudget(rows)
+		if err != nil {
 			return nil, err
 		}
 		res = append(res, b)
@@ -60,12 +90,181 @@ func (r *BudgetRepository) FindBudgetsByUser(userID int) ([]domain.Budget, error
 	return res, nil
 }
 
+// DeleteBudget moves the budget's children up to its parent before deleting
+// it.
 func (r *BudgetRepository) DeleteBudget(id int) error {
-	_, err := r.db.Exec("DELETE FROM budgets WHERE id = $1", id)
-	return err
+	tx, err := beginTx(r.db)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`UPDATE budgets SET parent_id = (SELECT parent_id FROM budgets WHERE id = $1) WHERE parent_id = $1`, id); err != nil {
+		return fmt.Errorf("failed to move children of budget %d: %w", id, err)
+	}
+	if _, err := tx.Exec("DELETE FROM budgets WHERE id = $1", id); err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23503" && strings.HasPrefix(pqErr.Constraint, "budget_movements_") {
+			return domain.ErrNotEmpty
+		}
+		return fmt.Errorf("failed to delete budget %d: %w", id, err)
+	}
+	return tx.Commit()
 }
 
+func (r *BudgetRepository) UpdateBudgetParent(id int, parentID *int) error {
+	if _, err := r.db.Exec(`UPDATE budgets SET parent_id = $2 WHERE id = $1`, id, parentID); err != nil {
+		return fmt.Errorf("failed to move budget %d: %w", id, err)
+	}
+	return nil
+}
+
+func (r *BudgetRepository) SetBudgetBalance(id int, balanceCents int) error {
+	if _, err := r.db.Exec(`UPDATE budgets SET balance_cents = $2 WHERE id = $1`, id, balanceCents); err != nil {
+		return fmt.Errorf("failed to set balance of budget %d: %w", id, err)
+	}
+	return nil
+}
+
+func (r *BudgetRepository) LockBudgetsByUser(userID int) error {
+	if _, err := r.db.Exec("SELECT id FROM budgets WHERE user_id = $1 FOR UPDATE", userID); err != nil {
+		return fmt.Errorf("failed to lock the budgets of user %d: %w", userID, err)
+	}
+	return nil
+}
+
+// DeleteAllByUser deletes the user's movements first, as they keep budgets
+// from being deleted.
 func (r *BudgetRepository) DeleteAllByUser(userID int) error {
-	_, err := r.db.Exec("DELETE FROM budgets WHERE user_id = $1", userID)
-	return err
+	tx, err := beginTx(r.db)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM budget_movements WHERE user_id = $1", userID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM budgets WHERE user_id = $1", userID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *BudgetRepository) SaveAllocation(a domain.BudgetAllocation) error {
+	query := `
+		INSERT INTO budget_allocations (budget_id, period_start, amount_in_cents)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (budget_id, period_start) DO UPDATE SET amount_in_cents = EXCLUDED.amount_in_cents`
+	if _, err := r.db.Exec(query, a.BudgetID, a.PeriodStart, a.AmountInCents); err != nil {
+		return fmt.Errorf("failed to save budget allocation: %w", err)
+	}
+	return nil
+}
+
+func (r *BudgetRepository) FindAllocationsByBudget(budgetID int) ([]domain.BudgetAllocation, error) {
+	rows, err := r.db.Query(`SELECT budget_id, period_start, amount_in_cents FROM budget_allocations WHERE budget_id = $1 ORDER BY period_start`, budgetID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch budget allocations: %w", err)
+	}
+	defer rows.Close()
+
+	var res []domain.BudgetAllocation
+	for rows.Next() {
+		var a domain.BudgetAllocation
+		if err := rows.Scan(&a.BudgetID, &a.PeriodStart, &a.AmountInCents); err != nil {
+			return nil, fmt.Errorf("failed to scan budget allocation: %w", err)
+		}
+		res = append(res, a)
+	}
+	return res, rows.Err()
+}
+
+func (r *BudgetRepository) FindAllocationsByUser(userID int) (map[int][]domain.BudgetAllocation, error) {
+	query := `
+		SELECT a.budget_id, a.period_start, a.amount_in_cents
+		FROM budget_allocations a
+		JOIN budgets b ON b.id = a.budget_id
+		WHERE b.user_id = $1
+		ORDER BY a.period_start`
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch budget allocations: %w", err)
+	}
+	defer rows.Close()
+
+	res := make(map[int][]domain.BudgetAllocation)
+	for rows.Next() {
+		var a domain.BudgetAllocation
+		if err := rows.Scan(&a.BudgetID, &a.PeriodStart, &a.AmountInCents); err != nil {
+			return nil, fmt.Errorf("failed to scan budget allocation: %w", err)
+		}
+		res[a.BudgetID] = append(res[a.BudgetID], a)
+	}
+	return res, rows.Err()
+}
+
+func (r *BudgetRepository) SaveMovements(movements []domain.BudgetMovement) error {
+	tx, err := beginTx(r.db)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, m := range movements {
+		_, err := tx.Exec(
+			`INSERT INTO budget_movements (user_id, date, from_budget_id, to_budget_id, amount_in_cents, note) VALUES ($1, $2, $3, $4, $5, $6)`,
+			m.UserID, m.Date, m.FromBudgetID, m.ToBudgetID, m.AmountInCents, m.Note,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to insert budget movement: %w", err)
+		}
+		for _, budgetID := range []*int{m.FromBudgetID, m.ToBudgetID} {
+			if budgetID == nil {
+				continue
+			}
+			_, err := tx.Exec(`UPDATE budgets SET balance_cents = balance_cents + $1 WHERE id = $2 AND user_id = $3`, m.AmountOnBudget(*budgetID), *budgetID, m.UserID)
+			if err != nil {
+				return fmt.Errorf("failed to update budget balance: %w", err)
+			}
+		}
+	}
+	return tx.Commit()
+}
+
+const budgetMovementColumns = `id, user_id, date, from_budget_id, to_budget_id, amount_in_cents, note`
+
+func (r *BudgetRepository) FindMovementsByUser(userID int) ([]domain.BudgetMovement, error) {
+	return r.findMovements(`SELECT `+budgetMovementColumns+` FROM budget_movements WHERE user_id = $1 ORDER BY date, id`, userID)
+}
+
+func (r *BudgetRepository) FindMovementsByBudget(budgetID int) ([]domain.BudgetMovement, error) {
+	return r.findMovements(`SELECT `+budgetMovementColumns+` FROM budget_movements WHERE from_budget_id = $1 OR to_budget_id = $1 ORDER BY date, id`, budgetID)
+}
+
+func (r *BudgetRepository) findMovements(query string, arg int) ([]domain.BudgetMovement, error) {
+	rows, err := r.db.Query(query, arg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch budget movements: %w", err)
+	}
+	defer rows.Close()
+
+	var res []domain.BudgetMovement
+	for rows.Next() {
+		var m domain.BudgetMovement
+		var from, to sql.NullInt64
+		if err := rows.Scan(&m.ID, &m.UserID, &m.Date, &from, &to, &m.AmountInCents, &m.Note); err != nil {
+			return nil, fmt.Errorf("failed to scan budget movement: %w", err)
+		}
+		if from.Valid {
+			id := int(from.Int64)
+			m.FromBudgetID = &id
+		}
+		if to.Valid {
+			id := int(to.Int64)
+			m.ToBudgetID = &id
+		}
+		res = append(res, m)
+	}
+	return res, rows.Err()
 }
@@ -0,0 +1,77 @@
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/fim-lab/expense-tracker/internal/core/domain"
+)
+
+type CounterpartyRepository struct {
+	db dbtx
+}
+
+func NewCounterpartyRepository(db *sql.DB) *CounterpartyRepository {
+	return &CounterpartyRepository{db: db}
+}
+
+func (r *CounterpartyRepository) SaveCounterparty(c domain.Counterparty) (int, error) {
+	query := `
+		INSERT INTO counterparties (user_id, name, created_at)
+		VALUES ($1, $2, $3)
+		RETURNING id`
+	var id int
+	if err := r.db.QueryRow(query, c.UserID, c.Name, c.CreatedAt).Scan(&id); err != nil {
+		return 0, fmt.Errorf("error saving counterparty: %w", err)
+	}
+	return id, nil
+}
+
+const counterpartyColumns = `id, user_id, name, created_at`
+
+func scanCounterparty(row interface{ Scan(...any) error }) (domain.Counterparty, error) {
+	var c domain.Counterparty
+	if err := row.Scan(&c.ID, &c.UserID, &c.Name, &c.CreatedAt); err != nil {
+		return domain.Counterparty{}, err
+	}
+	return c, nil
+}
+
+func (r *CounterpartyRepository) GetCounterpartyByID(id int) (domain.Counterparty, error) {
+	c, err := scanCounterparty(r.db.QueryRow(`SELECT `+counterpartyColumns+` FROM counterparties WHERE id = $1`, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return domain.Counterparty{}, domain.ErrCounterpartyNotFound
+		}
+		return domain.Counterparty{}, fmt.Errorf("error getting counterparty by ID: %w", err)
+	}
+	return c, nil
+}
+
+func (r *CounterpartyRepository) FindCounterpartiesByUser(userID int) ([]domain.Counterparty, error) {
+	rows, err := r.db.Query(`SELECT `+counterpartyColumns+` FROM counterparties WHERE user_id = $1 ORDER BY name, id`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error finding counterparties by user: %w", err)
+	}
+	defer rows.Close()
+
+	var res []domain.Counterparty
+	for rows.Next() {
+		c, err := scanCounterparty(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning counterparty: %w", err)
+		}
+		res = append(res, c)
+	}
+	return res, rows.Err()
+}
+
+func (r *CounterpartyRepository) DeleteCounterparty(id int) error {
+	_, err := r.db.Exec("DELETE FROM counterparties WHERE id = $1", id)
+	return err
+}
+
+func (r *CounterpartyRepository) DeleteAllByUser(userID int) error {
+	_, err := r.db.Exec("DELETE FROM counterparties WHERE user_id = $1", userID)
+	return err
+}
@@ -0,0 +1,171 @@
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/fim-lab/expense-tracker/internal/core/domain"
+	"github.com/lib/pq"
+)
+
+type CSVProfileRepository struct {
+	db dbtx
+}
+
+func NewCSVProfileRepository(db *sql.DB) *CSVProfileRepository {
+	return &CSVProfileRepository{db: db}
+}
+
+func (r *CSVProfileRepository) SaveCSVProfile(p domain.CSVProfile) error {
+	query := `
+		INSERT INTO csv_profiles (user_id, name, encoding, delimiter, date_column, date_format, description_columns,
+		                          amount_column, decimal_separator, sign_convention, indicator_column, debit_indicator, reference_column)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+	`
+	_, err := r.db.Exec(
+		query,
+		p.UserID,
+		p.Name,
+		p.Encoding,
+		p.Delimiter,
+		p.DateColumn,
+		p.DateFormat,
+		pq.Array(p.DescriptionColumns),
+		p.AmountColumn,
+		p.DecimalSeparator,
+		p.SignConvention,
+		p.IndicatorColumn,
+		p.DebitIndicator,
+		p.ReferenceColumn,
+	)
+	if err != nil {
+		return fmt.Errorf("error saving csv profile: %w", err)
+	}
+	return nil
+}
+
+const csvProfileColumns = `id, user_id, name, encoding, delimiter, date_column, date_format, description_columns,
+	amount_column, decimal_separator, sign_convention, indicator_column, debit_indicator, reference_column`
+
+func scanCSVProfile(row interface{ Scan(...any) error }) (domain.CSVProfile, error) {
+	var p domain.CSVProfile
+	var descriptionColumns pq.StringArray
+
+	err := row.Scan(
+		&p.ID,
+		&p.UserID,
+		&p.Name,
+		&p.Encoding,
+		&p.Delimiter,
+		&p.DateColumn,
+		&p.DateFormat,
+		&descriptionColumns,
+		&p.AmountColumn,
+		&p.DecimalSeparator,
+		&p.SignConvention,
+		&p.IndicatorColumn,
+		&p.DebitIndicator,
+		&p.ReferenceColumn,
+	)
+	if err != nil {
+		return domain.CSVProfile{}, err
+	}
+	p.DescriptionColumns = []string(descriptionColumns)
+	return p, nil
+}
+
+func (r *CSVProfileRepository) GetCSVProfileByID(id int) (domain.CSVProfile, error) {
+	query := `SELECT ` + csvProfileColumns + ` FROM csv_profiles WHERE id = $1`
+	p, err := scanCSVProfile(r.db.QueryRow(query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return domain.CSVProfile{}, domain.ErrCSVProfileNotFound
+		}
+		return domain.CSVProfile{}, fmt.Errorf("error getting csv profile by ID: %w", err)
+	}
+	return p, nil
+}
+
+func (r *CSVProfileRepository) FindCSVProfilesByUser(userID int) ([]domain.CSVProfile, error) {
+	query := `SELECT ` + csvProfileColumns + ` FROM csv_profiles WHERE user_id = $1 ORDER BY id`
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error finding csv profiles by user: %w", err)
+	}
+	defer rows.Close()
+
+	var profiles []domain.CSVProfile
+	for rows.Next() {
+		p, err := scanCSVProfile(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning csv profile row: %w", err)
+		}
+		profiles = append(profiles, p)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error with rows in FindCSVProfilesByUser: %w", err)
+	}
+
+	return profiles, nil
+}
+
+func (r *CSVProfileRepository) UpdateCSVProfile(p domain.CSVProfile) error {
+	query := `
+		UPDATE csv_profiles
+		SET name = $1, encoding = $2, delimiter = $3, date_column = $4, date_format = $5, description_columns = $6,
+		    amount_column = $7, decimal_separator = $8, sign_convention = $9, indicator_column = $10,
+		    debit_indicator = $11, reference_column = $12
+		WHERE id = $13 AND user_id = $14
+	`
+	res, err := r.db.Exec(
+		query,
+		p.Name,
+		p.Encoding,
+		p.Delimiter,
+		p.DateColumn,
+		p.DateFormat,
+		pq.Array(p.DescriptionColumns),
+		p.AmountColumn,
+		p.DecimalSeparator,
+		p.SignConvention,
+		p.IndicatorColumn,
+		p.DebitIndicator,
+		p.ReferenceColumn,
+		p.ID,
+		p.UserID,
+	)
+	if err != nil {
+		return fmt.Errorf("error updating csv profile: %w", err)
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error checking rows affected for update: %w", err)
+	}
+	if rowsAffected == 0 {
+		return domain.ErrCSVProfileNotFound
+	}
+	return nil
+}
+
+func (r *CSVProfileRepository) DeleteCSVProfile(id int) error {
+	res, err := r.db.Exec("DELETE FROM csv_profiles WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("error deleting csv profile: %w", err)
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error checking rows affected for delete: %w", err)
+	}
+	if rowsAffected == 0 {
+		return domain.ErrCSVProfileNotFound
+	}
+	return nil
+}
+
+func (r *CSVProfileRepository) DeleteAllByUser(userID int) error {
+	_, err := r.db.Exec("DELETE FROM csv_profiles WHERE user_id = $1", userID)
+	return err
+}
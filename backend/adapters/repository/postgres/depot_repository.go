@@ -6,7 +6,7 @@ import (
 )
 
 type DepotRepository struct {
-	db *sql.DB
+	db dbtx
 }
 
 func NewDepotRepository(db *sql.DB) *DepotRepository {
@@ -0,0 +1,131 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/fim-lab/expense-tracker/internal/core/domain"
+)
+
+type MembershipRepository struct {
+	db dbtx
+}
+
+func NewMembershipRepository(db *sql.DB) *MembershipRepository {
+	return &MembershipRepository{db: db}
+}
+
+// A membership names its resource in the column of the resource's type, so
+// that it goes when the resource is deleted.
+func resourceColumn(resourceType domain.ResourceType) (string, error) {
+	switch resourceType {
+	case domain.ResourceWallet:
+		return "wallet_id", nil
+	case domain.ResourceBudget:
+		return "budget_id", nil
+	case domain.ResourceDepot:
+		return "depot_id", nil
+	}
+	return "", fmt.Errorf("%w: %q", domain.ErrInvalidResourceType, resourceType)
+}
+
+const membershipColumns = `id, owner_id, user_id, wallet_id, budget_id, depot_id, permission, created_at`
+
+func scanMembership(row interface{ Scan(...any) error }) (domain.Membership, error) {
+	var m domain.Membership
+	var walletID, budgetID, depotID sql.NullInt64
+	if err := row.Scan(&m.ID, &m.OwnerID, &m.UserID, &walletID, &budgetID, &depotID, &m.Permission, &m.CreatedAt); err != nil {
+		return domain.Membership{}, err
+	}
+	switch {
+	case walletID.Valid:
+		m.ResourceType, m.ResourceID = domain.ResourceWallet, int(walletID.Int64)
+	case budgetID.Valid:
+		m.ResourceType, m.ResourceID = domain.ResourceBudget, int(budgetID.Int64)
+	case depotID.Valid:
+		m.ResourceType, m.ResourceID = domain.ResourceDepot, int(depotID.Int64)
+	}
+	return m, nil
+}
+
+func (r *MembershipRepository) SaveMembership(m domain.Membership) (int, error) {
+	if m.ID != 0 {
+		if _, err := r.db.Exec(`UPDATE memberships SET permission = $2 WHERE id = $1`, m.ID, m.Permission); err != nil {
+			return 0, fmt.Errorf("error updating membership: %w", err)
+		}
+		return m.ID, nil
+	}
+	column, err := resourceColumn(m.ResourceType)
+	if err != nil {
+		return 0, err
+	}
+	query := `INSERT INTO memberships (owner_id, user_id, ` + column + `, permission, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id`
+	var id int
+	if err := r.db.QueryRow(query, m.OwnerID, m.UserID, m.ResourceID, m.Permission, m.CreatedAt).Scan(&id); err != nil {
+		return 0, fmt.Errorf("error saving membership: %w", err)
+	}
+	return id, nil
+}
+
+func (r *MembershipRepository) GetMembershipByID(id int) (domain.Membership, error) {
+	m, err := scanMembership(r.db.QueryRow(`SELECT `+membershipColumns+` FROM memberships WHERE id = $1`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.Membership{}, domain.ErrMembershipNotFound
+	}
+	return m, err
+}
+
+func (r *MembershipRepository) GetMembership(resourceType domain.ResourceType, resourceID int, userID int) (domain.Membership, error) {
+	column, err := resourceColumn(resourceType)
+	if err != nil {
+		return domain.Membership{}, err
+	}
+	m, err := scanMembership(r.db.QueryRow(`SELECT `+membershipColumns+` FROM memberships WHERE `+column+` = $1 AND user_id = $2`, resourceID, userID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.Membership{}, domain.ErrMembershipNotFound
+	}
+	return m, err
+}
+
+func (r *MembershipRepository) FindMembersOf(resourceType domain.ResourceType, resourceID int) ([]domain.Membership, error) {
+	column, err := resourceColumn(resourceType)
+	if err != nil {
+		return nil, err
+	}
+	return r.find(`WHERE `+column+` = $1`, resourceID)
+}
+
+func (r *MembershipRepository) FindMembershipsByUser(userID int) ([]domain.Membership, error) {
+	return r.find(`WHERE user_id = $1`, userID)
+}
+
+func (r *MembershipRepository) find(where string, args ...any) ([]domain.Membership, error) {
+	rows, err := r.db.Query(`SELECT `+membershipColumns+` FROM memberships `+where+` ORDER BY id`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error finding memberships: %w", err)
+	}
+	defer rows.Close()
+
+	var res []domain.Membership
+	for rows.Next() {
+		m, err := scanMembership(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning membership: %w", err)
+		}
+		res = append(res, m)
+	}
+	return res, rows.Err()
+}
+
+func (r *MembershipRepository) DeleteMembership(id int) error {
+	_, err := r.db.Exec("DELETE FROM memberships WHERE id = $1", id)
+	return err
+}
+
+func (r *MembershipRepository) DeleteAllByUser(userID int) error {
+	_, err := r.db.Exec("DELETE FROM memberships WHERE owner_id = $1 OR user_id = $1", userID)
+	return err
+}
@@ -0,0 +1,90 @@
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/fim-lab/expense-tracker/internal/core/domain"
+)
+
+type NotificationRepository struct {
+	db dbtx
+}
+
+func NewNotificationRepository(db *sql.DB) *NotificationRepository {
+	return &NotificationRepository{db: db}
+}
+
+func (r *NotificationRepository) SaveNotification(n domain.Notification) (int, error) {
+	query := `
+		INSERT INTO notifications (user_id, type, subject, message, budget_id, created_at, read)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id`
+	var id int
+	if err := r.db.QueryRow(query, n.UserID, n.Type, n.Subject, n.Message, n.BudgetID, n.CreatedAt, n.Read).Scan(&id); err != nil {
+		return 0, fmt.Errorf("error saving notification: %w", err)
+	}
+	return id, nil
+}
+
+const notificationColumns = `id, user_id, type, subject, message, budget_id, created_at, read`
+
+func scanNotification(row interface{ Scan(...any) error }) (domain.Notification, error) {
+	var n domain.Notification
+	var budgetID sql.NullInt64
+	if err := row.Scan(&n.ID, &n.UserID, &n.Type, &n.Subject, &n.Message, &budgetID, &n.CreatedAt, &n.Read); err != nil {
+		return domain.Notification{}, err
+	}
+	if budgetID.Valid {
+		id := int(budgetID.Int64)
+		n.BudgetID = &id
+	}
+	return n, nil
+}
+
+func (r *NotificationRepository) GetNotificationByID(id int) (domain.Notification, error) {
+	n, err := scanNotification(r.db.QueryRow(`SELECT `+notificationColumns+` FROM notifications WHERE id = $1`, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return domain.Notification{}, domain.ErrNotificationNotFound
+		}
+		return domain.Notification{}, fmt.Errorf("error getting notification by ID: %w", err)
+	}
+	return n, nil
+}
+
+func (r *NotificationRepository) FindNotificationsByUser(userID int) ([]domain.Notification, error) {
+	rows, err := r.db.Query(`SELECT `+notificationColumns+` FROM notifications WHERE user_id = $1 ORDER BY created_at DESC, id DESC`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error finding notifications by user: %w", err)
+	}
+	defer rows.Close()
+
+	var notifications []domain.Notification
+	for rows.Next() {
+		n, err := scanNotification(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning notification row: %w", err)
+		}
+		notifications = append(notifications, n)
+	}
+	return notifications, rows.Err()
+}
+
+func (r *NotificationRepository) MarkNotificationRead(id int) error {
+	res, err := r.db.Exec(`UPDATE notifications SET read = TRUE WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("error marking notification read: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return domain.ErrNotificationNotFound
+	}
+	return nil
+}
+
+func (r *NotificationRepository) DeleteAllByUser(userID int) error {
+	if _, err := r.db.Exec(`DELETE FROM notifications WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("error deleting notifications: %w", err)
+	}
+	return nil
+}
@@ -9,7 +9,49 @@ import (
 	"github.com/fim-lab/expense-tracker/internal/core/ports"
 )
 
+// dbtx is what repositories run their queries on: the database, or the
+// transaction of a unit of work.
+type dbtx interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	Query(query string, args ...any) (*sql.Rows, error)
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+// txScope is a transaction a repository method runs its statements in. Inside
+// a unit of work it is the unit's transaction, which only the unit commits or
+// rolls back.
+type txScope struct {
+	*sql.Tx
+	owned bool
+}
+
+func beginTx(db dbtx) (txScope, error) {
+	if tx, ok := db.(*sql.Tx); ok {
+		return txScope{Tx: tx}, nil
+	}
+	tx, err := db.(*sql.DB).Begin()
+	if err != nil {
+		return txScope{}, err
+	}
+	return txScope{Tx: tx, owned: true}, nil
+}
+
+func (t txScope) Commit() error {
+	if !t.owned {
+		return nil
+	}
+	return t.Tx.Commit()
+}
+
+func (t txScope) Rollback() error {
+	if !t.owned {
+		return nil
+	}
+	return t.Tx.Rollback()
+}
+
 type postgresRepositoryCollection struct {
+	db                      *sql.DB // nil inside a unit of work
 	userRepo                *UserRepository
 	sessionRepo             *SessionRepository
 	budgetRepo              *BudgetRepository
@@ -19,20 +61,40 @@ type postgresRepositoryCollection struct {
 	tradeRepo               *TradeRepository
 	transactionTemplateRepo *TransactionTemplateRepository
 	stockRepo               *StockRepository
+	csvProfileRepo          *CSVProfileRepository
+	notificationRepo        *NotificationRepository
+	savingsGoalRepo         *SavingsGoalRepository
+	reconciliationRepo      *ReconciliationRepository
+	counterpartyRepo        *CounterpartyRepository
+	sharedExpenseRepo       *SharedExpenseRepository
+	membershipRepo          *MembershipRepository
 }
 
 func NewPostgresRepositoryCollection() (*sql.DB, ports.Repositories) {
 	db := setupPostgresDB()
-	return db, &postgresRepositoryCollection{
-		userRepo:                NewUserRepository(db),
-		sessionRepo:             NewSessionRepository(db),
-		budgetRepo:              NewBudgetRepository(db),
-		walletRepo:              NewWalletRepository(db),
-		depotRepo:               NewDepotRepository(db),
-		transactionRepo:         NewTransactionRepository(db),
-		tradeRepo:               NewTradeRepository(db),
-		transactionTemplateRepo: NewTransactionTemplateRepository(db),
-		stockRepo:               NewStockRepository(db),
+	repos := newRepositoryCollection(db)
+	repos.db = db
+	return db, repos
+}
+
+func newRepositoryCollection(db dbtx) *postgresRepositoryCollection {
+	return &postgresRepositoryCollection{
+		userRepo:                &UserRepository{db: db},
+		sessionRepo:             &SessionRepository{db: db},
+		budgetRepo:              &BudgetRepository{db: db},
+		walletRepo:              &WalletRepository{db: db},
+		depotRepo:               &DepotRepository{db: db},
+		transactionRepo:         &TransactionRepository{db: db},
+		tradeRepo:               &TradeRepository{db: db},
+		transactionTemplateRepo: &TransactionTemplateRepository{db: db},
+		stockRepo:               &StockRepository{db: db},
+		csvProfileRepo:          &CSVProfileRepository{db: db},
+		notificationRepo:        &NotificationRepository{db: db},
+		savingsGoalRepo:         &SavingsGoalRepository{db: db},
+		reconciliationRepo:      &ReconciliationRepository{db: db},
+		counterpartyRepo:        &CounterpartyRepository{db: db},
+		sharedExpenseRepo:       &SharedExpenseRepository{db: db},
+		membershipRepo:          &MembershipRepository{db: db},
 	}
 }
 
@@ -89,3 +151,53 @@ func (prc *postgresRepositoryCollection) TransactionTemplateRepository() ports.T
 func (prc *postgresRepositoryCollection) StockRepository() ports.StockRepository {
 	return prc.stockRepo
 }
+
+func (prc *postgresRepositoryCollection) CSVProfileRepository() ports.CSVProfileRepository {
+	return prc.csvProfileRepo
+}
+
+func (prc *postgresRepositoryCollection) NotificationRepository() ports.NotificationRepository {
+	return prc.notificationRepo
+}
+
+func (prc *postgresRepositoryCollection) SavingsGoalRepository() ports.SavingsGoalRepository {
+	return prc.savingsGoalRepo
+}
+
+func (prc *postgresRepositoryCollection) ReconciliationRepository() ports.ReconciliationRepository {
+	return prc.reconciliationRepo
+}
+
+func (prc *postgresRepositoryCollection) CounterpartyRepository() ports.CounterpartyRepository {
+	return prc.counterpartyRepo
+}
+
+func (prc *postgresRepositoryCollection) SharedExpenseRepository() ports.SharedExpenseRepository {
+	return prc.sharedExpenseRepo
+}
+
+func (prc *postgresRepositoryCollection) MembershipRepository() ports.MembershipRepository {
+	return prc.membershipRepo
+}
+
+// WithinTransaction runs fn on repositories sharing one database transaction.
+// A unit of work started inside another one joins it.
+func (prc *postgresRepositoryCollection) WithinTransaction(fn func(repos ports.Repositories) error) error {
+	if prc.db == nil {
+		return fn(prc)
+	}
+
+	tx, err := prc.db.Begin()
+	if err != nil {
+		return fmt.Errorf("could not start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(newRepositoryCollection(tx)); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/fim-lab/expense-tracker/internal/core/domain"
+)
+
+type ReconciliationRepository struct {
+	db dbtx
+}
+
+func NewReconciliationRepository(db *sql.DB) *ReconciliationRepository {
+	return &ReconciliationRepository{db: db}
+}
+
+func (r *ReconciliationRepository) SaveReconciliation(rec domain.Reconciliation) (int, error) {
+	query := `
+		INSERT INTO reconciliations (user_id, wallet_id, statement_date, statement_balance_cents, status, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id`
+	var id int
+	err := r.db.QueryRow(query, rec.UserID, rec.WalletID, rec.StatementDate, rec.StatementBalanceCents, rec.Status, rec.CreatedAt).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("error saving reconciliation: %w", err)
+	}
+	return id, nil
+}
+
+const reconciliationColumns = `id, user_id, wallet_id, statement_date, statement_balance_cents, status, created_at, completed_at`
+
+func scanReconciliation(row interface{ Scan(...any) error }) (domain.Reconciliation, error) {
+	var rec domain.Reconciliation
+	var completedAt sql.NullTime
+	err := row.Scan(&rec.ID, &rec.UserID, &rec.WalletID, &rec.StatementDate, &rec.StatementBalanceCents, &rec.Status, &rec.CreatedAt, &completedAt)
+	if err != nil {
+		return domain.Reconciliation{}, err
+	}
+	if completedAt.Valid {
+		rec.CompletedAt = &completedAt.Time
+	}
+	return rec, nil
+}
+
+func (r *ReconciliationRepository) GetReconciliationByID(id int) (domain.Reconciliation, error) {
+	rec, err := scanReconciliation(r.db.QueryRow(`SELECT `+reconciliationColumns+` FROM reconciliations WHERE id = $1`, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return domain.Reconciliation{}, domain.ErrReconciliationNotFound
+		}
+		return domain.Reconciliation{}, fmt.Errorf("error getting reconciliation by ID: %w", err)
+	}
+	return rec, nil
+}
+
+func (r *ReconciliationRepository) FindReconciliationsByWallet(walletID int) ([]domain.Reconciliation, error) {
+	rows, err := r.db.Query(`SELECT `+reconciliationColumns+` FROM reconciliations WHERE wallet_id = $1 ORDER BY statement_date DESC, id DESC`, walletID)
+	if err != nil {
+		return nil, fmt.Errorf("error finding reconciliations by wallet: %w", err)
+	}
+	defer rows.Close()
+
+	var res []domain.Reconciliation
+	for rows.Next() {
+		rec, err := scanReconciliation(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning reconciliation: %w", err)
+		}
+		res = append(res, rec)
+	}
+	return res, rows.Err()
+}
+
+func (r *ReconciliationRepository) CompleteReconciliation(id int, completedAt time.Time) error {
+	_, err := r.db.Exec(`UPDATE reconciliations SET status = $2, completed_at = $3 WHERE id = $1`, id, domain.ReconciliationCompleted, completedAt)
+	if err != nil {
+		return fmt.Errorf("error completing reconciliation: %w", err)
+	}
+	return nil
+}
+
+// DeleteReconciliation leaves releasing its transactions to the foreign key,
+// which sets their reconciliation_id to NULL.
+func (r *ReconciliationRepository) DeleteReconciliation(id int) error {
+	_, err := r.db.Exec("DELETE FROM reconciliations WHERE id = $1", id)
+	return err
+}
+
+func (r *ReconciliationRepository) DeleteAllByUser(userID int) error {
+	_, err := r.db.Exec("DELETE FROM reconciliations WHERE user_id = $1", userID)
+	return err
+}
@@ -0,0 +1,119 @@
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/fim-lab/expense-tracker/internal/core/domain"
+)
+
+type SavingsGoalRepository struct {
+	db dbtx
+}
+
+func NewSavingsGoalRepository(db *sql.DB) *SavingsGoalRepository {
+	return &SavingsGoalRepository{db: db}
+}
+
+func (r *SavingsGoalRepository) SaveSavingsGoal(g domain.SavingsGoal) (int, error) {
+	query := `
+		INSERT INTO savings_goals (user_id, name, wallet_id, budget_id, target_cents, target_date, monthly_contribution_cents, from_wallet_id, contribution_template_id, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id`
+	var id int
+	err := r.db.QueryRow(query,
+		g.UserID, g.Name, g.WalletID, g.BudgetID, g.TargetCents, g.TargetDate,
+		g.MonthlyContributionCents, g.FromWalletID, g.ContributionTemplateID, g.CreatedAt,
+	).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("error saving savings goal: %w", err)
+	}
+	return id, nil
+}
+
+const savingsGoalColumns = `id, user_id, name, wallet_id, budget_id, target_cents, target_date, monthly_contribution_cents, from_wallet_id, contribution_template_id, created_at`
+
+func scanSavingsGoal(row interface{ Scan(...any) error }) (domain.SavingsGoal, error) {
+	var g domain.SavingsGoal
+	var walletID, budgetID, fromWalletID, templateID sql.NullInt64
+	err := row.Scan(&g.ID, &g.UserID, &g.Name, &walletID, &budgetID, &g.TargetCents, &g.TargetDate,
+		&g.MonthlyContributionCents, &fromWalletID, &templateID, &g.CreatedAt)
+	if err != nil {
+		return domain.SavingsGoal{}, err
+	}
+	g.WalletID = nullableID(walletID)
+	g.BudgetID = nullableID(budgetID)
+	g.FromWalletID = nullableID(fromWalletID)
+	g.ContributionTemplateID = nullableID(templateID)
+	return g, nil
+}
+
+func nullableID(id sql.NullInt64) *int {
+	if !id.Valid {
+		return nil
+	}
+	v := int(id.Int64)
+	return &v
+}
+
+func (r *SavingsGoalRepository) GetSavingsGoalByID(id int) (domain.SavingsGoal, error) {
+	g, err := scanSavingsGoal(r.db.QueryRow(`SELECT `+savingsGoalColumns+` FROM savings_goals WHERE id = $1`, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return domain.SavingsGoal{}, domain.ErrSavingsGoalNotFound
+		}
+		return domain.SavingsGoal{}, fmt.Errorf("error getting savings goal by ID: %w", err)
+	}
+	return g, nil
+}
+
+func (r *SavingsGoalRepository) FindSavingsGoalsByUser(userID int) ([]domain.SavingsGoal, error) {
+	rows, err := r.db.Query(`SELECT `+savingsGoalColumns+` FROM savings_goals WHERE user_id = $1 ORDER BY target_date, id`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error finding savings goals by user: %w", err)
+	}
+	defer rows.Close()
+
+	var goals []domain.SavingsGoal
+	for rows.Next() {
+		g, err := scanSavingsGoal(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning savings goal row: %w", err)
+		}
+		goals = append(goals, g)
+	}
+	return goals, rows.Err()
+}
+
+func (r *SavingsGoalRepository) UpdateSavingsGoal(g domain.SavingsGoal) error {
+	query := `
+		UPDATE savings_goals
+		SET name = $1, wallet_id = $2, budget_id = $3, target_cents = $4, target_date = $5,
+		    monthly_contribution_cents = $6, from_wallet_id = $7, contribution_template_id = $8
+		WHERE id = $9`
+	res, err := r.db.Exec(query,
+		g.Name, g.WalletID, g.BudgetID, g.TargetCents, g.TargetDate,
+		g.MonthlyContributionCents, g.FromWalletID, g.ContributionTemplateID, g.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("error updating savings goal: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return domain.ErrSavingsGoalNotFound
+	}
+	return nil
+}
+
+func (r *SavingsGoalRepository) DeleteSavingsGoal(id int) error {
+	if _, err := r.db.Exec(`DELETE FROM savings_goals WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("error deleting savings goal: %w", err)
+	}
+	return nil
+}
+
+func (r *SavingsGoalRepository) DeleteAllByUser(userID int) error {
+	if _, err := r.db.Exec(`DELETE FROM savings_goals WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("error deleting savings goals: %w", err)
+	}
+	return nil
+}
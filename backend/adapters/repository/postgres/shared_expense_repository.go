@@ -0,0 +1,198 @@
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/fim-lab/expense-tracker/internal/core/domain"
+)
+
+type SharedExpenseRepository struct {
+	db dbtx
+}
+
+func NewSharedExpenseRepository(db *sql.DB) *SharedExpenseRepository {
+	return &SharedExpenseRepository{db: db}
+}
+
+func (r *SharedExpenseRepository) SaveSharedExpense(e domain.SharedExpense) (int, error) {
+	tx, err := beginTx(r.db)
+	if err != nil {
+		return 0, fmt.Errorf("could not start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO shared_expenses (transaction_id, payer_id, method, created_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id`
+	var id int
+	if err := tx.QueryRow(query, e.TransactionID, e.PayerID, e.Method, e.CreatedAt).Scan(&id); err != nil {
+		return 0, fmt.Errorf("error saving shared expense: %w", err)
+	}
+	for _, s := range e.Shares {
+		_, err := tx.Exec(
+			`INSERT INTO expense_shares (shared_expense_id, user_id, percent, amount_in_cents) VALUES ($1, $2, $3, $4)`,
+			id, s.UserID, s.Percent, s.AmountInCents,
+		)
+		if err != nil {
+			return 0, fmt.Errorf("error saving expense share: %w", err)
+		}
+	}
+	return id, tx.Commit()
+}
+
+const sharedExpenseColumns = `e.id, e.transaction_id, e.payer_id, e.method, e.created_at, t.date, t.description, t.amount_in_cents`
+
+func (r *SharedExpenseRepository) findSharedExpenses(where string, args ...any) ([]domain.SharedExpense, error) {
+	rows, err := r.db.Query(`SELECT `+sharedExpenseColumns+`
+		FROM shared_expenses e JOIN transactions t ON t.id = e.transaction_id
+		WHERE `+where+`
+		ORDER BY t.date, e.id`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error finding shared expenses: %w", err)
+	}
+	defer rows.Close()
+
+	var res []domain.SharedExpense
+	for rows.Next() {
+		var e domain.SharedExpense
+		if err := rows.Scan(&e.ID, &e.TransactionID, &e.PayerID, &e.Method, &e.CreatedAt, &e.Date, &e.Description, &e.AmountInCents); err != nil {
+			return nil, fmt.Errorf("error scanning shared expense: %w", err)
+		}
+		res = append(res, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range res {
+		if res[i].Shares, err = r.findShares(res[i]); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// findShares returns the shares with the payer's last, as Allocate leaves
+// them.
+func (r *SharedExpenseRepository) findShares(e domain.SharedExpense) ([]domain.ExpenseShare, error) {
+	rows, err := r.db.Query(`
+		SELECT user_id, percent, amount_in_cents FROM expense_shares
+		WHERE shared_expense_id = $1
+		ORDER BY user_id = $2, user_id`, e.ID, e.PayerID)
+	if err != nil {
+		return nil, fmt.Errorf("error finding expense shares: %w", err)
+	}
+	defer rows.Close()
+
+	var shares []domain.ExpenseShare
+	for rows.Next() {
+		var s domain.ExpenseShare
+		if err := rows.Scan(&s.UserID, &s.Percent, &s.AmountInCents); err != nil {
+			return nil, fmt.Errorf("error scanning expense share: %w", err)
+		}
+		shares = append(shares, s)
+	}
+	return shares, rows.Err()
+}
+
+func (r *SharedExpenseRepository) GetSharedExpenseByTransaction(transactionID int) (domain.SharedExpense, error) {
+	res, err := r.findSharedExpenses(`e.transaction_id = $1`, transactionID)
+	if err != nil {
+		return domain.SharedExpense{}, err
+	}
+	if len(res) == 0 {
+		return domain.SharedExpense{}, domain.ErrSharedExpenseNotFound
+	}
+	return res[0], nil
+}
+
+func (r *SharedExpenseRepository) FindSharedExpensesByUser(userID int) ([]domain.SharedExpense, error) {
+	return r.findSharedExpenses(`e.payer_id = $1 OR e.id IN (SELECT shared_expense_id FROM expense_shares WHERE user_id = $1)`, userID)
+}
+
+func (r *SharedExpenseRepository) DeleteSharedExpense(id int) error {
+	_, err := r.db.Exec("DELETE FROM shared_expenses WHERE id = $1", id)
+	return err
+}
+
+func (r *SharedExpenseRepository) SaveSettlement(s domain.Settlement) (int, error) {
+	query := `
+		INSERT INTO settlements (from_user_id, to_user_id, amount_in_cents, date, from_transaction_id, to_transaction_id, confirmed_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id`
+	var id int
+	err := r.db.QueryRow(query, s.FromUserID, s.ToUserID, s.AmountInCents, s.Date, s.FromTransactionID, s.ToTransactionID, s.ConfirmedAt, s.CreatedAt).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("error saving settlement: %w", err)
+	}
+	return id, nil
+}
+
+const settlementColumns = `id, from_user_id, to_user_id, amount_in_cents, date, from_transaction_id, to_transaction_id, confirmed_at, created_at`
+
+func scanSettlement(row interface{ Scan(...any) error }) (domain.Settlement, error) {
+	var s domain.Settlement
+	var fromTransactionID, toTransactionID sql.NullInt64
+	var confirmedAt sql.NullTime
+	if err := row.Scan(&s.ID, &s.FromUserID, &s.ToUserID, &s.AmountInCents, &s.Date, &fromTransactionID, &toTransactionID, &confirmedAt, &s.CreatedAt); err != nil {
+		return domain.Settlement{}, err
+	}
+	s.FromTransactionID, s.ToTransactionID = nullableID(fromTransactionID), nullableID(toTransactionID)
+	if confirmedAt.Valid {
+		s.ConfirmedAt = &confirmedAt.Time
+	}
+	return s, nil
+}
+
+func (r *SharedExpenseRepository) GetSettlementByID(id int) (domain.Settlement, error) {
+	s, err := scanSettlement(r.db.QueryRow(`SELECT `+settlementColumns+` FROM settlements WHERE id = $1`, id))
+	if err == sql.ErrNoRows {
+		return domain.Settlement{}, domain.ErrSettlementNotFound
+	}
+	if err != nil {
+		return domain.Settlement{}, fmt.Errorf("error fetching settlement: %w", err)
+	}
+	return s, nil
+}
+
+func (r *SharedExpenseRepository) UpdateSettlement(s domain.Settlement) error {
+	result, err := r.db.Exec(`
+		UPDATE settlements SET from_transaction_id = $1, to_transaction_id = $2, confirmed_at = $3
+		WHERE id = $4`, s.FromTransactionID, s.ToTransactionID, s.ConfirmedAt, s.ID)
+	if err != nil {
+		return fmt.Errorf("error updating settlement: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return domain.ErrSettlementNotFound
+	}
+	return nil
+}
+
+func (r *SharedExpenseRepository) FindSettlementsByUser(userID int) ([]domain.Settlement, error) {
+	rows, err := r.db.Query(`
+		SELECT `+settlementColumns+`
+		FROM settlements
+		WHERE from_user_id = $1 OR to_user_id = $1
+		ORDER BY date, id`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error finding settlements: %w", err)
+	}
+	defer rows.Close()
+
+	var res []domain.Settlement
+	for rows.Next() {
+		s, err := scanSettlement(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning settlement: %w", err)
+		}
+		res = append(res, s)
+	}
+	return res, rows.Err()
+}
+
+func (r *SharedExpenseRepository) DeleteAllByUser(userID int) error {
+	_, err := r.db.Exec("DELETE FROM shared_expenses WHERE payer_id = $1", userID)
+	return err
+}
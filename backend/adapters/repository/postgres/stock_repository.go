@@ -7,7 +7,7 @@ import (
 )
 
 type StockRepository struct {
-	db *sql.DB
+	db dbtx
 }
 
 func NewStockRepository(db *sql.DB) *StockRepository {
@@ -7,7 +7,7 @@ import (
 )
 
 type TradeRepository struct {
-	db *sql.DB
+	db dbtx
 }
 
 func NewTradeRepository(db *sql.DB) *TradeRepository {
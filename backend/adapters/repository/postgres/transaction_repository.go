@@ -3,13 +3,16 @@ package postgres
 import (
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/fim-lab/expense-tracker/internal/core/domain"
+	"github.com/lib/pq"
 )
 
 type TransactionRepository struct {
-	db *sql.DB
+	db dbtx
 }
 
 func NewTransactionRepository(db *sql.DB) *TransactionRepository {
@@ -17,37 +20,36 @@ func NewTransactionRepository(db *sql.DB) *TransactionRepository {
 }
 
 func (r *TransactionRepository) SaveTransaction(t domain.Transaction) (int, error) {
-	tx, err := r.db.Begin()
+	tx, err := beginTx(r.db)
 	if err != nil {
 		return 0, fmt.Errorf("could not start transaction: %w", err)
 	}
 	defer tx.Rollback()
 	tags, _ := json.Marshal(t.Tags)
 
-	query := `INSERT INTO transactions (user_id, date, budget_id, wallet_id, description, amount_in_cents, type, is_pending, is_debt, tags)
-	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id`
+	query := `INSERT INTO transactions (user_id, date, budget_id, wallet_id, description, amount_in_cents, type, is_pending, is_debt, tags, external_id, cleared, counterparty_id, repayment_of_id, created_by, reconciliation_id)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NULLIF($11, ''), $12, $13, $14, NULLIF($15, 0), $16) RETURNING id`
 	var id int
-	err = tx.QueryRow(query, t.UserID, t.Date, t.BudgetID, t.WalletID, t.Description, t.AmountInCents, t.Type, t.IsPending, t.IsDebt, tags).Scan(&id)
+	err = tx.QueryRow(query, t.UserID, t.Date, t.BudgetID, t.WalletID, t.Description, t.AmountInCents, t.Type, t.IsPending, t.IsDebt, tags, t.ExternalID, t.Cleared, t.CounterpartyID, t.RepaymentOfID, t.CreatedBy, t.ReconciliationID).Scan(&id)
 	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" && pqErr.Constraint == "transactions_user_id_external_id_key" {
+			return 0, domain.ErrDuplicateExternalID
+		}
 		return 0, fmt.Errorf("failed to insert transaction: %w", err)
 	}
+	if err := saveSplits(tx, id, t.Splits); err != nil {
+		return 0, err
+	}
+	if err := adjustBudgets(tx, t, 1); err != nil {
+		return 0, err
+	}
+
 	adjustment := t.AmountInCents
 	if t.Type == domain.Expense {
 		adjustment = -t.AmountInCents
 	}
 
-	if t.BudgetID != nil {
-		queryBudget := `
-			UPDATE budgets 
-			SET balance_cents = balance_cents + $1 
-			WHERE id = $2 AND user_id = $3
-		`
-		_, err = tx.Exec(queryBudget, adjustment, t.BudgetID, t.UserID)
-		if err != nil {
-			return 0, fmt.Errorf("failed to update budget balance: %w", err)
-		}
-	}
-
 	queryWallet := `
 		UPDATE wallets
 		SET balance_cents = balance_cents + $1
@@ -66,25 +68,21 @@ func (r *TransactionRepository) SaveTransaction(t domain.Transaction) (int, erro
 }
 
 func (r *TransactionRepository) UpdateTransaction(t domain.Transaction) error {
-	tx, err := r.db.Begin()
+	tx, err := beginTx(r.db)
 	if err != nil {
 		return fmt.Errorf("could not start transaction: %w", err)
 	}
 	defer tx.Rollback()
 
-	var oldT domain.Transaction
-	var oldNullBudgetID sql.NullInt32
-	queryFetch := `SELECT amount_in_cents, type, budget_id, wallet_id FROM transactions WHERE id = $1 AND user_id = $2`
-	err = tx.QueryRow(queryFetch, t.ID, t.UserID).Scan(&oldT.AmountInCents, &oldT.Type, &oldNullBudgetID, &oldT.WalletID)
+	oldT, err := scanTransaction(tx.QueryRow(`SELECT `+transactionColumns+` FROM transactions WHERE id = $1 AND user_id = $2`, t.ID, t.UserID))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return domain.ErrTransactionNotFound
 		}
 		return fmt.Errorf("could not find original transaction: %w", err)
 	}
-	if oldNullBudgetID.Valid {
-		oldBudgetID := int(oldNullBudgetID.Int32)
-		oldT.BudgetID = &oldBudgetID
+	if oldT.Splits, err = findSplits(tx, oldT.ID); err != nil {
+		return err
 	}
 
 	oldAdjustment := oldT.AmountInCents
@@ -92,9 +90,7 @@ func (r *TransactionRepository) UpdateTransaction(t domain.Transaction) error {
 		oldAdjustment = -oldT.AmountInCents
 	}
 
-	queryRevertBudget := `UPDATE budgets SET balance_cents = balance_cents + $1 WHERE id = $2`
-	_, err = tx.Exec(queryRevertBudget, oldAdjustment, oldT.BudgetID)
-	if err != nil {
+	if err := adjustBudgets(tx, oldT, -1); err != nil {
 		return fmt.Errorf("failed to revert budget balance: %w", err)
 	}
 
@@ -107,24 +103,29 @@ func (r *TransactionRepository) UpdateTransaction(t domain.Transaction) error {
 	tags, _ := json.Marshal(t.Tags)
 	query := `
 		UPDATE transactions
-		SET date = $2, budget_id = $3, wallet_id = $4, description = $5, amount_in_cents = $6, type = $7, is_pending = $8, is_debt = $9, tags = $10
+		SET date = $2, budget_id = $3, wallet_id = $4, description = $5, amount_in_cents = $6, type = $7, is_pending = $8, is_debt = $9, tags = $10,
+		    pending_overdue = pending_overdue AND $8
 	    WHERE id = $1 AND user_id = $11`
 	_, err = tx.Exec(query, t.ID, t.Date, t.BudgetID, t.WalletID, t.Description, t.AmountInCents, t.Type, t.IsPending, t.IsDebt, tags, t.UserID)
 	if err != nil {
 		return fmt.Errorf("failed to update transaction record: %w", err)
 	}
 
+	if _, err := tx.Exec(`DELETE FROM transaction_splits WHERE transaction_id = $1`, t.ID); err != nil {
+		return fmt.Errorf("failed to remove old splits: %w", err)
+	}
+	if err := saveSplits(tx, t.ID, t.Splits); err != nil {
+		return err
+	}
+	if err := adjustBudgets(tx, t, 1); err != nil {
+		return fmt.Errorf("failed to apply new budget balance: %w", err)
+	}
+
 	newAdjustment := t.AmountInCents
 	if t.Type == domain.Expense {
 		newAdjustment = -t.AmountInCents
 	}
 
-	queryApplyBudget := `UPDATE budgets SET balance_cents = balance_cents + $1 WHERE id = $2`
-	_, err = tx.Exec(queryApplyBudget, newAdjustment, t.BudgetID)
-	if err != nil {
-		return fmt.Errorf("failed to apply new budget balance: %w", err)
-	}
-
 	queryApplyWallet := `UPDATE wallets SET balance_cents = balance_cents + $1 WHERE id = $2`
 	_, err = tx.Exec(queryApplyWallet, newAdjustment, t.WalletID)
 	if err != nil {
@@ -134,29 +135,283 @@ func (r *TransactionRepository) UpdateTransaction(t domain.Transaction) error {
 	return tx.Commit()
 }
 
-func (r *TransactionRepository) GetTransactionByID(id int) (domain.Transaction, error) {
+const transactionColumns = `id, user_id, date, budget_id, wallet_id, description, amount_in_cents, type, is_pending, is_debt, tags, COALESCE(external_id, ''), cleared, reconciliation_id, pending_overdue, counterparty_id, repayment_of_id, COALESCE(created_by, 0)`
+
+func scanTransaction(row interface{ Scan(...any) error }) (domain.Transaction, error) {
 	var t domain.Transaction
 	var tags []byte
-	query := `SELECT id, user_id, date, budget_id, wallet_id, description, amount_in_cents, type, is_pending, is_debt, tags 
-	          FROM transactions WHERE id = $1`
 	var nullBudgetID sql.NullInt32
-	err := r.db.QueryRow(query, id).Scan(
-		&t.ID, &t.UserID, &t.Date, &nullBudgetID, &t.WalletID, &t.Description, &t.AmountInCents, &t.Type, &t.IsPending, &t.IsDebt, &tags,
+	var reconciliationID, counterpartyID, repaymentOfID sql.NullInt64
+	err := row.Scan(
+		&t.ID, &t.UserID, &t.Date, &nullBudgetID, &t.WalletID, &t.Description, &t.AmountInCents, &t.Type, &t.IsPending, &t.IsDebt, &tags, &t.ExternalID, &t.Cleared, &reconciliationID, &t.PendingOverdue,
+		&counterpartyID, &repaymentOfID, &t.CreatedBy,
 	)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return domain.Transaction{}, domain.ErrTransactionNotFound
-		}
 		return domain.Transaction{}, err
 	}
 	if nullBudgetID.Valid {
 		budgetID := int(nullBudgetID.Int32)
 		t.BudgetID = &budgetID
 	}
+	t.ReconciliationID = nullableID(reconciliationID)
+	t.CounterpartyID, t.RepaymentOfID = nullableID(counterpartyID), nullableID(repaymentOfID)
 	json.Unmarshal(tags, &t.Tags)
 	return t, nil
 }
 
+func (r *TransactionRepository) GetTransactionByID(id int) (domain.Transaction, error) {
+	t, err := scanTransaction(r.db.QueryRow(`SELECT `+transactionColumns+` FROM transactions WHERE id = $1`, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return domain.Transaction{}, domain.ErrTransactionNotFound
+		}
+		return domain.Transaction{}, err
+	}
+	if t.Splits, err = findSplits(r.db, id); err != nil {
+		return domain.Transaction{}, err
+	}
+	return t, nil
+}
+
+// FindAllTransactionsByUser returns the user's transactions in the order they
+// were saved.
+func (r *TransactionRepository) FindAllTransactionsByUser(userID int) ([]domain.Transaction, error) {
+	rows, err := r.db.Query(`SELECT `+transactionColumns+` FROM transactions WHERE user_id = $1 ORDER BY id`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch transactions: %w", err)
+	}
+	defer rows.Close()
+
+	var transactions []domain.Transaction
+	for rows.Next() {
+		t, err := scanTransaction(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan transaction: %w", err)
+		}
+		transactions = append(transactions, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range transactions {
+		if transactions[i].Splits, err = findSplits(r.db, transactions[i].ID); err != nil {
+			return nil, err
+		}
+	}
+	return transactions, nil
+}
+
+func (r *TransactionRepository) FindTransactionsByWallet(walletID int) ([]domain.Transaction, error) {
+	rows, err := r.db.Query(`SELECT `+transactionColumns+` FROM transactions WHERE wallet_id = $1 ORDER BY date, id`, walletID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch transactions of wallet: %w", err)
+	}
+	defer rows.Close()
+
+	var transactions []domain.Transaction
+	for rows.Next() {
+		t, err := scanTransaction(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan transaction: %w", err)
+		}
+		transactions = append(transactions, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range transactions {
+		if transactions[i].Splits, err = findSplits(r.db, transactions[i].ID); err != nil {
+			return nil, err
+		}
+	}
+	return transactions, nil
+}
+
+func (r *TransactionRepository) SumWalletBalancesAt(userID int, at time.Time) (map[int]int, error) {
+	query := `
+		SELECT wallet_id, SUM(CASE WHEN type = 'EXPENSE' THEN -amount_in_cents ELSE amount_in_cents END)
+		FROM transactions
+		WHERE user_id = $1 AND date <= $2
+		GROUP BY wallet_id`
+	rows, err := r.db.Query(query, userID, domain.DateOf(at))
+	if err != nil {
+		return nil, fmt.Errorf("failed to sum wallet balances: %w", err)
+	}
+	defer rows.Close()
+
+	balances := make(map[int]int)
+	for rows.Next() {
+		var walletID, balance int
+		if err := rows.Scan(&walletID, &balance); err != nil {
+			return nil, fmt.Errorf("failed to scan wallet balance: %w", err)
+		}
+		balances[walletID] = balance
+	}
+	return balances, rows.Err()
+}
+
+func (r *TransactionRepository) FindWalletBalanceChanges(walletID int, from, until time.Time) ([]domain.BalanceChange, error) {
+	query := `
+		SELECT date, SUM(CASE WHEN type = 'EXPENSE' THEN -amount_in_cents ELSE amount_in_cents END)
+		FROM transactions
+		WHERE wallet_id = $1 AND date BETWEEN $2 AND $3
+		GROUP BY date
+		ORDER BY date`
+	rows, err := r.db.Query(query, walletID, domain.DateOf(from), domain.DateOf(until))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch balance changes of wallet ID %d: %w", walletID, err)
+	}
+	defer rows.Close()
+
+	var changes []domain.BalanceChange
+	for rows.Next() {
+		var c domain.BalanceChange
+		if err := rows.Scan(&c.Date, &c.AmountInCents); err != nil {
+			return nil, fmt.Errorf("failed to scan balance change: %w", err)
+		}
+		changes = append(changes, c)
+	}
+	return changes, rows.Err()
+}
+
+func (r *TransactionRepository) SetTransactionsCleared(ids []int, cleared bool) error {
+	if _, err := r.db.Exec(`UPDATE transactions SET cleared = $2 WHERE id = ANY($1)`, pq.Array(ids), cleared); err != nil {
+		return fmt.Errorf("failed to set transactions cleared: %w", err)
+	}
+	return nil
+}
+
+func (r *TransactionRepository) SumPendingAmounts(userID int) (map[int]int, map[int]int, error) {
+	wallets, err := sumPerID(r.db, `
+		SELECT wallet_id, SUM(CASE WHEN type = 'EXPENSE' THEN -amount_in_cents ELSE amount_in_cents END)
+		FROM transactions
+		WHERE user_id = $1 AND is_pending
+		GROUP BY wallet_id`, userID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to sum pending amounts of wallets: %w", err)
+	}
+	budgets, err := sumPerID(r.db, `
+		SELECT budget_id, SUM(amount) FROM (
+			SELECT t.budget_id, CASE WHEN t.type = 'EXPENSE' THEN -t.amount_in_cents ELSE t.amount_in_cents END AS amount
+			FROM transactions t
+			WHERE t.user_id = $1 AND t.is_pending AND t.budget_id IS NOT NULL
+			  AND NOT EXISTS (SELECT 1 FROM transaction_splits s WHERE s.transaction_id = t.id)
+			UNION ALL
+			SELECT s.budget_id, CASE WHEN t.type = 'EXPENSE' THEN -s.amount_in_cents ELSE s.amount_in_cents END
+			FROM transaction_splits s JOIN transactions t ON t.id = s.transaction_id
+			WHERE t.user_id = $1 AND t.is_pending
+		) pending
+		GROUP BY budget_id`, userID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to sum pending amounts of budgets: %w", err)
+	}
+	return wallets, budgets, nil
+}
+
+// sumPerID reads rows of an ID and a sum into a map.
+func sumPerID(db dbtx, query string, args ...any) (map[int]int, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sums := make(map[int]int)
+	for rows.Next() {
+		var id, sum int
+		if err := rows.Scan(&id, &sum); err != nil {
+			return nil, err
+		}
+		sums[id] = sum
+	}
+	return sums, rows.Err()
+}
+
+func (r *TransactionRepository) SettleTransactions(ids []int) (int, error) {
+	result, err := r.db.Exec(`
+		UPDATE transactions SET is_pending = false, pending_overdue = false
+		WHERE id = ANY($1) AND is_pending`, pq.Array(ids))
+	if err != nil {
+		return 0, fmt.Errorf("failed to settle transactions: %w", err)
+	}
+	settled, err := result.RowsAffected()
+	return int(settled), err
+}
+
+func (r *TransactionRepository) FlagOverduePending(before time.Time) ([]domain.Transaction, error) {
+	rows, err := r.db.Query(`
+		UPDATE transactions SET pending_overdue = true
+		WHERE is_pending AND NOT pending_overdue AND date < $1
+		RETURNING `+transactionColumns, before)
+	if err != nil {
+		return nil, fmt.Errorf("failed to flag overdue pending transactions: %w", err)
+	}
+	defer rows.Close()
+
+	var flagged []domain.Transaction
+	for rows.Next() {
+		t, err := scanTransaction(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan transaction: %w", err)
+		}
+		flagged = append(flagged, t)
+	}
+	return flagged, rows.Err()
+}
+
+func (r *TransactionRepository) MarkReconciled(walletID int, until time.Time, reconciliationID int) error {
+	query := `
+		UPDATE transactions SET reconciliation_id = $3
+		WHERE wallet_id = $1 AND cleared AND reconciliation_id IS NULL AND date <= $2`
+	if _, err := r.db.Exec(query, walletID, domain.DateOf(until), reconciliationID); err != nil {
+		return fmt.Errorf("failed to mark transactions of wallet ID %d reconciled: %w", walletID, err)
+	}
+	return nil
+}
+
+func (r *TransactionRepository) FindDebtTransactions(userID int) ([]domain.Transaction, error) {
+	query := `SELECT ` + transactionColumns + ` FROM transactions
+		WHERE user_id = $1 AND COALESCE(is_debt, false) AND counterparty_id IS NOT NULL
+		ORDER BY date, id`
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch debt transactions: %w", err)
+	}
+	defer rows.Close()
+
+	var transactions []domain.Transaction
+	for rows.Next() {
+		t, err := scanTransaction(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan transaction: %w", err)
+		}
+		transactions = append(transactions, t)
+	}
+	return transactions, rows.Err()
+}
+
+func (r *TransactionRepository) SetCounterparty(debtID int, counterpartyID int) error {
+	query := `UPDATE transactions SET counterparty_id = $2 WHERE id = $1 OR repayment_of_id = $1`
+	if _, err := r.db.Exec(query, debtID, counterpartyID); err != nil {
+		return fmt.Errorf("failed to set the counterparty of debt ID %d: %w", debtID, err)
+	}
+	return nil
+}
+
+func (r *TransactionRepository) FindTransactionByExternalID(userID int, externalID string) (domain.Transaction, error) {
+	var id int
+	err := r.db.QueryRow(`SELECT id FROM transactions WHERE user_id = $1 AND external_id = $2`, userID, externalID).Scan(&id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return domain.Transaction{}, domain.ErrTransactionNotFound
+		}
+		return domain.Transaction{}, fmt.Errorf("failed to find transaction by external ID: %w", err)
+	}
+	return r.GetTransactionByID(id)
+}
+
 func (r *TransactionRepository) GetTransactionCount(userID int) (int, error) {
 	query := `SELECT COUNT(*) FROM transactions WHERE user_id = $1`
 	var count int
@@ -169,10 +424,11 @@ func (r *TransactionRepository) GetTransactionCount(userID int) (int, error) {
 
 func (r *TransactionRepository) FindTransactionsByUser(userID int, limit int, offset int) ([]domain.TransactionDTO, error) {
 	query := `
-		SELECT t.id, t.date, t.description, t.amount_in_cents, t.type, t.is_pending, t.is_debt, b.name as budget_name, w.name as wallet_name
+		SELECT t.id, t.date, t.description, t.amount_in_cents, t.type, t.is_pending, t.is_debt, t.tags, t.cleared, t.reconciliation_id IS NOT NULL, t.pending_overdue, b.name as budget_name, w.name as wallet_name, COALESCE(u.username, '')
 		FROM transactions t
 		LEFT JOIN budgets b ON t.budget_id = b.id
 		LEFT JOIN wallets w ON t.wallet_id = w.id
+		LEFT JOIN users u ON t.created_by = u.id
 		WHERE t.user_id = $1
 		ORDER BY t.date DESC, t.id DESC
 		LIMIT $2 OFFSET $3`
@@ -187,11 +443,13 @@ func (r *TransactionRepository) FindTransactionsByUser(userID int, limit int, of
 		var t domain.TransactionDTO
 		var nullBudgetName sql.NullString
 		var isDebt *bool
-		err := rows.Scan(&t.ID, &t.Date, &t.Description, &t.AmountInCents, &t.Type, &t.IsPending, &isDebt, &nullBudgetName, &t.WalletName)
+		var tags []byte
+		err := rows.Scan(&t.ID, &t.Date, &t.Description, &t.AmountInCents, &t.Type, &t.IsPending, &isDebt, &tags, &t.Cleared, &t.Reconciled, &t.PendingOverdue, &nullBudgetName, &t.WalletName, &t.CreatedBy)
 		if err != nil {
 			return nil, err
 		}
 		t.IsDebt = isDebt != nil && *isDebt
+		json.Unmarshal(tags, &t.Tags)
 		if nullBudgetName.Valid {
 			t.BudgetName = nullBudgetName.String
 		} else {
@@ -199,15 +457,19 @@ func (r *TransactionRepository) FindTransactionsByUser(userID int, limit int, of
 		}
 		txs = append(txs, t)
 	}
-	return txs, nil
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return txs, r.attachSplitDTOs(txs)
 }
 
 func (r *TransactionRepository) SearchTransactions(userID int, criteria domain.TransactionSearchCriteria) ([]domain.TransactionDTO, error) {
 	query := `
-		SELECT t.id, t.date, t.description, t.amount_in_cents, t.type, t.is_pending, t.is_debt, b.name as budget_name, w.name as wallet_name
+		SELECT t.id, t.date, t.description, t.amount_in_cents, t.type, t.is_pending, t.is_debt, t.tags, t.cleared, t.reconciliation_id IS NOT NULL, t.pending_overdue, b.name as budget_name, w.name as wallet_name, COALESCE(u.username, '')
 		FROM transactions t
 		LEFT JOIN budgets b ON t.budget_id = b.id
 		LEFT JOIN wallets w ON t.wallet_id = w.id
+		LEFT JOIN users u ON t.created_by = u.id
 	`
 	whereClause := " WHERE t.user_id = $1"
 	args := []interface{}{userID}
@@ -229,7 +491,7 @@ func (r *TransactionRepository) SearchTransactions(userID int, criteria domain.T
 		argID++
 	}
 	if criteria.BudgetID != nil {
-		whereClause += fmt.Sprintf(" AND t.budget_id = $%d", argID)
+		whereClause += fmt.Sprintf(" AND %s", onBudgetCondition(argID))
 		args = append(args, *criteria.BudgetID)
 		argID++
 	}
@@ -248,6 +510,11 @@ func (r *TransactionRepository) SearchTransactions(userID int, criteria domain.T
 		args = append(args, *criteria.IsDebt)
 		argID++
 	}
+	if len(criteria.Tags) > 0 {
+		whereClause += fmt.Sprintf(" AND %s", hasTagsCondition(argID, criteria.AllTags))
+		args = append(args, pq.Array(criteria.Tags))
+		argID++
+	}
 
 	query += whereClause
 	query += " ORDER BY t.date DESC, t.id DESC"
@@ -265,11 +532,13 @@ func (r *TransactionRepository) SearchTransactions(userID int, criteria domain.T
 		var t domain.TransactionDTO
 		var nullBudgetName sql.NullString
 		var isDebt *bool
-		err := rows.Scan(&t.ID, &t.Date, &t.Description, &t.AmountInCents, &t.Type, &t.IsPending, &isDebt, &nullBudgetName, &t.WalletName)
+		var tags []byte
+		err := rows.Scan(&t.ID, &t.Date, &t.Description, &t.AmountInCents, &t.Type, &t.IsPending, &isDebt, &tags, &t.Cleared, &t.Reconciled, &t.PendingOverdue, &nullBudgetName, &t.WalletName, &t.CreatedBy)
 		if err != nil {
 			return nil, err
 		}
 		t.IsDebt = isDebt != nil && *isDebt
+		json.Unmarshal(tags, &t.Tags)
 		if nullBudgetName.Valid {
 			t.BudgetName = nullBudgetName.String
 		} else {
@@ -277,7 +546,10 @@ func (r *TransactionRepository) SearchTransactions(userID int, criteria domain.T
 		}
 		txs = append(txs, t)
 	}
-	return txs, nil
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return txs, r.attachSplitDTOs(txs)
 }
 
 func (r *TransactionRepository) CountSearchedTransactions(userID int, criteria domain.TransactionSearchCriteria) (int, error) {
@@ -302,7 +574,7 @@ func (r *TransactionRepository) CountSearchedTransactions(userID int, criteria d
 		argID++
 	}
 	if criteria.BudgetID != nil {
-		whereClause += fmt.Sprintf(" AND t.budget_id = $%d", argID)
+		whereClause += fmt.Sprintf(" AND %s", onBudgetCondition(argID))
 		args = append(args, *criteria.BudgetID)
 		argID++
 	}
@@ -321,6 +593,11 @@ func (r *TransactionRepository) CountSearchedTransactions(userID int, criteria d
 		args = append(args, *criteria.IsDebt)
 		argID++
 	}
+	if len(criteria.Tags) > 0 {
+		whereClause += fmt.Sprintf(" AND %s", hasTagsCondition(argID, criteria.AllTags))
+		args = append(args, pq.Array(criteria.Tags))
+		argID++
+	}
 
 	query += whereClause
 	var count int
@@ -332,7 +609,7 @@ func (r *TransactionRepository) CountSearchedTransactions(userID int, criteria d
 }
 
 func (r *TransactionRepository) SumSearchedTransactionAmounts(userID int, criteria domain.TransactionSearchCriteria) (int, error) {
-	query := `SELECT COALESCE(SUM(CASE WHEN t.type = 'EXPENSE' THEN -t.amount_in_cents ELSE t.amount_in_cents END), 0) FROM transactions t`
+	amount := "t.amount_in_cents"
 	whereClause := " WHERE t.user_id = $1"
 	args := []interface{}{userID}
 	argID := 2
@@ -353,7 +630,9 @@ func (r *TransactionRepository) SumSearchedTransactionAmounts(userID int, criter
 		argID++
 	}
 	if criteria.BudgetID != nil {
-		whereClause += fmt.Sprintf(" AND t.budget_id = $%d", argID)
+		// Only the lines of a split transaction booked on the budget count.
+		amount = fmt.Sprintf("COALESCE((SELECT SUM(s.amount_in_cents) FROM transaction_splits s WHERE s.transaction_id = t.id AND s.budget_id = $%d), t.amount_in_cents)", argID)
+		whereClause += fmt.Sprintf(" AND %s", onBudgetCondition(argID))
 		args = append(args, *criteria.BudgetID)
 		argID++
 	}
@@ -372,7 +651,13 @@ func (r *TransactionRepository) SumSearchedTransactionAmounts(userID int, criter
 		args = append(args, *criteria.IsDebt)
 		argID++
 	}
+	if len(criteria.Tags) > 0 {
+		whereClause += fmt.Sprintf(" AND %s", hasTagsCondition(argID, criteria.AllTags))
+		args = append(args, pq.Array(criteria.Tags))
+		argID++
+	}
 
+	query := fmt.Sprintf("SELECT COALESCE(SUM(CASE WHEN t.type = 'EXPENSE' THEN -%[1]s ELSE %[1]s END), 0) FROM transactions t", amount)
 	query += whereClause
 	var sum int
 	err := r.db.QueryRow(query, args...).Scan(&sum)
@@ -383,41 +668,47 @@ func (r *TransactionRepository) SumSearchedTransactionAmounts(userID int, criter
 }
 
 func (r *TransactionRepository) DeleteTransaction(id int) error {
-	tx, err := r.db.Begin()
+	tx, err := beginTx(r.db)
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback()
 
-	var amount int
-	var tType domain.TransactionType
-	var nullBudgetID sql.NullInt32
-	var walletID int
-	var userID int
-	queryFetch := `SELECT amount_in_cents, type, budget_id, wallet_id, user_id FROM transactions WHERE id = $1`
-	err = tx.QueryRow(queryFetch, id).Scan(&amount, &tType, &nullBudgetID, &walletID, &userID)
+	old, err := scanTransaction(tx.QueryRow(`SELECT `+transactionColumns+` FROM transactions WHERE id = $1`, id))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return domain.ErrTransactionNotFound
 		}
 		return err
 	}
+	if old.Splits, err = findSplits(tx, id); err != nil {
+		return err
+	}
 
-	adjustment := -amount
-	if tType == domain.Expense {
-		adjustment = amount
+	// Deleting the transaction deletes the settlement it books, which is
+	// only right while the other user has not booked theirs.
+	var confirmed bool
+	err = tx.QueryRow(`
+		SELECT EXISTS (SELECT 1 FROM settlements
+		WHERE (from_transaction_id = $1 OR to_transaction_id = $1) AND confirmed_at IS NOT NULL)`, id).Scan(&confirmed)
+	if err != nil {
+		return err
+	}
+	if confirmed {
+		return domain.ErrSettlementConfirmed
 	}
 
-	if nullBudgetID.Valid {
-		queryBudget := `UPDATE budgets SET balance_cents = balance_cents + $1 WHERE id = $2 AND user_id = $3`
-		_, err = tx.Exec(queryBudget, adjustment, nullBudgetID.Int32, userID)
-		if err != nil {
-			return err
-		}
+	if err := adjustBudgets(tx, old, -1); err != nil {
+		return err
+	}
+
+	adjustment := -old.AmountInCents
+	if old.Type == domain.Expense {
+		adjustment = old.AmountInCents
 	}
 
 	queryWallet := `UPDATE wallets SET balance_cents = balance_cents + $1 WHERE id = $2 AND user_id = $3`
-	_, err = tx.Exec(queryWallet, adjustment, walletID, userID)
+	_, err = tx.Exec(queryWallet, adjustment, old.WalletID, old.UserID)
 	if err != nil {
 		return err
 	}
@@ -441,16 +732,16 @@ func (r *TransactionRepository) DeleteAllByUser(userID int) error {
 }
 
 func (r *TransactionRepository) CreateTransfer(from, to domain.Transaction) error {
-	tx, err := r.db.Begin()
+	tx, err := beginTx(r.db)
 	if err != nil {
 		return fmt.Errorf("could not start transaction: %w", err)
 	}
 	defer tx.Rollback()
 
 	fromTags, _ := json.Marshal(from.Tags)
-	query := `INSERT INTO transactions (user_id, date, budget_id, wallet_id, description, amount_in_cents, type, is_pending, is_debt, tags)
-	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
-	_, err = tx.Exec(query, from.UserID, from.Date, from.BudgetID, from.WalletID, from.Description, from.AmountInCents, from.Type, from.IsPending, from.IsDebt, fromTags)
+	query := `INSERT INTO transactions (user_id, date, budget_id, wallet_id, description, amount_in_cents, type, is_pending, is_debt, tags, created_by)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NULLIF($11, 0))`
+	_, err = tx.Exec(query, from.UserID, from.Date, from.BudgetID, from.WalletID, from.Description, from.AmountInCents, from.Type, from.IsPending, from.IsDebt, fromTags, from.CreatedBy)
 	if err != nil {
 		return fmt.Errorf("failed to insert from-transaction: %w", err)
 	}
@@ -466,9 +757,9 @@ func (r *TransactionRepository) CreateTransfer(from, to domain.Transaction) erro
 	}
 
 	toTags, _ := json.Marshal(to.Tags)
-	query = `INSERT INTO transactions (user_id, date, budget_id, wallet_id, description, amount_in_cents, type, is_pending, is_debt, tags)
-	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
-	_, err = tx.Exec(query, to.UserID, to.Date, to.BudgetID, to.WalletID, to.Description, to.AmountInCents, to.Type, to.IsPending, to.IsDebt, toTags)
+	query = `INSERT INTO transactions (user_id, date, budget_id, wallet_id, description, amount_in_cents, type, is_pending, is_debt, tags, created_by)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NULLIF($11, 0))`
+	_, err = tx.Exec(query, to.UserID, to.Date, to.BudgetID, to.WalletID, to.Description, to.AmountInCents, to.Type, to.IsPending, to.IsDebt, toTags, to.CreatedBy)
 	if err != nil {
 		return fmt.Errorf("failed to insert to-transaction: %w", err)
 	}
@@ -490,9 +781,127 @@ func (r *TransactionRepository) CreateTransfer(from, to domain.Transaction) erro
 	return nil
 }
 
+// ReplaceTags swaps the tags for the replacement on the user's transactions
+// and their split lines, dropping the duplicates this leaves behind.
+func (r *TransactionRepository) ReplaceTags(userID int, tags []string, replacement string) (int, error) {
+	tx, err := beginTx(r.db)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		UPDATE transactions SET tags = `+replacedTagsJSON+`
+		WHERE user_id = $1 AND (tags ?| $2 OR id IN (SELECT transaction_id FROM transaction_splits WHERE tags ?| $2))`, userID, pq.Array(tags), replacement)
+	if err != nil {
+		return 0, fmt.Errorf("failed to replace transaction tags: %w", err)
+	}
+	_, err = tx.Exec(`
+		UPDATE transaction_splits SET tags = `+replacedTagsJSON+`
+		WHERE tags ?| $2 AND transaction_id IN (SELECT id FROM transactions WHERE user_id = $1)`, userID, pq.Array(tags), replacement)
+	if err != nil {
+		return 0, fmt.Errorf("failed to replace split tags: %w", err)
+	}
+	changed, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return int(changed), tx.Commit()
+}
+
+func (r *TransactionRepository) SumTransactionsWithoutBudget(userID int) (int, error) {
+	query := `
+		SELECT COALESCE(SUM(CASE WHEN t.type = 'EXPENSE' THEN -t.amount_in_cents ELSE t.amount_in_cents END), 0)
+		FROM transactions t
+		WHERE t.user_id = $1 AND t.budget_id IS NULL AND NOT COALESCE(t.is_debt, false)
+		  AND NOT EXISTS (SELECT 1 FROM transaction_splits s WHERE s.transaction_id = t.id)`
+	var sum int
+	if err := r.db.QueryRow(query, userID).Scan(&sum); err != nil {
+		return 0, fmt.Errorf("failed to sum transactions without budget: %w", err)
+	}
+	return sum, nil
+}
+
+// budgetBookingColumns are the date, type and amount on the budget in $1 of
+// a transaction t.
+const budgetBookingColumns = `t.date, t.type, COALESCE((SELECT SUM(s.amount_in_cents) FROM transaction_splits s WHERE s.transaction_id = t.id AND s.budget_id = $1), t.amount_in_cents)`
+
+func (r *TransactionRepository) FindBudgetBookings(budgetID int) ([]domain.BudgetBooking, error) {
+	query := `
+		SELECT ` + budgetBookingColumns + `
+		FROM transactions t
+		WHERE ` + onBudgetCondition(1) + `
+		ORDER BY t.date, t.id`
+	return r.findBudgetBookings(budgetID, query, budgetID)
+}
+
+func (r *TransactionRepository) FindBudgetBookingsBetween(budgetID int, from, until time.Time) ([]domain.BudgetBooking, error) {
+	query := `
+		SELECT ` + budgetBookingColumns + `
+		FROM transactions t
+		WHERE ` + onBudgetCondition(1) + ` AND t.date >= $2 AND t.date < $3
+		ORDER BY t.date, t.id`
+	return r.findBudgetBookings(budgetID, query, budgetID, from, until)
+}
+
+// onUserBudgetJoin joins the transactions booked on each budget b, on their
+// own or with one of their split lines.
+const onUserBudgetJoin = `JOIN transactions t ON t.budget_id = b.id OR EXISTS (SELECT 1 FROM transaction_splits s WHERE s.transaction_id = t.id AND s.budget_id = b.id)`
+
+func (r *TransactionRepository) FindBudgetBookingsByUser(userID int, from time.Time) (map[int][]domain.BudgetBooking, error) {
+	query := `
+		SELECT b.id, t.date, t.type, COALESCE((SELECT SUM(s.amount_in_cents) FROM transaction_splits s WHERE s.transaction_id = t.id AND s.budget_id = b.id), t.amount_in_cents)
+		FROM budgets b
+		` + onUserBudgetJoin + `
+		WHERE b.user_id = $1 AND t.date >= $2
+		ORDER BY t.date, t.id`
+	rows, err := r.db.Query(query, userID, from)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch budget bookings of user ID %d: %w", userID, err)
+	}
+	defer rows.Close()
+
+	bookings := make(map[int][]domain.BudgetBooking)
+	for rows.Next() {
+		var budgetID int
+		var booking domain.BudgetBooking
+		var transactionType domain.TransactionType
+		if err := rows.Scan(&budgetID, &booking.Date, &transactionType, &booking.AmountInCents); err != nil {
+			return nil, fmt.Errorf("failed to scan booking: %w", err)
+		}
+		if transactionType == domain.Expense {
+			booking.AmountInCents = -booking.AmountInCents
+		}
+		bookings[budgetID] = append(bookings[budgetID], booking)
+	}
+	return bookings, rows.Err()
+}
+
+func (r *TransactionRepository) findBudgetBookings(budgetID int, query string, args ...any) ([]domain.BudgetBooking, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch bookings of budget ID %d: %w", budgetID, err)
+	}
+	defer rows.Close()
+
+	var bookings []domain.BudgetBooking
+	for rows.Next() {
+		var booking domain.BudgetBooking
+		var transactionType domain.TransactionType
+		if err := rows.Scan(&booking.Date, &transactionType, &booking.AmountInCents); err != nil {
+			return nil, fmt.Errorf("failed to scan booking: %w", err)
+		}
+		if transactionType == domain.Expense {
+			booking.AmountInCents = -booking.AmountInCents
+		}
+		bookings = append(bookings, booking)
+	}
+	return bookings, rows.Err()
+}
+
 func (r *TransactionRepository) CountTransactionsByBudgetID(budgetID int) (int, error) {
 	var count int
-	query := `SELECT COUNT(*) FROM transactions WHERE budget_id = $1`
+	query := `SELECT COUNT(*) FROM transactions t WHERE ` + onBudgetCondition(1)
 	err := r.db.QueryRow(query, budgetID).Scan(&count)
 	if err != nil {
 		return 0, fmt.Errorf("failed to count transactions for budget ID %d: %w", budgetID, err)
@@ -500,6 +909,25 @@ func (r *TransactionRepository) CountTransactionsByBudgetID(budgetID int) (int,
 	return count, nil
 }
 
+func (r *TransactionRepository) CountTransactionsByBudget(userID int) (map[int]int, error) {
+	query := `SELECT b.id, COUNT(*) FROM budgets b ` + onUserBudgetJoin + ` WHERE b.user_id = $1 GROUP BY b.id`
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count transactions per budget of user ID %d: %w", userID, err)
+	}
+	defer rows.Close()
+
+	counts := make(map[int]int)
+	for rows.Next() {
+		var budgetID, count int
+		if err := rows.Scan(&budgetID, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan transaction count: %w", err)
+		}
+		counts[budgetID] = count
+	}
+	return counts, rows.Err()
+}
+
 func (r *TransactionRepository) CountTransactionsByWalletID(walletID int) (int, error) {
 	var count int
 	query := `SELECT COUNT(*) FROM transactions WHERE wallet_id = $1`
@@ -509,3 +937,111 @@ func (r *TransactionRepository) CountTransactionsByWalletID(walletID int) (int,
 	}
 	return count, nil
 }
+
+// onBudgetCondition matches transactions booked on the budget given as the
+// argID-th argument, on their own or with one of their split lines.
+func onBudgetCondition(argID int) string {
+	return fmt.Sprintf("(t.budget_id = $%[1]d OR EXISTS (SELECT 1 FROM transaction_splits s WHERE s.transaction_id = t.id AND s.budget_id = $%[1]d))", argID)
+}
+
+// hasTagsCondition matches transactions whose own or split tags hold any of
+// the tags given as the argID-th argument, or with all set every one of them.
+func hasTagsCondition(argID int, all bool) string {
+	if all {
+		return fmt.Sprintf(`NOT EXISTS (SELECT 1 FROM unnest($%[1]d::text[]) AS wanted(tag)
+			WHERE NOT (t.tags ? wanted.tag OR EXISTS (SELECT 1 FROM transaction_splits s WHERE s.transaction_id = t.id AND s.tags ? wanted.tag)))`, argID)
+	}
+	return fmt.Sprintf("(t.tags ?| $%[1]d OR EXISTS (SELECT 1 FROM transaction_splits s WHERE s.transaction_id = t.id AND s.tags ?| $%[1]d))", argID)
+}
+
+// replacedTagsJSON is the tags column with every tag in $2 swapped for $3,
+// in the original order and without duplicates.
+const replacedTagsJSON = `(
+	SELECT COALESCE(jsonb_agg(tag ORDER BY first), '[]'::jsonb) FROM (
+		SELECT CASE WHEN e.tag = ANY($2) THEN $3 ELSE e.tag END AS tag, MIN(e.ord) AS first
+		FROM jsonb_array_elements_text(tags) WITH ORDINALITY AS e(tag, ord)
+		GROUP BY 1
+	) replaced
+)`
+
+// adjustBudgets books the transaction on its budgets, or with sign -1 takes
+// it back off them.
+func adjustBudgets(tx txScope, t domain.Transaction, sign int) error {
+	for budgetID, amount := range t.BudgetAmounts() {
+		_, err := tx.Exec(`UPDATE budgets SET balance_cents = balance_cents + $1 WHERE id = $2 AND user_id = $3`, sign*amount, budgetID, t.UserID)
+		if err != nil {
+			return fmt.Errorf("failed to update budget balance: %w", err)
+		}
+	}
+	return nil
+}
+
+func saveSplits(tx txScope, transactionID int, splits []domain.Split) error {
+	for _, split := range splits {
+		tags, _ := json.Marshal(split.Tags)
+		_, err := tx.Exec(
+			`INSERT INTO transaction_splits (transaction_id, budget_id, amount_in_cents, tags, note) VALUES ($1, $2, $3, $4, $5)`,
+			transactionID, split.BudgetID, split.AmountInCents, tags, split.Note,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to insert split: %w", err)
+		}
+	}
+	return nil
+}
+
+func findSplits(db dbtx, transactionID int) ([]domain.Split, error) {
+	rows, err := db.Query(`SELECT budget_id, amount_in_cents, tags, note FROM transaction_splits WHERE transaction_id = $1 ORDER BY id`, transactionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch splits: %w", err)
+	}
+	defer rows.Close()
+
+	var splits []domain.Split
+	for rows.Next() {
+		var split domain.Split
+		var tags []byte
+		if err := rows.Scan(&split.BudgetID, &split.AmountInCents, &tags, &split.Note); err != nil {
+			return nil, fmt.Errorf("failed to scan split: %w", err)
+		}
+		json.Unmarshal(tags, &split.Tags)
+		splits = append(splits, split)
+	}
+	return splits, rows.Err()
+}
+
+func (r *TransactionRepository) attachSplitDTOs(txs []domain.TransactionDTO) error {
+	if len(txs) == 0 {
+		return nil
+	}
+	ids := make([]int64, len(txs))
+	index := make(map[int]int, len(txs))
+	for i, t := range txs {
+		ids[i] = int64(t.ID)
+		index[t.ID] = i
+	}
+
+	rows, err := r.db.Query(`
+		SELECT s.transaction_id, s.budget_id, b.name, s.amount_in_cents, s.tags, s.note
+		FROM transaction_splits s
+		JOIN budgets b ON b.id = s.budget_id
+		WHERE s.transaction_id = ANY($1)
+		ORDER BY s.id`, pq.Array(ids))
+	if err != nil {
+		return fmt.Errorf("failed to fetch splits: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var transactionID int
+		var split domain.SplitDTO
+		var tags []byte
+		if err := rows.Scan(&transactionID, &split.BudgetID, &split.BudgetName, &split.AmountInCents, &tags, &split.Note); err != nil {
+			return fmt.Errorf("failed to scan split: %w", err)
+		}
+		json.Unmarshal(tags, &split.Tags)
+		i := index[transactionID]
+		txs[i].Splits = append(txs[i].Splits, split)
+	}
+	return rows.Err()
+}
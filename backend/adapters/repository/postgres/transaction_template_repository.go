@@ -3,23 +3,29 @@ package postgres
 import (
 	"database/sql"
 	"fmt"
+	"time"
 
 	"github.com/fim-lab/expense-tracker/internal/core/domain"
 	"github.com/lib/pq"
 )
 
 type TransactionTemplateRepository struct {
-	db *sql.DB
+	db dbtx
 }
 
 func NewTransactionTemplateRepository(db *sql.DB) *TransactionTemplateRepository {
 	return &TransactionTemplateRepository{db: db}
 }
 
-func (r *TransactionTemplateRepository) SaveTransactionTemplate(tt domain.TransactionTemplate) error {
+func (r *TransactionTemplateRepository) SaveTransactionTemplate(tt domain.TransactionTemplate) (int, error) {
+	// Imported templates keep when they were created and what they booked.
+	var createdAt *time.Time
+	if !tt.CreatedAt.IsZero() {
+		createdAt = &tt.CreatedAt
+	}
 	query := `
-		INSERT INTO transaction_templates (user_id, day, budget_id, wallet_id, description, amount_in_cents, type, tags)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		INSERT INTO transaction_templates (user_id, day, budget_id, wallet_id, description, amount_in_cents, type, tags, frequency, recurrence_interval, start_date, end_date, occurrence_count, created_at, last_occurrence, to_wallet_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, COALESCE($14, CURRENT_TIMESTAMP), $15, $16)
 		RETURNING id
 	`
 	var id int
@@ -33,26 +39,32 @@ func (r *TransactionTemplateRepository) SaveTransactionTemplate(tt domain.Transa
 		tt.AmountInCents,
 		tt.Type,
 		pq.Array(tt.Tags),
+		tt.Recurrence.Frequency,
+		tt.Recurrence.Interval,
+		tt.Recurrence.StartDate,
+		tt.Recurrence.EndDate,
+		tt.Recurrence.Count,
+		createdAt,
+		tt.LastOccurrence,
+		tt.ToWalletID,
 	).Scan(&id)
 
 	if err != nil {
-		return fmt.Errorf("error saving transaction template: %w", err)
+		return 0, fmt.Errorf("error saving transaction template: %w", err)
 	}
-	tt.ID = id
-	return nil
+	return id, nil
 }
 
-func (r *TransactionTemplateRepository) GetTransactionTemplateByID(id int) (domain.TransactionTemplate, error) {
-	query := `
-		SELECT id, user_id, day, budget_id, wallet_id, description, amount_in_cents, type, tags
-		FROM transaction_templates
-		WHERE id = $1
-	`
+const transactionTemplateColumns = `id, user_id, day, budget_id, wallet_id, description, amount_in_cents, type, tags,
+	frequency, recurrence_interval, COALESCE(start_date, created_at::date, CURRENT_DATE), end_date, occurrence_count,
+	COALESCE(created_at, CURRENT_TIMESTAMP), last_occurrence, to_wallet_id`
+
+func scanTransactionTemplate(row interface{ Scan(...any) error }) (domain.TransactionTemplate, error) {
 	var tt domain.TransactionTemplate
-	var budgetID sql.NullInt64
+	var budgetID, toWalletID sql.NullInt64
 	var tags pq.StringArray
 
-	err := r.db.QueryRow(query, id).Scan(
+	err := row.Scan(
 		&tt.ID,
 		&tt.UserID,
 		&tt.Day,
@@ -62,31 +74,45 @@ func (r *TransactionTemplateRepository) GetTransactionTemplateByID(id int) (doma
 		&tt.AmountInCents,
 		&tt.Type,
 		&tags,
+		&tt.Recurrence.Frequency,
+		&tt.Recurrence.Interval,
+		&tt.Recurrence.StartDate,
+		&tt.Recurrence.EndDate,
+		&tt.Recurrence.Count,
+		&tt.CreatedAt,
+		&tt.LastOccurrence,
+		&toWalletID,
 	)
-
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return domain.TransactionTemplate{}, domain.ErrTransactionTemplateNotFound
-		}
-		return domain.TransactionTemplate{}, fmt.Errorf("error getting transaction template by ID: %w", err)
+		return domain.TransactionTemplate{}, err
 	}
 
 	if budgetID.Valid {
 		bID := int(budgetID.Int64)
 		tt.BudgetID = &bID
 	}
-	tt.Tags = tags
+	if toWalletID.Valid {
+		id := int(toWalletID.Int64)
+		tt.ToWalletID = &id
+	}
+	tt.Tags = []string(tags)
+	return tt, nil
+}
 
+func (r *TransactionTemplateRepository) GetTransactionTemplateByID(id int) (domain.TransactionTemplate, error) {
+	query := `SELECT ` + transactionTemplateColumns + ` FROM transaction_templates WHERE id = $1`
+	tt, err := scanTransactionTemplate(r.db.QueryRow(query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return domain.TransactionTemplate{}, domain.ErrTransactionTemplateNotFound
+		}
+		return domain.TransactionTemplate{}, fmt.Errorf("error getting transaction template by ID: %w", err)
+	}
 	return tt, nil
 }
 
 func (r *TransactionTemplateRepository) FindTransactionTemplatesByUser(userID int) ([]domain.TransactionTemplate, error) {
-	query := `
-		SELECT id, user_id, day, budget_id, wallet_id, description, amount_in_cents, type, tags
-		FROM transaction_templates
-		WHERE user_id = $1
-		ORDER BY id
-	`
+	query := `SELECT ` + transactionTemplateColumns + ` FROM transaction_templates WHERE user_id = $1 ORDER BY id`
 	rows, err := r.db.Query(query, userID)
 	if err != nil {
 		return nil, fmt.Errorf("error finding transaction templates by user: %w", err)
@@ -95,45 +121,69 @@ func (r *TransactionTemplateRepository) FindTransactionTemplatesByUser(userID in
 
 	var templates []domain.TransactionTemplate
 	for rows.Next() {
-		var tt domain.TransactionTemplate
-		var budgetID sql.NullInt64
-		var tags pq.StringArray
-
-		if err := rows.Scan(
-			&tt.ID,
-			&tt.UserID,
-			&tt.Day,
-			&budgetID,
-			&tt.WalletID,
-			&tt.Description,
-			&tt.AmountInCents,
-			&tt.Type,
-			&tags,
-		); err != nil {
+		tt, err := scanTransactionTemplate(rows)
+		if err != nil {
 			return nil, fmt.Errorf("error scanning transaction template row: %w", err)
 		}
+		templates = append(templates, tt)
+	}
 
-		if budgetID.Valid {
-			bID := int(budgetID.Int64)
-			tt.BudgetID = &bID
-		}
-		tt.Tags = []string(tags)
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error with rows in FindTransactionTemplatesByUser: %w", err)
+	}
+
+	return templates, nil
+}
+
+func (r *TransactionTemplateRepository) FindAllTransactionTemplates() ([]domain.TransactionTemplate, error) {
+	query := `SELECT ` + transactionTemplateColumns + ` FROM transaction_templates ORDER BY id`
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("error finding transaction templates: %w", err)
+	}
+	defer rows.Close()
 
+	var templates []domain.TransactionTemplate
+	for rows.Next() {
+		tt, err := scanTransactionTemplate(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning transaction template row: %w", err)
+		}
 		templates = append(templates, tt)
 	}
 
 	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("error with rows in FindTransactionTemplatesByUser: %w", err)
+		return nil, fmt.Errorf("error with rows in FindAllTransactionTemplates: %w", err)
 	}
 
 	return templates, nil
 }
 
+func (r *TransactionTemplateRepository) AdvanceLastOccurrence(id int, from *time.Time, to *time.Time) (bool, error) {
+	query := `
+		UPDATE transaction_templates
+		SET last_occurrence = $1
+		WHERE id = $2 AND last_occurrence IS NOT DISTINCT FROM $3
+	`
+	res, err := r.db.Exec(query, to, id, from)
+	if err != nil {
+		return false, fmt.Errorf("error advancing last occurrence: %w", err)
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("error checking rows affected for last occurrence: %w", err)
+	}
+	return rowsAffected == 1, nil
+}
+
 func (r *TransactionTemplateRepository) UpdateTransactionTemplate(tt domain.TransactionTemplate) error {
 	query := `
 		UPDATE transaction_templates
-		SET day = $1, budget_id = $2, wallet_id = $3, description = $4, amount_in_cents = $5, type = $6, tags = $7
-		WHERE id = $8 AND user_id = $9
+		SET day = $1, budget_id = $2, wallet_id = $3, description = $4, amount_in_cents = $5, type = $6, tags = $7,
+		    frequency = $8, recurrence_interval = $9, start_date = $10, end_date = $11, occurrence_count = $12,
+		    to_wallet_id = $15
+		WHERE id = $13 AND user_id = $14
 	`
 
 	res, err := r.db.Exec(
@@ -145,8 +195,14 @@ func (r *TransactionTemplateRepository) UpdateTransactionTemplate(tt domain.Tran
 		tt.AmountInCents,
 		tt.Type,
 		pq.Array(tt.Tags),
+		tt.Recurrence.Frequency,
+		tt.Recurrence.Interval,
+		tt.Recurrence.StartDate,
+		tt.Recurrence.EndDate,
+		tt.Recurrence.Count,
 		tt.ID,
 		tt.UserID,
+		tt.ToWalletID,
 	)
 	if err != nil {
 		return fmt.Errorf("error updating transaction template: %w", err)
@@ -162,6 +218,33 @@ func (r *TransactionTemplateRepository) UpdateTransactionTemplate(tt domain.Tran
 	return nil
 }
 
+// ReplaceTags swaps the tags for the replacement on the user's templates,
+// keeping their order and dropping the duplicates this leaves behind.
+func (r *TransactionTemplateRepository) ReplaceTags(userID int, tags []string, replacement string) (int, error) {
+	query := `
+		UPDATE transaction_templates
+		SET tags = ARRAY(
+			SELECT tag FROM (
+				SELECT CASE WHEN e.tag = ANY($2) THEN $3 ELSE e.tag END AS tag, MIN(e.ord) AS first
+				FROM unnest(tags) WITH ORDINALITY AS e(tag, ord)
+				GROUP BY 1
+			) replaced
+			ORDER BY first
+		)
+		WHERE user_id = $1 AND tags && $2
+	`
+	res, err := r.db.Exec(query, userID, pq.Array(tags), replacement)
+	if err != nil {
+		return 0, fmt.Errorf("error replacing transaction template tags: %w", err)
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("error checking rows affected for tag replacement: %w", err)
+	}
+	return int(rowsAffected), nil
+}
+
 func (r *TransactionTemplateRepository) DeleteTransactionTemplate(id int) error {
 	query := `
 		DELETE FROM transaction_templates
@@ -6,7 +6,7 @@ import (
 )
 
 type UserRepository struct {
-	db *sql.DB
+	db dbtx
 }
 
 func NewUserRepository(db *sql.DB) *UserRepository {
@@ -15,8 +15,8 @@ func NewUserRepository(db *sql.DB) *UserRepository {
 
 func (r *UserRepository) GetUserByUsername(username string) (domain.User, error) {
 	var u domain.User
-	query := `SELECT id, username, password_hash, salary_cents FROM users WHERE username = $1`
-	err := r.db.QueryRow(query, username).Scan(&u.ID, &u.Username, &u.PasswordHash, &u.SalaryCents)
+	query := `SELECT id, username, password_hash, salary_cents, email, is_admin FROM users WHERE username = $1`
+	err := r.db.QueryRow(query, username).Scan(&u.ID, &u.Username, &u.PasswordHash, &u.SalaryCents, &u.Email, &u.IsAdmin)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return domain.User{}, domain.ErrUserNotFound
@@ -27,16 +27,16 @@ func (r *UserRepository) GetUserByUsername(username string) (domain.User, error)
 }
 
 func (r *UserRepository) SaveUser(u domain.User) error {
-	query := `INSERT INTO users (username, password_hash, salary_cents) 
-	          VALUES ($1, $2, $3)`
-	_, err := r.db.Exec(query, u.Username, u.PasswordHash, u.SalaryCents)
+	query := `INSERT INTO users (username, password_hash, salary_cents, email) 
+	          VALUES ($1, $2, $3, $4)`
+	_, err := r.db.Exec(query, u.Username, u.PasswordHash, u.SalaryCents, u.Email)
 	return err
 }
 
 func (r *UserRepository) GetUserByID(userID int) (domain.User, error) {
 	var u domain.User
-	query := `SELECT id, username, password_hash, salary_cents FROM users WHERE id = $1`
-	err := r.db.QueryRow(query, userID).Scan(&u.ID, &u.Username, &u.PasswordHash, &u.SalaryCents)
+	query := `SELECT id, username, password_hash, salary_cents, email, is_admin FROM users WHERE id = $1`
+	err := r.db.QueryRow(query, userID).Scan(&u.ID, &u.Username, &u.PasswordHash, &u.SalaryCents, &u.Email, &u.IsAdmin)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return domain.User{}, domain.ErrUserNotFound
@@ -51,3 +51,27 @@ func (r *UserRepository) UpdateUserSalary(userID int, salary int) error {
 	_, err := r.db.Exec(query, salary, userID)
 	return err
 }
+
+func (r *UserRepository) UpdateUserEmail(userID int, email string) error {
+	query := `UPDATE users SET email = $1 WHERE id = $2`
+	_, err := r.db.Exec(query, email, userID)
+	return err
+}
+
+func (r *UserRepository) FindAllUsers() ([]domain.User, error) {
+	rows, err := r.db.Query(`SELECT id, username, password_hash, salary_cents, email, is_admin FROM users ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []domain.User
+	for rows.Next() {
+		var u domain.User
+		if err := rows.Scan(&u.ID, &u.Username, &u.PasswordHash, &u.SalaryCents, &u.Email, &u.IsAdmin); err != nil {
+			return nil, err
+		}
+		users = append(users, u)
+	}
+	return users, rows.Err()
+}
@@ -2,11 +2,13 @@ package postgres
 
 import (
 	"database/sql"
+	"fmt"
+
 	"github.com/fim-lab/expense-tracker/internal/core/domain"
 )
 
 type WalletRepository struct {
-	db *sql.DB
+	db dbtx
 }
 
 func NewWalletRepository(db *sql.DB) *WalletRepository {
@@ -14,23 +16,36 @@ func NewWalletRepository(db *sql.DB) *WalletRepository {
 }
 
 func (r *WalletRepository) SaveWallet(w domain.Wallet) error {
-	query := `INSERT INTO wallets (user_id, name) VALUES ($1, $2)`
-	_, err := r.db.Exec(query, w.UserID, w.Name)
+	query := `
+		INSERT INTO wallets (user_id, name, type, statement_closing_day, payment_due_day, payment_wallet_id)
+		VALUES ($1, $2, $3, $4, $5, $6)`
+	_, err := r.db.Exec(query, w.UserID, w.Name, w.Type, w.StatementClosingDay, w.PaymentDueDay, w.PaymentWalletID)
 	return err
 }
 
 func (r *WalletRepository) UpdateWallet(w domain.Wallet) error {
 	query := `
 		UPDATE wallets
-		SET name = $2
+		SET name = $2, type = $3, statement_closing_day = $4, payment_due_day = $5, payment_wallet_id = $6
 	    WHERE id = $1`
-	_, err := r.db.Exec(query, w.ID, w.Name)
+	_, err := r.db.Exec(query, w.ID, w.Name, w.Type, w.StatementClosingDay, w.PaymentDueDay, w.PaymentWalletID)
 	return err
 }
-func (r *WalletRepository) GetWalletByID(id int) (domain.Wallet, error) {
+
+const walletColumns = `id, user_id, name, type, balance_cents, statement_closing_day, payment_due_day, payment_wallet_id`
+
+func scanWallet(row interface{ Scan(...any) error }) (domain.Wallet, error) {
 	var w domain.Wallet
-	query := `SELECT id, user_id, name FROM wallets WHERE id = $1`
-	err := r.db.QueryRow(query, id).Scan(&w.ID, &w.UserID, &w.Name)
+	var paymentWalletID sql.NullInt64
+	if err := row.Scan(&w.ID, &w.UserID, &w.Name, &w.Type, &w.BalanceCents, &w.StatementClosingDay, &w.PaymentDueDay, &paymentWalletID); err != nil {
+		return domain.Wallet{}, err
+	}
+	w.PaymentWalletID = nullableID(paymentWalletID)
+	return w, nil
+}
+
+func (r *WalletRepository) GetWalletByID(id int) (domain.Wallet, error) {
+	w, err := scanWallet(r.db.QueryRow(`SELECT `+walletColumns+` FROM wallets WHERE id = $1`, id))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return domain.Wallet{}, domain.ErrWalletNotFound
@@ -41,8 +56,7 @@ func (r *WalletRepository) GetWalletByID(id int) (domain.Wallet, error) {
 }
 
 func (r *WalletRepository) FindWalletsByUser(userID int) ([]domain.Wallet, error) {
-	query := `SELECT id, user_id, name, balance_cents FROM wallets WHERE user_id = $1 ORDER BY id ASC`
-	rows, err := r.db.Query(query, userID)
+	rows, err := r.db.Query(`SELECT `+walletColumns+` FROM wallets WHERE user_id = $1 ORDER BY id ASC`, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -50,8 +64,8 @@ func (r *WalletRepository) FindWalletsByUser(userID int) ([]domain.Wallet, error
 
 	var res []domain.Wallet
 	for rows.Next() {
-		var w domain.Wallet
-		if err := rows.Scan(&w.ID, &w.UserID, &w.Name, &w.BalanceCents); err != nil {
+		w, err := scanWallet(rows)
+		if err != nil {
 			return nil, err
 		}
 		res = append(res, w)
@@ -68,3 +82,15 @@ func (r *WalletRepository) DeleteAllByUser(userID int) error {
 	_, err := r.db.Exec("DELETE FROM wallets WHERE user_id = $1", userID)
 	return err
 }
+
+func (r *WalletRepository) SetWalletBalance(id int, balanceCents int) error {
+	_, err := r.db.Exec("UPDATE wallets SET balance_cents = $2 WHERE id = $1", id, balanceCents)
+	return err
+}
+
+func (r *WalletRepository) LockWalletsByUser(userID int) error {
+	if _, err := r.db.Exec("SELECT id FROM wallets WHERE user_id = $1 FOR UPDATE", userID); err != nil {
+		return fmt.Errorf("failed to lock the wallets of user %d: %w", userID, err)
+	}
+	return nil
+}
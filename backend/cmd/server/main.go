@@ -5,11 +5,16 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/fim-lab/expense-tracker/adapters/handler/httpadapter"
 	"github.com/fim-lab/expense-tracker/adapters/handler/middleware"
+	"github.com/fim-lab/expense-tracker/adapters/importer"
+	"github.com/fim-lab/expense-tracker/adapters/notifier"
 	"github.com/fim-lab/expense-tracker/adapters/repository/memory"
 	"github.com/fim-lab/expense-tracker/adapters/repository/postgres"
+	"github.com/fim-lab/expense-tracker/internal/core/domain"
 	"github.com/fim-lab/expense-tracker/internal/core/ports"
 	"github.com/fim-lab/expense-tracker/internal/core/services"
 	"github.com/go-chi/chi/v5"
@@ -21,6 +26,11 @@ const (
 	EnvDemo       = "demo"
 	EnvProduction = "production"
 	DefaultPort   = "8080"
+
+	DefaultTemplateSchedulerInterval = time.Hour
+	DefaultBalanceCheckInterval      = 24 * time.Hour
+	DefaultPendingCheckInterval      = time.Hour
+	DefaultPendingOverdueDays        = 5
 )
 
 func main() {
@@ -39,26 +49,66 @@ func main() {
 	}
 
 	// Setup services
+	authorizer := services.NewAuthorizer(repos)
 	userService := services.NewUserService(repos.UserRepository())
 	sessionService := services.NewSessionService(repos.SessionRepository())
-	budgetService := services.NewBudgetService(repos.BudgetRepository(), repos.TransactionRepository())
-	walletService := services.NewWalletService(repos.WalletRepository(), repos.TransactionRepository())
+	walletService := services.NewWalletService(repos.WalletRepository(), repos.TransactionRepository(), repos.MembershipRepository(), authorizer)
 	stockService := services.NewStockService(repos.StockRepository(), repos.TradeRepository())
-	depotService := services.NewDepotService(repos.DepotRepository(), repos.WalletRepository(), repos.BudgetRepository(), repos.TradeRepository(), stockService)
-	transactionService := services.NewTransactionService(repos.TransactionRepository(), repos.BudgetRepository(), repos.WalletRepository())
-	tradeService := services.NewTradeService(repos.TradeRepository(), depotService, transactionService, stockService)
+	depotService := services.NewDepotService(repos.DepotRepository(), repos.TradeRepository(), repos.MembershipRepository(), authorizer, stockService)
+	notificationService := services.NewNotificationService(repos.NotificationRepository(), repos.UserRepository(), notifiersFromEnv()...)
+	budgetAlertService := services.NewBudgetAlertService(repos.TransactionRepository(), repos.BudgetRepository(), notificationService)
+	budgetService := services.NewBudgetService(repos, budgetAlertService)
+	transactionService := services.NewBudgetAlertingTransactionService(
+		services.NewTransactionService(repos.TransactionRepository(), authorizer),
+		repos.TransactionRepository(), budgetAlertService)
+	tradeService := services.NewTradeService(repos.TradeRepository(), repos.UserRepository(), depotService, transactionService, stockService)
 	portfolioService := services.NewPortfolioService(repos.TradeRepository(), depotService, stockService)
-	transactionTemplateService := services.NewTransactionTemplateService(repos.TransactionTemplateRepository(), repos.WalletRepository(), repos.BudgetRepository())
-	importService := services.NewImportService(
-		repos.UserRepository(),
-		repos.BudgetRepository(),
-		repos.WalletRepository(),
-		repos.DepotRepository(),
-		repos.TransactionRepository(),
-		repos.TradeRepository(),
-		repos.TransactionTemplateRepository(),
-		stockService,
-	)
+	transactionTemplateService := services.NewTransactionTemplateService(repos.TransactionTemplateRepository(), authorizer)
+	importService := services.NewImportService(repos, budgetAlertService)
+	tagService := services.NewTagService(repos)
+	savingsGoalService := services.NewSavingsGoalService(repos)
+	dashboardService := services.NewDashboardService(repos.UserRepository(), repos.BudgetRepository(), budgetService, walletService)
+	statementImportService := services.NewStatementImportService(importService, authorizer, repos.CSVProfileRepository(), importer.NewStatementParser())
+	transactionTemplateScheduler := services.NewTransactionTemplateScheduler(repos.TransactionTemplateRepository(), transactionService)
+	balanceService := services.NewBalanceService(repos)
+	reconciliationService := services.NewReconciliationService(repos)
+	debtService := services.NewDebtService(repos)
+	sharedExpenseService := services.NewSharedExpenseService(repos)
+	membershipService := services.NewMembershipService(repos.MembershipRepository(), repos.UserRepository(), authorizer)
+	pendingMonitor := services.NewPendingTransactionMonitor(repos.TransactionRepository(), notificationService, intFromEnv("PENDING_OVERDUE_DAYS", DefaultPendingOverdueDays))
+
+	// Background jobs
+	go runPeriodically(durationFromEnv("TEMPLATE_SCHEDULER_INTERVAL", DefaultTemplateSchedulerInterval), func() {
+		booked, err := transactionTemplateScheduler.BookDueTransactions(time.Now())
+		if err != nil {
+			log.Printf("Booking due template transactions failed: %v", err)
+			return
+		}
+		if booked > 0 {
+			log.Printf("Booked %d due template transactions", booked)
+		}
+	})
+	repairBalances := os.Getenv("BALANCE_CHECK_REPAIR") == "true"
+	go runPeriodically(durationFromEnv("BALANCE_CHECK_INTERVAL", DefaultBalanceCheckInterval), func() {
+		report, err := balanceService.CheckBalances(repairBalances)
+		if err != nil {
+			log.Printf("Checking balances failed: %v", err)
+			return
+		}
+		for _, d := range report.Drifts {
+			log.Printf("Balance of %s %d of user %d drifted: stored %d, ledger %d (repaired: %t)", d.Kind, d.ID, d.UserID, d.StoredCents, d.LedgerCents, report.Repaired)
+		}
+	})
+	go runPeriodically(durationFromEnv("PENDING_CHECK_INTERVAL", DefaultPendingCheckInterval), func() {
+		flagged, err := pendingMonitor.FlagOverduePending(time.Now())
+		if err != nil {
+			log.Printf("Flagging overdue pending transactions failed: %v", err)
+			return
+		}
+		if flagged > 0 {
+			log.Printf("Flagged %d overdue pending transactions", flagged)
+		}
+	})
 
 	// Setup router
 	router := chi.NewRouter()
@@ -66,7 +116,7 @@ func main() {
 
 	// Mount routers
 	router.Mount("/auth", authRouter(&userService, &sessionService))
-	router.Mount("/api", apiRouter(env, &sessionService, &budgetService, &walletService, &depotService, &transactionService, &portfolioService, &tradeService, &userService, &transactionTemplateService, &importService, &stockService))
+	router.Mount("/api", apiRouter(env, &sessionService, &budgetService, &walletService, &depotService, &transactionService, &portfolioService, &tradeService, &userService, &transactionTemplateService, &importService, &statementImportService, &stockService, &tagService, &dashboardService, &notificationService, &savingsGoalService, &balanceService, &reconciliationService, &debtService, &sharedExpenseService, &membershipService))
 
 	log.Printf("Start Server on port %s in %s mode", DefaultPort, env)
 	if err := http.ListenAndServe(":"+DefaultPort, router); err != nil {
@@ -74,6 +124,63 @@ func main() {
 	}
 }
 
+// runPeriodically runs job right away and then once per interval.
+func runPeriodically(interval time.Duration, job func()) {
+	job()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		job()
+	}
+}
+
+func durationFromEnv(name string, fallback time.Duration) time.Duration {
+	raw := os.Getenv(name)
+	if raw == "" {
+		return fallback
+	}
+
+	d, err := time.ParseDuration(raw)
+	if err != nil || d <= 0 {
+		log.Printf("Invalid %s %q, falling back to %s", name, raw, fallback)
+		return fallback
+	}
+	return d
+}
+
+func intFromEnv(name string, fallback int) int {
+	raw := os.Getenv(name)
+	if raw == "" {
+		return fallback
+	}
+
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 0 {
+		log.Printf("Invalid %s %q, falling back to %d", name, raw, fallback)
+		return fallback
+	}
+	return n
+}
+
+// notifiersFromEnv sets up a notifier for every channel configured: SMTP
+// with NOTIFY_SMTP_HOST, a webhook with NOTIFY_WEBHOOK_URL.
+func notifiersFromEnv() []ports.Notifier {
+	var notifiers []ports.Notifier
+	if host := os.Getenv("NOTIFY_SMTP_HOST"); host != "" {
+		port := os.Getenv("NOTIFY_SMTP_PORT")
+		if port == "" {
+			port = "587"
+		}
+		notifiers = append(notifiers, notifier.NewSMTPNotifier(host, port, os.Getenv("NOTIFY_SMTP_USERNAME"), os.Getenv("NOTIFY_SMTP_PASSWORD"), os.Getenv("NOTIFY_SMTP_FROM")))
+		log.Printf("Mailing notifications through %s:%s", host, port)
+	}
+	if url := os.Getenv("NOTIFY_WEBHOOK_URL"); url != "" {
+		notifiers = append(notifiers, notifier.NewWebhookNotifier(url))
+		log.Printf("Posting notifications to a webhook")
+	}
+	return notifiers
+}
+
 func authRouter(userService *ports.UserService, sessionService *ports.SessionService) http.Handler {
 	r := chi.NewRouter()
 	authHandler := httpadapter.NewAuthHandler(userService, sessionService)
@@ -83,7 +190,7 @@ func authRouter(userService *ports.UserService, sessionService *ports.SessionSer
 	return r
 }
 
-func apiRouter(env string, sessionService *ports.SessionService, budgetService *ports.BudgetService, walletService *ports.WalletService, depotService *ports.DepotService, transactionService *ports.TransactionService, portfolioService *ports.PortfolioService, tradeService *ports.TradeService, userService *ports.UserService, transactionTemplateService *ports.TransactionTemplateService, importService *ports.ImportService, stockService *ports.StockService) http.Handler {
+func apiRouter(env string, sessionService *ports.SessionService, budgetService *ports.BudgetService, walletService *ports.WalletService, depotService *ports.DepotService, transactionService *ports.TransactionService, portfolioService *ports.PortfolioService, tradeService *ports.TradeService, userService *ports.UserService, transactionTemplateService *ports.TransactionTemplateService, importService *ports.ImportService, statementImportService *ports.StatementImportService, stockService *ports.StockService, tagService *ports.TagService, dashboardService *ports.DashboardService, notificationService *ports.NotificationService, savingsGoalService *ports.SavingsGoalService, balanceService *ports.BalanceService, reconciliationService *ports.ReconciliationService, debtService *ports.DebtService, sharedExpenseService *ports.SharedExpenseService, membershipService *ports.MembershipService) http.Handler {
 	r := chi.NewRouter()
 
 	// Middleware
@@ -105,22 +212,85 @@ func apiRouter(env string, sessionService *ports.SessionService, budgetService *
 	userHandler := httpadapter.NewUserHandler(userService)
 	transactionTemplateHandler := httpadapter.NewTransactionTemplateHandler(transactionTemplateService)
 	stockHandler := httpadapter.NewStockHandler(*stockService)
+	statementImportHandler := httpadapter.NewStatementImportHandler(*statementImportService)
+	tagHandler := httpadapter.NewTagHandler(*tagService)
+	dashboardHandler := httpadapter.NewDashboardHandler(*dashboardService)
+	notificationHandler := httpadapter.NewNotificationHandler(*notificationService)
+	savingsGoalHandler := httpadapter.NewSavingsGoalHandler(*savingsGoalService)
+	balanceHandler := httpadapter.NewBalanceHandler(*balanceService)
+	reconciliationHandler := httpadapter.NewReconciliationHandler(*reconciliationService)
+	debtHandler := httpadapter.NewDebtHandler(*debtService)
+	sharedExpenseHandler := httpadapter.NewSharedExpenseHandler(*sharedExpenseService)
+	membershipHandler := httpadapter.NewMembershipHandler(*membershipService)
 
 	// Routes
 	r.Get("/users/me", userHandler.GetUser)
 	r.Put("/users/me/salary", userHandler.UpdateSalary)
+	r.Put("/users/me/email", userHandler.UpdateEmail)
+
+	r.Get("/dashboard", dashboardHandler.GetDashboard)
+
+	r.Get("/notifications", notificationHandler.GetNotifications)
+	r.Post("/notifications/{id}/read", notificationHandler.MarkRead)
 
 	r.Get("/budgets", budgetHandler.GetBudgets)
+	r.Get("/budgets/to-be-assigned", budgetHandler.GetToBeAssigned)
+	r.Post("/budgets/assign", budgetHandler.AssignToBudgets)
+	r.Post("/budgets/transfer", budgetHandler.Transfer)
+	r.Get("/budgets/movements", budgetHandler.GetMovements)
 	r.Get("/budgets/{id}", budgetHandler.GetBudget)
 	r.Post("/budgets", budgetHandler.CreateBudget)
 	r.Put("/budgets/{id}", budgetHandler.UpdateBudget)
 	r.Delete("/budgets/{id}", budgetHandler.DeleteBudget)
+	r.Get("/budgets/{id}/history", budgetHandler.GetBudgetHistory)
+	r.Put("/budgets/{id}/allocations", budgetHandler.SetAllocation)
+	r.Put("/budgets/{id}/parent", budgetHandler.MoveBudget)
+
+	r.Get("/savings-goals", savingsGoalHandler.GetSavingsGoals)
+	r.Get("/savings-goals/{id}", savingsGoalHandler.GetSavingsGoal)
+	r.Post("/savings-goals", savingsGoalHandler.CreateSavingsGoal)
+	r.Put("/savings-goals/{id}", savingsGoalHandler.UpdateSavingsGoal)
+	r.Delete("/savings-goals/{id}", savingsGoalHandler.DeleteSavingsGoal)
 
 	r.Get("/wallets", walletHandler.GetWallets)
+	r.Get("/wallets/balances", walletHandler.GetBalances)
 	r.Get("/wallets/{id}", walletHandler.GetWallet)
+	r.Get("/wallets/{id}/balance-history", walletHandler.GetBalanceHistory)
 	r.Post("/wallets", walletHandler.CreateWallet)
 	r.Put("/wallets/{id}", walletHandler.UpdateWallet)
 	r.Delete("/wallets/{id}", walletHandler.DeleteWallet)
+	r.Post("/wallets/{id}/settle", walletHandler.SettleStatement)
+	r.Get("/wallets/{id}/reconciliations", reconciliationHandler.GetReconciliations)
+	r.Post("/wallets/{id}/reconciliations", reconciliationHandler.StartReconciliation)
+
+	r.Get("/reconciliations/{id}", reconciliationHandler.GetReconciliation)
+	r.Put("/reconciliations/{id}/cleared", reconciliationHandler.SetCleared)
+	r.Post("/reconciliations/{id}/complete", reconciliationHandler.CompleteReconciliation)
+	r.Delete("/reconciliations/{id}", reconciliationHandler.DeleteReconciliation)
+
+	r.Get("/counterparties", debtHandler.GetCounterparties)
+	r.Post("/counterparties", debtHandler.CreateCounterparty)
+	r.Delete("/counterparties/{id}", debtHandler.DeleteCounterparty)
+	r.Get("/debts", debtHandler.GetDebts)
+	r.Post("/debts", debtHandler.CreateDebt)
+	r.Put("/debts/{id}/counterparty", debtHandler.AssignCounterparty)
+	r.Post("/debts/{id}/repayments", debtHandler.RepayDebt)
+
+	r.Put("/transactions/{id}/share", sharedExpenseHandler.ShareExpense)
+	r.Delete("/transactions/{id}/share", sharedExpenseHandler.UnshareExpense)
+	r.Get("/shared/expenses", sharedExpenseHandler.GetSharedExpenses)
+	r.Get("/shared/ledger", sharedExpenseHandler.GetLedger)
+	r.Post("/shared/settlements", sharedExpenseHandler.SettleUp)
+	r.Post("/shared/settlements/{id}/confirm", sharedExpenseHandler.ConfirmSettlement)
+
+	r.Get("/wallets/{id}/members", membershipHandler.GetMembers(domain.ResourceWallet))
+	r.Post("/wallets/{id}/members", membershipHandler.Invite(domain.ResourceWallet))
+	r.Get("/budgets/{id}/members", membershipHandler.GetMembers(domain.ResourceBudget))
+	r.Post("/budgets/{id}/members", membershipHandler.Invite(domain.ResourceBudget))
+	r.Get("/depots/{id}/members", membershipHandler.GetMembers(domain.ResourceDepot))
+	r.Post("/depots/{id}/members", membershipHandler.Invite(domain.ResourceDepot))
+	r.Get("/memberships", membershipHandler.GetSharedWithMe)
+	r.Delete("/memberships/{id}", membershipHandler.RemoveMember)
 
 	r.Get("/depots", depotHandler.GetDepots)
 	r.Get("/depots/{id}", depotHandler.GetDepot)
@@ -133,10 +303,17 @@ func apiRouter(env string, sessionService *ports.SessionService, budgetService *
 	r.Get("/transactions/{id}", transactionHandler.GetTransaction)
 	r.Post("/transactions", transactionHandler.CreateTransaction)
 	r.Post("/transactions/transfer", transactionHandler.Transfer)
+	r.Post("/transactions/settle", transactionHandler.SettleTransactions)
 	r.Put("/transactions/{id}", transactionHandler.UpdateTransaction)
 	r.Delete("/transactions/{id}", transactionHandler.DeleteTransaction)
 	r.Post("/transactions/import", transactionHandler.ImportTransactions)
 	r.Post("/transactions/import/testdata", transactionHandler.ImportTestData)
+	r.Post("/transactions/import/preview", transactionHandler.PreviewImport)
+	r.Post("/transactions/import/confirm", transactionHandler.ConfirmImport)
+	r.Post("/transactions/import/csv", statementImportHandler.ImportCSV)
+	r.Post("/transactions/import/camt053", statementImportHandler.ImportCAMT053)
+	r.Post("/transactions/import/mt940", statementImportHandler.ImportMT940)
+	r.Get("/users/me/export", transactionHandler.ExportAccount)
 	r.Delete("/users/me/data", transactionHandler.DeleteAllUserData)
 
 	r.Get("/depots/{id}/portfolio", portfolioHandler.GetPortfolio)
@@ -145,16 +322,33 @@ func apiRouter(env string, sessionService *ports.SessionService, budgetService *
 	r.Put("/trades/{id}", tradeHandler.UpdateTrade)
 	r.Delete("/trades/{id}", tradeHandler.DeleteTrade)
 
+	r.Get("/tags", tagHandler.GetTags)
+	r.Post("/tags/rename", tagHandler.RenameTag)
+	r.Post("/tags/merge", tagHandler.MergeTags)
+
 	r.Get("/transaction-templates", transactionTemplateHandler.GetTransactionTemplates)
+	r.Get("/transaction-templates/upcoming", transactionTemplateHandler.GetUpcomingTransactions)
 	r.Get("/transaction-templates/{id}", transactionTemplateHandler.GetTransactionTemplateByID)
 	r.Post("/transaction-templates", transactionTemplateHandler.CreateTransactionTemplate)
 	r.Put("/transaction-templates/{id}", transactionTemplateHandler.UpdateTransactionTemplate)
 	r.Delete("/transaction-templates/{id}", transactionTemplateHandler.DeleteTransactionTemplate)
 
+	r.Get("/csv-profiles", statementImportHandler.GetCSVProfiles)
+	r.Post("/csv-profiles", statementImportHandler.CreateCSVProfile)
+	r.Put("/csv-profiles/{id}", statementImportHandler.UpdateCSVProfile)
+	r.Delete("/csv-profiles/{id}", statementImportHandler.DeleteCSVProfile)
+
 	r.Get("/stocks", stockHandler.GetStocks)
 	r.Post("/stocks", stockHandler.CreateStock)
 	r.Put("/stocks/{id}", stockHandler.UpdateStock)
 	r.Delete("/stocks/{id}", stockHandler.DeleteStock)
 
+	r.Route("/admin", func(r chi.Router) {
+		r.Use(middleware.NewAdminMiddleware(userService).Handle)
+		r.Get("/balance-check", balanceHandler.CheckBalances)
+		r.Post("/balance-check/repair", balanceHandler.RepairBalances)
+		r.Post("/trades/sync-cash-flows", tradeHandler.SyncCashFlows)
+	})
+
 	return r
 }
@@ -0,0 +1,156 @@
+package domain
+
+import "time"
+
+// AccountExportVersion is the version of the AccountExport format written by
+// this build. Raise it whenever older exports would no longer import the
+// same way, or the format gains something older builds would drop on import.
+// Version 2 added reconciliations.
+const AccountExportVersion = 2
+
+// AccountExport is everything a user owns, as written by the account export
+// and read back by the import. Wallets, budgets and depots have unique names
+// per user and are referred to by name; stocks by WKN. Ref numbers only link
+// a trade to its wallet transaction within one export.
+type AccountExport struct {
+	Version              int                         `json:"version"`
+	ExportedAt           time.Time                   `json:"exportedAt"`
+	SalaryCents          int                         `json:"salaryCents"`
+	Wallets              []ExportWallet              `json:"wallets"`
+	Reconciliations      []ExportReconciliation      `json:"reconciliations,omitempty"`
+	Budgets              []ExportBudget              `json:"budgets"`
+	BudgetMovements      []ExportBudgetMovement      `json:"budgetMovements,omitempty"`
+	Depots               []ExportDepot               `json:"depots"`
+	Stocks               []ExportStock               `json:"stocks"`
+	Transactions         []ExportTransaction         `json:"transactions"`
+	Trades               []ExportTrade               `json:"trades"`
+	TransactionTemplates []ExportTransactionTemplate `json:"transactionTemplates"`
+	SavingsGoals         []ExportSavingsGoal         `json:"savingsGoals,omitempty"`
+	Counterparties       []string                    `json:"counterparties,omitempty"`
+	CSVProfiles          []CSVProfile                `json:"csvProfiles"`
+}
+
+type ExportWallet struct {
+	Name                string     `json:"name"`
+	Type                WalletType `json:"type,omitempty"`
+	StatementClosingDay int        `json:"statementClosingDay,omitempty"`
+	PaymentDueDay       int        `json:"paymentDueDay,omitempty"`
+	PaymentWallet       string     `json:"paymentWallet,omitempty"`
+}
+
+type ExportReconciliation struct {
+	Wallet                string               `json:"wallet"`
+	StatementDate         time.Time            `json:"statementDate"`
+	StatementBalanceCents int                  `json:"statementBalanceCents"`
+	Status                ReconciliationStatus `json:"status"`
+	CreatedAt             time.Time            `json:"createdAt"`
+	CompletedAt           *time.Time           `json:"completedAt,omitempty"`
+}
+
+type ExportBudget struct {
+	Name        string             `json:"name"`
+	Parent      string             `json:"parent,omitempty"`
+	LimitCents  int                `json:"limitCents"`
+	Period      Frequency          `json:"period,omitempty"`
+	Rollover    RolloverPolicy     `json:"rollover,omitempty"`
+	Allocations []ExportAllocation `json:"allocations,omitempty"`
+	// AlertThresholds are percentages of LimitCents.
+	AlertThresholds []int `json:"alertThresholds,omitempty"`
+}
+
+// ExportBudgetMovement leaves From or To empty for money taken from or given
+// back to the income to be assigned.
+type ExportBudgetMovement struct {
+	Date          time.Time `json:"date"`
+	From          string    `json:"from,omitempty"`
+	To            string    `json:"to,omitempty"`
+	AmountInCents int       `json:"amountInCents"`
+	Note          string    `json:"note,omitempty"`
+}
+
+type ExportAllocation struct {
+	PeriodStart   time.Time `json:"periodStart"`
+	AmountInCents int       `json:"amountInCents"`
+}
+
+type ExportDepot struct {
+	Name   string `json:"name"`
+	Wallet string `json:"wallet"`
+	Budget string `json:"budget"`
+}
+
+type ExportStock struct {
+	WKN          string `json:"wkn"`
+	Ticker       string `json:"ticker"`
+	PriceInCents int    `json:"priceInCents"`
+}
+
+type ExportTransaction struct {
+	Ref           int             `json:"ref"`
+	Date          time.Time       `json:"date"`
+	Wallet        string          `json:"wallet"`
+	Budget        string          `json:"budget,omitempty"`
+	Description   string          `json:"description"`
+	AmountInCents int             `json:"amountInCents"`
+	Type          TransactionType `json:"type"`
+	IsPending     bool            `json:"isPending"`
+	IsDebt        bool            `json:"isDebt"`
+	Tags          []string        `json:"tags,omitempty"`
+	ExternalID    string          `json:"externalId,omitempty"`
+	Splits        []ExportSplit   `json:"splits,omitempty"`
+	Cleared       bool            `json:"cleared,omitempty"`
+	// Reconciliation is the position, counting from one, of the completed
+	// reconciliation in Reconciliations that locked the transaction.
+	Reconciliation *int `json:"reconciliation,omitempty"`
+	// A debt names its counterparty, and a repayment also the Ref of the
+	// debt it pays back, which comes before it.
+	Counterparty string `json:"counterparty,omitempty"`
+	RepaymentOf  *int   `json:"repaymentOf,omitempty"`
+}
+
+type ExportSplit struct {
+	Budget        string   `json:"budget"`
+	AmountInCents int      `json:"amountInCents"`
+	Tags          []string `json:"tags,omitempty"`
+	Note          string   `json:"note,omitempty"`
+}
+
+type ExportTrade struct {
+	Depot             string    `json:"depot"`
+	WKN               string    `json:"wkn"`
+	Type              TradeType `json:"type"`
+	Quantity          float64   `json:"quantity"`
+	TotalInCents      int       `json:"totalInCents"`
+	FeesInCents       int       `json:"feesInCents"`
+	TaxesInCents      int       `json:"taxesInCents"`
+	Timestamp         time.Time `json:"timestamp"`
+	WalletTransaction *int      `json:"walletTransaction,omitempty"` // Ref of the transaction booking the trade on the wallet
+}
+
+type ExportTransactionTemplate struct {
+	Day            int             `json:"day"`
+	Wallet         string          `json:"wallet"`
+	ToWallet       string          `json:"toWallet,omitempty"`
+	Budget         string          `json:"budget,omitempty"`
+	Description    string          `json:"description"`
+	AmountInCents  int             `json:"amountInCents"`
+	Type           TransactionType `json:"type"`
+	Tags           []string        `json:"tags,omitempty"`
+	Recurrence     RecurrenceRule  `json:"recurrence"`
+	CreatedAt      time.Time       `json:"createdAt"`
+	LastOccurrence *time.Time      `json:"lastOccurrence"`
+}
+
+type ExportSavingsGoal struct {
+	Name                     string    `json:"name"`
+	Wallet                   string    `json:"wallet,omitempty"`
+	Budget                   string    `json:"budget,omitempty"`
+	TargetCents              int       `json:"targetCents"`
+	TargetDate               time.Time `json:"targetDate"`
+	MonthlyContributionCents int       `json:"monthlyContributionCents"`
+	FromWallet               string    `json:"fromWallet,omitempty"`
+	// ContributionTemplate is the position, counting from one, of the
+	// template booking the automatic contribution in TransactionTemplates.
+	ContributionTemplate *int      `json:"contributionTemplate,omitempty"`
+	CreatedAt            time.Time `json:"createdAt"`
+}
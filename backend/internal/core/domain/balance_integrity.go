@@ -0,0 +1,54 @@
+package domain
+
+import "time"
+
+type BalanceKind string
+
+const (
+	WalletBalanceKind BalanceKind = "WALLET"
+	BudgetBalanceKind BalanceKind = "BUDGET"
+)
+
+// BalanceDrift is a wallet or budget whose stored balance differs from the
+// one its ledger adds up to.
+type BalanceDrift struct {
+	Kind        BalanceKind `json:"kind"`
+	ID          int         `json:"id"`
+	UserID      int         `json:"userId"`
+	Name        string      `json:"name"`
+	StoredCents int         `json:"storedCents"`
+	LedgerCents int         `json:"ledgerCents"`
+	// DifferenceCents is how much the stored balance is off, StoredCents
+	// minus LedgerCents.
+	DifferenceCents int `json:"differenceCents"`
+}
+
+// BalanceIntegrityReport is the outcome of comparing every stored balance with its
+// ledger. Repaired tells whether the drifted balances were set to the ledger.
+type BalanceIntegrityReport struct {
+	CheckedAt      time.Time      `json:"checkedAt"`
+	WalletsChecked int            `json:"walletsChecked"`
+	BudgetsChecked int            `json:"budgetsChecked"`
+	Drifts         []BalanceDrift `json:"drifts"`
+	Repaired       bool           `json:"repaired"`
+}
+
+// LedgerBalances adds up what the transactions book on their wallets and
+// what the transactions and movements book on their budgets.
+func LedgerBalances(transactions []Transaction, movements []BudgetMovement) (wallets, budgets map[int]int) {
+	wallets, budgets = make(map[int]int), make(map[int]int)
+	for _, t := range transactions {
+		wallets[t.WalletID] += t.SignedAmount()
+		for budgetID, amount := range t.BudgetAmounts() {
+			budgets[budgetID] += amount
+		}
+	}
+	for _, m := range movements {
+		for _, budgetID := range []*int{m.FromBudgetID, m.ToBudgetID} {
+			if budgetID != nil {
+				budgets[*budgetID] += m.AmountOnBudget(*budgetID)
+			}
+		}
+	}
+	return wallets, budgets
+}
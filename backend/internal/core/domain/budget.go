@@ -1,10 +1,83 @@
 package domain
 
+import (
+	"fmt"
+	"slices"
+)
+
 type Budget struct {
-	ID           int    `json:"id"`
-	UserID       int    `json:"userId"`
-	Name         string `json:"name"`
-	LimitCents   int    `json:"limitCents"`
-	BalanceCents int    `json:"balanceCents"`
-	CanDelete    bool   `json:"canDelete"`
+	ID            int                  `json:"id"`
+	UserID        int                  `json:"userId"`
+	ParentID      *int                 `json:"parentId,omitempty"`
+	Name          string               `json:"name"`
+	LimitCents    int                  `json:"limitCents"` // Allocation of every period that has none of its own
+	BalanceCents  int                  `json:"balanceCents"`
+	BookedCents   int                  `json:"bookedCents"`  // The balance without pending transactions
+	PendingCents  int                  `json:"pendingCents"` // What pending transactions add to the balance
+	CanDelete     bool                 `json:"canDelete"`
+	Period        Frequency            `json:"period"`
+	Rollover      RolloverPolicy       `json:"rollover"`
+	CurrentPeriod *BudgetPeriodSummary `json:"currentPeriod,omitempty"`
+	// AlertThresholds are percentages of what a period is allocated;
+	// spending in the period reaching one of them raises an alert.
+	AlertThresholds []int `json:"alertThresholds"`
+	// Permission is what the user asking may do with the budget, which may
+	// be someone else's.
+	Permission Permission `json:"permission,omitempty"`
+	// The totals add the budget's own limit and balance to those of all
+	// budgets below it.
+	TotalLimitCents   int      `json:"totalLimitCents"`
+	TotalBalanceCents int      `json:"totalBalanceCents"`
+	Children          []Budget `json:"children,omitempty"`
+}
+
+func (b *Budget) SplitPending(pendingCents int) {
+	b.PendingCents = pendingCents
+	b.BookedCents = b.BalanceCents - pendingCents
+}
+
+// ApplyDefaults makes a budget without period or rollover policy a monthly
+// one that starts every month afresh.
+func (b *Budget) ApplyDefaults() {
+	if b.Period == "" {
+		b.Period = Monthly
+	}
+	if b.Rollover == "" {
+		b.Rollover = RolloverReset
+	}
+}
+
+// NormalizeAlertThresholds sorts the thresholds and drops duplicates.
+func (b *Budget) NormalizeAlertThresholds() error {
+	for _, threshold := range b.AlertThresholds {
+		if threshold < 1 || threshold > 1000 {
+			return fmt.Errorf("%w: %d", ErrInvalidAlertThreshold, threshold)
+		}
+	}
+	slices.Sort(b.AlertThresholds)
+	b.AlertThresholds = slices.Compact(b.AlertThresholds)
+	return nil
+}
+
+// BudgetUsage is what a budget had to spend in a period, its allocation and
+// the money moved onto it less the money moved off it, and what was spent.
+type BudgetUsage struct {
+	AvailableCents int
+	SpentCents     int
+}
+
+func (u BudgetUsage) reaches(threshold int) bool {
+	return u.SpentCents*100 >= threshold*u.AvailableCents
+}
+
+// CrossedThresholds are the alert thresholds the usage of a period reaches at
+// after but had not reached at before.
+func (b Budget) CrossedThresholds(before, after BudgetUsage) []int {
+	var crossed []int
+	for _, threshold := range b.AlertThresholds {
+		if !before.reaches(threshold) && after.reaches(threshold) {
+			crossed = append(crossed, threshold)
+		}
+	}
+	return crossed
 }
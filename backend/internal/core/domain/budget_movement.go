@@ -0,0 +1,58 @@
+package domain
+
+import "time"
+
+// BudgetMovement moves money onto or off a budget without touching any
+// wallet. Money that comes from no budget is taken from the income still to
+// be assigned, and money that goes to no budget is given back to it. With
+// both budgets set it is a transfer between them.
+type BudgetMovement struct {
+	ID            int       `json:"id"`
+	UserID        int       `json:"userId"`
+	Date          time.Time `json:"date"`
+	FromBudgetID  *int      `json:"fromBudgetId"`
+	ToBudgetID    *int      `json:"toBudgetId"`
+	AmountInCents int       `json:"amountInCents"`
+	Note          string    `json:"note,omitempty"`
+}
+
+// AmountOnBudget is what the movement adds to the budget's balance.
+func (m BudgetMovement) AmountOnBudget(budgetID int) int {
+	amount := 0
+	if m.ToBudgetID != nil && *m.ToBudgetID == budgetID {
+		amount += m.AmountInCents
+	}
+	if m.FromBudgetID != nil && *m.FromBudgetID == budgetID {
+		amount -= m.AmountInCents
+	}
+	return amount
+}
+
+// AmountToBeAssigned is what the movement adds to the income still to be
+// assigned.
+func (m BudgetMovement) AmountToBeAssigned() int {
+	amount := 0
+	if m.ToBudgetID == nil {
+		amount += m.AmountInCents
+	}
+	if m.FromBudgetID == nil {
+		amount -= m.AmountInCents
+	}
+	return amount
+}
+
+// BudgetAssignment asks for money to be put on a budget from the income to
+// be assigned, or with a negative amount to be given back to it.
+type BudgetAssignment struct {
+	BudgetID      int `json:"budgetId"`
+	AmountInCents int `json:"amountInCents"`
+}
+
+// Dashboard sums up where the user's money is.
+type Dashboard struct {
+	SalaryCents            int `json:"salaryCents"`
+	ToBeAssignedCents      int `json:"toBeAssignedCents"`      // Income not yet put on any budget
+	AssignedThisMonthCents int `json:"assignedThisMonthCents"` // Put on budgets from the income this month
+	BudgetsTotalCents      int `json:"budgetsTotalCents"`
+	WalletsTotalCents      int `json:"walletsTotalCents"`
+}
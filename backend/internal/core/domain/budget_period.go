@@ -0,0 +1,170 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// RolloverPolicy decides what of a period's balance carries into the next.
+type RolloverPolicy string
+
+const (
+	RolloverCarryOver RolloverPolicy = "CARRY_OVER"
+	RolloverReset     RolloverPolicy = "RESET"
+	// RolloverOverspending carries only a negative balance: money left over
+	// is gone, money spent too much is missing next period.
+	RolloverOverspending RolloverPolicy = "CARRY_OVERSPENDING"
+)
+
+func (p RolloverPolicy) carry(balanceCents int) int {
+	switch p {
+	case RolloverCarryOver:
+		return balanceCents
+	case RolloverOverspending:
+		return min(balanceCents, 0)
+	default:
+		return 0
+	}
+}
+
+// ValidatePeriod checks the budget's period and rollover policy.
+func (b Budget) ValidatePeriod() error {
+	if b.Period != Weekly && b.Period != Monthly && b.Period != Yearly {
+		return fmt.Errorf("%w: period must be WEEKLY, MONTHLY or YEARLY", ErrInvalidBudgetPeriod)
+	}
+	if b.Rollover != RolloverCarryOver && b.Rollover != RolloverReset && b.Rollover != RolloverOverspending {
+		return fmt.Errorf("%w: rollover must be CARRY_OVER, RESET or CARRY_OVERSPENDING", ErrInvalidBudgetPeriod)
+	}
+	return nil
+}
+
+// PeriodStart is the first day of the budget period date falls in. Weeks
+// start on Monday.
+func PeriodStart(period Frequency, date time.Time) time.Time {
+	date = DateOf(date)
+	switch period {
+	case Weekly:
+		return date.AddDate(0, 0, -(int(date.Weekday())+6)%7)
+	case Yearly:
+		return time.Date(date.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+}
+
+// NextPeriodStart is the first day of the budget period after the one date
+// falls in.
+func NextPeriodStart(period Frequency, date time.Time) time.Time {
+	return nextPeriodStart(period, PeriodStart(period, date))
+}
+
+// nextPeriodStart is the first day of the period after the one starting on
+// start.
+func nextPeriodStart(period Frequency, start time.Time) time.Time {
+	switch period {
+	case Weekly:
+		return start.AddDate(0, 0, 7)
+	case Yearly:
+		return start.AddDate(1, 0, 0)
+	default:
+		return start.AddDate(0, 1, 0)
+	}
+}
+
+// BudgetAllocation is the money given to a budget for one period, in place
+// of its LimitCents.
+type BudgetAllocation struct {
+	BudgetID      int       `json:"budgetId"`
+	PeriodStart   time.Time `json:"periodStart"`
+	AmountInCents int       `json:"amountInCents"`
+}
+
+// AllocatedIn is what the budget is given for the period starting on start:
+// the allocation of that period, or else LimitCents.
+func (b Budget) AllocatedIn(start time.Time, allocations []BudgetAllocation) int {
+	for _, a := range allocations {
+		if PeriodStart(b.Period, a.PeriodStart).Equal(start) {
+			return a.AmountInCents
+		}
+	}
+	return b.LimitCents
+}
+
+// BudgetBooking is what one transaction or movement added to a budget:
+// income and money moved onto it positive, expenses and money moved off it
+// negative.
+type BudgetBooking struct {
+	Date          time.Time
+	AmountInCents int
+	Movement      bool
+}
+
+type BudgetPeriodSummary struct {
+	Start            time.Time `json:"start"`
+	End              time.Time `json:"end"` // Last day of the period
+	CarriedOverCents int       `json:"carriedOverCents"`
+	AllocatedCents   int       `json:"allocatedCents"`
+	AssignedCents    int       `json:"assignedCents"` // Moved onto the budget, or off it if negative
+	SpentCents       int       `json:"spentCents"`
+	IncomeCents      int       `json:"incomeCents"`
+	BalanceCents     int       `json:"balanceCents"` // Carried over + allocated + assigned + income - spent
+}
+
+// BudgetHistory plays the budget's bookings and allocations forward period by
+// period until the period holding until. It starts with the first period
+// anything was booked or allocated in, as before that nothing could be spent
+// or carried over.
+func BudgetHistory(b Budget, bookings []BudgetBooking, allocations []BudgetAllocation, until time.Time) []BudgetPeriodSummary {
+	last := PeriodStart(b.Period, until)
+	start := last
+	allocated := make(map[time.Time]int)
+	for _, a := range allocations {
+		periodStart := PeriodStart(b.Period, a.PeriodStart)
+		allocated[periodStart] = a.AmountInCents
+		if periodStart.Before(start) {
+			start = periodStart
+		}
+	}
+	for _, booking := range bookings {
+		if periodStart := PeriodStart(b.Period, booking.Date); periodStart.Before(start) {
+			start = periodStart
+		}
+	}
+
+	var history []BudgetPeriodSummary
+	for periodStart := start; !periodStart.After(last); periodStart = nextPeriodStart(b.Period, periodStart) {
+		history = append(history, BudgetPeriodSummary{
+			Start:          periodStart,
+			End:            nextPeriodStart(b.Period, periodStart).AddDate(0, 0, -1),
+			AllocatedCents: b.LimitCents,
+		})
+		if amount, ok := allocated[periodStart]; ok {
+			history[len(history)-1].AllocatedCents = amount
+		}
+	}
+	summaries := make(map[time.Time]*BudgetPeriodSummary, len(history))
+	for i := range history {
+		summaries[history[i].Start] = &history[i]
+	}
+	for _, booking := range bookings {
+		summary, ok := summaries[PeriodStart(b.Period, booking.Date)]
+		if !ok {
+			continue // Booked after until
+		}
+		if booking.Movement {
+			summary.AssignedCents += booking.AmountInCents
+		} else if booking.AmountInCents < 0 {
+			summary.SpentCents -= booking.AmountInCents
+		} else {
+			summary.IncomeCents += booking.AmountInCents
+		}
+	}
+
+	carried := 0
+	for i := range history {
+		history[i].CarriedOverCents = carried
+		history[i].BalanceCents = carried + history[i].AllocatedCents + history[i].AssignedCents + history[i].IncomeCents - history[i].SpentCents
+		carried = b.Rollover.carry(history[i].BalanceCents)
+	}
+	return history
+}
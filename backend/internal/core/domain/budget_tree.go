@@ -0,0 +1,72 @@
+package domain
+
+// BudgetTree nests the budgets under their parents, keeping the order they
+// are given in, and adds up the totals of every subtree. A budget whose
+// parent is not among them is a root.
+func BudgetTree(budgets []Budget) []Budget {
+	ids := make(map[int]bool, len(budgets))
+	for _, b := range budgets {
+		ids[b.ID] = true
+	}
+	children := make(map[int][]Budget)
+	var roots []Budget
+	for _, b := range budgets {
+		if b.ParentID != nil && ids[*b.ParentID] {
+			children[*b.ParentID] = append(children[*b.ParentID], b)
+		} else {
+			roots = append(roots, b)
+		}
+	}
+
+	var build func(b Budget) Budget
+	build = func(b Budget) Budget {
+		b.TotalLimitCents, b.TotalBalanceCents = b.LimitCents, b.BalanceCents
+		b.Children = nil
+		for _, child := range children[b.ID] {
+			child = build(child)
+			b.TotalLimitCents += child.TotalLimitCents
+			b.TotalBalanceCents += child.TotalBalanceCents
+			b.Children = append(b.Children, child)
+		}
+		return b
+	}
+	for i := range roots {
+		roots[i] = build(roots[i])
+	}
+	return roots
+}
+
+// FindInBudgetTree returns the budget with the id from anywhere in the tree.
+func FindInBudgetTree(tree []Budget, id int) (Budget, bool) {
+	for _, b := range tree {
+		if b.ID == id {
+			return b, true
+		}
+		if found, ok := FindInBudgetTree(b.Children, id); ok {
+			return found, true
+		}
+	}
+	return Budget{}, false
+}
+
+// ValidateBudgetParent checks that parentID is one of the budgets and that
+// nesting the budget with the id under it keeps them a tree. A new budget
+// has the id 0.
+func ValidateBudgetParent(budgets []Budget, id int, parentID *int) error {
+	if parentID == nil {
+		return nil
+	}
+	parents := make(map[int]*int, len(budgets))
+	for _, b := range budgets {
+		parents[b.ID] = b.ParentID
+	}
+	if _, ok := parents[*parentID]; !ok {
+		return ErrBudgetNotFound
+	}
+	for ancestor, depth := parentID, 0; ancestor != nil && depth <= len(budgets); ancestor, depth = parents[*ancestor], depth+1 {
+		if *ancestor == id {
+			return ErrInvalidBudgetParent
+		}
+	}
+	return nil
+}
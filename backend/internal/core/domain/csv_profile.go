@@ -0,0 +1,114 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+type Encoding string
+
+const (
+	EncodingUTF8        Encoding = "UTF-8"
+	EncodingLatin1      Encoding = "ISO-8859-1"
+	EncodingWindows1252 Encoding = "WINDOWS-1252"
+)
+
+// SignConvention tells how a bank marks money leaving the account.
+type SignConvention string
+
+const (
+	NegativeIsExpense SignConvention = "NEGATIVE_IS_EXPENSE"
+	PositiveIsExpense SignConvention = "POSITIVE_IS_EXPENSE"
+	// DebitIndicator amounts are unsigned; a separate column marks debits,
+	// e.g. "S" for Soll next to "H" for Haben.
+	DebitIndicator SignConvention = "DEBIT_INDICATOR"
+)
+
+// CSVProfile maps the columns of a bank's CSV export onto transactions.
+// Built-in profiles ship with the app and have no ID or user.
+type CSVProfile struct {
+	ID                 int            `json:"id"`
+	UserID             int            `json:"userId"`
+	Name               string         `json:"name"`
+	BuiltIn            bool           `json:"builtIn"`
+	Encoding           Encoding       `json:"encoding"`
+	Delimiter          string         `json:"delimiter"`
+	DateColumn         string         `json:"dateColumn"`
+	DateFormat         string         `json:"dateFormat"` // e.g. DD.MM.YYYY, DD.MM.YY or YYYY-MM-DD
+	DescriptionColumns []string       `json:"descriptionColumns"`
+	AmountColumn       string         `json:"amountColumn"`
+	DecimalSeparator   string         `json:"decimalSeparator"`
+	SignConvention     SignConvention `json:"signConvention"`
+	IndicatorColumn    string         `json:"indicatorColumn"`
+	DebitIndicator     string         `json:"debitIndicator"`
+	ReferenceColumn    string         `json:"referenceColumn"` // Optional column with the bank's transaction reference
+}
+
+// ApplyDefaults fills in the conventions of German bank exports for
+// everything a profile leaves out.
+func (p *CSVProfile) ApplyDefaults() {
+	if p.Encoding == "" {
+		p.Encoding = EncodingUTF8
+	}
+	if p.Delimiter == "" {
+		p.Delimiter = ";"
+	}
+	if p.DateFormat == "" {
+		p.DateFormat = "DD.MM.YYYY"
+	}
+	if p.DecimalSeparator == "" {
+		p.DecimalSeparator = ","
+	}
+	if p.SignConvention == "" {
+		p.SignConvention = NegativeIsExpense
+	}
+}
+
+func (p CSVProfile) Validate() error {
+	if strings.TrimSpace(p.Name) == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidCSVProfile)
+	}
+	if p.Encoding != EncodingUTF8 && p.Encoding != EncodingLatin1 && p.Encoding != EncodingWindows1252 {
+		return fmt.Errorf("%w: encoding must be UTF-8, ISO-8859-1 or WINDOWS-1252", ErrInvalidCSVProfile)
+	}
+	if utf8.RuneCountInString(p.Delimiter) != 1 {
+		return fmt.Errorf("%w: delimiter must be a single character", ErrInvalidCSVProfile)
+	}
+	if p.DateColumn == "" || p.AmountColumn == "" || len(p.DescriptionColumns) == 0 {
+		return fmt.Errorf("%w: date, amount and description columns are required", ErrInvalidCSVProfile)
+	}
+	if _, err := p.DateLayout(); err != nil {
+		return err
+	}
+	if p.DecimalSeparator != "," && p.DecimalSeparator != "." {
+		return fmt.Errorf("%w: decimal separator must be a comma or a dot", ErrInvalidCSVProfile)
+	}
+	switch p.SignConvention {
+	case NegativeIsExpense, PositiveIsExpense:
+	case DebitIndicator:
+		if p.IndicatorColumn == "" || p.DebitIndicator == "" {
+			return fmt.Errorf("%w: indicator column and debit indicator are required for %s", ErrInvalidCSVProfile, DebitIndicator)
+		}
+	default:
+		return fmt.Errorf("%w: invalid sign convention", ErrInvalidCSVProfile)
+	}
+	return nil
+}
+
+// DateLayout translates DateFormat into a layout for time.Parse.
+func (p CSVProfile) DateLayout() (string, error) {
+	layout := p.DateFormat
+	for _, token := range []struct{ from, to string }{
+		{"YYYY", "2006"},
+		{"YY", "06"},
+		{"MM", "01"},
+		{"DD", "02"},
+	} {
+		layout = strings.ReplaceAll(layout, token.from, token.to)
+	}
+	if strings.ContainsAny(layout, "DMY") || !strings.Contains(layout, "01") || !strings.Contains(layout, "02") {
+		return "", fmt.Errorf("%w: date format %q must consist of DD, MM and YYYY or YY", ErrInvalidCSVProfile, p.DateFormat)
+	}
+	return layout, nil
+}
@@ -0,0 +1,88 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// Counterparty is someone the user lends money to or borrows it from.
+type Counterparty struct {
+	ID        int       `json:"id"`
+	UserID    int       `json:"userId"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func (c Counterparty) Validate() error {
+	if strings.TrimSpace(c.Name) == "" {
+		return ErrMissingCounterparty
+	}
+	return nil
+}
+
+// Debt is a debt transaction with the repayments booked against it. Money
+// spent as a debt was lent to the counterparty, money received was borrowed
+// from them; a repayment moves it the other way.
+type Debt struct {
+	Transaction Transaction   `json:"transaction"`
+	Repayments  []Transaction `json:"repayments"`
+	RepaidCents int           `json:"repaidCents"`
+	OpenCents   int           `json:"openCents"`
+}
+
+// CounterpartyDebts is the history of the debts with a counterparty.
+// BalanceCents is what the counterparty still owes the user, or, if
+// negative, what the user owes them.
+type CounterpartyDebts struct {
+	Counterparty Counterparty `json:"counterparty"`
+	BalanceCents int          `json:"balanceCents"`
+	Debts        []Debt       `json:"debts"`
+}
+
+// IsRepayment tells whether the transaction pays back a debt.
+func (t Transaction) IsRepayment() bool {
+	return t.RepaymentOfID != nil
+}
+
+// RepaymentType is the type of the transactions paying back the debt.
+func (t Transaction) RepaymentType() TransactionType {
+	if t.Type == Expense {
+		return Income
+	}
+	return Expense
+}
+
+// DebtsWith sorts the counterparty's debt transactions into debts and their
+// repayments. A repayment whose debt is gone is listed on its own with
+// nothing open, so the balance still adds up all the money that changed
+// hands.
+func DebtsWith(counterparty Counterparty, transactions []Transaction) CounterpartyDebts {
+	res := CounterpartyDebts{Counterparty: counterparty, Debts: []Debt{}}
+	index := make(map[int]int)
+	for _, t := range transactions {
+		if !t.IsRepayment() {
+			index[t.ID] = len(res.Debts)
+			res.Debts = append(res.Debts, Debt{Transaction: t, Repayments: []Transaction{}})
+		}
+	}
+	for _, t := range transactions {
+		if !t.IsRepayment() {
+			continue
+		}
+		if i, ok := index[*t.RepaymentOfID]; ok {
+			res.Debts[i].Repayments = append(res.Debts[i].Repayments, t)
+			res.Debts[i].RepaidCents += t.AmountInCents
+		} else {
+			res.Debts = append(res.Debts, Debt{Transaction: t, Repayments: []Transaction{}})
+		}
+	}
+	for i, d := range res.Debts {
+		if !d.Transaction.IsRepayment() {
+			res.Debts[i].OpenCents = d.Transaction.AmountInCents - d.RepaidCents
+		}
+	}
+	for _, t := range transactions {
+		res.BalanceCents -= t.SignedAmount()
+	}
+	return res
+}
@@ -6,13 +6,17 @@ type Depot struct {
 	UserID   int    `json:"userId"`
 	WalletID int    `json:"walletId"`
 	BudgetID int    `json:"budgetId"`
+	// Permission is what the user asking may do with the depot, which may be
+	// someone else's.
+	Permission Permission `json:"permission,omitempty"`
 }
 
 type DepotDTO struct {
-	ID                  int    `json:"id"`
-	Name                string `json:"name"`
-	WalletID            int    `json:"walletId"`
-	BudgetID            int    `json:"budgetId"`
-	InvestedInCents     int    `json:"investedInCents"`
-	CurrentValueInCents int    `json:"currentValueInCents"`
+	ID                  int        `json:"id"`
+	Name                string     `json:"name"`
+	WalletID            int        `json:"walletId"`
+	BudgetID            int        `json:"budgetId"`
+	InvestedInCents     int        `json:"investedInCents"`
+	CurrentValueInCents int        `json:"currentValueInCents"`
+	Permission          Permission `json:"permission,omitempty"`
 }
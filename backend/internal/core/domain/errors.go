@@ -10,6 +10,7 @@ var (
 	ErrMissingWallet               = errors.New("wallet name is required")
 	ErrWalletNotFound              = errors.New("wallet not found or unauthorized")
 	ErrSameWalletTransfer          = errors.New("cannot transfer to the same wallet")
+	ErrSameBudgetTransfer          = errors.New("cannot transfer to the same budget")
 	ErrMissingDepot                = errors.New("depot name is required")
 	ErrDepotNotFound               = errors.New("depot not found or unauthorized")
 	ErrUnauthorized                = errors.New("user not authorized")
@@ -25,4 +26,46 @@ var (
 	ErrInsufficientShares          = errors.New("not enough shares available to sell")
 	ErrTradeDepotChange            = errors.New("a trade cannot be moved to another depot: delete it and create a new one")
 	ErrStockNotFound               = errors.New("stock not found")
+	ErrCSVProfileNotFound          = errors.New("csv profile not found")
+	ErrInvalidCSVProfile           = errors.New("invalid csv profile")
+	ErrDuplicateExternalID         = errors.New("a transaction with this external ID already exists")
+	ErrImportPlanChanged           = errors.New("the import would now do something else than previewed, preview it again")
+	ErrInvalidStatement            = errors.New("invalid bank statement")
+	ErrUnsupportedExportVersion    = errors.New("unsupported export version")
+	ErrInvalidAccountExport        = errors.New("invalid account export")
+	ErrInvalidSplit                = errors.New("invalid split")
+	ErrTagNotFound                 = errors.New("tag not found")
+	ErrTagExists                   = errors.New("tag already exists, merge the tags instead")
+	ErrMissingTag                  = errors.New("tag name is required")
+	ErrInvalidBudgetPeriod         = errors.New("invalid budget period")
+	ErrOverAssigned                = errors.New("cannot assign more than is to be assigned")
+	ErrInvalidAlertThreshold       = errors.New("alert thresholds must be between 1 and 1000 percent")
+	ErrNotificationNotFound        = errors.New("notification not found")
+	ErrInvalidEmail                = errors.New("invalid email address")
+	ErrInvalidBudgetParent         = errors.New("a budget cannot be nested under itself or one of its children")
+	ErrInvalidSavingsGoal          = errors.New("invalid savings goal")
+	ErrSavingsGoalNotFound         = errors.New("savings goal not found")
+	ErrInvalidWalletType           = errors.New("invalid wallet type")
+	ErrInvalidStatementCycle       = errors.New("invalid statement cycle")
+	ErrNothingToSettle             = errors.New("the statement has nothing left to pay")
+	ErrInvalidDateRange            = errors.New("invalid date range")
+	ErrReconciliationNotFound      = errors.New("reconciliation not found")
+	ErrReconciliationOpen          = errors.New("the wallet already has an open reconciliation")
+	ErrReconciliationCompleted     = errors.New("the reconciliation is already completed")
+	ErrReconciliationUnbalanced    = errors.New("the cleared balance does not match the statement")
+	ErrTransactionReconciled       = errors.New("the transaction is reconciled and locked")
+	ErrNotOnStatement              = errors.New("the transaction is not in the wallet up to the statement date")
+	ErrMissingCounterparty         = errors.New("counterparty name is required")
+	ErrCounterpartyNotFound        = errors.New("counterparty not found")
+	ErrCounterpartyExists          = errors.New("counterparty already exists")
+	ErrDebtNotFound                = errors.New("debt not found")
+	ErrOverRepaid                  = errors.New("cannot repay more than is still open")
+	ErrInvalidShare                = errors.New("invalid split of a shared expense")
+	ErrSharedExpenseNotFound       = errors.New("shared expense not found")
+	ErrSettlementNotFound          = errors.New("pending settlement not found")
+	ErrSettlementConfirmed         = errors.New("the transaction settles up with another user who confirmed it")
+	ErrInvalidResourceType         = errors.New("resource type must be WALLET, BUDGET or DEPOT")
+	ErrInvalidPermission           = errors.New("permission must be READ or WRITE")
+	ErrMembershipNotFound          = errors.New("membership not found")
+	ErrAccountNotEmpty             = errors.New("the account already holds data, delete it before importing an export")
 )
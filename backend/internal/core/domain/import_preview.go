@@ -0,0 +1,39 @@
+package domain
+
+// ImportPreview shows what an import would do without writing anything.
+// Confirming the import with its Token applies exactly this preview.
+type ImportPreview struct {
+	Token      string               `json:"token"`
+	Salary     int                  `json:"salary,omitempty"` // New salary; 0 leaves it unchanged
+	NewWallets []string             `json:"newWallets"`
+	NewBudgets []string             `json:"newBudgets"`
+	NewDepots  []string             `json:"newDepots"`
+	Wallets    []ImportWalletCounts `json:"wallets"`
+	Trades     []ImportTradePreview `json:"trades"`
+	Dropped    []ImportDroppedRow   `json:"dropped"`
+	Result     ImportResult         `json:"result"`
+}
+
+// ImportWalletCounts is how many rows of an import land on a wallet.
+type ImportWalletCounts struct {
+	Wallet string `json:"wallet"`
+	ImportResult
+}
+
+// ImportTradePreview is a row the import books as a trade.
+type ImportTradePreview struct {
+	Row         int       `json:"row"` // Position in the import file, starting at 1
+	Wallet      string    `json:"wallet"`
+	Description string    `json:"description"`
+	Type        TradeType `json:"type"`
+	Quantity    float64   `json:"quantity"`
+	WKN         string    `json:"wkn"`
+}
+
+// ImportDroppedRow is a row the import ignores because its wallet is neither
+// an existing wallet nor one the import creates.
+type ImportDroppedRow struct {
+	Row         int    `json:"row"` // Position in the import file, starting at 1
+	Wallet      string `json:"wallet"`
+	Description string `json:"description"`
+}
@@ -0,0 +1,70 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+type ResourceType string
+
+const (
+	ResourceWallet ResourceType = "WALLET"
+	ResourceBudget ResourceType = "BUDGET"
+	ResourceDepot  ResourceType = "DEPOT"
+)
+
+// Permission is what a user may do with a wallet, budget or depot. Every
+// permission includes the ones before it.
+type Permission string
+
+const (
+	PermissionRead  Permission = "READ"  // See it and what is booked on it
+	PermissionWrite Permission = "WRITE" // Also book on it and change it
+	PermissionOwner Permission = "OWNER" // Also delete it and invite members; never granted
+)
+
+func (p Permission) rank() int {
+	switch p {
+	case PermissionRead:
+		return 1
+	case PermissionWrite:
+		return 2
+	case PermissionOwner:
+		return 3
+	}
+	return 0
+}
+
+// Allows tells whether holding the permission is enough for need.
+func (p Permission) Allows(need Permission) bool {
+	return p.rank() > 0 && p.rank() >= need.rank()
+}
+
+// Membership lets a user other than the owner see or change a wallet, budget
+// or depot, like a joint account of a couple.
+type Membership struct {
+	ID           int          `json:"id"`
+	ResourceType ResourceType `json:"resourceType"`
+	ResourceID   int          `json:"resourceId"`
+	OwnerID      int          `json:"ownerId"`
+	OwnerName    string       `json:"ownerName"`
+	UserID       int          `json:"userId"`
+	Username     string       `json:"username"`
+	Permission   Permission   `json:"permission"`
+	CreatedAt    time.Time    `json:"createdAt"`
+}
+
+func (m Membership) Validate() error {
+	switch m.ResourceType {
+	case ResourceWallet, ResourceBudget, ResourceDepot:
+	default:
+		return fmt.Errorf("%w: %q", ErrInvalidResourceType, m.ResourceType)
+	}
+	if m.Permission != PermissionRead && m.Permission != PermissionWrite {
+		return fmt.Errorf("%w: %q", ErrInvalidPermission, m.Permission)
+	}
+	if m.UserID == m.OwnerID {
+		return fmt.Errorf("%w: the owner cannot be a member", ErrInvalidPermission)
+	}
+	return nil
+}
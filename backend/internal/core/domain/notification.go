@@ -0,0 +1,23 @@
+package domain
+
+import "time"
+
+type NotificationType string
+
+const (
+	NotificationBudgetThreshold NotificationType = "BUDGET_THRESHOLD"
+	NotificationPendingOverdue  NotificationType = "PENDING_OVERDUE"
+)
+
+// Notification is a message in the user's inbox, which notifiers may also
+// deliver elsewhere.
+type Notification struct {
+	ID        int              `json:"id"`
+	UserID    int              `json:"userId"`
+	Type      NotificationType `json:"type"`
+	Subject   string           `json:"subject"`
+	Message   string           `json:"message"`
+	BudgetID  *int             `json:"budgetId,omitempty"`
+	CreatedAt time.Time        `json:"createdAt"`
+	Read      bool             `json:"read"`
+}
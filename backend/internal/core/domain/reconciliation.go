@@ -0,0 +1,59 @@
+package domain
+
+import "time"
+
+type ReconciliationStatus string
+
+const (
+	ReconciliationOpen      ReconciliationStatus = "OPEN"
+	ReconciliationCompleted ReconciliationStatus = "COMPLETED"
+)
+
+// Reconciliation compares a wallet with a bank statement. The user ticks the
+// transactions the statement shows as cleared until the cleared balance
+// matches the statement's ending balance, then completes it, which locks
+// them.
+type Reconciliation struct {
+	ID                    int                  `json:"id"`
+	UserID                int                  `json:"userId"`
+	WalletID              int                  `json:"walletId"`
+	StatementDate         time.Time            `json:"statementDate"`
+	StatementBalanceCents int                  `json:"statementBalanceCents"`
+	Status                ReconciliationStatus `json:"status"`
+	CreatedAt             time.Time            `json:"createdAt"`
+	CompletedAt           *time.Time           `json:"completedAt,omitempty"`
+	// ClearedBalanceCents adds up the cleared transactions up to the
+	// statement date, and DifferenceCents is what the statement shows more.
+	ClearedBalanceCents int `json:"clearedBalanceCents"`
+	DifferenceCents     int `json:"differenceCents"`
+	// Transactions are the ones up to the statement date not reconciled
+	// before, to be ticked. They are only filled in for an open one.
+	Transactions []Transaction `json:"transactions,omitempty"`
+}
+
+// Balance works out the cleared balance and the difference to the statement
+// from the wallet's transactions, and for an open reconciliation lists the
+// ones still to be ticked.
+func (r *Reconciliation) Balance(transactions []Transaction) {
+	statementDate := DateOf(r.StatementDate)
+	r.ClearedBalanceCents = 0
+	r.Transactions = nil
+	for _, t := range transactions {
+		if DateOf(t.Date).After(statementDate) {
+			continue
+		}
+		if t.Cleared {
+			r.ClearedBalanceCents += t.SignedAmount()
+		}
+		if r.Status == ReconciliationOpen && t.ReconciliationID == nil {
+			r.Transactions = append(r.Transactions, t)
+		}
+	}
+	r.DifferenceCents = r.StatementBalanceCents - r.ClearedBalanceCents
+}
+
+// CanClear tells whether the transaction may be ticked or unticked in the
+// reconciliation.
+func (r Reconciliation) CanClear(t Transaction) bool {
+	return t.WalletID == r.WalletID && t.ReconciliationID == nil && !DateOf(t.Date).After(DateOf(r.StatementDate))
+}
@@ -0,0 +1,75 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+type Frequency string
+
+const (
+	Weekly  Frequency = "WEEKLY"
+	Monthly Frequency = "MONTHLY"
+	Yearly  Frequency = "YEARLY"
+)
+
+// UpcomingHorizonYears is how many years ahead occurrences are projected at
+// most. A weekly template would otherwise project without bound.
+const UpcomingHorizonYears = 2
+
+// RecurrenceRule describes when a template repeats. Weekly rules repeat on the
+// weekday of StartDate, monthly rules on the template's Day and yearly rules on
+// the template's Day in the month of StartDate.
+type RecurrenceRule struct {
+	Frequency Frequency  `json:"frequency"`
+	Interval  int        `json:"interval"` // Every Interval weeks, months or years
+	StartDate time.Time  `json:"startDate"`
+	EndDate   *time.Time `json:"endDate"` // Last day an occurrence may fall on
+	Count     *int       `json:"count"`   // Number of occurrences after which the rule ends
+}
+
+func (r RecurrenceRule) Validate() error {
+	if r.Frequency != Weekly && r.Frequency != Monthly && r.Frequency != Yearly {
+		return fmt.Errorf("frequency must be WEEKLY, MONTHLY or YEARLY")
+	}
+	if r.Interval < 1 {
+		return fmt.Errorf("interval must be at least 1")
+	}
+	if r.StartDate.IsZero() {
+		return fmt.Errorf("recurrence must have a start date")
+	}
+	if r.EndDate != nil && DateOf(*r.EndDate).Before(DateOf(r.StartDate)) {
+		return fmt.Errorf("end date must not be before the start date")
+	}
+	if r.Count != nil && *r.Count < 1 {
+		return fmt.Errorf("count must be at least 1")
+	}
+	return nil
+}
+
+// UpcomingTransaction is a projected, not yet booked occurrence of a template.
+type UpcomingTransaction struct {
+	TemplateID    int             `json:"templateId"`
+	Date          time.Time       `json:"date"`
+	BudgetID      *int            `json:"budgetId"`
+	WalletID      int             `json:"walletId"`
+	Description   string          `json:"description"`
+	AmountInCents int             `json:"amountInCents"`
+	Type          TransactionType `json:"type"`
+}
+
+// dayInMonth returns the given day of a month. Days the month does not have
+// fall on its last day, so the 31st is the 30th in April and the 28th (or 29th)
+// in February.
+func dayInMonth(year int, month time.Month, day int) time.Time {
+	first := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	if last := first.AddDate(0, 1, -1).Day(); day > last {
+		day = last
+	}
+	return time.Date(first.Year(), first.Month(), day, 0, 0, 0, 0, time.UTC)
+}
+
+// DateOf strips the time of day, leaving the calendar date in UTC.
+func DateOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,164 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SavingsGoal is an amount to be saved by a date, either on a wallet or on a
+// budget. What counts as saved is the wallet's or budget's balance.
+type SavingsGoal struct {
+	ID          int       `json:"id"`
+	UserID      int       `json:"userId"`
+	Name        string    `json:"name"`
+	WalletID    *int      `json:"walletId,omitempty"`
+	BudgetID    *int      `json:"budgetId,omitempty"`
+	TargetCents int       `json:"targetCents"`
+	TargetDate  time.Time `json:"targetDate"`
+	// MonthlyContributionCents is what the user plans to put aside each
+	// month. With FromWalletID set on a wallet goal it is transferred
+	// automatically on the first of every month until the target date.
+	MonthlyContributionCents int  `json:"monthlyContributionCents"`
+	FromWalletID             *int `json:"fromWalletId,omitempty"`
+	// ContributionTemplateID is the template booking the automatic
+	// contribution.
+	ContributionTemplateID *int                 `json:"contributionTemplateId,omitempty"`
+	CreatedAt              time.Time            `json:"createdAt"`
+	Progress               *SavingsGoalProgress `json:"progress,omitempty"`
+}
+
+type SavingsGoalProgress struct {
+	SavedCents     int `json:"savedCents"`
+	RemainingCents int `json:"remainingCents"`
+	Percent        int `json:"percent"`
+	// RequiredMonthlyCents is what has to be saved each month from now on
+	// to reach the target in time.
+	RequiredMonthlyCents int `json:"requiredMonthlyCents"`
+	// MonthlySavingCents is what was actually saved per month lately.
+	MonthlySavingCents int `json:"monthlySavingCents"`
+	// ProjectedCompletion is when the target is reached at the current
+	// saving rate, or when it was reached. It is empty if nothing is saved.
+	ProjectedCompletion *time.Time `json:"projectedCompletion,omitempty"`
+	OnTrack             bool       `json:"onTrack"`
+}
+
+// savingRateWindow is how far back the saving rate looks.
+const savingRateWindow = 3
+
+func (g SavingsGoal) Validate() error {
+	if strings.TrimSpace(g.Name) == "" {
+		return fmt.Errorf("%w: the goal needs a name", ErrInvalidSavingsGoal)
+	}
+	if (g.WalletID == nil) == (g.BudgetID == nil) {
+		return fmt.Errorf("%w: the goal needs either a wallet or a budget", ErrInvalidSavingsGoal)
+	}
+	if g.TargetCents <= 0 || g.MonthlyContributionCents < 0 {
+		return ErrInvalidAmount
+	}
+	if g.TargetDate.IsZero() {
+		return fmt.Errorf("%w: the goal needs a target date", ErrInvalidSavingsGoal)
+	}
+	if g.FromWalletID != nil {
+		if g.WalletID == nil {
+			return fmt.Errorf("%w: only a goal on a wallet can be contributed to automatically", ErrInvalidSavingsGoal)
+		}
+		if *g.FromWalletID == *g.WalletID {
+			return ErrSameWalletTransfer
+		}
+		if g.MonthlyContributionCents == 0 {
+			return fmt.Errorf("%w: an automatic contribution needs a monthly amount", ErrInvalidSavingsGoal)
+		}
+	}
+	return nil
+}
+
+// ContributionTemplate is the monthly transfer booking the goal's automatic
+// contribution.
+func (g SavingsGoal) ContributionTemplate() TransactionTemplate {
+	end := g.TargetDate
+	return TransactionTemplate{
+		UserID:        g.UserID,
+		Day:           1,
+		WalletID:      *g.FromWalletID,
+		ToWalletID:    g.WalletID,
+		Description:   g.Name,
+		AmountInCents: g.MonthlyContributionCents,
+		Type:          Expense,
+		Recurrence: RecurrenceRule{
+			Frequency: Monthly,
+			Interval:  1,
+			EndDate:   &end,
+		},
+	}
+}
+
+// SavingsProgress measures the goal against the bookings on its wallet or
+// budget. The saving rate averages the last months, or the time since the
+// first booking if that is shorter, but never less than a month.
+func SavingsProgress(g SavingsGoal, bookings []BudgetBooking, now time.Time) SavingsGoalProgress {
+	sort.SliceStable(bookings, func(i, j int) bool {
+		return bookings[i].Date.Before(bookings[j].Date)
+	})
+
+	var p SavingsGoalProgress
+	var reached *time.Time
+	windowStart := now.AddDate(0, -savingRateWindow, 0)
+	if len(bookings) > 0 && bookings[0].Date.After(windowStart) {
+		windowStart = bookings[0].Date
+	}
+	recent := 0
+	for _, b := range bookings {
+		if b.Date.After(now) {
+			break
+		}
+		p.SavedCents += b.AmountInCents
+		if !b.Date.Before(windowStart) {
+			recent += b.AmountInCents
+		}
+		if reached == nil && p.SavedCents >= g.TargetCents {
+			date := b.Date
+			reached = &date
+		} else if p.SavedCents < g.TargetCents {
+			reached = nil
+		}
+	}
+
+	days := int(now.Sub(windowStart).Hours() / 24)
+	if days < 30 {
+		days = 30
+	}
+	p.MonthlySavingCents = recent * 30 / days
+
+	p.RemainingCents = max(g.TargetCents-p.SavedCents, 0)
+	p.Percent = min(max(p.SavedCents, 0)*100/g.TargetCents, 100)
+
+	if p.RemainingCents == 0 {
+		p.ProjectedCompletion = reached
+		p.OnTrack = true
+		return p
+	}
+
+	p.RequiredMonthlyCents = ceilDiv(p.RemainingCents, monthsUntil(now, g.TargetDate))
+	if p.MonthlySavingCents > 0 {
+		projected := DateOf(now).AddDate(0, ceilDiv(p.RemainingCents, p.MonthlySavingCents), 0)
+		p.ProjectedCompletion = &projected
+		p.OnTrack = !projected.After(g.TargetDate)
+	}
+	return p
+}
+
+// monthsUntil counts the months left until the date, a month begun counting
+// as a whole one, and at least one.
+func monthsUntil(now, date time.Time) int {
+	months := (date.Year()-now.Year())*12 + int(date.Month()) - int(now.Month())
+	if now.AddDate(0, months, 0).Before(date) {
+		months++
+	}
+	return max(months, 1)
+}
+
+func ceilDiv(a, b int) int {
+	return (a + b - 1) / b
+}
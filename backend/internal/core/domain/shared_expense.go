@@ -0,0 +1,234 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+type SplitMethod string
+
+const (
+	SplitEqual      SplitMethod = "EQUAL"
+	SplitPercentage SplitMethod = "PERCENTAGE"
+	SplitExact      SplitMethod = "EXACT"
+)
+
+// SharedExpense is an expense one user paid for others using the same
+// instance. It is visible to everyone sharing it, but only the payer may
+// change it. Date, Description and AmountInCents come from the payer's
+// transaction.
+type SharedExpense struct {
+	ID            int            `json:"id"`
+	TransactionID int            `json:"transactionId"`
+	PayerID       int            `json:"payerId"`
+	PayerName     string         `json:"payerName"`
+	Method        SplitMethod    `json:"method"`
+	Date          time.Time      `json:"date"`
+	Description   string         `json:"description"`
+	AmountInCents int            `json:"amountInCents"`
+	Shares        []ExpenseShare `json:"shares"`
+	CreatedAt     time.Time      `json:"createdAt"`
+}
+
+// ExpenseShare is one user's part of a shared expense. A share names its
+// user by ID or by username. Percent is only read for percentage splits and
+// AmountInCents is given for exact ones; Allocate works it out for the
+// others.
+type ExpenseShare struct {
+	UserID        int     `json:"userId"`
+	Username      string  `json:"username,omitempty"`
+	Percent       float64 `json:"percent,omitempty"`
+	AmountInCents int     `json:"amountInCents"`
+}
+
+// Settlement is money one user paid another to settle up. The user settling
+// up books their side; the settlement stays pending, and leaves the balance
+// alone, until the other user confirms it by booking theirs.
+type Settlement struct {
+	ID                int        `json:"id"`
+	FromUserID        int        `json:"fromUserId"`
+	ToUserID          int        `json:"toUserId"`
+	AmountInCents     int        `json:"amountInCents"`
+	Date              time.Time  `json:"date"`
+	FromTransactionID *int       `json:"fromTransactionId,omitempty"`
+	ToTransactionID   *int       `json:"toTransactionId,omitempty"`
+	ConfirmedAt       *time.Time `json:"confirmedAt,omitempty"`
+	CreatedAt         time.Time  `json:"createdAt"`
+}
+
+// SharedBalance is what another user owes the user over their shared
+// expenses and settlements, or, if negative, what the user owes them.
+type SharedBalance struct {
+	UserID       int                 `json:"userId"`
+	Username     string              `json:"username"`
+	BalanceCents int                 `json:"balanceCents"`
+	Entries      []SharedLedgerEntry `json:"entries"`
+}
+
+// SharedLedgerEntry is how much an expense or settlement moved the balance
+// with the other user; positive if they owe more. A pending settlement is
+// listed with what it will move the balance by once confirmed.
+type SharedLedgerEntry struct {
+	Date            time.Time `json:"date"`
+	Description     string    `json:"description"`
+	AmountInCents   int       `json:"amountInCents"`
+	Pending         bool      `json:"pending,omitempty"`
+	SharedExpenseID *int      `json:"sharedExpenseId,omitempty"`
+	SettlementID    *int      `json:"settlementId,omitempty"`
+}
+
+// Involves tells whether the user paid or shares the expense.
+func (e SharedExpense) Involves(userID int) bool {
+	if e.PayerID == userID {
+		return true
+	}
+	for _, s := range e.Shares {
+		if s.UserID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// Pending tells whether the other user has yet to confirm the settlement.
+func (s Settlement) Pending() bool {
+	return s.ConfirmedAt == nil
+}
+
+// BookedBy tells whether the transaction books one side of the settlement.
+func (s Settlement) BookedBy(transactionID int) bool {
+	return (s.FromTransactionID != nil && *s.FromTransactionID == transactionID) ||
+		(s.ToTransactionID != nil && *s.ToTransactionID == transactionID)
+}
+
+// Allocate works out every user's part of the amount. The payer always
+// takes part and bears what the others' shares leave, including the cents
+// left over by rounding, and comes last. Listing the payer is optional, but
+// then their share has to make the split add up.
+func (e *SharedExpense) Allocate(amount int) error {
+	var others []ExpenseShare
+	var payer *ExpenseShare
+	seen := map[int]bool{}
+	for _, s := range e.Shares {
+		if seen[s.UserID] {
+			return fmt.Errorf("%w: user %d is listed twice", ErrInvalidShare, s.UserID)
+		}
+		seen[s.UserID] = true
+		if s.UserID == e.PayerID {
+			payer = &s
+		} else {
+			others = append(others, s)
+		}
+	}
+	if len(others) == 0 {
+		return fmt.Errorf("%w: share it with at least one other user", ErrInvalidShare)
+	}
+
+	rest := amount
+	switch e.Method {
+	case SplitEqual:
+		part := amount / (len(others) + 1)
+		for i := range others {
+			others[i].AmountInCents = part
+		}
+	case SplitPercentage:
+		total := 0.0
+		for i, s := range others {
+			if s.Percent <= 0 {
+				return fmt.Errorf("%w: every share needs a percentage", ErrInvalidShare)
+			}
+			total += s.Percent
+			others[i].AmountInCents = int(math.Round(float64(amount) * s.Percent / 100))
+		}
+		if payer != nil {
+			total += payer.Percent
+		}
+		if total > 100+1e-9 || (payer != nil && math.Abs(total-100) > 1e-9) {
+			return fmt.Errorf("%w: the percentages add up to %g", ErrInvalidShare, total)
+		}
+	case SplitExact:
+		total := 0
+		for _, s := range others {
+			if s.AmountInCents <= 0 {
+				return fmt.Errorf("%w: every share needs an amount", ErrInvalidShare)
+			}
+			total += s.AmountInCents
+		}
+		if payer != nil {
+			total += payer.AmountInCents
+		}
+		if total > amount || (payer != nil && total != amount) {
+			return fmt.Errorf("%w: the shares add up to %d of %d cents", ErrInvalidShare, total, amount)
+		}
+	default:
+		return fmt.Errorf("%w: method must be EQUAL, PERCENTAGE or EXACT", ErrInvalidShare)
+	}
+
+	sort.Slice(others, func(i, j int) bool {
+		return others[i].UserID < others[j].UserID
+	})
+	e.Shares = nil
+	for _, s := range others {
+		rest -= s.AmountInCents
+		e.Shares = append(e.Shares, ExpenseShare{UserID: s.UserID, Percent: s.Percent, AmountInCents: s.AmountInCents})
+	}
+	payerShare := ExpenseShare{UserID: e.PayerID, AmountInCents: rest}
+	if payer != nil {
+		payerShare.Percent = payer.Percent
+	}
+	e.Shares = append(e.Shares, payerShare)
+	return nil
+}
+
+// SharedLedger works out the balance with every user the user shares
+// expenses with, oldest entries first. Pending settlements are listed but
+// not counted.
+func SharedLedger(userID int, expenses []SharedExpense, settlements []Settlement) []SharedBalance {
+	balances := map[int]*SharedBalance{}
+	add := func(other int, entry SharedLedgerEntry) {
+		b, ok := balances[other]
+		if !ok {
+			b = &SharedBalance{UserID: other, Entries: []SharedLedgerEntry{}}
+			balances[other] = b
+		}
+		if !entry.Pending {
+			b.BalanceCents += entry.AmountInCents
+		}
+		b.Entries = append(b.Entries, entry)
+	}
+
+	for _, e := range expenses {
+		id := e.ID
+		for _, s := range e.Shares {
+			switch {
+			case e.PayerID == userID && s.UserID != userID:
+				add(s.UserID, SharedLedgerEntry{Date: e.Date, Description: e.Description, AmountInCents: s.AmountInCents, SharedExpenseID: &id})
+			case e.PayerID != userID && s.UserID == userID:
+				add(e.PayerID, SharedLedgerEntry{Date: e.Date, Description: e.Description, AmountInCents: -s.AmountInCents, SharedExpenseID: &id})
+			}
+		}
+	}
+	for _, s := range settlements {
+		id := s.ID
+		switch userID {
+		case s.FromUserID:
+			add(s.ToUserID, SharedLedgerEntry{Date: s.Date, Description: "Settlement", AmountInCents: s.AmountInCents, Pending: s.Pending(), SettlementID: &id})
+		case s.ToUserID:
+			add(s.FromUserID, SharedLedgerEntry{Date: s.Date, Description: "Settlement", AmountInCents: -s.AmountInCents, Pending: s.Pending(), SettlementID: &id})
+		}
+	}
+
+	res := make([]SharedBalance, 0, len(balances))
+	for _, b := range balances {
+		sort.SliceStable(b.Entries, func(i, j int) bool {
+			return b.Entries[i].Date.Before(b.Entries[j].Date)
+		})
+		res = append(res, *b)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].UserID < res[j].UserID
+	})
+	return res
+}
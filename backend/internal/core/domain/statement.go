@@ -0,0 +1,25 @@
+package domain
+
+// Statement is a parsed bank statement. The balances are nil for formats that
+// do not carry them, like most CSV exports.
+type Statement struct {
+	Transactions        []ImportTransaction
+	OpeningBalanceCents *int
+	ClosingBalanceCents *int
+}
+
+// BalanceCheck compares the balances a statement reports with the balance of
+// the wallet it was imported into, before and after the import.
+type BalanceCheck struct {
+	OpeningBalanceCents      int    `json:"openingBalanceCents"`
+	ClosingBalanceCents      int    `json:"closingBalanceCents"`
+	WalletBalanceBeforeCents int    `json:"walletBalanceBeforeCents"`
+	WalletBalanceAfterCents  int    `json:"walletBalanceAfterCents"`
+	Matches                  bool   `json:"matches"`
+	Mismatch                 string `json:"mismatch,omitempty"`
+}
+
+type StatementImportResult struct {
+	ImportResult
+	BalanceCheck *BalanceCheck `json:"balanceCheck,omitempty"`
+}
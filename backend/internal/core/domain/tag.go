@@ -0,0 +1,35 @@
+package domain
+
+import "slices"
+
+// TagUsage is a tag together with how often it is used.
+type TagUsage struct {
+	Name             string `json:"name"`
+	TransactionCount int    `json:"transactionCount"`
+	TemplateCount    int    `json:"templateCount"`
+}
+
+// TagChange tells how many transactions and templates a rename or merge
+// rewrote.
+type TagChange struct {
+	Transactions int `json:"transactions"`
+	Templates    int `json:"templates"`
+}
+
+// ReplaceTags swaps every tag in from for to, keeping the order and dropping
+// the duplicates a merge leaves behind. It reports whether anything changed.
+func ReplaceTags(tags []string, from []string, to string) ([]string, bool) {
+	if !slices.ContainsFunc(tags, func(tag string) bool { return slices.Contains(from, tag) }) {
+		return tags, false
+	}
+	replaced := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		if slices.Contains(from, tag) {
+			tag = to
+		}
+		if !slices.Contains(replaced, tag) {
+			replaced = append(replaced, tag)
+		}
+	}
+	return replaced, true
+}
@@ -23,9 +23,20 @@ type Trade struct {
 	Timestamp           time.Time `json:"timestamp"`
 }
 
-// CashFlowInCents is the amount that moves on the depot's wallet for this trade.
-// Fees and taxes are stored but deliberately not part of the cash flow yet;
-// this is the single place to add them once that feature is wanted.
+// NetInCents is the trade's total net of fees: what a buy cost and what a
+// sell brought in. Gains are reckoned from it, before taxes.
+func (t Trade) NetInCents() int {
+	if t.Type == TradeTypeSell {
+		return t.TotalInCents - t.FeesInCents
+	}
+	return t.TotalInCents + t.FeesInCents
+}
+
+// CashFlowInCents is the amount that moves on the depot's wallet for this
+// trade, fees and taxes included: paid out for a buy, paid in for a sell.
 func (t Trade) CashFlowInCents() int {
-	return t.TotalInCents
+	if t.Type == TradeTypeSell {
+		return t.NetInCents() - t.TaxesInCents
+	}
+	return t.NetInCents() + t.TaxesInCents
 }
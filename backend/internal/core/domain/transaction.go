@@ -1,6 +1,10 @@
 package domain
 
-import "time"
+import (
+	"fmt"
+	"slices"
+	"time"
+)
 
 type TransactionType string
 
@@ -21,4 +25,134 @@ type Transaction struct {
 	IsPending     *bool           `json:"isPending,omitempty"`
 	IsDebt        *bool           `json:"isDebt,omitempty"`
 	Tags          []string        `json:"tags,omitempty"`
+	ExternalID    string          `json:"externalId,omitempty"` // Identifies an imported transaction across imports, unique per user
+	Splits        []Split         `json:"splits,omitempty"`     // Lines booked on their own budgets; the transaction then has no BudgetID
+	// Cleared transactions showed up on a bank statement. Once a
+	// reconciliation is completed they belong to it and are locked.
+	Cleared          bool `json:"cleared"`
+	ReconciliationID *int `json:"reconciliationId,omitempty"`
+	// PendingOverdue flags a transaction that stayed pending for too long.
+	// Settling it clears the flag.
+	PendingOverdue bool `json:"pendingOverdue"`
+	// A debt may name the counterparty who owes or is owed the money. A
+	// repayment is a debt paying back RepaymentOfID, in part or in full, and
+	// has the debt's counterparty.
+	CounterpartyID *int `json:"counterpartyId,omitempty"`
+	RepaymentOfID  *int `json:"repaymentOfId,omitempty"`
+	// A transaction belongs to the owner of its wallet. CreatedBy is the user
+	// who booked it, who may be a member of a shared wallet; 0 if not known.
+	CreatedBy int `json:"createdBy,omitempty"`
+}
+
+// Split is a part of a transaction booked on its own budget, like the
+// household items on a supermarket receipt.
+type Split struct {
+	BudgetID      int      `json:"budgetId"`
+	AmountInCents int      `json:"amountInCents"`
+	Tags          []string `json:"tags,omitempty"`
+	Note          string   `json:"note,omitempty"`
+}
+
+// ValidateSplits checks that the split lines, if there are any, add up to the
+// amount of the transaction.
+func (t Transaction) ValidateSplits() error {
+	if len(t.Splits) == 0 {
+		return nil
+	}
+	if t.BudgetID != nil {
+		return fmt.Errorf("%w: a split transaction has no budget of its own", ErrInvalidSplit)
+	}
+	sum := 0
+	for _, s := range t.Splits {
+		if s.BudgetID == 0 {
+			return fmt.Errorf("%w: every line needs a budget", ErrInvalidSplit)
+		}
+		if s.AmountInCents <= 0 {
+			return fmt.Errorf("%w: %w", ErrInvalidSplit, ErrInvalidAmount)
+		}
+		sum += s.AmountInCents
+	}
+	if sum != t.AmountInCents {
+		return fmt.Errorf("%w: the lines add up to %d, not %d", ErrInvalidSplit, sum, t.AmountInCents)
+	}
+	return nil
+}
+
+// SignedAmount is what the transaction adds to its wallet's balance.
+func (t Transaction) SignedAmount() int {
+	if t.Type == Expense {
+		return -t.AmountInCents
+	}
+	return t.AmountInCents
+}
+
+// BudgetAmounts is what the transaction adds to each budget's balance:
+// income counts positive, expenses negative.
+func (t Transaction) BudgetAmounts() map[int]int {
+	sign := 1
+	if t.Type == Expense {
+		sign = -1
+	}
+	amounts := make(map[int]int)
+	if len(t.Splits) > 0 {
+		for _, s := range t.Splits {
+			amounts[s.BudgetID] += sign * s.AmountInCents
+		}
+	} else if t.BudgetID != nil {
+		amounts[*t.BudgetID] = sign * t.AmountInCents
+	}
+	return amounts
+}
+
+// AmountOnBudget is the part of the amount booked on the budget.
+func (t Transaction) AmountOnBudget(budgetID int) int {
+	if len(t.Splits) == 0 {
+		if t.BudgetID != nil && *t.BudgetID == budgetID {
+			return t.AmountInCents
+		}
+		return 0
+	}
+	amount := 0
+	for _, s := range t.Splits {
+		if s.BudgetID == budgetID {
+			amount += s.AmountInCents
+		}
+	}
+	return amount
+}
+
+// OnBudget reports whether any part of the transaction is booked on the budget.
+func (t Transaction) OnBudget(budgetID int) bool {
+	if t.BudgetID != nil && *t.BudgetID == budgetID {
+		return true
+	}
+	for _, s := range t.Splits {
+		if s.BudgetID == budgetID {
+			return true
+		}
+	}
+	return false
+}
+
+// HasTags reports whether the transaction or one of its split lines carries
+// any of the tags, or with all set every one of them.
+func (t Transaction) HasTags(tags []string, all bool) bool {
+	for _, tag := range tags {
+		if t.hasTag(tag) != all {
+			return !all
+		}
+	}
+	return all
+}
+
+func (t Transaction) hasTag(tag string) bool {
+	if slices.Contains(t.Tags, tag) {
+		return true
+	}
+	for _, s := range t.Splits {
+		if slices.Contains(s.Tags, tag) {
+			return true
+		}
+	}
+	return false
 }
@@ -12,6 +12,22 @@ type TransactionDTO struct {
 	WalletName    string          `json:"walletName"`
 	IsPending     bool            `json:"isPending"`
 	IsDebt        bool            `json:"isDebt"`
+	Tags          []string        `json:"tags,omitempty"`
+	Splits        []SplitDTO      `json:"splits,omitempty"`
+	Cleared       bool            `json:"cleared"`
+	Reconciled    bool            `json:"reconciled"`
+	// PendingOverdue flags a pending transaction that did not settle in time.
+	PendingOverdue bool `json:"pendingOverdue"`
+	// CreatedBy names the user who booked the transaction.
+	CreatedBy string `json:"createdBy,omitempty"`
+}
+
+type SplitDTO struct {
+	BudgetID      int      `json:"budgetId"`
+	BudgetName    string   `json:"budgetName"`
+	AmountInCents int      `json:"amountInCents"`
+	Tags          []string `json:"tags,omitempty"`
+	Note          string   `json:"note,omitempty"`
 }
 
 type TransactionSearchCriteria struct {
@@ -22,6 +38,8 @@ type TransactionSearchCriteria struct {
 	WalletID   *int
 	Type       *TransactionType
 	IsDebt     *bool
+	Tags       []string // Matches transactions carrying any of the tags
+	AllTags    bool     // Matches only transactions carrying all of the Tags
 	Page       int
 	PageSize   int
 }
@@ -63,6 +81,14 @@ type ImportSettings struct {
 	Wallets []ImportWallet `json:"wallets"`
 }
 
+// ImportResult counts what an import did with its rows. Rows of unknown
+// wallets show up in none of the counts.
+type ImportResult struct {
+	Created int `json:"created"`
+	Skipped int `json:"skipped"`
+	Updated int `json:"updated"`
+}
+
 type FullImportData struct {
 	Settings     ImportSettings      `json:"settings"`
 	Transactions []ImportTransaction `json:"transactions"`
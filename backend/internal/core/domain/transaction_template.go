@@ -1,26 +1,36 @@
 package domain
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
 
 type TransactionTemplate struct {
-	ID            int             `json:"id"`
-	UserID        int             `json:"userId"`
-	Day           int             `json:"day"` // Day of the month (1-31)
-	BudgetID      *int            `json:"budgetId"`
-	WalletID      int             `json:"walletId"`
-	Description   string          `json:"description"`
-	AmountInCents int             `json:"amountInCents"`
-	Type          TransactionType `json:"type"`
-	Tags          []string        `json:"tags,omitempty"`
+	ID             int             `json:"id"`
+	UserID         int             `json:"userId"`
+	Day            int             `json:"day"` // Day of the month (1-31) for monthly and yearly templates
+	BudgetID       *int            `json:"budgetId"`
+	WalletID       int             `json:"walletId"`
+	ToWalletID     *int            `json:"toWalletId,omitempty"` // Makes every occurrence a transfer from WalletID to this wallet
+	Description    string          `json:"description"`
+	AmountInCents  int             `json:"amountInCents"`
+	Type           TransactionType `json:"type"`
+	Tags           []string        `json:"tags,omitempty"`
+	Recurrence     RecurrenceRule  `json:"recurrence"`
+	CreatedAt      time.Time       `json:"createdAt"`
+	LastOccurrence *time.Time      `json:"lastOccurrence"` // Date of the last occurrence booked as a transaction
 }
 
 func (tt *TransactionTemplate) Validate() error {
 	if tt.UserID == 0 {
 		return fmt.Errorf("transaction template must have a user ID")
 	}
-	if tt.Day < 1 || tt.Day > 31 {
+	if tt.Recurrence.Frequency != Weekly && (tt.Day < 1 || tt.Day > 31) {
 		return fmt.Errorf("day must be between 1 and 31")
 	}
+	if err := tt.Recurrence.Validate(); err != nil {
+		return err
+	}
 	if tt.WalletID == 0 {
 		return fmt.Errorf("transaction template must have a wallet ID")
 	}
@@ -33,5 +43,115 @@ func (tt *TransactionTemplate) Validate() error {
 	if tt.Type != Income && tt.Type != Expense {
 		return fmt.Errorf("invalid transaction type")
 	}
+	if tt.ToWalletID != nil {
+		if *tt.ToWalletID == tt.WalletID {
+			return ErrSameWalletTransfer
+		}
+		if tt.BudgetID != nil {
+			return fmt.Errorf("a transfer template cannot book on a budget")
+		}
+	}
 	return nil
 }
+
+// ApplyRecurrenceDefaults fills in what clients may leave out: templates repeat
+// every month, starting today.
+func (tt *TransactionTemplate) ApplyRecurrenceDefaults(today time.Time) {
+	if tt.Recurrence.Frequency == "" {
+		tt.Recurrence.Frequency = Monthly
+	}
+	if tt.Recurrence.Interval == 0 {
+		tt.Recurrence.Interval = 1
+	}
+	if tt.Recurrence.StartDate.IsZero() {
+		tt.Recurrence.StartDate = DateOf(today)
+	}
+}
+
+// Occurrences lists the dates the template is due on between from and until,
+// both inclusive, oldest first.
+func (tt TransactionTemplate) Occurrences(from, until time.Time) []time.Time {
+	rule := tt.rule()
+	start := DateOf(rule.StartDate)
+	from, until = DateOf(from), DateOf(until)
+	if rule.EndDate != nil && DateOf(*rule.EndDate).Before(until) {
+		until = DateOf(*rule.EndDate)
+	}
+
+	var occurrences []time.Time
+	counted := 0
+	for n := 0; ; n++ {
+		occurrence := tt.nthOccurrence(rule, start, n)
+		if occurrence.After(until) {
+			break
+		}
+		if occurrence.Before(start) {
+			continue
+		}
+		counted++
+		if rule.Count != nil && counted > *rule.Count {
+			break
+		}
+		if !occurrence.Before(from) {
+			occurrences = append(occurrences, occurrence)
+		}
+	}
+	return occurrences
+}
+
+// DueOccurrences lists every occurrence after the last booked one up to and
+// including the day of now, oldest first.
+func (tt TransactionTemplate) DueOccurrences(now time.Time) []time.Time {
+	return tt.Occurrences(tt.nextUnbooked(), now)
+}
+
+// UpcomingUntil projects the occurrences that are not booked yet up to until.
+func (tt TransactionTemplate) UpcomingUntil(until time.Time) []UpcomingTransaction {
+	var upcoming []UpcomingTransaction
+	for _, occurrence := range tt.Occurrences(tt.nextUnbooked(), until) {
+		upcoming = append(upcoming, UpcomingTransaction{
+			TemplateID:    tt.ID,
+			Date:          occurrence,
+			BudgetID:      tt.BudgetID,
+			WalletID:      tt.WalletID,
+			Description:   tt.Description,
+			AmountInCents: tt.AmountInCents,
+			Type:          tt.Type,
+		})
+	}
+	return upcoming
+}
+
+func (tt TransactionTemplate) nextUnbooked() time.Time {
+	if tt.LastOccurrence != nil {
+		return DateOf(*tt.LastOccurrence).AddDate(0, 0, 1)
+	}
+	return tt.rule().StartDate
+}
+
+// rule returns the recurrence with defaults for templates stored before
+// recurrence rules existed: monthly, starting the day they were created.
+func (tt TransactionTemplate) rule() RecurrenceRule {
+	rule := tt.Recurrence
+	if rule.Frequency == "" {
+		rule.Frequency = Monthly
+	}
+	if rule.Interval < 1 {
+		rule.Interval = 1
+	}
+	if rule.StartDate.IsZero() {
+		rule.StartDate = DateOf(tt.CreatedAt)
+	}
+	return rule
+}
+
+func (tt TransactionTemplate) nthOccurrence(rule RecurrenceRule, start time.Time, n int) time.Time {
+	switch rule.Frequency {
+	case Weekly:
+		return start.AddDate(0, 0, 7*rule.Interval*n)
+	case Yearly:
+		return dayInMonth(start.Year()+rule.Interval*n, start.Month(), tt.Day)
+	default:
+		return dayInMonth(start.Year(), start.Month()+time.Month(rule.Interval*n), tt.Day)
+	}
+}
@@ -5,4 +5,6 @@ type User struct {
 	Username     string `json:"username"`
 	PasswordHash string `json:"-"`
 	SalaryCents  int    `json:"salaryCents"`
+	Email        string `json:"email"` // Where notifications are mailed to, if anywhere
+	IsAdmin      bool   `json:"isAdmin"`
 }
@@ -1,9 +1,127 @@
 package domain
 
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+type WalletType string
+
+const (
+	WalletChecking   WalletType = "CHECKING"
+	WalletCash       WalletType = "CASH"
+	WalletSavings    WalletType = "SAVINGS"
+	WalletCreditCard WalletType = "CREDIT_CARD"
+	WalletLoan       WalletType = "LOAN"
+)
+
 type Wallet struct {
-	ID           int    `json:"id"`
-	UserID       int    `json:"userId"`
-	Name         string `json:"name"`
-	BalanceCents int    `json:"balanceCents"`
-	CanDelete    bool   `json:"canDelete"`
+	ID           int        `json:"id"`
+	UserID       int        `json:"userId"`
+	Name         string     `json:"name"`
+	Type         WalletType `json:"type"`
+	BalanceCents int        `json:"balanceCents"`
+	// The balance splits into what is booked and what pending transactions
+	// add to it.
+	BookedCents  int  `json:"bookedCents"`
+	PendingCents int  `json:"pendingCents"`
+	CanDelete    bool `json:"canDelete"`
+	// Permission is what the user asking may do with the wallet, which may
+	// be someone else's.
+	Permission Permission `json:"permission,omitempty"`
+	// A credit card's statement closes on StatementClosingDay and is due on
+	// the next PaymentDueDay. Days past the end of a month fall on its last
+	// day. Settling pays it from PaymentWalletID.
+	StatementClosingDay int                  `json:"statementClosingDay,omitempty"`
+	PaymentDueDay       int                  `json:"paymentDueDay,omitempty"`
+	PaymentWalletID     *int                 `json:"paymentWalletId,omitempty"`
+	CurrentStatement    *CreditCardStatement `json:"currentStatement,omitempty"`
+}
+
+// CreditCardStatement is the last closed statement of a credit card. Amounts
+// are what is owed, so positive.
+type CreditCardStatement struct {
+	PeriodStart  time.Time `json:"periodStart"`
+	ClosingDate  time.Time `json:"closingDate"`
+	DueDate      time.Time `json:"dueDate"`
+	BalanceCents int       `json:"balanceCents"` // Owed when the statement closed
+	// PaidCents was paid onto the card since the statement closed, and
+	// OutstandingCents is what is left to pay of it.
+	PaidCents        int `json:"paidCents"`
+	OutstandingCents int `json:"outstandingCents"`
+	// UnbilledCents is spent since the statement closed and goes on the next
+	// one.
+	UnbilledCents int `json:"unbilledCents"`
+}
+
+func (w *Wallet) SplitPending(pendingCents int) {
+	w.PendingCents = pendingCents
+	w.BookedCents = w.BalanceCents - pendingCents
+}
+
+// ApplyDefaults makes a wallet without a type a checking account.
+func (w *Wallet) ApplyDefaults() {
+	if w.Type == "" {
+		w.Type = WalletChecking
+	}
+}
+
+func (w Wallet) Validate() error {
+	if strings.TrimSpace(w.Name) == "" {
+		return ErrMissingWallet
+	}
+	switch w.Type {
+	case WalletChecking, WalletCash, WalletSavings, WalletLoan:
+		if w.StatementClosingDay != 0 || w.PaymentDueDay != 0 || w.PaymentWalletID != nil {
+			return fmt.Errorf("%w: only credit cards have statements", ErrInvalidStatementCycle)
+		}
+	case WalletCreditCard:
+		if w.StatementClosingDay < 1 || w.StatementClosingDay > 31 || w.PaymentDueDay < 1 || w.PaymentDueDay > 31 {
+			return fmt.Errorf("%w: closing and due day must be days of the month", ErrInvalidStatementCycle)
+		}
+		if w.PaymentWalletID != nil && *w.PaymentWalletID == w.ID {
+			return ErrSameWalletTransfer
+		}
+	default:
+		return fmt.Errorf("%w: %q", ErrInvalidWalletType, w.Type)
+	}
+	return nil
+}
+
+// Statement works out the card's last closed statement from its transactions.
+func (w Wallet) Statement(transactions []Transaction, now time.Time) CreditCardStatement {
+	today := DateOf(now)
+	closing := dayOfMonth(today.Year(), today.Month(), w.StatementClosingDay)
+	if closing.After(today) {
+		closing = dayOfMonth(today.Year(), today.Month()-1, w.StatementClosingDay)
+	}
+	previous := dayOfMonth(closing.Year(), closing.Month()-1, w.StatementClosingDay)
+	due := dayOfMonth(closing.Year(), closing.Month(), w.PaymentDueDay)
+	if !due.After(closing) {
+		due = dayOfMonth(closing.Year(), closing.Month()+1, w.PaymentDueDay)
+	}
+
+	s := CreditCardStatement{PeriodStart: previous.AddDate(0, 0, 1), ClosingDate: closing, DueDate: due}
+	balance := 0
+	for _, t := range transactions {
+		if !DateOf(t.Date).After(closing) {
+			balance += t.SignedAmount()
+		} else if t.Type == Income {
+			s.PaidCents += t.AmountInCents
+		} else {
+			s.UnbilledCents += t.AmountInCents
+		}
+	}
+	s.BalanceCents = max(-balance, 0)
+	s.OutstandingCents = max(s.BalanceCents-s.PaidCents, 0)
+	return s
+}
+
+// dayOfMonth is the day in the month, or the month's last day if it is
+// shorter. The month may lie outside 1 to 12 and rolls over into the year.
+func dayOfMonth(year int, month time.Month, day int) time.Time {
+	first := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	last := first.AddDate(0, 1, -1).Day()
+	return first.AddDate(0, 0, min(day, last)-1)
 }
@@ -0,0 +1,112 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+type BalanceInterval string
+
+const (
+	BalanceDaily   BalanceInterval = "day"
+	BalanceWeekly  BalanceInterval = "week"
+	BalanceMonthly BalanceInterval = "month"
+)
+
+// MaxBalancePoints is how many points a balance history may have at most,
+// nearly three years of days.
+const MaxBalancePoints = 1000
+
+// BalanceChange is what the transactions of one day added to a wallet.
+type BalanceChange struct {
+	Date          time.Time
+	AmountInCents int
+}
+
+// BalancePoint is a wallet's balance at the end of a day.
+type BalancePoint struct {
+	Date         time.Time `json:"date"`
+	BalanceCents int       `json:"balanceCents"`
+}
+
+type WalletBalance struct {
+	WalletID     int        `json:"walletId"`
+	Name         string     `json:"name"`
+	Type         WalletType `json:"type"`
+	BalanceCents int        `json:"balanceCents"`
+}
+
+// WalletBalances are the balances of all of a user's wallets at the end of a
+// day.
+type WalletBalances struct {
+	At         time.Time       `json:"at"`
+	Wallets    []WalletBalance `json:"wallets"`
+	TotalCents int             `json:"totalCents"`
+}
+
+func (i BalanceInterval) Validate() error {
+	switch i {
+	case BalanceDaily, BalanceWeekly, BalanceMonthly:
+		return nil
+	}
+	return fmt.Errorf("%w: interval must be day, week or month", ErrInvalidDateRange)
+}
+
+// CountBalancePoints is how many points BalanceSeries has from from to until.
+func CountBalancePoints(from, until time.Time, interval BalanceInterval) int {
+	from, until = DateOf(from), DateOf(until)
+	if until.Before(from) {
+		return 0
+	}
+	switch interval {
+	case BalanceWeekly:
+		first := intervalEnd(from, interval)
+		if !first.Before(until) {
+			return 1
+		}
+		return 1 + (daysBetween(first, until)+6)/7
+	case BalanceMonthly:
+		return (until.Year()-from.Year())*12 + int(until.Month()-from.Month()) + 1
+	default:
+		return daysBetween(from, until) + 1
+	}
+}
+
+func daysBetween(from, until time.Time) int {
+	return int(until.Sub(from).Hours() / 24)
+}
+
+// BalanceSeries rebuilds the balance from the one before from and the daily
+// changes after it. It has a point at the end of every day, week (ending on
+// Sunday) or month from from to until; the last one is until itself.
+func BalanceSeries(opening int, changes []BalanceChange, from, until time.Time, interval BalanceInterval) []BalancePoint {
+	from, until = DateOf(from), DateOf(until)
+	points := []BalancePoint{}
+	balance := opening
+	next := 0
+	for end := intervalEnd(from, interval); ; end = intervalEnd(end.AddDate(0, 0, 1), interval) {
+		if end.After(until) {
+			end = until
+		}
+		for next < len(changes) && !DateOf(changes[next].Date).After(end) {
+			balance += changes[next].AmountInCents
+			next++
+		}
+		points = append(points, BalancePoint{Date: end, BalanceCents: balance})
+		if !end.Before(until) {
+			return points
+		}
+	}
+}
+
+// intervalEnd is the last day of the interval the date falls in.
+func intervalEnd(date time.Time, interval BalanceInterval) time.Time {
+	switch interval {
+	case BalanceWeekly:
+		return date.AddDate(0, 0, (7-int(date.Weekday()))%7)
+	case BalanceMonthly:
+		return time.Date(date.Year(), date.Month()+1, 0, 0, 0, 0, 0, time.UTC)
+	default:
+		return date
+	}
+}
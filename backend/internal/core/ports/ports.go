@@ -1,26 +1,71 @@
 package ports
 
-import "github.com/fim-lab/expense-tracker/internal/core/domain"
+import (
+	"time"
+
+	"github.com/fim-lab/expense-tracker/internal/core/domain"
+)
 
 // --- Driving Ports ---
 type TransactionService interface {
 	CreateTransaction(userID int, t domain.Transaction) (int, error)
-	CreateTransfer(userID, fromWalletID, toWalletID, amount int) error
+	CreateTransfer(userID, fromWalletID, toWalletID, amount int, date time.Time) error
 	GetTransactions(userID int, limit int, offset int) ([]domain.TransactionDTO, error)
 	Search(userID int, criteria domain.TransactionSearchCriteria) (*domain.PaginatedTransactions, error)
 	GetTransactionCount(userID int) (int, error)
 	UpdateTransaction(userID int, t domain.Transaction) error
 	DeleteTransaction(userID int, id int) error
 	GetTransactionByID(userID int, id int) (domain.Transaction, error)
+	// SettleTransactions marks the pending ones among the transactions the
+	// user may change as booked and returns how many there were.
+	SettleTransactions(userID int, ids []int) (int, error)
 }
 
 type BudgetService interface {
 	CreateBudget(userID int, b domain.Budget) error
 	GetBudget(userID int, id int) (domain.Budget, error)
 	UpdateBudget(userID int, budget domain.Budget) error
+	// GetBudgets returns the top level budgets with the ones below them as
+	// their children.
 	GetBudgets(userID int) ([]domain.Budget, error)
+	// MoveBudget nests the budget under the parent, or makes it a top level
+	// budget if parentID is nil.
+	MoveBudget(userID int, id int, parentID *int) error
 	GetTotalOfBudgets(userID int) (int, error)
 	DeleteBudget(userID int, id int) error
+	GetBudgetHistory(userID int, id int, until time.Time) ([]domain.BudgetPeriodSummary, error)
+	SetAllocation(userID int, a domain.BudgetAllocation) error
+	GetToBeAssigned(userID int) (int, error)
+	// AssignToBudgets puts money from the income to be assigned on the
+	// budgets, all or nothing, and returns what is left to be assigned.
+	AssignToBudgets(userID int, assignments []domain.BudgetAssignment) (int, error)
+	TransferBetweenBudgets(userID, fromBudgetID, toBudgetID, amount int, note string) error
+	GetMovements(userID int) ([]domain.BudgetMovement, error)
+}
+
+type NotificationService interface {
+	// Notify puts the notification in the user's inbox and hands it to every
+	// notifier. A notifier failing does not fail Notify.
+	Notify(n domain.Notification) error
+	GetNotifications(userID int, unreadOnly bool) ([]domain.Notification, error)
+	MarkRead(userID int, id int) error
+}
+
+// BudgetAlertService raises an alert whenever a change makes a budget's
+// spending in a period reach one of the budget's alert thresholds. It is told
+// about changes once they are saved and works out from them what the budgets
+// stood at before.
+type BudgetAlertService interface {
+	// TransactionsChanged checks the budgets the transactions are booked on,
+	// as they were before the change and as they are after it. Transactions
+	// created are only in after, the ones deleted only in before.
+	TransactionsChanged(before, after []domain.Transaction)
+	// MovementsSaved checks the budgets money was moved off.
+	MovementsSaved(movements []domain.BudgetMovement)
+}
+
+type DashboardService interface {
+	GetDashboard(userID int) (domain.Dashboard, error)
 }
 
 type WalletService interface {
@@ -30,12 +75,20 @@ type WalletService interface {
 	GetWallets(userID int) ([]domain.Wallet, error)
 	GetTotalOfWallets(userID int) (int, error)
 	DeleteWallet(userID int, id int) error
+	// SettleStatement pays what is left of a credit card's last statement
+	// from its payment wallet.
+	SettleStatement(userID int, id int) (domain.Wallet, error)
+	GetBalanceHistory(userID int, id int, from, until time.Time, interval domain.BalanceInterval) ([]domain.BalancePoint, error)
+	GetBalancesAt(userID int, at time.Time) (domain.WalletBalances, error)
 }
 
 type UserService interface {
 	Authenticate(username, password string) (domain.User, error)
 	GetUserByID(userID int) (domain.User, error)
 	UpdateSalary(userID int, salary int) error
+	// UpdateEmail sets where notifications are mailed to; an empty address
+	// stops the mails.
+	UpdateEmail(userID int, email string) error
 }
 
 type SessionService interface {
@@ -56,6 +109,9 @@ type TradeService interface {
 	CreateTrade(userID int, t domain.Trade) (domain.Trade, error)
 	UpdateTrade(userID int, t domain.Trade) error
 	DeleteTrade(userID int, id int) error
+	// SyncCashFlows books the wallet transactions of all trades that do not
+	// match their trade's cash flow at it and returns how many it changed.
+	SyncCashFlows() (int, error)
 }
 
 type PortfolioService interface {
@@ -63,20 +119,147 @@ type PortfolioService interface {
 	GetTrades(userID int, depotID int) ([]domain.TradeDTO, error)
 }
 
+type TagService interface {
+	GetTags(userID int) ([]domain.TagUsage, error)
+	RenameTag(userID int, from, to string) (domain.TagChange, error)
+	MergeTags(userID int, tags []string, into string) (domain.TagChange, error)
+}
+
 type TransactionTemplateService interface {
 	CreateTransactionTemplate(userID int, tt domain.TransactionTemplate) error
 	GetTransactionTemplate(userID int, id int) (domain.TransactionTemplate, error)
 	GetTransactionTemplates(userID int) ([]domain.TransactionTemplate, error)
 	UpdateTransactionTemplate(userID int, tt domain.TransactionTemplate) error
 	DeleteTransactionTemplate(userID int, id int) error
+	GetUpcomingTransactions(userID int, until time.Time) ([]domain.UpcomingTransaction, error)
+}
+
+type SavingsGoalService interface {
+	// CreateSavingsGoal also sets up the template booking the automatic
+	// contribution, if the goal asks for one.
+	CreateSavingsGoal(userID int, goal domain.SavingsGoal) (domain.SavingsGoal, error)
+	GetSavingsGoals(userID int) ([]domain.SavingsGoal, error)
+	GetSavingsGoal(userID int, id int) (domain.SavingsGoal, error)
+	UpdateSavingsGoal(userID int, goal domain.SavingsGoal) error
+	DeleteSavingsGoal(userID int, id int) error
+}
+
+type ReconciliationService interface {
+	// StartReconciliation opens a reconciliation of the wallet against a
+	// statement; a wallet has at most one open at a time.
+	StartReconciliation(userID int, walletID int, statementDate time.Time, statementBalanceCents int) (domain.Reconciliation, error)
+	GetReconciliations(userID int, walletID int) ([]domain.Reconciliation, error)
+	GetReconciliation(userID int, id int) (domain.Reconciliation, error)
+	// SetCleared ticks or unticks transactions of an open reconciliation.
+	SetCleared(userID int, id int, transactionIDs []int, cleared bool) (domain.Reconciliation, error)
+	// CompleteReconciliation locks the cleared transactions once their
+	// balance matches the statement.
+	CompleteReconciliation(userID int, id int) (domain.Reconciliation, error)
+	// DeleteReconciliation cancels an open reconciliation or undoes a
+	// completed one, unlocking its transactions. They stay cleared.
+	DeleteReconciliation(userID int, id int) error
+}
+
+// Authorizer is the one place deciding who may do what with a wallet, budget
+// or depot: its owner anything, other users what their membership permits.
+// A transaction goes with its wallet. The resource comes back with
+// Permission set to what the user may do; lacking the permission needed is
+// ErrUnauthorized, and errors looking the resource up are passed on.
+type Authorizer interface {
+	Wallet(userID int, id int, need domain.Permission) (domain.Wallet, error)
+	Budget(userID int, id int, need domain.Permission) (domain.Budget, error)
+	Depot(userID int, id int, need domain.Permission) (domain.Depot, error)
+	Transaction(userID int, id int, need domain.Permission) (domain.Transaction, error)
+}
+
+type MembershipService interface {
+	// Invite lets the user named by Username see or also change the owner's
+	// wallet, budget or depot. Inviting a member again changes what they may
+	// do.
+	Invite(userID int, m domain.Membership) (domain.Membership, error)
+	GetMembers(userID int, resourceType domain.ResourceType, resourceID int) ([]domain.Membership, error)
+	// RemoveMember is open to the owner and to the member leaving.
+	RemoveMember(userID int, membershipID int) error
+	GetSharedWithMe(userID int) ([]domain.Membership, error)
+}
+
+// SharedExpenseService splits expenses between users of the instance and
+// keeps the ledger of who owes whom. Unlike everything else, a shared
+// expense is seen by all users sharing it, not only its owner.
+type SharedExpenseService interface {
+	// ShareExpense shares the user's expense transaction, replacing how it
+	// was shared before.
+	ShareExpense(userID int, transactionID int, e domain.SharedExpense) (domain.SharedExpense, error)
+	UnshareExpense(userID int, transactionID int) error
+	GetSharedExpenses(userID int) ([]domain.SharedExpense, error)
+	GetLedger(userID int) ([]domain.SharedBalance, error)
+	// SettleUp pays off the balance with the other user, or amountInCents of
+	// it, booking the payment on the user's wallet. The settlement is pending
+	// until the other user confirms it.
+	SettleUp(userID int, otherUserID int, walletID int, amountInCents int) (domain.Settlement, error)
+	// ConfirmSettlement books the other side of a pending settlement on the
+	// user's wallet, which makes it count.
+	ConfirmSettlement(userID int, settlementID int, walletID int) (domain.Settlement, error)
+}
+
+// DebtService keeps track of who owes the user money and whom the user owes,
+// through debt transactions with a counterparty and their repayments.
+type DebtService interface {
+	CreateCounterparty(userID int, c domain.Counterparty) (domain.Counterparty, error)
+	GetCounterparties(userID int) ([]domain.Counterparty, error)
+	// DeleteCounterparty refuses to delete a counterparty with debts.
+	DeleteCounterparty(userID int, id int) error
+	// GetDebts returns the balance and the history of the debts with each
+	// counterparty.
+	GetDebts(userID int) ([]domain.CounterpartyDebts, error)
+	// CreateDebt books a debt with the counterparty: an expense lends the
+	// money to them, an income borrows it.
+	CreateDebt(userID int, counterpartyID int, t domain.Transaction) (domain.Transaction, error)
+	// AssignCounterparty names the counterparty of a debt booked without one.
+	// Its repayments move along.
+	AssignCounterparty(userID int, debtID int, counterpartyID int) error
+	// RepayDebt books a repayment of up to what is still open of the debt.
+	RepayDebt(userID int, debtID int, repayment domain.Transaction) (domain.Transaction, error)
+}
+
+type TransactionTemplateScheduler interface {
+	BookDueTransactions(now time.Time) (int, error)
+}
+
+type PendingTransactionMonitor interface {
+	// FlagOverduePending flags the transactions still pending too long
+	// before now, tells their users and returns how many it flagged.
+	FlagOverduePending(now time.Time) (int, error)
+}
+
+// BalanceService compares the stored balances of all users' wallets and
+// budgets with their ledgers of transactions and budget movements.
+type BalanceService interface {
+	// CheckBalances reports every balance that drifted from its ledger, and
+	// with repair sets it to the ledger.
+	CheckBalances(repair bool) (domain.BalanceIntegrityReport, error)
 }
 
 type ImportService interface {
-	ImportData(userID int, data domain.FullImportData) error
+	ImportData(userID int, data domain.FullImportData) (domain.ImportResult, error)
+	PreviewImport(userID int, data domain.FullImportData) (domain.ImportPreview, error)
+	ConfirmImport(userID int, data domain.FullImportData, token string) (domain.ImportResult, error)
+	ExportAccount(userID int) (domain.AccountExport, error)
+	ImportAccount(userID int, export domain.AccountExport) (domain.ImportResult, error)
 	ImportTestData(userID int) error
 	DeleteAllUserData(userID int) error
 }
 
+type StatementImportService interface {
+	ImportCSV(userID int, walletID int, profileName string, data []byte) (domain.StatementImportResult, error)
+	ImportCAMT053(userID int, walletID int, data []byte) (domain.StatementImportResult, error)
+	ImportMT940(userID int, walletID int, data []byte) (domain.StatementImportResult, error)
+	GetCSVProfiles(userID int) ([]domain.CSVProfile, error)
+	CreateCSVProfile(userID int, p domain.CSVProfile) error
+	UpdateCSVProfile(userID int, p domain.CSVProfile) error
+	DeleteCSVProfile(userID int, id int) error
+}
+
 type StockService interface {
 	GetStocks() ([]domain.Stock, error)
 	GetOrCreateByWKN(wkn string, fallbackPriceInCents int) (domain.Stock, error)
@@ -92,6 +275,8 @@ type UserRepository interface {
 	GetUserByID(userID int) (domain.User, error)
 	SaveUser(u domain.User) error
 	UpdateUserSalary(userID int, salary int) error
+	UpdateUserEmail(userID int, email string) error
+	FindAllUsers() ([]domain.User, error)
 }
 
 type SessionRepository interface {
@@ -105,8 +290,29 @@ type BudgetRepository interface {
 	GetBudgetByID(id int) (domain.Budget, error)
 	UpdateBudget(budget domain.Budget) error
 	FindBudgetsByUser(userID int) ([]domain.Budget, error)
+	// DeleteBudget deletes the budget and moves its children up to its
+	// parent.
 	DeleteBudget(id int) error
 	DeleteAllByUser(userID int) error
+	UpdateBudgetParent(id int, parentID *int) error
+	// SaveAllocation sets the allocation of a budget for the period starting
+	// on PeriodStart, replacing any allocation that period had.
+	SaveAllocation(a domain.BudgetAllocation) error
+	FindAllocationsByBudget(budgetID int) ([]domain.BudgetAllocation, error)
+	// FindAllocationsByUser returns the allocations of all the user's
+	// budgets, keyed by budget.
+	FindAllocationsByUser(userID int) (map[int][]domain.BudgetAllocation, error)
+	// SaveMovements saves the movements and books them on their budgets as
+	// one unit.
+	SaveMovements(movements []domain.BudgetMovement) error
+	FindMovementsByUser(userID int) ([]domain.BudgetMovement, error)
+	FindMovementsByBudget(budgetID int) ([]domain.BudgetMovement, error)
+	// SetBudgetBalance overwrites the stored balance, which is otherwise only
+	// ever adjusted by bookings.
+	SetBudgetBalance(id int, balanceCents int) error
+	// LockBudgetsByUser keeps bookings from changing the balances of the
+	// user's budgets until the unit of work it runs in ends.
+	LockBudgetsByUser(userID int) error
 }
 
 type WalletRepository interface {
@@ -116,6 +322,12 @@ type WalletRepository interface {
 	FindWalletsByUser(userID int) ([]domain.Wallet, error)
 	DeleteWallet(id int) error
 	DeleteAllByUser(userID int) error
+	// SetWalletBalance overwrites the stored balance, which is otherwise only
+	// ever adjusted by bookings.
+	SetWalletBalance(id int, balanceCents int) error
+	// LockWalletsByUser keeps bookings from changing the balances of the
+	// user's wallets until the unit of work it runs in ends.
+	LockWalletsByUser(userID int) error
 }
 
 type DepotRepository interface {
@@ -130,8 +342,10 @@ type DepotRepository interface {
 type TransactionRepository interface {
 	SaveTransaction(t domain.Transaction) (int, error)
 	GetTransactionByID(id int) (domain.Transaction, error)
+	FindTransactionByExternalID(userID int, externalID string) (domain.Transaction, error)
 	GetTransactionCount(userId int) (int, error)
 	FindTransactionsByUser(userID int, limit int, offset int) ([]domain.TransactionDTO, error)
+	FindAllTransactionsByUser(userID int) ([]domain.Transaction, error)
 	SearchTransactions(userID int, criteria domain.TransactionSearchCriteria) ([]domain.TransactionDTO, error)
 	CountSearchedTransactions(userID int, criteria domain.TransactionSearchCriteria) (int, error)
 	SumSearchedTransactionAmounts(userID int, criteria domain.TransactionSearchCriteria) (int, error)
@@ -140,7 +354,51 @@ type TransactionRepository interface {
 	DeleteAllByUser(userID int) error
 	CreateTransfer(from, to domain.Transaction) error
 	CountTransactionsByBudgetID(budgetID int) (int, error)
+	// CountTransactionsByBudget counts the transactions on each of the
+	// user's budgets. Budgets without any are left out.
+	CountTransactionsByBudget(userID int) (map[int]int, error)
+	FindBudgetBookings(budgetID int) ([]domain.BudgetBooking, error)
+	// FindBudgetBookingsByUser returns the bookings on each of the user's
+	// budgets dated on or after from, oldest first.
+	FindBudgetBookingsByUser(userID int, from time.Time) (map[int][]domain.BudgetBooking, error)
+	// FindBudgetBookingsBetween returns the budget's bookings dated on or
+	// after from and before until, oldest first.
+	FindBudgetBookingsBetween(budgetID int, from, until time.Time) ([]domain.BudgetBooking, error)
+	// SumTransactionsWithoutBudget sums the user's income minus expenses that
+	// are booked on no budget, leaving out debts.
+	SumTransactionsWithoutBudget(userID int) (int, error)
 	CountTransactionsByWalletID(walletID int) (int, error)
+	// FindTransactionsByWallet returns the oldest transactions first.
+	FindTransactionsByWallet(walletID int) ([]domain.Transaction, error)
+	// SumWalletBalancesAt rebuilds the balance of each of the user's wallets
+	// from its transactions dated up to and including at. Wallets without
+	// any are left out.
+	SumWalletBalancesAt(userID int, at time.Time) (map[int]int, error)
+	// FindWalletBalanceChanges sums the wallet's transactions per day from
+	// from to until, both inclusive, oldest first.
+	FindWalletBalanceChanges(walletID int, from, until time.Time) ([]domain.BalanceChange, error)
+	SetTransactionsCleared(ids []int, cleared bool) error
+	// SumPendingAmounts adds up what the user's pending transactions add to
+	// each wallet and budget.
+	SumPendingAmounts(userID int) (wallets, budgets map[int]int, err error)
+	// SettleTransactions books the pending transactions among ids and
+	// returns how many there were.
+	SettleTransactions(ids []int) (int, error)
+	// FlagOverduePending flags every pending transaction dated before the
+	// date that is not flagged yet, and returns the ones it flagged.
+	FlagOverduePending(before time.Time) ([]domain.Transaction, error)
+	// MarkReconciled hands the wallet's cleared transactions up to until that
+	// belong to no reconciliation yet to the reconciliation.
+	MarkReconciled(walletID int, until time.Time, reconciliationID int) error
+	// FindDebtTransactions returns the user's debts and repayments that have
+	// a counterparty, oldest first.
+	FindDebtTransactions(userID int) ([]domain.Transaction, error)
+	// SetCounterparty names the counterparty of the debt and its repayments.
+	SetCounterparty(debtID int, counterpartyID int) error
+	// ReplaceTags swaps every one of the tags for the replacement on the
+	// user's transactions and split lines, and reports how many transactions
+	// changed.
+	ReplaceTags(userID int, tags []string, replacement string) (int, error)
 }
 
 type TradeRepository interface {
@@ -165,12 +423,113 @@ type StockRepository interface {
 }
 
 type TransactionTemplateRepository interface {
-	SaveTransactionTemplate(tt domain.TransactionTemplate) error
+	SaveTransactionTemplate(tt domain.TransactionTemplate) (int, error)
 	GetTransactionTemplateByID(id int) (domain.TransactionTemplate, error)
 	FindTransactionTemplatesByUser(userID int) ([]domain.TransactionTemplate, error)
 	UpdateTransactionTemplate(tt domain.TransactionTemplate) error
 	DeleteTransactionTemplate(id int) error
 	DeleteAllByUser(userID int) error
+	FindAllTransactionTemplates() ([]domain.TransactionTemplate, error)
+	// AdvanceLastOccurrence moves the last booked occurrence from `from` to `to`
+	// only if it still is `from`, and reports whether it did. This lets several
+	// instances race for the same occurrence with exactly one winner.
+	AdvanceLastOccurrence(id int, from *time.Time, to *time.Time) (bool, error)
+	ReplaceTags(userID int, tags []string, replacement string) (int, error)
+}
+
+type NotificationRepository interface {
+	SaveNotification(n domain.Notification) (int, error)
+	GetNotificationByID(id int) (domain.Notification, error)
+	// FindNotificationsByUser returns the newest notifications first.
+	FindNotificationsByUser(userID int) ([]domain.Notification, error)
+	MarkNotificationRead(id int) error
+	DeleteAllByUser(userID int) error
+}
+
+type SavingsGoalRepository interface {
+	SaveSavingsGoal(g domain.SavingsGoal) (int, error)
+	GetSavingsGoalByID(id int) (domain.SavingsGoal, error)
+	FindSavingsGoalsByUser(userID int) ([]domain.SavingsGoal, error)
+	UpdateSavingsGoal(g domain.SavingsGoal) error
+	DeleteSavingsGoal(id int) error
+	DeleteAllByUser(userID int) error
+}
+
+type ReconciliationRepository interface {
+	SaveReconciliation(r domain.Reconciliation) (int, error)
+	GetReconciliationByID(id int) (domain.Reconciliation, error)
+	// FindReconciliationsByWallet returns the latest statements first.
+	FindReconciliationsByWallet(walletID int) ([]domain.Reconciliation, error)
+	CompleteReconciliation(id int, completedAt time.Time) error
+	// DeleteReconciliation also releases the transactions it locked.
+	DeleteReconciliation(id int) error
+	DeleteAllByUser(userID int) error
+}
+
+type CounterpartyRepository interface {
+	SaveCounterparty(c domain.Counterparty) (int, error)
+	GetCounterpartyByID(id int) (domain.Counterparty, error)
+	// FindCounterpartiesByUser returns the counterparties by name.
+	FindCounterpartiesByUser(userID int) ([]domain.Counterparty, error)
+	DeleteCounterparty(id int) error
+	DeleteAllByUser(userID int) error
+}
+
+// SharedExpenseRepository holds the expenses shared between users and the
+// settlements between them.
+type SharedExpenseRepository interface {
+	SaveSharedExpense(e domain.SharedExpense) (int, error)
+	GetSharedExpenseByTransaction(transactionID int) (domain.SharedExpense, error)
+	// FindSharedExpensesByUser returns the expenses the user paid or shares,
+	// oldest first.
+	FindSharedExpensesByUser(userID int) ([]domain.SharedExpense, error)
+	DeleteSharedExpense(id int) error
+	SaveSettlement(s domain.Settlement) (int, error)
+	GetSettlementByID(id int) (domain.Settlement, error)
+	UpdateSettlement(s domain.Settlement) error
+	FindSettlementsByUser(userID int) ([]domain.Settlement, error)
+	// DeleteAllByUser deletes the expenses the user paid. What others paid
+	// and the settlements stay, as they are part of the others' ledgers.
+	DeleteAllByUser(userID int) error
+}
+
+// MembershipRepository holds who besides their owners may use wallets,
+// budgets and depots. A membership goes when its resource is deleted.
+type MembershipRepository interface {
+	// SaveMembership adds a membership, or changes the permission of the one
+	// with the ID.
+	SaveMembership(m domain.Membership) (int, error)
+	GetMembershipByID(id int) (domain.Membership, error)
+	GetMembership(resourceType domain.ResourceType, resourceID int, userID int) (domain.Membership, error)
+	FindMembersOf(resourceType domain.ResourceType, resourceID int) ([]domain.Membership, error)
+	FindMembershipsByUser(userID int) ([]domain.Membership, error)
+	DeleteMembership(id int) error
+	// DeleteAllByUser deletes the memberships in the user's resources and
+	// the user's in those of others.
+	DeleteAllByUser(userID int) error
+}
+
+// Notifier delivers notifications outside of the app, e.g. by mail.
+type Notifier interface {
+	Deliver(user domain.User, n domain.Notification) error
+}
+
+type CSVProfileRepository interface {
+	SaveCSVProfile(p domain.CSVProfile) error
+	GetCSVProfileByID(id int) (domain.CSVProfile, error)
+	FindCSVProfilesByUser(userID int) ([]domain.CSVProfile, error)
+	UpdateCSVProfile(p domain.CSVProfile) error
+	DeleteCSVProfile(id int) error
+	DeleteAllByUser(userID int) error
+}
+
+// StatementParser reads bank exports into the rows ImportService saves.
+// Rows carry no wallet; the caller decides which wallet they are booked on.
+type StatementParser interface {
+	BuiltInCSVProfiles() []domain.CSVProfile
+	ParseCSV(data []byte, profile domain.CSVProfile) (domain.Statement, error)
+	ParseCAMT053(data []byte) (domain.Statement, error)
+	ParseMT940(data []byte) (domain.Statement, error)
 }
 
 type Repositories interface {
@@ -183,4 +542,15 @@ type Repositories interface {
 	TradeRepository() TradeRepository
 	TransactionTemplateRepository() TransactionTemplateRepository
 	StockRepository() StockRepository
+	CSVProfileRepository() CSVProfileRepository
+	NotificationRepository() NotificationRepository
+	SavingsGoalRepository() SavingsGoalRepository
+	ReconciliationRepository() ReconciliationRepository
+	CounterpartyRepository() CounterpartyRepository
+	SharedExpenseRepository() SharedExpenseRepository
+	MembershipRepository() MembershipRepository
+
+	// WithinTransaction runs fn as one unit of work on repositories handed to
+	// it. If fn returns an error, nothing it wrote through them is kept.
+	WithinTransaction(fn func(repos Repositories) error) error
 }
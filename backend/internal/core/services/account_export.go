@@ -0,0 +1,774 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fim-lab/expense-tracker/internal/core/domain"
+	"github.com/fim-lab/expense-tracker/internal/core/ports"
+)
+
+// ExportAccount collects everything the user owns into one document that
+// ImportAccount reads back. Balances are not part of it: they follow from the
+// transactions.
+func (s *importService) ExportAccount(userID int) (domain.AccountExport, error) {
+	user, err := s.repos.UserRepository().GetUserByID(userID)
+	if err != nil {
+		return domain.AccountExport{}, fmt.Errorf("failed to fetch user: %w", err)
+	}
+
+	export := domain.AccountExport{
+		Version:              domain.AccountExportVersion,
+		ExportedAt:           time.Now().UTC(),
+		SalaryCents:          user.SalaryCents,
+		Wallets:              []domain.ExportWallet{},
+		Reconciliations:      []domain.ExportReconciliation{},
+		Budgets:              []domain.ExportBudget{},
+		Depots:               []domain.ExportDepot{},
+		Stocks:               []domain.ExportStock{},
+		Transactions:         []domain.ExportTransaction{},
+		Trades:               []domain.ExportTrade{},
+		TransactionTemplates: []domain.ExportTransactionTemplate{},
+		CSVProfiles:          []domain.CSVProfile{},
+	}
+
+	wallets, err := s.repos.WalletRepository().FindWalletsByUser(userID)
+	if err != nil {
+		return domain.AccountExport{}, fmt.Errorf("failed to fetch wallets: %w", err)
+	}
+	walletNames := make(map[int]string)
+	for _, w := range wallets {
+		walletNames[w.ID] = w.Name
+	}
+	for _, w := range wallets {
+		w.ApplyDefaults()
+		export.Wallets = append(export.Wallets, domain.ExportWallet{
+			Name:                w.Name,
+			Type:                w.Type,
+			StatementClosingDay: w.StatementClosingDay,
+			PaymentDueDay:       w.PaymentDueDay,
+			PaymentWallet:       walletName(walletNames, w.PaymentWalletID),
+		})
+	}
+
+	reconciliationPositions := make(map[int]int)
+	for _, w := range wallets {
+		reconciliations, err := s.repos.ReconciliationRepository().FindReconciliationsByWallet(w.ID)
+		if err != nil {
+			return domain.AccountExport{}, fmt.Errorf("failed to fetch reconciliations of wallet %s: %w", w.Name, err)
+		}
+		// Oldest statement first, as they were reconciled.
+		slices.Reverse(reconciliations)
+		for _, rec := range reconciliations {
+			reconciliationPositions[rec.ID] = len(export.Reconciliations) + 1
+			export.Reconciliations = append(export.Reconciliations, domain.ExportReconciliation{
+				Wallet:                w.Name,
+				StatementDate:         rec.StatementDate,
+				StatementBalanceCents: rec.StatementBalanceCents,
+				Status:                rec.Status,
+				CreatedAt:             rec.CreatedAt,
+				CompletedAt:           rec.CompletedAt,
+			})
+		}
+	}
+
+	budgets, err := s.repos.BudgetRepository().FindBudgetsByUser(userID)
+	if err != nil {
+		return domain.AccountExport{}, fmt.Errorf("failed to fetch budgets: %w", err)
+	}
+	budgetNames := make(map[int]string)
+	for _, b := range budgets {
+		budgetNames[b.ID] = b.Name
+	}
+	for _, b := range budgets {
+		allocations, err := s.repos.BudgetRepository().FindAllocationsByBudget(b.ID)
+		if err != nil {
+			return domain.AccountExport{}, fmt.Errorf("failed to fetch allocations of budget %s: %w", b.Name, err)
+		}
+		exported := domain.ExportBudget{Name: b.Name, LimitCents: b.LimitCents, Period: b.Period, Rollover: b.Rollover, AlertThresholds: b.AlertThresholds}
+		if b.ParentID != nil {
+			exported.Parent = budgetNames[*b.ParentID]
+		}
+		for _, a := range allocations {
+			exported.Allocations = append(exported.Allocations, domain.ExportAllocation{PeriodStart: a.PeriodStart, AmountInCents: a.AmountInCents})
+		}
+		export.Budgets = append(export.Budgets, exported)
+	}
+	movements, err := s.repos.BudgetRepository().FindMovementsByUser(userID)
+	if err != nil {
+		return domain.AccountExport{}, fmt.Errorf("failed to fetch budget movements: %w", err)
+	}
+	for _, m := range movements {
+		export.BudgetMovements = append(export.BudgetMovements, domain.ExportBudgetMovement{
+			Date:          m.Date,
+			From:          budgetName(budgetNames, m.FromBudgetID),
+			To:            budgetName(budgetNames, m.ToBudgetID),
+			AmountInCents: m.AmountInCents,
+			Note:          m.Note,
+		})
+	}
+
+	counterparties, err := s.repos.CounterpartyRepository().FindCounterpartiesByUser(userID)
+	if err != nil {
+		return domain.AccountExport{}, fmt.Errorf("failed to fetch counterparties: %w", err)
+	}
+	counterpartyNames := make(map[int]string)
+	for _, c := range counterparties {
+		counterpartyNames[c.ID] = c.Name
+		export.Counterparties = append(export.Counterparties, c.Name)
+	}
+
+	transactions, err := s.repos.TransactionRepository().FindAllTransactionsByUser(userID)
+	if err != nil {
+		return domain.AccountExport{}, fmt.Errorf("failed to fetch transactions: %w", err)
+	}
+	exported := make(map[int]bool)
+	for _, t := range transactions {
+		var repaymentOf *int
+		if t.RepaymentOfID != nil && exported[*t.RepaymentOfID] {
+			repaymentOf = t.RepaymentOfID
+		}
+		exported[t.ID] = true
+		var reconciliation *int
+		if t.ReconciliationID != nil {
+			if position, ok := reconciliationPositions[*t.ReconciliationID]; ok {
+				reconciliation = &position
+			}
+		}
+		var splits []domain.ExportSplit
+		for _, split := range t.Splits {
+			splits = append(splits, domain.ExportSplit{
+				Budget:        budgetNames[split.BudgetID],
+				AmountInCents: split.AmountInCents,
+				Tags:          split.Tags,
+				Note:          split.Note,
+			})
+		}
+		export.Transactions = append(export.Transactions, domain.ExportTransaction{
+			Ref:            t.ID,
+			Date:           t.Date,
+			Wallet:         walletNames[t.WalletID],
+			Budget:         budgetName(budgetNames, t.BudgetID),
+			Description:    t.Description,
+			AmountInCents:  t.AmountInCents,
+			Type:           t.Type,
+			IsPending:      t.IsPending != nil && *t.IsPending,
+			IsDebt:         t.IsDebt != nil && *t.IsDebt,
+			Tags:           t.Tags,
+			ExternalID:     t.ExternalID,
+			Splits:         splits,
+			Cleared:        t.Cleared,
+			Reconciliation: reconciliation,
+			Counterparty:   counterpartyName(counterpartyNames, t.CounterpartyID),
+			RepaymentOf:    repaymentOf,
+		})
+	}
+
+	depots, err := s.repos.DepotRepository().FindDepotsByUser(userID)
+	if err != nil {
+		return domain.AccountExport{}, fmt.Errorf("failed to fetch depots: %w", err)
+	}
+	sort.Slice(depots, func(i, j int) bool { return depots[i].ID < depots[j].ID })
+	stocks := make(map[int]domain.Stock)
+	for _, d := range depots {
+		export.Depots = append(export.Depots, domain.ExportDepot{
+			Name:   d.Name,
+			Wallet: walletNames[d.WalletID],
+			Budget: budgetNames[d.BudgetID],
+		})
+
+		trades, err := s.repos.TradeRepository().FindTradesByDepot(d.ID)
+		if err != nil {
+			return domain.AccountExport{}, fmt.Errorf("failed to fetch trades of depot %s: %w", d.Name, err)
+		}
+		for _, t := range trades {
+			stock, ok := stocks[t.StockID]
+			if !ok {
+				stock, err = s.repos.StockRepository().GetStockByID(t.StockID)
+				if err != nil {
+					return domain.AccountExport{}, fmt.Errorf("failed to fetch stock %d: %w", t.StockID, err)
+				}
+				stocks[t.StockID] = stock
+				export.Stocks = append(export.Stocks, domain.ExportStock{
+					WKN:          stock.WKN,
+					Ticker:       stock.Ticker,
+					PriceInCents: stock.PriceInCents,
+				})
+			}
+
+			trade := domain.ExportTrade{
+				Depot:        d.Name,
+				WKN:          stock.WKN,
+				Type:         t.Type,
+				Quantity:     t.Quantity,
+				TotalInCents: t.TotalInCents,
+				FeesInCents:  t.FeesInCents,
+				TaxesInCents: t.TaxesInCents,
+				Timestamp:    t.Timestamp,
+			}
+			if t.WalletTransactionID != nil && exported[*t.WalletTransactionID] {
+				ref := *t.WalletTransactionID
+				trade.WalletTransaction = &ref
+			}
+			export.Trades = append(export.Trades, trade)
+		}
+	}
+
+	templates, err := s.repos.TransactionTemplateRepository().FindTransactionTemplatesByUser(userID)
+	if err != nil {
+		return domain.AccountExport{}, fmt.Errorf("failed to fetch transaction templates: %w", err)
+	}
+	templatePositions := make(map[int]int)
+	for _, tt := range templates {
+		templatePositions[tt.ID] = len(export.TransactionTemplates) + 1
+		export.TransactionTemplates = append(export.TransactionTemplates, domain.ExportTransactionTemplate{
+			Day:            tt.Day,
+			Wallet:         walletNames[tt.WalletID],
+			ToWallet:       walletName(walletNames, tt.ToWalletID),
+			Budget:         budgetName(budgetNames, tt.BudgetID),
+			Description:    tt.Description,
+			AmountInCents:  tt.AmountInCents,
+			Type:           tt.Type,
+			Tags:           tt.Tags,
+			Recurrence:     tt.Recurrence,
+			CreatedAt:      tt.CreatedAt,
+			LastOccurrence: tt.LastOccurrence,
+		})
+	}
+
+	goals, err := s.repos.SavingsGoalRepository().FindSavingsGoalsByUser(userID)
+	if err != nil {
+		return domain.AccountExport{}, fmt.Errorf("failed to fetch savings goals: %w", err)
+	}
+	for _, g := range goals {
+		goal := domain.ExportSavingsGoal{
+			Name:                     g.Name,
+			Wallet:                   walletName(walletNames, g.WalletID),
+			Budget:                   budgetName(budgetNames, g.BudgetID),
+			TargetCents:              g.TargetCents,
+			TargetDate:               g.TargetDate,
+			MonthlyContributionCents: g.MonthlyContributionCents,
+			FromWallet:               walletName(walletNames, g.FromWalletID),
+			CreatedAt:                g.CreatedAt,
+		}
+		if g.ContributionTemplateID != nil {
+			if position, ok := templatePositions[*g.ContributionTemplateID]; ok {
+				goal.ContributionTemplate = &position
+			}
+		}
+		export.SavingsGoals = append(export.SavingsGoals, goal)
+	}
+
+	profiles, err := s.repos.CSVProfileRepository().FindCSVProfilesByUser(userID)
+	if err != nil {
+		return domain.AccountExport{}, fmt.Errorf("failed to fetch csv profiles: %w", err)
+	}
+	for _, p := range profiles {
+		p.ID = 0
+		p.UserID = 0
+		export.CSVProfiles = append(export.CSVProfiles, p)
+	}
+
+	return export, nil
+}
+
+func walletName(names map[int]string, walletID *int) string {
+	if walletID == nil {
+		return ""
+	}
+	return names[*walletID]
+}
+
+func counterpartyName(names map[int]string, counterpartyID *int) string {
+	if counterpartyID == nil {
+		return ""
+	}
+	return names[*counterpartyID]
+}
+
+func budgetName(names map[int]string, budgetID *int) string {
+	if budgetID == nil {
+		return ""
+	}
+	return names[*budgetID]
+}
+
+// ImportAccount restores an export into an empty account, all or nothing.
+// Importing into an account that holds data is refused, as nothing in an
+// export tells which of its entries the account already has.
+func (s *importService) ImportAccount(userID int, export domain.AccountExport) (domain.ImportResult, error) {
+	if export.Version < 1 || export.Version > domain.AccountExportVersion {
+		return domain.ImportResult{}, fmt.Errorf("%w: %d", domain.ErrUnsupportedExportVersion, export.Version)
+	}
+
+	err := s.repos.WithinTransaction(func(repos ports.Repositories) error {
+		if err := ensureEmptyAccount(repos, userID); err != nil {
+			return err
+		}
+		return importAccount(repos, userID, export)
+	})
+	if err != nil {
+		return domain.ImportResult{}, err
+	}
+	return domain.ImportResult{Created: len(export.Transactions)}, nil
+}
+
+func ensureEmptyAccount(repos ports.Repositories, userID int) error {
+	wallets, err := repos.WalletRepository().FindWalletsByUser(userID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch wallets: %w", err)
+	}
+	budgets, err := repos.BudgetRepository().FindBudgetsByUser(userID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch budgets: %w", err)
+	}
+	profiles, err := repos.CSVProfileRepository().FindCSVProfilesByUser(userID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch csv profiles: %w", err)
+	}
+	counterparties, err := repos.CounterpartyRepository().FindCounterpartiesByUser(userID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch counterparties: %w", err)
+	}
+	// Everything else hangs off a wallet.
+	if len(wallets) > 0 || len(budgets) > 0 || len(profiles) > 0 || len(counterparties) > 0 {
+		return domain.ErrAccountNotEmpty
+	}
+	return nil
+}
+
+func importAccount(repos ports.Repositories, userID int, export domain.AccountExport) error {
+	if err := repos.UserRepository().UpdateUserSalary(userID, export.SalaryCents); err != nil {
+		return fmt.Errorf("failed to update salary: %w", err)
+	}
+
+	for _, w := range export.Wallets {
+		wallet := domain.Wallet{UserID: userID, Name: w.Name, Type: w.Type, StatementClosingDay: w.StatementClosingDay, PaymentDueDay: w.PaymentDueDay}
+		wallet.ApplyDefaults()
+		if err := wallet.Validate(); err != nil {
+			return fmt.Errorf("%w: wallet %s: %w", domain.ErrInvalidAccountExport, w.Name, err)
+		}
+		if err := repos.WalletRepository().SaveWallet(wallet); err != nil {
+			return fmt.Errorf("failed to save wallet %s: %w", w.Name, err)
+		}
+	}
+	wallets, err := repos.WalletRepository().FindWalletsByUser(userID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch wallets: %w", err)
+	}
+	walletIDs := make(map[string]int)
+	for _, w := range wallets {
+		walletIDs[w.Name] = w.ID
+	}
+	// Payment wallets can only be linked once all wallets exist.
+	for _, w := range export.Wallets {
+		if w.PaymentWallet == "" {
+			continue
+		}
+		payerID, ok := walletIDs[w.PaymentWallet]
+		if !ok || w.PaymentWallet == w.Name {
+			return fmt.Errorf("%w: wallet %s: unknown payment wallet %q", domain.ErrInvalidAccountExport, w.Name, w.PaymentWallet)
+		}
+		wallet := domain.Wallet{ID: walletIDs[w.Name], UserID: userID, Name: w.Name, Type: w.Type, StatementClosingDay: w.StatementClosingDay, PaymentDueDay: w.PaymentDueDay, PaymentWalletID: &payerID}
+		wallet.ApplyDefaults()
+		if err := wallet.Validate(); err != nil {
+			return fmt.Errorf("%w: wallet %s: %w", domain.ErrInvalidAccountExport, w.Name, err)
+		}
+		if err := repos.WalletRepository().UpdateWallet(wallet); err != nil {
+			return fmt.Errorf("failed to link the payment wallet of %s: %w", w.Name, err)
+		}
+	}
+
+	var reconciliations []domain.Reconciliation
+	for _, er := range export.Reconciliations {
+		recWalletID, ok := walletIDs[er.Wallet]
+		if !ok {
+			return fmt.Errorf("%w: reconciliation: unknown wallet %q", domain.ErrInvalidAccountExport, er.Wallet)
+		}
+		rec := domain.Reconciliation{
+			UserID:                userID,
+			WalletID:              recWalletID,
+			StatementDate:         er.StatementDate,
+			StatementBalanceCents: er.StatementBalanceCents,
+			Status:                domain.ReconciliationOpen,
+			CreatedAt:             er.CreatedAt,
+		}
+		if (er.Status != domain.ReconciliationOpen && er.Status != domain.ReconciliationCompleted) || (er.Status == domain.ReconciliationCompleted) != (er.CompletedAt != nil) {
+			return fmt.Errorf("%w: reconciliation of wallet %s on %s has invalid status %q", domain.ErrInvalidAccountExport, er.Wallet, er.StatementDate.Format("2006-01-02"), er.Status)
+		}
+		id, err := repos.ReconciliationRepository().SaveReconciliation(rec)
+		if err != nil {
+			return fmt.Errorf("failed to save reconciliation of wallet %s: %w", er.Wallet, err)
+		}
+		rec.ID = id
+		if er.CompletedAt != nil {
+			if err := repos.ReconciliationRepository().CompleteReconciliation(id, *er.CompletedAt); err != nil {
+				return fmt.Errorf("failed to complete reconciliation of wallet %s: %w", er.Wallet, err)
+			}
+			rec.Status, rec.CompletedAt = domain.ReconciliationCompleted, er.CompletedAt
+		}
+		reconciliations = append(reconciliations, rec)
+	}
+
+	for _, b := range export.Budgets {
+		if strings.TrimSpace(b.Name) == "" {
+			return fmt.Errorf("%w: %w", domain.ErrInvalidAccountExport, domain.ErrMissingBudget)
+		}
+		budget := domain.Budget{UserID: userID, Name: b.Name, LimitCents: b.LimitCents, Period: b.Period, Rollover: b.Rollover, AlertThresholds: b.AlertThresholds}
+		budget.ApplyDefaults()
+		if err := budget.ValidatePeriod(); err != nil {
+			return fmt.Errorf("%w: budget %s: %w", domain.ErrInvalidAccountExport, b.Name, err)
+		}
+		if err := budget.NormalizeAlertThresholds(); err != nil {
+			return fmt.Errorf("%w: budget %s: %w", domain.ErrInvalidAccountExport, b.Name, err)
+		}
+		if err := repos.BudgetRepository().SaveBudget(budget); err != nil {
+			return fmt.Errorf("failed to save budget %s: %w", b.Name, err)
+		}
+	}
+	budgets, err := repos.BudgetRepository().FindBudgetsByUser(userID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch budgets: %w", err)
+	}
+	budgetIDs := make(map[string]int)
+	for _, b := range budgets {
+		budgetIDs[b.Name] = b.ID
+	}
+	for _, b := range export.Budgets {
+		if b.Parent != "" {
+			parentID, ok := budgetIDs[b.Parent]
+			if !ok {
+				return fmt.Errorf("%w: unknown parent %q of budget %s", domain.ErrInvalidAccountExport, b.Parent, b.Name)
+			}
+			budgets, err := repos.BudgetRepository().FindBudgetsByUser(userID)
+			if err != nil {
+				return fmt.Errorf("failed to fetch budgets: %w", err)
+			}
+			if err := domain.ValidateBudgetParent(budgets, budgetIDs[b.Name], &parentID); err != nil {
+				return fmt.Errorf("%w: budget %s: %w", domain.ErrInvalidAccountExport, b.Name, err)
+			}
+			if err := repos.BudgetRepository().UpdateBudgetParent(budgetIDs[b.Name], &parentID); err != nil {
+				return fmt.Errorf("failed to nest budget %s: %w", b.Name, err)
+			}
+		}
+		for _, a := range b.Allocations {
+			if err := repos.BudgetRepository().SaveAllocation(domain.BudgetAllocation{BudgetID: budgetIDs[b.Name], PeriodStart: a.PeriodStart, AmountInCents: a.AmountInCents}); err != nil {
+				return fmt.Errorf("failed to save allocation of budget %s: %w", b.Name, err)
+			}
+		}
+	}
+
+	walletID := func(name string) (int, error) {
+		id, ok := walletIDs[name]
+		if !ok {
+			return 0, fmt.Errorf("%w: unknown wallet %q", domain.ErrInvalidAccountExport, name)
+		}
+		return id, nil
+	}
+	optionalBudgetID := func(name string) (*int, error) {
+		if name == "" {
+			return nil, nil
+		}
+		id, ok := budgetIDs[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown budget %q", domain.ErrInvalidAccountExport, name)
+		}
+		return &id, nil
+	}
+
+	var movements []domain.BudgetMovement
+	for _, em := range export.BudgetMovements {
+		from, err := optionalBudgetID(em.From)
+		if err != nil {
+			return err
+		}
+		to, err := optionalBudgetID(em.To)
+		if err != nil {
+			return err
+		}
+		if (from == nil && to == nil) || em.AmountInCents <= 0 {
+			return fmt.Errorf("%w: invalid budget movement on %s", domain.ErrInvalidAccountExport, em.Date.Format("2006-01-02"))
+		}
+		movements = append(movements, domain.BudgetMovement{UserID: userID, Date: em.Date, FromBudgetID: from, ToBudgetID: to, AmountInCents: em.AmountInCents, Note: em.Note})
+	}
+	if err := repos.BudgetRepository().SaveMovements(movements); err != nil {
+		return fmt.Errorf("failed to save budget movements: %w", err)
+	}
+
+	for _, d := range export.Depots {
+		depotWalletID, err := walletID(d.Wallet)
+		if err != nil {
+			return err
+		}
+		depotBudgetID, ok := budgetIDs[d.Budget]
+		if !ok {
+			return fmt.Errorf("%w: unknown budget %q", domain.ErrInvalidAccountExport, d.Budget)
+		}
+		depot := domain.Depot{UserID: userID, Name: d.Name, WalletID: depotWalletID, BudgetID: depotBudgetID}
+		if err := repos.DepotRepository().SaveDepot(depot); err != nil {
+			return fmt.Errorf("failed to save depot %s: %w", d.Name, err)
+		}
+	}
+	depots, err := repos.DepotRepository().FindDepotsByUser(userID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch depots: %w", err)
+	}
+	depotIDs := make(map[string]int)
+	for _, d := range depots {
+		depotIDs[d.Name] = d.ID
+	}
+
+	// Stocks are shared by all users; ones the instance already knows are kept
+	// as they are.
+	stockIDs := make(map[string]int)
+	for _, st := range export.Stocks {
+		wkn := strings.ToUpper(strings.TrimSpace(st.WKN))
+		stock, err := repos.StockRepository().FindStockByWKN(wkn)
+		if err == domain.ErrStockNotFound {
+			stock = domain.Stock{WKN: wkn, Ticker: st.Ticker, PriceInCents: st.PriceInCents}
+			stock.ID, err = repos.StockRepository().SaveStock(stock)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to resolve stock %q: %w", wkn, err)
+		}
+		stockIDs[wkn] = stock.ID
+	}
+
+	counterpartyIDs := make(map[string]int)
+	for _, name := range export.Counterparties {
+		c := domain.Counterparty{UserID: userID, Name: strings.TrimSpace(name), CreatedAt: time.Now()}
+		if err := c.Validate(); err != nil {
+			return fmt.Errorf("%w: %w", domain.ErrInvalidAccountExport, err)
+		}
+		if _, duplicate := counterpartyIDs[c.Name]; duplicate {
+			return fmt.Errorf("%w: counterparty %s: %w", domain.ErrInvalidAccountExport, c.Name, domain.ErrCounterpartyExists)
+		}
+		id, err := repos.CounterpartyRepository().SaveCounterparty(c)
+		if err != nil {
+			return fmt.Errorf("failed to save counterparty %s: %w", c.Name, err)
+		}
+		counterpartyIDs[c.Name] = id
+	}
+
+	transactionIDs := make(map[int]int)
+	for _, et := range export.Transactions {
+		if et.Type != domain.Income && et.Type != domain.Expense {
+			return fmt.Errorf("%w: transaction %q has invalid type %q", domain.ErrInvalidAccountExport, et.Description, et.Type)
+		}
+		if _, duplicate := transactionIDs[et.Ref]; duplicate {
+			return fmt.Errorf("%w: transaction ref %d is used twice", domain.ErrInvalidAccountExport, et.Ref)
+		}
+		txWalletID, err := walletID(et.Wallet)
+		if err != nil {
+			return err
+		}
+		txBudgetID, err := optionalBudgetID(et.Budget)
+		if err != nil {
+			return err
+		}
+		var splits []domain.Split
+		for _, es := range et.Splits {
+			splitBudgetID, ok := budgetIDs[es.Budget]
+			if !ok {
+				return fmt.Errorf("%w: unknown budget %q", domain.ErrInvalidAccountExport, es.Budget)
+			}
+			splits = append(splits, domain.Split{BudgetID: splitBudgetID, AmountInCents: es.AmountInCents, Tags: es.Tags, Note: es.Note})
+		}
+
+		isPending, isDebt := et.IsPending, et.IsDebt
+		t := domain.Transaction{
+			UserID:        userID,
+			Date:          et.Date,
+			BudgetID:      txBudgetID,
+			WalletID:      txWalletID,
+			Description:   et.Description,
+			AmountInCents: et.AmountInCents,
+			Type:          et.Type,
+			IsPending:     &isPending,
+			IsDebt:        &isDebt,
+			Tags:          et.Tags,
+			ExternalID:    et.ExternalID,
+			Splits:        splits,
+			Cleared:       et.Cleared,
+		}
+		if et.Counterparty != "" {
+			counterpartyID, ok := counterpartyIDs[strings.TrimSpace(et.Counterparty)]
+			if !ok {
+				return fmt.Errorf("%w: transaction %q: unknown counterparty %q", domain.ErrInvalidAccountExport, et.Description, et.Counterparty)
+			}
+			t.CounterpartyID = &counterpartyID
+		}
+		if et.Reconciliation != nil {
+			position := *et.Reconciliation
+			if position < 1 || position > len(reconciliations) {
+				return fmt.Errorf("%w: transaction %q: unknown reconciliation %d", domain.ErrInvalidAccountExport, et.Description, position)
+			}
+			rec := reconciliations[position-1]
+			if rec.Status != domain.ReconciliationCompleted || !t.Cleared || !rec.CanClear(t) {
+				return fmt.Errorf("%w: transaction %q cannot be locked by reconciliation %d", domain.ErrInvalidAccountExport, et.Description, position)
+			}
+			t.ReconciliationID = &rec.ID
+		}
+		if et.RepaymentOf != nil {
+			debtID, ok := transactionIDs[*et.RepaymentOf]
+			if !ok {
+				return fmt.Errorf("%w: transaction %q repays unknown transaction ref %d", domain.ErrInvalidAccountExport, et.Description, *et.RepaymentOf)
+			}
+			t.RepaymentOfID = &debtID
+		}
+		if err := t.ValidateSplits(); err != nil {
+			return fmt.Errorf("%w: transaction %q: %w", domain.ErrInvalidAccountExport, et.Description, err)
+		}
+		id, err := repos.TransactionRepository().SaveTransaction(t)
+		if err != nil {
+			return fmt.Errorf("failed to save transaction %q: %w", et.Description, err)
+		}
+		transactionIDs[et.Ref] = id
+	}
+
+	stockService := NewStockService(repos.StockRepository(), repos.TradeRepository())
+	for _, et := range export.Trades {
+		depotID, ok := depotIDs[et.Depot]
+		if !ok {
+			return fmt.Errorf("%w: unknown depot %q", domain.ErrInvalidAccountExport, et.Depot)
+		}
+		if et.Quantity <= 0 {
+			return fmt.Errorf("%w: trade of %s in depot %q: %w", domain.ErrInvalidAccountExport, et.WKN, et.Depot, domain.ErrInvalidQuantity)
+		}
+		wkn := strings.ToUpper(strings.TrimSpace(et.WKN))
+		stockID, ok := stockIDs[wkn]
+		if !ok {
+			stock, err := stockService.GetOrCreateByWKN(wkn, int(math.Round(float64(et.TotalInCents)/et.Quantity)))
+			if err != nil {
+				return fmt.Errorf("failed to resolve stock %q: %w", wkn, err)
+			}
+			stockID = stock.ID
+			stockIDs[wkn] = stockID
+		}
+
+		trade := domain.Trade{
+			DepotID:      depotID,
+			StockID:      stockID,
+			WKN:          wkn,
+			Type:         et.Type,
+			Quantity:     et.Quantity,
+			TotalInCents: et.TotalInCents,
+			FeesInCents:  et.FeesInCents,
+			TaxesInCents: et.TaxesInCents,
+			Timestamp:    et.Timestamp,
+		}
+		if et.WalletTransaction != nil {
+			id, ok := transactionIDs[*et.WalletTransaction]
+			if !ok {
+				return fmt.Errorf("%w: trade refers to unknown transaction ref %d", domain.ErrInvalidAccountExport, *et.WalletTransaction)
+			}
+			trade.WalletTransactionID = &id
+		}
+		if _, err := repos.TradeRepository().SaveTrade(trade); err != nil {
+			return fmt.Errorf("failed to save trade of %s: %w", wkn, err)
+		}
+	}
+
+	var templateIDs []int
+	for _, ett := range export.TransactionTemplates {
+		ttWalletID, err := walletID(ett.Wallet)
+		if err != nil {
+			return err
+		}
+		ttBudgetID, err := optionalBudgetID(ett.Budget)
+		if err != nil {
+			return err
+		}
+		var ttToWalletID *int
+		if ett.ToWallet != "" {
+			id, err := walletID(ett.ToWallet)
+			if err != nil {
+				return err
+			}
+			ttToWalletID = &id
+		}
+		tt := domain.TransactionTemplate{
+			UserID:         userID,
+			Day:            ett.Day,
+			BudgetID:       ttBudgetID,
+			WalletID:       ttWalletID,
+			ToWalletID:     ttToWalletID,
+			Description:    ett.Description,
+			AmountInCents:  ett.AmountInCents,
+			Type:           ett.Type,
+			Tags:           ett.Tags,
+			Recurrence:     ett.Recurrence,
+			CreatedAt:      ett.CreatedAt,
+			LastOccurrence: ett.LastOccurrence,
+		}
+		tt.ApplyRecurrenceDefaults(ett.CreatedAt)
+		if err := tt.Validate(); err != nil {
+			return fmt.Errorf("%w: template %q: %w", domain.ErrInvalidAccountExport, ett.Description, err)
+		}
+		id, err := repos.TransactionTemplateRepository().SaveTransactionTemplate(tt)
+		if err != nil {
+			return fmt.Errorf("failed to save template %q: %w", ett.Description, err)
+		}
+		templateIDs = append(templateIDs, id)
+	}
+
+	optionalWalletID := func(name string) (*int, error) {
+		if name == "" {
+			return nil, nil
+		}
+		id, err := walletID(name)
+		return &id, err
+	}
+	for _, eg := range export.SavingsGoals {
+		goal := domain.SavingsGoal{
+			UserID:                   userID,
+			Name:                     eg.Name,
+			TargetCents:              eg.TargetCents,
+			TargetDate:               eg.TargetDate,
+			MonthlyContributionCents: eg.MonthlyContributionCents,
+			CreatedAt:                eg.CreatedAt,
+		}
+		var err error
+		if goal.WalletID, err = optionalWalletID(eg.Wallet); err != nil {
+			return err
+		}
+		if goal.FromWalletID, err = optionalWalletID(eg.FromWallet); err != nil {
+			return err
+		}
+		if goal.BudgetID, err = optionalBudgetID(eg.Budget); err != nil {
+			return err
+		}
+		if eg.ContributionTemplate != nil {
+			position := *eg.ContributionTemplate
+			if position < 1 || position > len(templateIDs) {
+				return fmt.Errorf("%w: savings goal %q: unknown template %d", domain.ErrInvalidAccountExport, eg.Name, position)
+			}
+			goal.ContributionTemplateID = &templateIDs[position-1]
+		}
+		if err := goal.Validate(); err != nil {
+			return fmt.Errorf("%w: savings goal %q: %w", domain.ErrInvalidAccountExport, eg.Name, err)
+		}
+		if _, err := repos.SavingsGoalRepository().SaveSavingsGoal(goal); err != nil {
+			return fmt.Errorf("failed to save savings goal %q: %w", eg.Name, err)
+		}
+	}
+
+	for _, p := range export.CSVProfiles {
+		p.ID = 0
+		p.UserID = userID
+		p.BuiltIn = false
+		p.ApplyDefaults()
+		if err := p.Validate(); err != nil {
+			return fmt.Errorf("%w: %w", domain.ErrInvalidAccountExport, err)
+		}
+		if err := repos.CSVProfileRepository().SaveCSVProfile(p); err != nil {
+			return fmt.Errorf("failed to save csv profile %s: %w", p.Name, err)
+		}
+	}
+
+	return nil
+}
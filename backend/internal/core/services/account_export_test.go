@@ -0,0 +1,280 @@
+package services
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/fim-lab/expense-tracker/internal/core/domain"
+)
+
+// seedAccount fills the stock fixture's account with one of everything an
+// export covers.
+func seedAccount(t *testing.T, f stockFixture) {
+	t.Helper()
+
+	if err := f.repos.UserRepository().SaveUser(domain.User{ID: f.userID, Username: "test"}); err != nil {
+		t.Fatalf("could not seed the user: %v", err)
+	}
+	if err := f.repos.UserRepository().UpdateUserSalary(f.userID, 250000); err != nil {
+		t.Fatalf("could not set the salary: %v", err)
+	}
+	if err := f.repos.WalletRepository().SaveWallet(domain.Wallet{ID: 2, UserID: f.userID, Name: "Tagesgeld", Type: domain.WalletSavings}); err != nil {
+		t.Fatalf("could not seed the wallet: %v", err)
+	}
+	if err := f.repos.BudgetRepository().SaveBudget(domain.Budget{ID: 2, UserID: f.userID, ParentID: &f.budgetID, Name: "ETF-Sparplan", LimitCents: 20000, AlertThresholds: []int{90}}); err != nil {
+		t.Fatalf("could not seed the child budget: %v", err)
+	}
+	if err := f.repos.BudgetRepository().SaveMovements([]domain.BudgetMovement{{UserID: f.userID, Date: onDate(2026, 3, 1), ToBudgetID: &f.budgetID, AmountInCents: 30000}}); err != nil {
+		t.Fatalf("could not seed the budget movement: %v", err)
+	}
+	if err := f.repos.BudgetRepository().SaveAllocation(domain.BudgetAllocation{BudgetID: f.budgetID, PeriodStart: onDate(2026, 3, 1), AmountInCents: 40000}); err != nil {
+		t.Fatalf("could not seed the allocation: %v", err)
+	}
+
+	pending, notPending, debt, noDebt := true, false, true, false
+	budgetID := f.budgetID
+	if _, err := f.txSvc.CreateTransaction(f.userID, domain.Transaction{
+		Date:          onDate(2026, 3, 1),
+		BudgetID:      &budgetID,
+		WalletID:      f.walletID,
+		Description:   "Gehalt",
+		AmountInCents: 250000,
+		Type:          domain.Income,
+		IsPending:     &pending,
+		IsDebt:        &noDebt,
+		Tags:          []string{"arbeit"},
+	}); err != nil {
+		t.Fatalf("could not seed a transaction: %v", err)
+	}
+	debtSvc := NewDebtService(f.repos)
+	anna, err := debtSvc.CreateCounterparty(f.userID, domain.Counterparty{Name: "Anna"})
+	if err != nil {
+		t.Fatalf("could not seed a counterparty: %v", err)
+	}
+	lent, err := debtSvc.CreateDebt(f.userID, anna.ID, domain.Transaction{
+		Date:          onDate(2026, 3, 2),
+		WalletID:      2,
+		Description:   "Geliehen",
+		AmountInCents: 5000,
+		Type:          domain.Expense,
+		IsPending:     &notPending,
+		IsDebt:        &debt,
+	})
+	if err != nil {
+		t.Fatalf("could not seed a debt: %v", err)
+	}
+	if _, err := debtSvc.RepayDebt(f.userID, lent.ID, domain.Transaction{Date: onDate(2026, 3, 9), AmountInCents: 2000}); err != nil {
+		t.Fatalf("could not seed a repayment: %v", err)
+	}
+	reconciliationSvc := NewReconciliationService(f.repos)
+	rec, err := reconciliationSvc.StartReconciliation(f.userID, 2, onDate(2026, 3, 5), -5000)
+	if err != nil {
+		t.Fatalf("could not start a reconciliation: %v", err)
+	}
+	if _, err := reconciliationSvc.SetCleared(f.userID, rec.ID, []int{lent.ID}, true); err != nil {
+		t.Fatalf("could not clear the debt: %v", err)
+	}
+	if _, err := reconciliationSvc.CompleteReconciliation(f.userID, rec.ID); err != nil {
+		t.Fatalf("could not complete the reconciliation: %v", err)
+	}
+
+	trade := f.trade(domain.TradeTypeBuy, 3, 2, 20000)
+	trade.FeesInCents = 150
+	trade.TaxesInCents = 20
+	if _, err := f.tradeSvc.CreateTrade(f.userID, trade); err != nil {
+		t.Fatalf("could not seed a trade: %v", err)
+	}
+
+	lastOccurrence := onDate(2026, 3, 1)
+	if _, err := f.repos.TransactionTemplateRepository().SaveTransactionTemplate(domain.TransactionTemplate{
+		UserID:        f.userID,
+		Day:           1,
+		WalletID:      f.walletID,
+		Description:   "Miete",
+		AmountInCents: 85000,
+		Type:          domain.Expense,
+		Recurrence: domain.RecurrenceRule{
+			Frequency: domain.Monthly,
+			Interval:  1,
+			StartDate: onDate(2026, 1, 1),
+		},
+		CreatedAt:      onDate(2025, 12, 20),
+		LastOccurrence: &lastOccurrence,
+	}); err != nil {
+		t.Fatalf("could not seed a template: %v", err)
+	}
+
+	savings := 2
+	if _, err := NewSavingsGoalService(f.repos).CreateSavingsGoal(f.userID, domain.SavingsGoal{
+		Name:                     "Notgroschen",
+		WalletID:                 &savings,
+		TargetCents:              500000,
+		TargetDate:               onDate(2027, 12, 31),
+		MonthlyContributionCents: 20000,
+		FromWalletID:             &f.walletID,
+	}); err != nil {
+		t.Fatalf("could not seed a savings goal: %v", err)
+	}
+
+	profile := domain.CSVProfile{
+		UserID:             f.userID,
+		Name:               "Hausbank",
+		DateColumn:         "Datum",
+		DescriptionColumns: []string{"Verwendungszweck"},
+		AmountColumn:       "Betrag",
+	}
+	profile.ApplyDefaults()
+	if err := f.repos.CSVProfileRepository().SaveCSVProfile(profile); err != nil {
+		t.Fatalf("could not seed a csv profile: %v", err)
+	}
+}
+
+// withoutIDs strips what legitimately differs between two exports of the
+// same account: when they were taken and the ref numbers, which are IDs.
+func withoutIDs(export domain.AccountExport) domain.AccountExport {
+	export.ExportedAt = time.Time{}
+	refs := make(map[int]int)
+	for i := range export.Transactions {
+		refs[export.Transactions[i].Ref] = i + 1
+		export.Transactions[i].Ref = i + 1
+	}
+	for i, t := range export.Transactions {
+		if t.RepaymentOf != nil {
+			ref := refs[*t.RepaymentOf]
+			export.Transactions[i].RepaymentOf = &ref
+		}
+	}
+	for i, trade := range export.Trades {
+		if trade.WalletTransaction != nil {
+			ref := refs[*trade.WalletTransaction]
+			export.Trades[i].WalletTransaction = &ref
+		}
+	}
+	return export
+}
+
+func TestAccountExport_RoundTripsThroughDeleteAndImport(t *testing.T) {
+	f := newStockFixture(t)
+	seedAccount(t, f)
+	importSvc := NewImportService(f.repos, noBudgetAlerts{})
+
+	before, err := importSvc.ExportAccount(f.userID)
+	if err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+	if before.Version != domain.AccountExportVersion {
+		t.Errorf("expected version %d, got %d", domain.AccountExportVersion, before.Version)
+	}
+	if len(before.Transactions) != 4 || len(before.Trades) != 1 || len(before.TransactionTemplates) != 2 || len(before.SavingsGoals) != 1 || len(before.CSVProfiles) != 1 {
+		t.Fatalf("expected the export to hold everything seeded, got %+v", before)
+	}
+	if len(before.Budgets) != 2 || before.Budgets[1].Parent != "Investments" {
+		t.Errorf("expected the child budget to name its parent, got %+v", before.Budgets)
+	}
+	if goal := before.SavingsGoals[0]; goal.ContributionTemplate == nil || before.TransactionTemplates[*goal.ContributionTemplate-1].ToWallet != "Tagesgeld" {
+		t.Errorf("expected the savings goal to point at its contribution template, got %+v", goal)
+	}
+	if before.Trades[0].WalletTransaction == nil || before.Trades[0].FeesInCents != 150 {
+		t.Errorf("expected the trade to keep its wallet transaction and fees, got %+v", before.Trades[0])
+	}
+	if repayment := before.Transactions[2]; repayment.Counterparty != "Anna" || repayment.RepaymentOf == nil || *repayment.RepaymentOf != before.Transactions[1].Ref {
+		t.Errorf("expected the repayment to point at its debt, got %+v", repayment)
+	}
+	if lent := before.Transactions[1]; len(before.Reconciliations) != 1 || lent.Reconciliation == nil || *lent.Reconciliation != 1 || !lent.Cleared {
+		t.Errorf("expected the debt to be locked by the exported reconciliation, got %+v and %+v", before.Reconciliations, lent)
+	}
+	balanceBefore := f.walletBalance(t)
+
+	if err := importSvc.DeleteAllUserData(f.userID); err != nil {
+		t.Fatalf("delete failed: %v", err)
+	}
+	result, err := importSvc.ImportAccount(f.userID, before)
+	if err != nil {
+		t.Fatalf("import failed: %v", err)
+	}
+	if result.Created != 4 {
+		t.Errorf("expected 4 transactions to be created, got %+v", result)
+	}
+
+	after, err := importSvc.ExportAccount(f.userID)
+	if err != nil {
+		t.Fatalf("second export failed: %v", err)
+	}
+	if !reflect.DeepEqual(withoutIDs(before), withoutIDs(after)) {
+		t.Errorf("expected the account to survive the round trip\nbefore: %+v\nafter:  %+v", withoutIDs(before), withoutIDs(after))
+	}
+
+	wallets, _ := f.repos.WalletRepository().FindWalletsByUser(f.userID)
+	if len(wallets) != 2 || wallets[0].BalanceCents != balanceBefore {
+		t.Fatalf("expected the balances to be rebuilt, got %+v (want %d on the first wallet)", wallets, balanceBefore)
+	}
+	reconciliations, _ := f.repos.ReconciliationRepository().FindReconciliationsByWallet(wallets[1].ID)
+	if len(reconciliations) != 1 || reconciliations[0].Status != domain.ReconciliationCompleted {
+		t.Errorf("expected the completed reconciliation to be restored, got %+v", reconciliations)
+	}
+}
+
+func TestImportAccount_RefusesAnAccountWithData(t *testing.T) {
+	f := newStockFixture(t)
+	seedAccount(t, f)
+	importSvc := NewImportService(f.repos, noBudgetAlerts{})
+
+	export, err := importSvc.ExportAccount(f.userID)
+	if err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+	if _, err := importSvc.ImportAccount(f.userID, export); !errors.Is(err, domain.ErrAccountNotEmpty) {
+		t.Errorf("expected ErrAccountNotEmpty, got %v", err)
+	}
+
+	export.Version = domain.AccountExportVersion + 1
+	if _, err := importSvc.ImportAccount(f.userID, export); !errors.Is(err, domain.ErrUnsupportedExportVersion) {
+		t.Errorf("expected ErrUnsupportedExportVersion, got %v", err)
+	}
+}
+
+func TestImportAccount_InvalidExportLeavesNoTrace(t *testing.T) {
+	f := newStockFixture(t)
+	seedAccount(t, f)
+	importSvc := NewImportService(f.repos, noBudgetAlerts{})
+
+	export, err := importSvc.ExportAccount(f.userID)
+	if err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+	if err := importSvc.DeleteAllUserData(f.userID); err != nil {
+		t.Fatalf("delete failed: %v", err)
+	}
+
+	export.TransactionTemplates[0].Wallet = "Unbekannt"
+	if _, err := importSvc.ImportAccount(f.userID, export); !errors.Is(err, domain.ErrInvalidAccountExport) {
+		t.Fatalf("expected ErrInvalidAccountExport, got %v", err)
+	}
+	if wallets, _ := f.repos.WalletRepository().FindWalletsByUser(f.userID); len(wallets) != 0 {
+		t.Errorf("expected no wallets after the failed import, got %d", len(wallets))
+	}
+	if count := f.transactionCount(t); count != 0 {
+		t.Errorf("expected no transactions after the failed import, got %d", count)
+	}
+}
+
+func TestImportAccount_RefusesLockingAnUnclearedTransaction(t *testing.T) {
+	f := newStockFixture(t)
+	seedAccount(t, f)
+	importSvc := NewImportService(f.repos, noBudgetAlerts{})
+
+	export, err := importSvc.ExportAccount(f.userID)
+	if err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+	if err := importSvc.DeleteAllUserData(f.userID); err != nil {
+		t.Fatalf("delete failed: %v", err)
+	}
+
+	export.Transactions[1].Cleared = false
+	if _, err := importSvc.ImportAccount(f.userID, export); !errors.Is(err, domain.ErrInvalidAccountExport) {
+		t.Fatalf("expected ErrInvalidAccountExport, got %v", err)
+	}
+}
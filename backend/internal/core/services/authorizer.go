@@ -0,0 +1,75 @@
+package services
+
+import (
+	"github.com/fim-lab/expense-tracker/internal/core/domain"
+	"github.com/fim-lab/expense-tracker/internal/core/ports"
+)
+
+type authorizer struct {
+	repos ports.Repositories
+}
+
+// NewAuthorizer checks against the repositories it is given, so within a unit
+// of work it is made from the repositories of that unit.
+func NewAuthorizer(repos ports.Repositories) ports.Authorizer {
+	return &authorizer{repos: repos}
+}
+
+func (a *authorizer) Wallet(userID int, id int, need domain.Permission) (domain.Wallet, error) {
+	wallet, err := a.repos.WalletRepository().GetWalletByID(id)
+	if err != nil {
+		return domain.Wallet{}, err
+	}
+	if wallet.Permission, err = a.permit(userID, domain.ResourceWallet, id, wallet.UserID, need); err != nil {
+		return domain.Wallet{}, err
+	}
+	return wallet, nil
+}
+
+func (a *authorizer) Budget(userID int, id int, need domain.Permission) (domain.Budget, error) {
+	budget, err := a.repos.BudgetRepository().GetBudgetByID(id)
+	if err != nil {
+		return domain.Budget{}, err
+	}
+	if budget.Permission, err = a.permit(userID, domain.ResourceBudget, id, budget.UserID, need); err != nil {
+		return domain.Budget{}, err
+	}
+	return budget, nil
+}
+
+func (a *authorizer) Depot(userID int, id int, need domain.Permission) (domain.Depot, error) {
+	depot, err := a.repos.DepotRepository().GetDepotByID(id)
+	if err != nil {
+		return domain.Depot{}, err
+	}
+	if depot.Permission, err = a.permit(userID, domain.ResourceDepot, id, depot.UserID, need); err != nil {
+		return domain.Depot{}, err
+	}
+	return depot, nil
+}
+
+// Transaction lets the owner of the transaction do anything with it, and
+// members of its wallet what they may do with the wallet.
+func (a *authorizer) Transaction(userID int, id int, need domain.Permission) (domain.Transaction, error) {
+	t, err := a.repos.TransactionRepository().GetTransactionByID(id)
+	if err != nil {
+		return domain.Transaction{}, err
+	}
+	if _, err := a.permit(userID, domain.ResourceWallet, t.WalletID, t.UserID, need); err != nil {
+		return domain.Transaction{}, err
+	}
+	return t, nil
+}
+
+// permit works out what the user may do with the resource and whether that
+// is enough.
+func (a *authorizer) permit(userID int, resourceType domain.ResourceType, id int, ownerID int, need domain.Permission) (domain.Permission, error) {
+	if ownerID == userID {
+		return domain.PermissionOwner, nil
+	}
+	m, err := a.repos.MembershipRepository().GetMembership(resourceType, id, userID)
+	if err != nil || !m.Permission.Allows(need) {
+		return "", domain.ErrUnauthorized
+	}
+	return m.Permission, nil
+}
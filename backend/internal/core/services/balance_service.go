@@ -0,0 +1,108 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/fim-lab/expense-tracker/internal/core/domain"
+	"github.com/fim-lab/expense-tracker/internal/core/ports"
+)
+
+type balanceService struct {
+	repos ports.Repositories
+}
+
+func NewBalanceService(repos ports.Repositories) ports.BalanceService {
+	return &balanceService{repos: repos}
+}
+
+// CheckBalances rebuilds the balances of every user's wallets and budgets
+// from their transactions and budget movements. Each user is checked, and
+// with repair fixed, within one transaction that locks their wallets and
+// budgets first, so no booking changes a balance between reading the ledger
+// and overwriting it.
+func (s *balanceService) CheckBalances(repair bool) (domain.BalanceIntegrityReport, error) {
+	report := domain.BalanceIntegrityReport{CheckedAt: time.Now(), Drifts: []domain.BalanceDrift{}, Repaired: repair}
+	users, err := s.repos.UserRepository().FindAllUsers()
+	if err != nil {
+		return domain.BalanceIntegrityReport{}, fmt.Errorf("failed to fetch users: %w", err)
+	}
+
+	for _, u := range users {
+		err := s.repos.WithinTransaction(func(repos ports.Repositories) error {
+			if err := repos.BudgetRepository().LockBudgetsByUser(u.ID); err != nil {
+				return err
+			}
+			if err := repos.WalletRepository().LockWalletsByUser(u.ID); err != nil {
+				return err
+			}
+			drifts, err := checkUserBalances(repos, u.ID, &report)
+			if err != nil {
+				return err
+			}
+			if repair {
+				if err := repairBalances(repos, drifts); err != nil {
+					return err
+				}
+			}
+			report.Drifts = append(report.Drifts, drifts...)
+			return nil
+		})
+		if err != nil {
+			return domain.BalanceIntegrityReport{}, fmt.Errorf("checking the balances of user %d failed: %w", u.ID, err)
+		}
+	}
+	return report, nil
+}
+
+func repairBalances(repos ports.Repositories, drifts []domain.BalanceDrift) error {
+	for _, d := range drifts {
+		var err error
+		if d.Kind == domain.WalletBalanceKind {
+			err = repos.WalletRepository().SetWalletBalance(d.ID, d.LedgerCents)
+		} else {
+			err = repos.BudgetRepository().SetBudgetBalance(d.ID, d.LedgerCents)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to repair the balance of %s %d: %w", d.Kind, d.ID, err)
+		}
+	}
+	return nil
+}
+
+// checkUserBalances compares the stored balances of the user's wallets and
+// budgets with their ledgers and counts them as checked in the report.
+func checkUserBalances(repos ports.Repositories, userID int, report *domain.BalanceIntegrityReport) ([]domain.BalanceDrift, error) {
+	transactions, err := repos.TransactionRepository().FindAllTransactionsByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	movements, err := repos.BudgetRepository().FindMovementsByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	wallets, err := repos.WalletRepository().FindWalletsByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	budgets, err := repos.BudgetRepository().FindBudgetsByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	walletLedger, budgetLedger := domain.LedgerBalances(transactions, movements)
+
+	var drifts []domain.BalanceDrift
+	for _, w := range wallets {
+		if w.BalanceCents != walletLedger[w.ID] {
+			drifts = append(drifts, domain.BalanceDrift{Kind: domain.WalletBalanceKind, ID: w.ID, UserID: userID, Name: w.Name, StoredCents: w.BalanceCents, LedgerCents: walletLedger[w.ID], DifferenceCents: w.BalanceCents - walletLedger[w.ID]})
+		}
+	}
+	for _, b := range budgets {
+		if b.BalanceCents != budgetLedger[b.ID] {
+			drifts = append(drifts, domain.BalanceDrift{Kind: domain.BudgetBalanceKind, ID: b.ID, UserID: userID, Name: b.Name, StoredCents: b.BalanceCents, LedgerCents: budgetLedger[b.ID], DifferenceCents: b.BalanceCents - budgetLedger[b.ID]})
+		}
+	}
+	report.WalletsChecked += len(wallets)
+	report.BudgetsChecked += len(budgets)
+	return drifts, nil
+}
@@ -0,0 +1,62 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fim-lab/expense-tracker/internal/core/domain"
+)
+
+func TestCheckBalances_FindsAndRepairsDrift(t *testing.T) {
+	f := newWalletFixture(t)
+	budgetID := 2
+	if err := f.repos.UserRepository().SaveUser(domain.User{ID: f.userID, Username: "demo"}); err != nil {
+		t.Fatalf("could not seed the user: %v", err)
+	}
+	if err := f.repos.BudgetRepository().SaveBudget(domain.Budget{ID: budgetID, UserID: f.userID, Name: "Lebensmittel"}); err != nil {
+		t.Fatalf("could not seed the budget: %v", err)
+	}
+	for _, tx := range []domain.Transaction{
+		{ID: 11, Date: time.Now(), WalletID: f.walletID, Description: "Gehalt", AmountInCents: 1000, Type: domain.Income},
+		{ID: 12, Date: time.Now(), WalletID: f.walletID, BudgetID: &budgetID, Description: "Einkauf", AmountInCents: 300, Type: domain.Expense},
+	} {
+		if _, err := f.txSvc.CreateTransaction(f.userID, tx); err != nil {
+			t.Fatalf("could not book %q: %v", tx.Description, err)
+		}
+	}
+	if err := f.repos.BudgetRepository().SaveMovements([]domain.BudgetMovement{{UserID: f.userID, Date: time.Now(), ToBudgetID: &budgetID, AmountInCents: 500}}); err != nil {
+		t.Fatalf("could not assign to the budget: %v", err)
+	}
+
+	svc := NewBalanceService(f.repos)
+	if report, err := svc.CheckBalances(false); err != nil || len(report.Drifts) != 0 || report.WalletsChecked != 1 || report.BudgetsChecked != 1 {
+		t.Fatalf("expected the balances to match their ledger, got %+v (%v)", report, err)
+	}
+
+	// Balances edited behind the ledger's back, like by hand in the database.
+	f.repos.WalletRepository().SetWalletBalance(f.walletID, 9999)
+	f.repos.BudgetRepository().SetBudgetBalance(budgetID, 0)
+
+	report, err := svc.CheckBalances(false)
+	if err != nil {
+		t.Fatalf("checking failed: %v", err)
+	}
+	if len(report.Drifts) != 2 || report.Drifts[0].LedgerCents != 700 || report.Drifts[0].DifferenceCents != 9299 || report.Drifts[1].LedgerCents != 200 || report.Drifts[1].DifferenceCents != -200 {
+		t.Fatalf("expected the wallet and the budget to have drifted, got %+v", report.Drifts)
+	}
+	if w, _ := f.repos.WalletRepository().GetWalletByID(f.walletID); w.BalanceCents != 9999 {
+		t.Errorf("expected a check without repair to leave the balance, got %d", w.BalanceCents)
+	}
+
+	if report, err := svc.CheckBalances(true); err != nil || len(report.Drifts) != 2 || !report.Repaired {
+		t.Fatalf("expected both balances to be repaired, got %+v (%v)", report, err)
+	}
+	w, _ := f.repos.WalletRepository().GetWalletByID(f.walletID)
+	b, _ := f.repos.BudgetRepository().GetBudgetByID(budgetID)
+	if w.BalanceCents != 700 || b.BalanceCents != 200 {
+		t.Errorf("expected the balances to be set to their ledger, got %d and %d", w.BalanceCents, b.BalanceCents)
+	}
+	if report, _ := svc.CheckBalances(false); len(report.Drifts) != 0 {
+		t.Errorf("expected no drift after the repair, got %+v", report.Drifts)
+	}
+}
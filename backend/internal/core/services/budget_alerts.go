@@ -0,0 +1,261 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/fim-lab/expense-tracker/internal/core/domain"
+	"github.com/fim-lab/expense-tracker/internal/core/ports"
+)
+
+// alertQueueSize is how many alerts may wait to be raised. Notifiers bound
+// every delivery themselves, so the queue only fills up if they keep timing
+// out; alerts raised then are dropped.
+const alertQueueSize = 100
+
+// budgetAlertService raises the alerts in the background, one after the
+// other, as the changes behind them are done by then and failing to raise an
+// alert is only logged.
+type budgetAlertService struct {
+	transactionRepo ports.TransactionRepository
+	budgetRepo      ports.BudgetRepository
+	notifications   ports.NotificationService
+	alerts          chan domain.Notification
+	raising         sync.WaitGroup // Alerts queued and not raised yet
+}
+
+// NewBudgetAlertService starts the worker raising the alerts.
+func NewBudgetAlertService(transactionRepo ports.TransactionRepository, budgetRepo ports.BudgetRepository, notifications ports.NotificationService) ports.BudgetAlertService {
+	s := &budgetAlertService{
+		transactionRepo: transactionRepo,
+		budgetRepo:      budgetRepo,
+		notifications:   notifications,
+		alerts:          make(chan domain.Notification, alertQueueSize),
+	}
+	go s.raiseQueuedAlerts()
+	return s
+}
+
+// periodChange is what a change added to the spending and to the money
+// available of one budget period.
+type periodChange struct {
+	spent     int
+	available int
+}
+
+func (s *budgetAlertService) TransactionsChanged(before, after []domain.Transaction) {
+	changes := make(map[int]map[time.Time]periodChange)
+	book := func(transactions []domain.Transaction, sign int) {
+		for _, t := range transactions {
+			for budgetID, amount := range t.BudgetAmounts() {
+				if changes[budgetID] == nil {
+					changes[budgetID] = make(map[time.Time]periodChange)
+				}
+				date := domain.DateOf(t.Date)
+				change := changes[budgetID][date]
+				change.spent -= sign * amount
+				changes[budgetID][date] = change
+			}
+		}
+	}
+	book(before, -1)
+	book(after, 1)
+	s.check(changes)
+}
+
+func (s *budgetAlertService) MovementsSaved(movements []domain.BudgetMovement) {
+	changes := make(map[int]map[time.Time]periodChange)
+	for _, m := range movements {
+		if m.FromBudgetID == nil {
+			continue
+		}
+		if changes[*m.FromBudgetID] == nil {
+			changes[*m.FromBudgetID] = make(map[time.Time]periodChange)
+		}
+		date := domain.DateOf(m.Date)
+		change := changes[*m.FromBudgetID][date]
+		change.available -= m.AmountInCents
+		changes[*m.FromBudgetID][date] = change
+	}
+	s.check(changes)
+}
+
+// check alerts every threshold the budgets reach now but had not reached
+// without the changes, which are given per budget and day.
+func (s *budgetAlertService) check(changes map[int]map[time.Time]periodChange) {
+	budgetIDs := make([]int, 0, len(changes))
+	for budgetID := range changes {
+		budgetIDs = append(budgetIDs, budgetID)
+	}
+	slices.Sort(budgetIDs)
+
+	for _, budgetID := range budgetIDs {
+		budget, err := s.budgetRepo.GetBudgetByID(budgetID)
+		if err != nil || len(budget.AlertThresholds) == 0 {
+			continue
+		}
+		budget.ApplyDefaults()
+
+		perPeriod := make(map[time.Time]periodChange)
+		var starts []time.Time
+		for date, change := range changes[budgetID] {
+			start := domain.PeriodStart(budget.Period, date)
+			if _, ok := perPeriod[start]; !ok {
+				starts = append(starts, start)
+			}
+			total := perPeriod[start]
+			total.spent += change.spent
+			total.available += change.available
+			perPeriod[start] = total
+		}
+		slices.SortFunc(starts, time.Time.Compare)
+
+		for _, start := range starts {
+			change := perPeriod[start]
+			if change == (periodChange{}) {
+				continue
+			}
+			after, err := s.usage(budget, start)
+			if err != nil {
+				log.Printf("Could not check the alert thresholds of budget %d: %v", budget.ID, err)
+				continue
+			}
+			before := domain.BudgetUsage{AvailableCents: after.AvailableCents - change.available, SpentCents: after.SpentCents - change.spent}
+			for _, threshold := range budget.CrossedThresholds(before, after) {
+				s.queue(domain.Notification{
+					UserID:   budget.UserID,
+					Type:     domain.NotificationBudgetThreshold,
+					Subject:  fmt.Sprintf("Budget %s reached %d%% of its limit", budget.Name, threshold),
+					Message:  fmt.Sprintf("%s of %s spent in the period starting %s.", formatCents(after.SpentCents), formatCents(after.AvailableCents), start.Format("2006-01-02")),
+					BudgetID: &budgetID,
+				})
+			}
+		}
+	}
+}
+
+// usage is what the budget has to spend in the period starting on start and
+// what its transactions in the period spent, less what they brought in. Only
+// the bookings of the period are read.
+func (s *budgetAlertService) usage(budget domain.Budget, start time.Time) (domain.BudgetUsage, error) {
+	end := domain.NextPeriodStart(budget.Period, start)
+	bookings, err := s.transactionRepo.FindBudgetBookingsBetween(budget.ID, start, end)
+	if err != nil {
+		return domain.BudgetUsage{}, err
+	}
+	allocations, err := s.budgetRepo.FindAllocationsByBudget(budget.ID)
+	if err != nil {
+		return domain.BudgetUsage{}, err
+	}
+	movements, err := s.budgetRepo.FindMovementsByBudget(budget.ID)
+	if err != nil {
+		return domain.BudgetUsage{}, err
+	}
+
+	usage := domain.BudgetUsage{AvailableCents: budget.AllocatedIn(start, allocations)}
+	for _, booking := range bookings {
+		usage.SpentCents -= booking.AmountInCents
+	}
+	for _, m := range movements {
+		if date := domain.DateOf(m.Date); !date.Before(start) && date.Before(end) {
+			usage.AvailableCents += m.AmountOnBudget(budget.ID)
+		}
+	}
+	return usage, nil
+}
+
+func (s *budgetAlertService) queue(alert domain.Notification) {
+	s.raising.Add(1)
+	select {
+	case s.alerts <- alert:
+	default:
+		s.raising.Done()
+		log.Printf("Too many budget alerts queued, dropped %q of budget %d", alert.Subject, *alert.BudgetID)
+	}
+}
+
+func (s *budgetAlertService) raiseQueuedAlerts() {
+	for alert := range s.alerts {
+		if err := s.notifications.Notify(alert); err != nil {
+			log.Printf("Could not raise the alert %q of budget %d: %v", alert.Subject, *alert.BudgetID, err)
+		}
+		s.raising.Done()
+	}
+}
+
+// budgetAlertingTransactionService tells the budget alerts about every
+// transaction created, updated or deleted, whoever booked it. Everything
+// else is left to the wrapped service.
+type budgetAlertingTransactionService struct {
+	ports.TransactionService
+	transactionRepo ports.TransactionRepository
+	alerts          ports.BudgetAlertService
+}
+
+func NewBudgetAlertingTransactionService(transactions ports.TransactionService, transactionRepo ports.TransactionRepository, alerts ports.BudgetAlertService) ports.TransactionService {
+	return &budgetAlertingTransactionService{
+		TransactionService: transactions,
+		transactionRepo:    transactionRepo,
+		alerts:             alerts,
+	}
+}
+
+func (s *budgetAlertingTransactionService) CreateTransaction(userID int, t domain.Transaction) (int, error) {
+	id, err := s.TransactionService.CreateTransaction(userID, t)
+	if err != nil {
+		return 0, err
+	}
+	s.alerts.TransactionsChanged(nil, []domain.Transaction{t})
+	return id, nil
+}
+
+func (s *budgetAlertingTransactionService) UpdateTransaction(userID int, t domain.Transaction) error {
+	var before []domain.Transaction
+	if existing, err := s.transactionRepo.GetTransactionByID(t.ID); err == nil {
+		before = append(before, existing)
+	}
+	if err := s.TransactionService.UpdateTransaction(userID, t); err != nil {
+		return err
+	}
+	s.alerts.TransactionsChanged(before, []domain.Transaction{t})
+	return nil
+}
+
+func (s *budgetAlertingTransactionService) DeleteTransaction(userID int, id int) error {
+	var before []domain.Transaction
+	if existing, err := s.transactionRepo.GetTransactionByID(id); err == nil {
+		before = append(before, existing)
+	}
+	if err := s.TransactionService.DeleteTransaction(userID, id); err != nil {
+		return err
+	}
+	s.alerts.TransactionsChanged(before, nil)
+	return nil
+}
+
+// bookedChanges collects the transactions a unit of work books, as they were
+// before and are after it, to tell the budget alerts once the unit is done.
+type bookedChanges struct {
+	before []domain.Transaction
+	after  []domain.Transaction
+}
+
+func (c *bookedChanges) created(t domain.Transaction) {
+	c.after = append(c.after, t)
+}
+
+func (c *bookedChanges) updated(before, after domain.Transaction) {
+	c.before = append(c.before, before)
+	c.after = append(c.after, after)
+}
+
+func formatCents(cents int) string {
+	sign := ""
+	if cents < 0 {
+		sign, cents = "-", -cents
+	}
+	return fmt.Sprintf("%s%d.%02d €", sign, cents/100, cents%100)
+}
@@ -0,0 +1,206 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/fim-lab/expense-tracker/adapters/repository/memory"
+	"github.com/fim-lab/expense-tracker/internal/core/domain"
+	"github.com/fim-lab/expense-tracker/internal/core/ports"
+)
+
+type recordingNotifier struct {
+	delivered []domain.Notification
+}
+
+func (n *recordingNotifier) Deliver(user domain.User, notification domain.Notification) error {
+	n.delivered = append(n.delivered, notification)
+	return nil
+}
+
+// noBudgetAlerts stands in for the budget alerts where a test is not about
+// them.
+type noBudgetAlerts struct{}
+
+func (noBudgetAlerts) TransactionsChanged(before, after []domain.Transaction) {}
+
+func (noBudgetAlerts) MovementsSaved(movements []domain.BudgetMovement) {}
+
+// waitForAlerts waits for the alerts the service raises in the background.
+func waitForAlerts(alerts ports.BudgetAlertService) {
+	alerts.(*budgetAlertService).raising.Wait()
+}
+
+func TestBudgetAlerts_RaisedWhenAThresholdIsCrossed(t *testing.T) {
+	repos := memory.NewCleanRepositories()
+	notifier := &recordingNotifier{}
+	notificationSvc := NewNotificationService(repos.NotificationRepository(), repos.UserRepository(), notifier)
+	alerts := NewBudgetAlertService(repos.TransactionRepository(), repos.BudgetRepository(), notificationSvc)
+	svc := NewBudgetAlertingTransactionService(
+		NewTransactionService(repos.TransactionRepository(), NewAuthorizer(repos)),
+		repos.TransactionRepository(), alerts)
+
+	repos.UserRepository().SaveUser(domain.User{ID: 1, Username: "anna"})
+	repos.WalletRepository().SaveWallet(domain.Wallet{ID: 2, UserID: 1, Name: "Girokonto"})
+	repos.BudgetRepository().SaveBudget(domain.Budget{ID: 3, UserID: 1, Name: "Freizeit", LimitCents: 10000, AlertThresholds: []int{80, 100}})
+	budgetID := 3
+	expense := func(id, amount int) domain.Transaction {
+		return domain.Transaction{ID: id, Date: onDate(2026, 4, 10), BudgetID: &budgetID, WalletID: 2, Description: "Kino", AmountInCents: amount, Type: domain.Expense}
+	}
+	subjects := func() []string {
+		waitForAlerts(alerts)
+		var subjects []string
+		for _, n := range notifier.delivered {
+			subjects = append(subjects, n.Subject)
+		}
+		return subjects
+	}
+
+	if _, err := svc.CreateTransaction(1, expense(10, 7000)); err != nil {
+		t.Fatalf("creating failed: %v", err)
+	}
+	waitForAlerts(alerts)
+	if len(notifier.delivered) != 0 {
+		t.Fatalf("expected no alert below 80%%, got %v", subjects())
+	}
+
+	if _, err := svc.CreateTransaction(1, expense(11, 1500)); err != nil {
+		t.Fatalf("creating failed: %v", err)
+	}
+	if err := svc.UpdateTransaction(1, expense(11, 3500)); err != nil {
+		t.Fatalf("updating failed: %v", err)
+	}
+	want := []string{"Budget Freizeit reached 80% of its limit", "Budget Freizeit reached 100% of its limit"}
+	if !reflect.DeepEqual(subjects(), want) {
+		t.Fatalf("expected %v, got %v", want, subjects())
+	}
+
+	if err := svc.DeleteTransaction(1, 11); err != nil {
+		t.Fatalf("deleting failed: %v", err)
+	}
+	if _, err := svc.CreateTransaction(1, expense(12, 1000)); err != nil {
+		t.Fatalf("creating failed: %v", err)
+	}
+	waitForAlerts(alerts)
+	if len(notifier.delivered) != 3 || notifier.delivered[2].Subject != want[0] {
+		t.Fatalf("expected the 80%% alert again after falling back below it, got %v", subjects())
+	}
+
+	inbox, err := notificationSvc.GetNotifications(1, true)
+	if err != nil || len(inbox) != 3 {
+		t.Fatalf("expected three unread notifications, got %+v (%v)", inbox, err)
+	}
+	if inbox[0].Message != "80.00 € of 100.00 € spent in the period starting 2026-04-01." || *inbox[0].BudgetID != 3 {
+		t.Errorf("expected the newest alert first, got %+v", inbox[0])
+	}
+	if err := notificationSvc.MarkRead(2, inbox[0].ID); err == nil {
+		t.Error("expected another user not to mark the notification read")
+	}
+	if err := notificationSvc.MarkRead(1, inbox[0].ID); err != nil {
+		t.Fatalf("marking read failed: %v", err)
+	}
+	if unread, _ := notificationSvc.GetNotifications(1, true); len(unread) != 2 {
+		t.Errorf("expected two unread notifications left, got %d", len(unread))
+	}
+}
+
+func TestBudgetAlerts_ThresholdsOfThePeriodsAllocation(t *testing.T) {
+	repos := memory.NewCleanRepositories()
+	notifier := &recordingNotifier{}
+	alerts := NewBudgetAlertService(repos.TransactionRepository(), repos.BudgetRepository(),
+		NewNotificationService(repos.NotificationRepository(), repos.UserRepository(), notifier))
+	svc := NewBudgetAlertingTransactionService(
+		NewTransactionService(repos.TransactionRepository(), NewAuthorizer(repos)),
+		repos.TransactionRepository(), alerts)
+
+	repos.UserRepository().SaveUser(domain.User{ID: 1, Username: "anna"})
+	repos.WalletRepository().SaveWallet(domain.Wallet{ID: 2, UserID: 1, Name: "Girokonto"})
+	repos.BudgetRepository().SaveBudget(domain.Budget{ID: 3, UserID: 1, Name: "Urlaub", LimitCents: 10000, AlertThresholds: []int{80}})
+	repos.BudgetRepository().SaveAllocation(domain.BudgetAllocation{BudgetID: 3, PeriodStart: onDate(2026, 4, 1), AmountInCents: 20000})
+	budgetID := 3
+	expense := func(date time.Time, amount int) domain.Transaction {
+		return domain.Transaction{Date: date, BudgetID: &budgetID, WalletID: 2, Description: "Hotel", AmountInCents: amount, Type: domain.Expense}
+	}
+
+	if _, err := svc.CreateTransaction(1, expense(onDate(2026, 4, 10), 10000)); err != nil {
+		t.Fatalf("creating failed: %v", err)
+	}
+	waitForAlerts(alerts)
+	if len(notifier.delivered) != 0 {
+		t.Fatalf("expected no alert at half of April's allocation, got %+v", notifier.delivered)
+	}
+	if _, err := svc.CreateTransaction(1, expense(onDate(2026, 4, 11), 6000)); err != nil {
+		t.Fatalf("creating failed: %v", err)
+	}
+	if _, err := svc.CreateTransaction(1, expense(onDate(2026, 5, 11), 8000)); err != nil {
+		t.Fatalf("creating failed: %v", err)
+	}
+	waitForAlerts(alerts)
+	want := []string{
+		"160.00 € of 200.00 € spent in the period starting 2026-04-01.",
+		"80.00 € of 100.00 € spent in the period starting 2026-05-01.",
+	}
+	var got []string
+	for _, n := range notifier.delivered {
+		got = append(got, n.Message)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected April's alert against its allocation and May's against the limit, got %v", got)
+	}
+}
+
+func TestBudgetAlerts_RaisedForImportsAndTransfers(t *testing.T) {
+	repos := memory.NewCleanRepositories()
+	notifier := &recordingNotifier{}
+	alerts := NewBudgetAlertService(repos.TransactionRepository(), repos.BudgetRepository(),
+		NewNotificationService(repos.NotificationRepository(), repos.UserRepository(), notifier))
+	budgetSvc := NewBudgetService(repos, alerts)
+	importSvc := NewImportService(repos, alerts)
+
+	repos.UserRepository().SaveUser(domain.User{ID: 1, Username: "anna"})
+	repos.WalletRepository().SaveWallet(domain.Wallet{ID: 2, UserID: 1, Name: "Girokonto"})
+	repos.BudgetRepository().SaveBudget(domain.Budget{ID: 3, UserID: 1, Name: "Lebensmittel", LimitCents: 10000, AlertThresholds: []int{80, 100}})
+	repos.BudgetRepository().SaveBudget(domain.Budget{ID: 4, UserID: 1, Name: "Freizeit", LimitCents: 10000})
+	today := domain.DateOf(time.Now())
+	messages := func() []string {
+		waitForAlerts(alerts)
+		var messages []string
+		for _, n := range notifier.delivered {
+			messages = append(messages, n.Message)
+		}
+		return messages
+	}
+
+	_, err := importSvc.ImportData(1, domain.FullImportData{Transactions: []domain.ImportTransaction{
+		{Date: today, Budget: "Lebensmittel", Wallet: "Girokonto", Description: "REWE", AmountInCents: 5000, Type: string(domain.Expense)},
+		{Date: today, Budget: "Lebensmittel", Wallet: "Girokonto", Description: "EDEKA", AmountInCents: 4000, Type: string(domain.Expense)},
+	}})
+	if err != nil {
+		t.Fatalf("importing failed: %v", err)
+	}
+	period := domain.PeriodStart(domain.Monthly, today).Format("2006-01-02")
+	want := []string{"90.00 € of 100.00 € spent in the period starting " + period + "."}
+	if got := messages(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected the import to cross 80%%, got %v", got)
+	}
+
+	if err := budgetSvc.TransferBetweenBudgets(1, 4, 3, 2000, "Grillen"); err != nil {
+		t.Fatalf("transferring onto the budget failed: %v", err)
+	}
+	if got := messages(); len(got) != 1 {
+		t.Fatalf("expected no alert for money moved onto the budget, got %v", got)
+	}
+	if err := budgetSvc.TransferBetweenBudgets(1, 3, 4, 3000, "Kino"); err != nil {
+		t.Fatalf("transferring off the budget failed: %v", err)
+	}
+	var subjects []string
+	waitForAlerts(alerts)
+	for _, n := range notifier.delivered[1:] {
+		subjects = append(subjects, n.Subject)
+	}
+	want = []string{"Budget Lebensmittel reached 80% of its limit", "Budget Lebensmittel reached 100% of its limit"}
+	if !reflect.DeepEqual(subjects, want) {
+		t.Errorf("expected moving money off the budget to cross 80%% and 100%% again, got %v", subjects)
+	}
+}
@@ -1,83 +1,226 @@
 package services
 
 import (
+	"fmt"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/fim-lab/expense-tracker/internal/core/domain"
 	"github.com/fim-lab/expense-tracker/internal/core/ports"
 )
 
 type budgetService struct {
+	repos           ports.Repositories
 	budgetRepo      ports.BudgetRepository
 	transactionRepo ports.TransactionRepository
+	membershipRepo  ports.MembershipRepository
+	auth            ports.Authorizer
+	alerts          ports.BudgetAlertService
 }
 
-func NewBudgetService(budgetRepo ports.BudgetRepository, transactionRepo ports.TransactionRepository) ports.BudgetService {
-	return &budgetService{budgetRepo: budgetRepo, transactionRepo: transactionRepo}
+// NewBudgetService takes the repositories as a whole, as moving money onto
+// or between budgets checks and saves in one unit of work.
+func NewBudgetService(repos ports.Repositories, alerts ports.BudgetAlertService) ports.BudgetService {
+	return &budgetService{
+		repos:           repos,
+		budgetRepo:      repos.BudgetRepository(),
+		transactionRepo: repos.TransactionRepository(),
+		membershipRepo:  repos.MembershipRepository(),
+		auth:            NewAuthorizer(repos),
+		alerts:          alerts,
+	}
 }
 
 func (s *budgetService) CreateBudget(userID int, b domain.Budget) error {
 	b.UserID = userID
+	b.Children = nil
 
 	if strings.TrimSpace(b.Name) == "" {
 		return domain.ErrMissingBudget
 	}
 
-	if b.LimitCents <= 0 {
+	// A budget may only group its children and have no limit of its own.
+	if b.LimitCents < 0 {
 		return domain.ErrInvalidAmount
 	}
 
+	b.ApplyDefaults()
+	if err := b.ValidatePeriod(); err != nil {
+		return err
+	}
+	if err := b.NormalizeAlertThresholds(); err != nil {
+		return err
+	}
+
+	if b.ParentID != nil {
+		budgets, err := s.budgetRepo.FindBudgetsByUser(userID)
+		if err != nil {
+			return err
+		}
+		if err := domain.ValidateBudgetParent(budgets, 0, b.ParentID); err != nil {
+			return err
+		}
+	}
+
 	return s.budgetRepo.SaveBudget(b)
 }
 
+// GetBudget returns the budget with the budgets below it.
 func (s *budgetService) GetBudget(userID int, id int) (domain.Budget, error) {
-	budget, err := s.budgetRepo.GetBudgetByID(id)
+	if _, err := s.auth.Budget(userID, id, domain.PermissionRead); err != nil {
+		return domain.Budget{}, err
+	}
+
+	tree, err := s.GetBudgets(userID)
 	if err != nil {
 		return domain.Budget{}, err
 	}
+	budget, ok := domain.FindInBudgetTree(tree, id)
+	if !ok {
+		return domain.Budget{}, domain.ErrBudgetNotFound
+	}
+	return budget, nil
+}
 
-	if budget.UserID != userID {
-		return domain.Budget{}, domain.ErrUnauthorized
+// GetBudgets returns the user's budget tree followed by the budgets others
+// shared with them, each at the top.
+func (s *budgetService) GetBudgets(userID int) ([]domain.Budget, error) {
+	now := time.Now()
+	budgets, err := s.budgetRepo.FindBudgetsByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range budgets {
+		budgets[i].Permission = domain.PermissionOwner
+	}
+	ledger, err := s.ledgerOf(userID, budgets, now)
+	if err != nil {
+		return nil, err
 	}
+	for i := range budgets {
+		ledger.describe(&budgets[i], now)
+	}
+	tree := domain.BudgetTree(budgets)
 
-	budget.CanDelete = true
-	if budget.BalanceCents != 0 {
-		budget.CanDelete = false
-	} else {
-		count, err := s.transactionRepo.CountTransactionsByBudgetID(budget.ID)
+	memberships, err := s.membershipRepo.FindMembershipsByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	var shared []domain.Budget
+	sharedByOwner := make(map[int][]domain.Budget)
+	for _, m := range memberships {
+		if m.ResourceType != domain.ResourceBudget {
+			continue
+		}
+		budget, err := s.budgetRepo.GetBudgetByID(m.ResourceID)
 		if err != nil {
-			return domain.Budget{}, err
+			return nil, err
 		}
-		if count > 0 {
-			budget.CanDelete = false
+		budget.Permission, budget.ParentID = m.Permission, nil
+		shared = append(shared, budget)
+		sharedByOwner[budget.UserID] = append(sharedByOwner[budget.UserID], budget)
+	}
+	ledgers := make(map[int]budgetLedger)
+	for ownerID, owned := range sharedByOwner {
+		if ledgers[ownerID], err = s.ledgerOf(ownerID, owned, now); err != nil {
+			return nil, err
 		}
 	}
+	for _, budget := range shared {
+		ledgers[budget.UserID].describe(&budget, now)
+		tree = append(tree, budget)
+	}
 
-	return budget, nil
+	return tree, nil
 }
 
-func (s *budgetService) GetBudgets(userID int) ([]domain.Budget, error) {
-	budgets, err := s.budgetRepo.FindBudgetsByUser(userID)
-	if err != nil {
-		return nil, err
+// budgetLedger holds what describing an owner's budgets takes, fetched for
+// all of them at once rather than budget by budget.
+type budgetLedger struct {
+	pending      map[int]int
+	bookings     map[int][]domain.BudgetBooking
+	movements    []domain.BudgetMovement
+	allocations  map[int][]domain.BudgetAllocation
+	transactions map[int]int
+}
+
+// ledgerOf fetches the ledger of the owner's budgets up to now. Budgets
+// starting every period afresh need only the bookings of their current
+// period; once one of them carries its balance over, all bookings are read.
+func (s *budgetService) ledgerOf(ownerID int, budgets []domain.Budget, now time.Time) (budgetLedger, error) {
+	from := now
+	for _, b := range budgets {
+		b.ApplyDefaults()
+		if b.Rollover != domain.RolloverReset {
+			from = time.Time{}
+			break
+		}
+		if start := domain.PeriodStart(b.Period, now); start.Before(from) {
+			from = start
+		}
 	}
 
-	for i := range budgets {
-		budgets[i].CanDelete = true
-		if budgets[i].BalanceCents != 0 {
-			budgets[i].CanDelete = false
-		} else {
-			count, err := s.transactionRepo.CountTransactionsByBudgetID(budgets[i].ID)
-			if err != nil {
-				return nil, err
-			}
-			if count > 0 {
-				budgets[i].CanDelete = false
-			}
+	var ledger budgetLedger
+	var err error
+	if _, ledger.pending, err = s.transactionRepo.SumPendingAmounts(ownerID); err != nil {
+		return budgetLedger{}, err
+	}
+	if ledger.bookings, err = s.transactionRepo.FindBudgetBookingsByUser(ownerID, from); err != nil {
+		return budgetLedger{}, err
+	}
+	if ledger.transactions, err = s.transactionRepo.CountTransactionsByBudget(ownerID); err != nil {
+		return budgetLedger{}, err
+	}
+	if ledger.movements, err = s.budgetRepo.FindMovementsByUser(ownerID); err != nil {
+		return budgetLedger{}, err
+	}
+	if ledger.allocations, err = s.budgetRepo.FindAllocationsByUser(ownerID); err != nil {
+		return budgetLedger{}, err
+	}
+	return ledger, nil
+}
+
+// describe fills in what its owner's pending transactions add to the budget,
+// whether the user may delete it and its current period.
+func (l budgetLedger) describe(budget *domain.Budget, now time.Time) {
+	budget.SplitPending(l.pending[budget.ID])
+	bookings := withMovements(l.bookings[budget.ID], l.movements, budget.ID)
+	moved := len(bookings) > len(l.bookings[budget.ID])
+	budget.CanDelete = budget.Permission == domain.PermissionOwner &&
+		budget.BalanceCents == 0 && l.transactions[budget.ID] == 0 && !moved
+
+	b := *budget
+	b.ApplyDefaults()
+	history := domain.BudgetHistory(b, bookings, l.allocations[budget.ID], now)
+	current := history[len(history)-1]
+	budget.CurrentPeriod = &current
+}
+
+// withMovements adds the movements onto or off the budget to its bookings.
+func withMovements(bookings []domain.BudgetBooking, movements []domain.BudgetMovement, budgetID int) []domain.BudgetBooking {
+	bookings = slices.Clip(bookings)
+	for _, m := range movements {
+		if amount := m.AmountOnBudget(budgetID); amount != 0 {
+			bookings = append(bookings, domain.BudgetBooking{Date: m.Date, AmountInCents: amount, Movement: true})
 		}
 	}
+	return bookings
+}
+
+func (s *budgetService) MoveBudget(userID int, id int, parentID *int) error {
+	if _, err := s.auth.Budget(userID, id, domain.PermissionOwner); err != nil {
+		return err
+	}
 
-	return budgets, nil
+	budgets, err := s.budgetRepo.FindBudgetsByUser(userID)
+	if err != nil {
+		return err
+	}
+	if err := domain.ValidateBudgetParent(budgets, id, parentID); err != nil {
+		return err
+	}
+	return s.budgetRepo.UpdateBudgetParent(id, parentID)
 }
 
 func (s *budgetService) GetTotalOfBudgets(userID int) (int, error) {
@@ -95,40 +238,227 @@ func (s *budgetService) GetTotalOfBudgets(userID int) (int, error) {
 }
 
 func (s *budgetService) UpdateBudget(userID int, budget domain.Budget) error {
-	existingBudget, err := s.budgetRepo.GetBudgetByID(budget.ID)
+	existingBudget, err := s.auth.Budget(userID, budget.ID, domain.PermissionWrite)
 	if err != nil {
 		return err
 	}
 
-	if existingBudget.UserID != userID {
-		return domain.ErrUnauthorized
-	}
-
 	if strings.TrimSpace(budget.Name) == "" {
 		return domain.ErrMissingDescription
 	}
-	if budget.LimitCents <= 0 {
+	if budget.LimitCents < 0 {
 		return domain.ErrInvalidAmount
 	}
+	if budget.Period == "" {
+		budget.Period = existingBudget.Period
+	}
+	if budget.Rollover == "" {
+		budget.Rollover = existingBudget.Rollover
+	}
+	if budget.AlertThresholds == nil {
+		budget.AlertThresholds = existingBudget.AlertThresholds
+	}
+	budget.ApplyDefaults()
+	if err := budget.ValidatePeriod(); err != nil {
+		return err
+	}
+	if err := budget.NormalizeAlertThresholds(); err != nil {
+		return err
+	}
 
 	return s.budgetRepo.UpdateBudget(budget)
 }
 
+// DeleteBudget deletes a budget nothing is booked on or moved to or from, so
+// no money goes with it. Budgets below it move up to its parent.
 func (s *budgetService) DeleteBudget(userID int, id int) error {
-	existing, err := s.budgetRepo.GetBudgetByID(id)
+	budget, err := s.auth.Budget(userID, id, domain.PermissionOwner)
 	if err != nil {
 		return err
 	}
-	if existing.UserID != userID {
-		return domain.ErrUnauthorized
-	}
-	transactionCount, err := s.transactionRepo.CountTransactionsByBudgetID(id)
+	empty, err := s.isEmpty(budget)
 	if err != nil {
 		return err
 	}
-	if transactionCount > 0 {
+	if !empty {
 		return domain.ErrNotEmpty
 	}
 
 	return s.budgetRepo.DeleteBudget(id)
 }
+
+// isEmpty tells whether the budget holds no money and nothing was ever
+// booked on it or moved to or from it.
+func (s *budgetService) isEmpty(budget domain.Budget) (bool, error) {
+	if budget.BalanceCents != 0 {
+		return false, nil
+	}
+	transactionCount, err := s.transactionRepo.CountTransactionsByBudgetID(budget.ID)
+	if err != nil || transactionCount > 0 {
+		return false, err
+	}
+	movements, err := s.budgetRepo.FindMovementsByBudget(budget.ID)
+	if err != nil {
+		return false, err
+	}
+	return len(movements) == 0, nil
+}
+
+// GetBudgetHistory returns the budget's periods up to the one holding until,
+// oldest first.
+func (s *budgetService) GetBudgetHistory(userID int, id int, until time.Time) ([]domain.BudgetPeriodSummary, error) {
+	budget, err := s.auth.Budget(userID, id, domain.PermissionRead)
+	if err != nil {
+		return nil, err
+	}
+	return s.history(budget, until)
+}
+
+// SetAllocation gives the budget its own allocation for the period holding
+// PeriodStart, in place of its limit.
+func (s *budgetService) SetAllocation(userID int, a domain.BudgetAllocation) error {
+	budget, err := s.auth.Budget(userID, a.BudgetID, domain.PermissionWrite)
+	if err != nil {
+		return err
+	}
+	if a.AmountInCents < 0 {
+		return domain.ErrInvalidAmount
+	}
+	if a.PeriodStart.IsZero() {
+		return fmt.Errorf("%w: the allocation needs a period", domain.ErrInvalidBudgetPeriod)
+	}
+
+	budget.ApplyDefaults()
+	a.PeriodStart = domain.PeriodStart(budget.Period, a.PeriodStart)
+	return s.budgetRepo.SaveAllocation(a)
+}
+
+func (s *budgetService) history(budget domain.Budget, until time.Time) ([]domain.BudgetPeriodSummary, error) {
+	budget.ApplyDefaults()
+	bookings, err := s.transactionRepo.FindBudgetBookings(budget.ID)
+	if err != nil {
+		return nil, err
+	}
+	movements, err := s.budgetRepo.FindMovementsByBudget(budget.ID)
+	if err != nil {
+		return nil, err
+	}
+	bookings = withMovements(bookings, movements, budget.ID)
+	allocations, err := s.budgetRepo.FindAllocationsByBudget(budget.ID)
+	if err != nil {
+		return nil, err
+	}
+	return domain.BudgetHistory(budget, bookings, allocations, until), nil
+}
+
+// GetToBeAssigned is the income booked on no budget, less what was moved
+// from it onto budgets.
+func (s *budgetService) GetToBeAssigned(userID int) (int, error) {
+	return toBeAssigned(s.repos, userID)
+}
+
+func toBeAssigned(repos ports.Repositories, userID int) (int, error) {
+	income, err := repos.TransactionRepository().SumTransactionsWithoutBudget(userID)
+	if err != nil {
+		return 0, err
+	}
+	movements, err := repos.BudgetRepository().FindMovementsByUser(userID)
+	if err != nil {
+		return 0, err
+	}
+	for _, m := range movements {
+		income += m.AmountToBeAssigned()
+	}
+	return income, nil
+}
+
+// AssignToBudgets locks the user's budgets while it checks and saves the
+// assignments, so two assignments at once cannot both assign the same money.
+func (s *budgetService) AssignToBudgets(userID int, assignments []domain.BudgetAssignment) (int, error) {
+	var assigned int
+	var movements []domain.BudgetMovement
+	err := s.repos.WithinTransaction(func(repos ports.Repositories) error {
+		if err := repos.BudgetRepository().LockBudgetsByUser(userID); err != nil {
+			return err
+		}
+		before, err := toBeAssigned(repos, userID)
+		if err != nil {
+			return err
+		}
+
+		auth := NewAuthorizer(repos)
+		assigned = before
+		now := time.Now()
+		movements = make([]domain.BudgetMovement, 0, len(assignments))
+		for _, a := range assignments {
+			if _, err := auth.Budget(userID, a.BudgetID, domain.PermissionOwner); err != nil {
+				return domain.ErrBudgetNotFound
+			}
+			if a.AmountInCents == 0 {
+				continue
+			}
+			budgetID := a.BudgetID
+			m := domain.BudgetMovement{UserID: userID, Date: now, ToBudgetID: &budgetID, AmountInCents: a.AmountInCents}
+			if a.AmountInCents < 0 {
+				m.FromBudgetID, m.ToBudgetID, m.AmountInCents = &budgetID, nil, -a.AmountInCents
+			}
+			assigned += m.AmountToBeAssigned()
+			movements = append(movements, m)
+		}
+		if len(movements) == 0 {
+			return domain.ErrInvalidAmount
+		}
+		// Giving money back is fine even while more was spent than came in.
+		if assigned < 0 && assigned < before {
+			return domain.ErrOverAssigned
+		}
+
+		return repos.BudgetRepository().SaveMovements(movements)
+	})
+	if err != nil {
+		return 0, err
+	}
+	s.alerts.MovementsSaved(movements)
+	return assigned, nil
+}
+
+// TransferBetweenBudgets moves money from one budget to another, e.g. to
+// cover overspending, leaving every wallet as it is.
+func (s *budgetService) TransferBetweenBudgets(userID, fromBudgetID, toBudgetID, amount int, note string) error {
+	if fromBudgetID == toBudgetID {
+		return domain.ErrSameBudgetTransfer
+	}
+	if amount <= 0 {
+		return domain.ErrInvalidAmount
+	}
+
+	movements := []domain.BudgetMovement{{
+		UserID:        userID,
+		Date:          time.Now(),
+		FromBudgetID:  &fromBudgetID,
+		ToBudgetID:    &toBudgetID,
+		AmountInCents: amount,
+		Note:          strings.TrimSpace(note),
+	}}
+	err := s.repos.WithinTransaction(func(repos ports.Repositories) error {
+		if err := repos.BudgetRepository().LockBudgetsByUser(userID); err != nil {
+			return err
+		}
+		auth := NewAuthorizer(repos)
+		for _, id := range []int{fromBudgetID, toBudgetID} {
+			if _, err := auth.Budget(userID, id, domain.PermissionOwner); err != nil {
+				return domain.ErrBudgetNotFound
+			}
+		}
+		return repos.BudgetRepository().SaveMovements(movements)
+	})
+	if err != nil {
+		return err
+	}
+	s.alerts.MovementsSaved(movements)
+	return nil
+}
+
+func (s *budgetService) GetMovements(userID int) ([]domain.BudgetMovement, error) {
+	return s.budgetRepo.FindMovementsByUser(userID)
+}
@@ -1,6 +1,8 @@
 package services
 
 import (
+	"errors"
+	"sync"
 	"testing"
 	"time"
 
@@ -11,7 +13,7 @@ import (
 
 func TestCreateBudget(t *testing.T) {
 	repos := memory.NewSeededRepositories()
-	svc := NewBudgetService(repos.BudgetRepository(), repos.TransactionRepository())
+	svc := NewBudgetService(repos, noBudgetAlerts{})
 
 	t.Run("Valid budget creation", func(t *testing.T) {
 		budget := domain.Budget{
@@ -84,7 +86,7 @@ func TestGetBudgetCanDelete(t *testing.T) {
 
 	t.Run("CanDelete is false when BalanceCents is not zero", func(t *testing.T) {
 		repos := memory.NewCleanRepositories()
-		svc := NewBudgetService(repos.BudgetRepository(), repos.TransactionRepository())
+		svc := NewBudgetService(repos, noBudgetAlerts{})
 		budget := domain.Budget{UserID: userID, Name: "Non-Zero Balance", LimitCents: 10000, BalanceCents: 500}
 		svc.CreateBudget(userID, budget)
 		budgets, _ := repos.BudgetRepository().FindBudgetsByUser(userID)
@@ -101,7 +103,7 @@ func TestGetBudgetCanDelete(t *testing.T) {
 
 	t.Run("CanDelete is false when BalanceCents is zero but transactions exist", func(t *testing.T) {
 		repos := memory.NewCleanRepositories()
-		svc := NewBudgetService(repos.BudgetRepository(), repos.TransactionRepository())
+		svc := NewBudgetService(repos, noBudgetAlerts{})
 		budget := domain.Budget{UserID: userID, Name: "Zero Balance, Has Transactions", LimitCents: 10000, BalanceCents: -100}
 		svc.CreateBudget(userID, budget)
 		budgets, _ := repos.BudgetRepository().FindBudgetsByUser(userID)
@@ -126,7 +128,7 @@ func TestGetBudgetCanDelete(t *testing.T) {
 
 	t.Run("CanDelete is true when BalanceCents is zero and no transactions exist", func(t *testing.T) {
 		repos := memory.NewCleanRepositories()
-		svc := NewBudgetService(repos.BudgetRepository(), repos.TransactionRepository())
+		svc := NewBudgetService(repos, noBudgetAlerts{})
 		budget := domain.Budget{UserID: userID, Name: "Zero Balance, No Transactions", LimitCents: 10000, BalanceCents: 0}
 		svc.CreateBudget(userID, budget)
 		budgets, _ := repos.BudgetRepository().FindBudgetsByUser(userID)
@@ -145,7 +147,7 @@ func TestGetBudgetCanDelete(t *testing.T) {
 func TestGetBudgetsCanDelete(t *testing.T) {
 	userID := 1
 	repos := memory.NewCleanRepositories()
-	svc := NewBudgetService(repos.BudgetRepository(), repos.TransactionRepository())
+	svc := NewBudgetService(repos, noBudgetAlerts{})
 
 	budget1 := domain.Budget{UserID: userID, Name: "Budget 1", LimitCents: 10000, BalanceCents: 500}
 	svc.CreateBudget(userID, budget1)
@@ -194,7 +196,7 @@ func TestDeleteBudget(t *testing.T) {
 
 	t.Run("Successfully delete empty budget", func(t *testing.T) {
 		repos := memory.NewCleanRepositories()
-		svc := NewBudgetService(repos.BudgetRepository(), repos.TransactionRepository())
+		svc := NewBudgetService(repos, noBudgetAlerts{})
 		budget := domain.Budget{UserID: userID, Name: "Test Budget", LimitCents: 10000}
 		svc.CreateBudget(userID, budget)
 		budgets, _ := repos.BudgetRepository().FindBudgetsByUser(userID)
@@ -213,7 +215,7 @@ func TestDeleteBudget(t *testing.T) {
 
 	t.Run("Fail to delete budget with transactions", func(t *testing.T) {
 		repos := memory.NewCleanRepositories()
-		svc := NewBudgetService(repos.BudgetRepository(), repos.TransactionRepository())
+		svc := NewBudgetService(repos, noBudgetAlerts{})
 		budget := domain.Budget{UserID: userID, Name: "Test Budget", LimitCents: 10000}
 		svc.CreateBudget(userID, budget)
 		budgets, _ := repos.BudgetRepository().FindBudgetsByUser(userID)
@@ -240,7 +242,7 @@ func TestDeleteBudget(t *testing.T) {
 
 	t.Run("Unauthorized deletion", func(t *testing.T) {
 		repos := memory.NewCleanRepositories()
-		svc := NewBudgetService(repos.BudgetRepository(), repos.TransactionRepository())
+		svc := NewBudgetService(repos, noBudgetAlerts{})
 		budget := domain.Budget{UserID: userID, Name: "Test Budget", LimitCents: 10000}
 		svc.CreateBudget(userID, budget)
 		budgets, _ := repos.BudgetRepository().FindBudgetsByUser(userID)
@@ -252,3 +254,321 @@ func TestDeleteBudget(t *testing.T) {
 		}
 	})
 }
+
+func TestBudgetHistory_RolloverPolicies(t *testing.T) {
+	for _, tc := range []struct {
+		rollover domain.RolloverPolicy
+		balances []int
+	}{
+		{domain.RolloverReset, []int{6000, -2000, 5000}},
+		{domain.RolloverCarryOver, []int{6000, 4000, 9000}},
+		{domain.RolloverOverspending, []int{6000, -2000, 3000}},
+	} {
+		t.Run(string(tc.rollover), func(t *testing.T) {
+			repos := memory.NewCleanRepositories()
+			svc := NewBudgetService(repos, noBudgetAlerts{})
+			budget := domain.Budget{ID: 1, UserID: 1, Name: "Essen", LimitCents: 10000, Rollover: tc.rollover}
+			if err := repos.BudgetRepository().SaveBudget(budget); err != nil {
+				t.Fatalf("could not seed the budget: %v", err)
+			}
+			budgetID := budget.ID
+			for _, tx := range []domain.Transaction{
+				{Date: time.Date(2026, 1, 20, 0, 0, 0, 0, time.UTC), AmountInCents: 4000},
+				{Date: time.Date(2026, 2, 3, 0, 0, 0, 0, time.UTC), AmountInCents: 12000},
+			} {
+				tx.UserID, tx.BudgetID, tx.Type = 1, &budgetID, domain.Expense
+				repos.TransactionRepository().SaveTransaction(tx)
+			}
+			if err := svc.SetAllocation(1, domain.BudgetAllocation{BudgetID: 1, PeriodStart: time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC), AmountInCents: 5000}); err != nil {
+				t.Fatalf("allocating failed: %v", err)
+			}
+
+			history, err := svc.GetBudgetHistory(1, 1, time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC))
+			if err != nil {
+				t.Fatalf("fetching the history failed: %v", err)
+			}
+			if len(history) != 3 {
+				t.Fatalf("expected January to March, got %+v", history)
+			}
+			if history[1].SpentCents != 12000 || history[2].AllocatedCents != 5000 || !history[2].Start.Equal(time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)) {
+				t.Errorf("expected February's spending and March's own allocation, got %+v", history)
+			}
+			for i, want := range tc.balances {
+				if history[i].BalanceCents != want {
+					t.Errorf("expected a balance of %d in %s, got %d", want, history[i].Start.Month(), history[i].BalanceCents)
+				}
+			}
+		})
+	}
+}
+
+func TestGetBudgets_CurrentPeriodMatchesHistory(t *testing.T) {
+	repos := memory.NewCleanRepositories()
+	svc := NewBudgetService(repos, noBudgetAlerts{})
+	now := time.Now()
+	for id, rollover := range map[int]domain.RolloverPolicy{1: domain.RolloverReset, 2: domain.RolloverCarryOver} {
+		if err := repos.BudgetRepository().SaveBudget(domain.Budget{ID: id, UserID: 1, Name: string(rollover), LimitCents: 10000, Rollover: rollover}); err != nil {
+			t.Fatalf("could not seed the budget: %v", err)
+		}
+		budgetID := id
+		for _, tx := range []domain.Transaction{
+			{Date: now.AddDate(0, -2, 0), AmountInCents: 4000},
+			{Date: now, AmountInCents: 1500},
+		} {
+			tx.UserID, tx.BudgetID, tx.Type = 1, &budgetID, domain.Expense
+			repos.TransactionRepository().SaveTransaction(tx)
+		}
+	}
+	toBudget := 2
+	if err := repos.BudgetRepository().SaveMovements([]domain.BudgetMovement{{UserID: 1, Date: now, ToBudgetID: &toBudget, AmountInCents: 700}}); err != nil {
+		t.Fatalf("could not seed the movement: %v", err)
+	}
+
+	budgets, err := svc.GetBudgets(1)
+	if err != nil {
+		t.Fatalf("fetching the budgets failed: %v", err)
+	}
+	if len(budgets) != 2 {
+		t.Fatalf("expected both budgets, got %+v", budgets)
+	}
+	for _, b := range budgets {
+		history, err := svc.GetBudgetHistory(1, b.ID, now)
+		if err != nil {
+			t.Fatalf("fetching the history failed: %v", err)
+		}
+		if want := history[len(history)-1]; b.CurrentPeriod == nil || *b.CurrentPeriod != want {
+			t.Errorf("%s: expected the current period %+v, got %+v", b.Name, want, b.CurrentPeriod)
+		}
+		if b.CanDelete {
+			t.Errorf("%s: expected a budget with bookings not to be deletable", b.Name)
+		}
+	}
+}
+
+func TestBudgetHistory_WeeklyPeriodsStartOnMonday(t *testing.T) {
+	repos := memory.NewCleanRepositories()
+	svc := NewBudgetService(repos, noBudgetAlerts{})
+	if err := svc.CreateBudget(1, domain.Budget{Name: "Kantine", LimitCents: 3000, Period: domain.Weekly}); err != nil {
+		t.Fatalf("creating the budget failed: %v", err)
+	}
+	budgets, _ := svc.GetBudgets(1)
+	if budgets[0].CurrentPeriod == nil || budgets[0].CurrentPeriod.Start.Weekday() != time.Monday || budgets[0].CurrentPeriod.AllocatedCents != 3000 {
+		t.Errorf("expected the current week starting on Monday with its allocation, got %+v", budgets[0].CurrentPeriod)
+	}
+
+	if err := svc.CreateBudget(1, domain.Budget{Name: "Quartal", LimitCents: 3000, Period: "QUARTERLY"}); !errors.Is(err, domain.ErrInvalidBudgetPeriod) {
+		t.Errorf("expected ErrInvalidBudgetPeriod, got %v", err)
+	}
+}
+
+func TestAssignToBudgets_MovesIncomeOntoBudgets(t *testing.T) {
+	repos := memory.NewCleanRepositories()
+	svc := NewBudgetService(repos, noBudgetAlerts{})
+	walletSvc := NewWalletService(repos.WalletRepository(), repos.TransactionRepository(), repos.MembershipRepository(), NewAuthorizer(repos))
+	dashboardSvc := NewDashboardService(repos.UserRepository(), repos.BudgetRepository(), svc, walletSvc)
+
+	repos.UserRepository().SaveUser(domain.User{Username: "zero", SalaryCents: 300000})
+	user, _ := repos.UserRepository().GetUserByUsername("zero")
+	repos.WalletRepository().SaveWallet(domain.Wallet{ID: 10, UserID: user.ID, Name: "Girokonto"})
+	repos.BudgetRepository().SaveBudget(domain.Budget{ID: 11, UserID: user.ID, Name: "Wohnen", LimitCents: 85000})
+	repos.BudgetRepository().SaveBudget(domain.Budget{ID: 12, UserID: user.ID, Name: "Essen", LimitCents: 20000})
+	repos.TransactionRepository().SaveTransaction(domain.Transaction{ID: 13, UserID: user.ID, WalletID: 10, Date: time.Now(), Description: "Gehalt", AmountInCents: 300000, Type: domain.Income})
+	repos.TransactionRepository().SaveTransaction(domain.Transaction{ID: 14, UserID: user.ID, WalletID: 10, Date: time.Now(), Description: "Bargeld", AmountInCents: 5000, Type: domain.Expense})
+
+	if toBeAssigned, _ := svc.GetToBeAssigned(user.ID); toBeAssigned != 295000 {
+		t.Fatalf("expected 295000 to be assigned, got %d", toBeAssigned)
+	}
+
+	_, err := svc.AssignToBudgets(user.ID, []domain.BudgetAssignment{{BudgetID: 11, AmountInCents: 200000}, {BudgetID: 12, AmountInCents: 100000}})
+	if !errors.Is(err, domain.ErrOverAssigned) {
+		t.Errorf("expected ErrOverAssigned, got %v", err)
+	}
+	if budget, _ := repos.BudgetRepository().GetBudgetByID(11); budget.BalanceCents != 0 {
+		t.Errorf("expected a refused assignment to move nothing, got a balance of %d", budget.BalanceCents)
+	}
+
+	left, err := svc.AssignToBudgets(user.ID, []domain.BudgetAssignment{{BudgetID: 11, AmountInCents: 200000}, {BudgetID: 12, AmountInCents: 50000}})
+	if err != nil || left != 45000 {
+		t.Fatalf("expected 45000 left to be assigned, got %d (%v)", left, err)
+	}
+	left, err = svc.AssignToBudgets(user.ID, []domain.BudgetAssignment{{BudgetID: 11, AmountInCents: -20000}})
+	if err != nil || left != 65000 {
+		t.Fatalf("expected giving money back to leave 65000 to be assigned, got %d (%v)", left, err)
+	}
+
+	budget, err := svc.GetBudget(user.ID, 11)
+	if err != nil {
+		t.Fatalf("fetching the budget failed: %v", err)
+	}
+	if budget.BalanceCents != 180000 || budget.CurrentPeriod.AssignedCents != 180000 || budget.CurrentPeriod.IncomeCents != 0 {
+		t.Errorf("expected 180000 assigned rather than booked as income, got %+v and %+v", budget, budget.CurrentPeriod)
+	}
+	if count, _ := repos.TransactionRepository().GetTransactionCount(user.ID); count != 2 {
+		t.Errorf("expected the assignments to create no transactions, got %d", count)
+	}
+
+	dashboard, err := dashboardSvc.GetDashboard(user.ID)
+	if err != nil {
+		t.Fatalf("fetching the dashboard failed: %v", err)
+	}
+	want := domain.Dashboard{SalaryCents: 300000, ToBeAssignedCents: 65000, AssignedThisMonthCents: 230000, BudgetsTotalCents: 230000, WalletsTotalCents: 295000}
+	if dashboard != want {
+		t.Errorf("expected %+v, got %+v", want, dashboard)
+	}
+}
+
+func TestAssignToBudgets_ConcurrentAssignmentsCannotAssignTheSameMoney(t *testing.T) {
+	repos := memory.NewCleanRepositories()
+	svc := NewBudgetService(repos, noBudgetAlerts{})
+	repos.WalletRepository().SaveWallet(domain.Wallet{ID: 10, UserID: 1, Name: "Girokonto"})
+	repos.BudgetRepository().SaveBudget(domain.Budget{ID: 11, UserID: 1, Name: "Wohnen", LimitCents: 85000})
+	repos.TransactionRepository().SaveTransaction(domain.Transaction{ID: 13, UserID: 1, WalletID: 10, Date: time.Now(), Description: "Gehalt", AmountInCents: 100000, Type: domain.Income})
+
+	const assigners = 4
+	errs := make(chan error, assigners)
+	var wg sync.WaitGroup
+	for range assigners {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := svc.AssignToBudgets(1, []domain.BudgetAssignment{{BudgetID: 11, AmountInCents: 100000}})
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	assigned := 0
+	for err := range errs {
+		switch {
+		case err == nil:
+			assigned++
+		case !errors.Is(err, domain.ErrOverAssigned):
+			t.Errorf("expected ErrOverAssigned for the assignments too late, got %v", err)
+		}
+	}
+	if assigned != 1 {
+		t.Errorf("expected the income to be assigned once, got %d times", assigned)
+	}
+	if left, _ := svc.GetToBeAssigned(1); left != 0 {
+		t.Errorf("expected nothing left to be assigned, got %d", left)
+	}
+}
+
+func TestTransferBetweenBudgets_LeavesWalletsAlone(t *testing.T) {
+	repos := memory.NewCleanRepositories()
+	svc := NewBudgetService(repos, noBudgetAlerts{})
+
+	repos.WalletRepository().SaveWallet(domain.Wallet{ID: 1, UserID: 1, Name: "Girokonto", BalanceCents: 10000})
+	repos.BudgetRepository().SaveBudget(domain.Budget{ID: 2, UserID: 1, Name: "Essen", BalanceCents: 8000})
+	repos.BudgetRepository().SaveBudget(domain.Budget{ID: 3, UserID: 1, Name: "Freizeit", BalanceCents: -2000})
+	repos.BudgetRepository().SaveBudget(domain.Budget{ID: 4, UserID: 99, Name: "Fremd"})
+
+	if err := svc.TransferBetweenBudgets(1, 2, 2, 2000, ""); !errors.Is(err, domain.ErrSameBudgetTransfer) {
+		t.Errorf("expected ErrSameBudgetTransfer, got %v", err)
+	}
+	if err := svc.TransferBetweenBudgets(1, 2, 4, 2000, ""); !errors.Is(err, domain.ErrBudgetNotFound) {
+		t.Errorf("expected ErrBudgetNotFound for another user's budget, got %v", err)
+	}
+	if err := svc.TransferBetweenBudgets(1, 2, 3, 0, ""); !errors.Is(err, domain.ErrInvalidAmount) {
+		t.Errorf("expected ErrInvalidAmount, got %v", err)
+	}
+
+	if err := svc.TransferBetweenBudgets(1, 2, 3, 2000, " Kino "); err != nil {
+		t.Fatalf("transfer failed: %v", err)
+	}
+	from, _ := repos.BudgetRepository().GetBudgetByID(2)
+	to, _ := repos.BudgetRepository().GetBudgetByID(3)
+	if from.BalanceCents != 6000 || to.BalanceCents != 0 {
+		t.Errorf("expected balances of 6000 and 0, got %d and %d", from.BalanceCents, to.BalanceCents)
+	}
+	if wallet, _ := repos.WalletRepository().GetWalletByID(1); wallet.BalanceCents != 10000 {
+		t.Errorf("expected the wallet to stay at 10000, got %d", wallet.BalanceCents)
+	}
+
+	movements, err := svc.GetMovements(1)
+	if err != nil || len(movements) != 1 {
+		t.Fatalf("expected one recorded movement, got %+v (%v)", movements, err)
+	}
+	if m := movements[0]; *m.FromBudgetID != 2 || *m.ToBudgetID != 3 || m.AmountInCents != 2000 || m.Note != "Kino" {
+		t.Errorf("expected the movement to link both budgets, got %+v", m)
+	}
+	if toBeAssigned, _ := svc.GetToBeAssigned(1); toBeAssigned != 0 {
+		t.Errorf("expected the transfer to leave the income to be assigned alone, got %d", toBeAssigned)
+	}
+}
+
+func TestDeleteBudget_KeepsBudgetsMoneyWasMovedTo(t *testing.T) {
+	repos := memory.NewCleanRepositories()
+	svc := NewBudgetService(repos, noBudgetAlerts{})
+	repos.BudgetRepository().SaveBudget(domain.Budget{ID: 2, UserID: 1, Name: "Essen", BalanceCents: 2000})
+	repos.BudgetRepository().SaveBudget(domain.Budget{ID: 3, UserID: 1, Name: "Freizeit"})
+
+	if err := svc.TransferBetweenBudgets(1, 2, 3, 2000, ""); err != nil {
+		t.Fatalf("transfer failed: %v", err)
+	}
+	if err := svc.DeleteBudget(1, 2); err != domain.ErrNotEmpty {
+		t.Errorf("expected the emptied budget money was moved from to be kept, got %v", err)
+	}
+	if err := svc.DeleteBudget(1, 3); err != domain.ErrNotEmpty {
+		t.Errorf("expected the budget money was moved to to be kept, got %v", err)
+	}
+	if err := repos.BudgetRepository().DeleteBudget(2); err != domain.ErrNotEmpty {
+		t.Errorf("expected the repository to refuse as the database does, got %v", err)
+	}
+	if budgets, _ := svc.GetBudgets(1); len(budgets) != 2 || budgets[0].CanDelete || budgets[1].CanDelete {
+		t.Errorf("expected neither budget to be offered for deletion, got %+v", budgets)
+	}
+}
+
+func TestBudgetTree_AggregatesChildrenAndMovesThemUpOnDelete(t *testing.T) {
+	repos := memory.NewCleanRepositories()
+	svc := NewBudgetService(repos, noBudgetAlerts{})
+
+	repos.BudgetRepository().SaveBudget(domain.Budget{ID: 11, UserID: 1, Name: "Mobilität", LimitCents: 0})
+	parentID := 11
+	repos.BudgetRepository().SaveBudget(domain.Budget{ID: 12, UserID: 1, ParentID: &parentID, Name: "Fuel", LimitCents: 15000})
+	repos.BudgetRepository().SaveBudget(domain.Budget{ID: 13, UserID: 1, ParentID: &parentID, Name: "Train", LimitCents: 5000, BalanceCents: -4000})
+	repos.BudgetRepository().SaveBudget(domain.Budget{ID: 14, UserID: 1, Name: "Essen", LimitCents: 40000})
+	repos.TransactionRepository().SaveTransaction(domain.Transaction{ID: 15, UserID: 1, BudgetID: &parentID, Date: time.Now(), Description: "Parkhaus", AmountInCents: 500, Type: domain.Expense})
+
+	tree, err := svc.GetBudgets(1)
+	if err != nil {
+		t.Fatalf("fetching budgets failed: %v", err)
+	}
+	if len(tree) != 2 || tree[0].Name != "Mobilität" || len(tree[0].Children) != 2 {
+		t.Fatalf("expected Mobilität with two children and Essen, got %+v", tree)
+	}
+	if tree[0].TotalLimitCents != 20000 || tree[0].TotalBalanceCents != -4500 || tree[0].BalanceCents != -500 {
+		t.Errorf("expected the parent to add up its children and its own booking, got %+v", tree[0])
+	}
+
+	insurance := domain.Budget{Name: "Car insurance", LimitCents: 8000, ParentID: &parentID}
+	if err := svc.CreateBudget(1, insurance); err != nil {
+		t.Fatalf("creating the child failed: %v", err)
+	}
+	foreignParent := 99
+	if err := svc.CreateBudget(1, domain.Budget{Name: "Bus", LimitCents: 100, ParentID: &foreignParent}); !errors.Is(err, domain.ErrBudgetNotFound) {
+		t.Errorf("expected ErrBudgetNotFound for an unknown parent, got %v", err)
+	}
+
+	fuelID := 12
+	if err := svc.MoveBudget(1, 11, &fuelID); !errors.Is(err, domain.ErrInvalidBudgetParent) {
+		t.Errorf("expected ErrInvalidBudgetParent for nesting a budget under its child, got %v", err)
+	}
+	if err := svc.MoveBudget(1, 13, &fuelID); err != nil {
+		t.Fatalf("moving failed: %v", err)
+	}
+	fuel, err := svc.GetBudget(1, 12)
+	if err != nil || len(fuel.Children) != 1 || fuel.TotalLimitCents != 20000 {
+		t.Fatalf("expected Train below Fuel, got %+v (%v)", fuel, err)
+	}
+
+	if err := svc.DeleteBudget(1, 12); err != nil {
+		t.Fatalf("deleting failed: %v", err)
+	}
+	train, _ := repos.BudgetRepository().GetBudgetByID(13)
+	if train.ParentID == nil || *train.ParentID != 11 {
+		t.Errorf("expected Train to move up to Mobilität, got parent %v", train.ParentID)
+	}
+}
@@ -0,0 +1,49 @@
+package services
+
+import (
+	"time"
+
+	"github.com/fim-lab/expense-tracker/internal/core/domain"
+	"github.com/fim-lab/expense-tracker/internal/core/ports"
+)
+
+type dashboardService struct {
+	userRepo      ports.UserRepository
+	budgetRepo    ports.BudgetRepository
+	budgetService ports.BudgetService
+	walletService ports.WalletService
+}
+
+func NewDashboardService(userRepo ports.UserRepository, budgetRepo ports.BudgetRepository, budgetService ports.BudgetService, walletService ports.WalletService) ports.DashboardService {
+	return &dashboardService{userRepo: userRepo, budgetRepo: budgetRepo, budgetService: budgetService, walletService: walletService}
+}
+
+func (s *dashboardService) GetDashboard(userID int) (domain.Dashboard, error) {
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		return domain.Dashboard{}, err
+	}
+	dashboard := domain.Dashboard{SalaryCents: user.SalaryCents}
+
+	if dashboard.ToBeAssignedCents, err = s.budgetService.GetToBeAssigned(userID); err != nil {
+		return domain.Dashboard{}, err
+	}
+	if dashboard.BudgetsTotalCents, err = s.budgetService.GetTotalOfBudgets(userID); err != nil {
+		return domain.Dashboard{}, err
+	}
+	if dashboard.WalletsTotalCents, err = s.walletService.GetTotalOfWallets(userID); err != nil {
+		return domain.Dashboard{}, err
+	}
+
+	movements, err := s.budgetRepo.FindMovementsByUser(userID)
+	if err != nil {
+		return domain.Dashboard{}, err
+	}
+	month := domain.PeriodStart(domain.Monthly, time.Now())
+	for _, m := range movements {
+		if domain.PeriodStart(domain.Monthly, m.Date).Equal(month) {
+			dashboard.AssignedThisMonthCents -= m.AmountToBeAssigned()
+		}
+	}
+	return dashboard, nil
+}
@@ -0,0 +1,191 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fim-lab/expense-tracker/internal/core/domain"
+	"github.com/fim-lab/expense-tracker/internal/core/ports"
+)
+
+type debtService struct {
+	repos ports.Repositories
+}
+
+func NewDebtService(repos ports.Repositories) ports.DebtService {
+	return &debtService{repos: repos}
+}
+
+func (s *debtService) CreateCounterparty(userID int, c domain.Counterparty) (domain.Counterparty, error) {
+	c.Name = strings.TrimSpace(c.Name)
+	if err := c.Validate(); err != nil {
+		return domain.Counterparty{}, err
+	}
+	err := s.repos.WithinTransaction(func(repos ports.Repositories) error {
+		existing, err := repos.CounterpartyRepository().FindCounterpartiesByUser(userID)
+		if err != nil {
+			return err
+		}
+		for _, other := range existing {
+			if strings.EqualFold(other.Name, c.Name) {
+				return domain.ErrCounterpartyExists
+			}
+		}
+		c = domain.Counterparty{UserID: userID, Name: c.Name, CreatedAt: time.Now()}
+		c.ID, err = repos.CounterpartyRepository().SaveCounterparty(c)
+		return err
+	})
+	if err != nil {
+		return domain.Counterparty{}, err
+	}
+	return c, nil
+}
+
+func (s *debtService) GetCounterparties(userID int) ([]domain.Counterparty, error) {
+	counterparties, err := s.repos.CounterpartyRepository().FindCounterpartiesByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if counterparties == nil {
+		counterparties = []domain.Counterparty{}
+	}
+	return counterparties, nil
+}
+
+func (s *debtService) DeleteCounterparty(userID int, id int) error {
+	return s.repos.WithinTransaction(func(repos ports.Repositories) error {
+		if _, err := ownCounterparty(repos, userID, id); err != nil {
+			return err
+		}
+		transactions, err := repos.TransactionRepository().FindDebtTransactions(userID)
+		if err != nil {
+			return err
+		}
+		for _, t := range transactions {
+			if *t.CounterpartyID == id {
+				return domain.ErrNotEmpty
+			}
+		}
+		return repos.CounterpartyRepository().DeleteCounterparty(id)
+	})
+}
+
+func (s *debtService) GetDebts(userID int) ([]domain.CounterpartyDebts, error) {
+	counterparties, err := s.repos.CounterpartyRepository().FindCounterpartiesByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	transactions, err := s.repos.TransactionRepository().FindDebtTransactions(userID)
+	if err != nil {
+		return nil, err
+	}
+	perCounterparty := make(map[int][]domain.Transaction)
+	for _, t := range transactions {
+		perCounterparty[*t.CounterpartyID] = append(perCounterparty[*t.CounterpartyID], t)
+	}
+
+	debts := make([]domain.CounterpartyDebts, 0, len(counterparties))
+	for _, c := range counterparties {
+		debts = append(debts, domain.DebtsWith(c, perCounterparty[c.ID]))
+	}
+	return debts, nil
+}
+
+func (s *debtService) CreateDebt(userID int, counterpartyID int, t domain.Transaction) (domain.Transaction, error) {
+	if t.AmountInCents <= 0 {
+		return domain.Transaction{}, domain.ErrInvalidAmount
+	}
+	err := s.repos.WithinTransaction(func(repos ports.Repositories) error {
+		if _, err := ownCounterparty(repos, userID, counterpartyID); err != nil {
+			return err
+		}
+		if err := checkWalletOwner(repos, userID, t.WalletID); err != nil {
+			return err
+		}
+		// Debts are booked on no budget, so they never reach a budget's
+		// alert thresholds and the budget alerts need not hear of them.
+		isDebt := true
+		t.UserID, t.IsDebt, t.BudgetID, t.Splits = userID, &isDebt, nil, nil
+		t.CounterpartyID, t.RepaymentOfID, t.ReconciliationID = &counterpartyID, nil, nil
+		if t.Date.IsZero() {
+			t.Date = domain.DateOf(time.Now())
+		}
+		var err error
+		t.ID, err = repos.TransactionRepository().SaveTransaction(t)
+		return err
+	})
+	if err != nil {
+		return domain.Transaction{}, err
+	}
+	return t, nil
+}
+
+func (s *debtService) AssignCounterparty(userID int, debtID int, counterpartyID int) error {
+	return s.repos.WithinTransaction(func(repos ports.Repositories) error {
+		if _, err := ownCounterparty(repos, userID, counterpartyID); err != nil {
+			return err
+		}
+		debt, err := repos.TransactionRepository().GetTransactionByID(debtID)
+		if err != nil || debt.UserID != userID || debt.IsDebt == nil || !*debt.IsDebt || debt.IsRepayment() {
+			return domain.ErrDebtNotFound
+		}
+		return repos.TransactionRepository().SetCounterparty(debtID, counterpartyID)
+	})
+}
+
+func (s *debtService) RepayDebt(userID int, debtID int, repayment domain.Transaction) (domain.Transaction, error) {
+	if repayment.AmountInCents <= 0 {
+		return domain.Transaction{}, domain.ErrInvalidAmount
+	}
+	err := s.repos.WithinTransaction(func(repos ports.Repositories) error {
+		debt, err := repos.TransactionRepository().GetTransactionByID(debtID)
+		if err != nil || debt.UserID != userID || debt.IsDebt == nil || !*debt.IsDebt || debt.IsRepayment() || debt.CounterpartyID == nil {
+			return domain.ErrDebtNotFound
+		}
+		transactions, err := repos.TransactionRepository().FindDebtTransactions(userID)
+		if err != nil {
+			return err
+		}
+		open := debt.AmountInCents
+		for _, t := range transactions {
+			if t.RepaymentOfID != nil && *t.RepaymentOfID == debtID {
+				open -= t.AmountInCents
+			}
+		}
+		if repayment.AmountInCents > open {
+			return fmt.Errorf("%w: %s open", domain.ErrOverRepaid, formatCents(max(open, 0)))
+		}
+
+		if repayment.WalletID == 0 {
+			repayment.WalletID = debt.WalletID
+		}
+		if err := checkWalletOwner(repos, userID, repayment.WalletID); err != nil {
+			return err
+		}
+		if repayment.Date.IsZero() {
+			repayment.Date = domain.DateOf(time.Now())
+		}
+		if strings.TrimSpace(repayment.Description) == "" {
+			repayment.Description = "Repayment: " + debt.Description
+		}
+		isDebt := true
+		repayment.UserID, repayment.Type, repayment.IsDebt = userID, debt.RepaymentType(), &isDebt
+		repayment.BudgetID, repayment.Splits, repayment.ReconciliationID = nil, nil, nil
+		repayment.CounterpartyID, repayment.RepaymentOfID = debt.CounterpartyID, &debtID
+		repayment.ID, err = repos.TransactionRepository().SaveTransaction(repayment)
+		return err
+	})
+	if err != nil {
+		return domain.Transaction{}, err
+	}
+	return repayment, nil
+}
+
+func ownCounterparty(repos ports.Repositories, userID int, id int) (domain.Counterparty, error) {
+	c, err := repos.CounterpartyRepository().GetCounterpartyByID(id)
+	if err != nil || c.UserID != userID {
+		return domain.Counterparty{}, domain.ErrCounterpartyNotFound
+	}
+	return c, nil
+}
@@ -0,0 +1,106 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/fim-lab/expense-tracker/internal/core/domain"
+)
+
+func TestDebts_PartialRepaymentsLeaveTheRestOpen(t *testing.T) {
+	f := newWalletFixture(t)
+	debtSvc := NewDebtService(f.repos)
+	anna, err := debtSvc.CreateCounterparty(f.userID, domain.Counterparty{Name: "Anna"})
+	if err != nil {
+		t.Fatalf("could not create the counterparty: %v", err)
+	}
+	ben, err := debtSvc.CreateCounterparty(f.userID, domain.Counterparty{Name: "Ben"})
+	if err != nil {
+		t.Fatalf("could not create the counterparty: %v", err)
+	}
+	if _, err := debtSvc.CreateCounterparty(f.userID, domain.Counterparty{Name: " anna "}); !errors.Is(err, domain.ErrCounterpartyExists) {
+		t.Errorf("expected the name to be taken, got %v", err)
+	}
+
+	lent, err := debtSvc.CreateDebt(f.userID, anna.ID, domain.Transaction{Date: onDate(2026, 3, 1), WalletID: f.walletID, Description: "Konzertkarten", AmountInCents: 10000, Type: domain.Expense})
+	if err != nil {
+		t.Fatalf("could not lend the money: %v", err)
+	}
+	if _, err := debtSvc.CreateDebt(f.userID, ben.ID, domain.Transaction{Date: onDate(2026, 3, 2), WalletID: f.walletID, Description: "Taxi", AmountInCents: 4000, Type: domain.Income}); err != nil {
+		t.Fatalf("could not borrow the money: %v", err)
+	}
+	for _, amount := range []int{3000, 5000} {
+		if _, err := debtSvc.RepayDebt(f.userID, lent.ID, domain.Transaction{Date: onDate(2026, 3, 10), AmountInCents: amount}); err != nil {
+			t.Fatalf("could not repay %d: %v", amount, err)
+		}
+	}
+	if _, err := debtSvc.RepayDebt(f.userID, lent.ID, domain.Transaction{AmountInCents: 3000}); !errors.Is(err, domain.ErrOverRepaid) {
+		t.Errorf("expected repaying more than is open to be refused, got %v", err)
+	}
+
+	debts, err := debtSvc.GetDebts(f.userID)
+	if err != nil || len(debts) != 2 {
+		t.Fatalf("expected the debts with both counterparties, got %+v (%v)", debts, err)
+	}
+	withAnna := debts[0]
+	if withAnna.BalanceCents != 2000 || len(withAnna.Debts) != 1 {
+		t.Fatalf("expected Anna to still owe 20.00, got %+v", withAnna)
+	}
+	if d := withAnna.Debts[0]; d.RepaidCents != 8000 || d.OpenCents != 2000 || len(d.Repayments) != 2 || d.Repayments[0].Type != domain.Income {
+		t.Errorf("expected two repayments coming in, got %+v", d)
+	}
+	if debts[1].BalanceCents != -4000 || debts[1].Debts[0].OpenCents != 4000 {
+		t.Errorf("expected to owe Ben 40.00, got %+v", debts[1])
+	}
+	if wallet, _ := f.repos.WalletRepository().GetWalletByID(f.walletID); wallet.BalanceCents != -2000+4000 {
+		t.Errorf("expected the wallet to follow the debts, got %d", wallet.BalanceCents)
+	}
+
+	if err := debtSvc.DeleteCounterparty(f.userID, anna.ID); !errors.Is(err, domain.ErrNotEmpty) {
+		t.Errorf("expected a counterparty with debts to be kept, got %v", err)
+	}
+}
+
+func TestDebts_AssignCounterpartyToAnExistingDebt(t *testing.T) {
+	f := newWalletFixture(t)
+	debtSvc := NewDebtService(f.repos)
+	carla, err := debtSvc.CreateCounterparty(f.userID, domain.Counterparty{Name: "Carla"})
+	if err != nil {
+		t.Fatalf("could not create the counterparty: %v", err)
+	}
+	foreign, err := debtSvc.CreateCounterparty(2, domain.Counterparty{Name: "Dieter"})
+	if err != nil {
+		t.Fatalf("could not create the foreign counterparty: %v", err)
+	}
+	debt, noDebt := true, false
+	if _, err := f.txSvc.CreateTransaction(f.userID, domain.Transaction{ID: 11, Date: onDate(2026, 3, 1), WalletID: f.walletID, Description: "Miete vorgestreckt", AmountInCents: 50000, Type: domain.Expense, IsDebt: &debt, CounterpartyID: &foreign.ID}); err != nil {
+		t.Fatalf("could not book the debt: %v", err)
+	}
+	if _, err := f.txSvc.CreateTransaction(f.userID, domain.Transaction{ID: 12, Date: onDate(2026, 3, 1), WalletID: f.walletID, Description: "Einkauf", AmountInCents: 2000, Type: domain.Expense, IsDebt: &noDebt}); err != nil {
+		t.Fatalf("could not book the expense: %v", err)
+	}
+	if tx, _ := f.repos.TransactionRepository().GetTransactionByID(11); tx.CounterpartyID != nil {
+		t.Errorf("expected a plain transaction not to take a counterparty, got %+v", tx)
+	}
+	if _, err := debtSvc.RepayDebt(f.userID, 11, domain.Transaction{AmountInCents: 100}); !errors.Is(err, domain.ErrDebtNotFound) {
+		t.Errorf("expected a debt without counterparty to need one before repaying, got %v", err)
+	}
+
+	if err := debtSvc.AssignCounterparty(f.userID, 11, foreign.ID); !errors.Is(err, domain.ErrCounterpartyNotFound) {
+		t.Errorf("expected another user's counterparty to be refused, got %v", err)
+	}
+	if err := debtSvc.AssignCounterparty(f.userID, 12, carla.ID); !errors.Is(err, domain.ErrDebtNotFound) {
+		t.Errorf("expected a transaction that is no debt to be refused, got %v", err)
+	}
+	if err := debtSvc.AssignCounterparty(f.userID, 11, carla.ID); err != nil {
+		t.Fatalf("assigning the counterparty failed: %v", err)
+	}
+	if _, err := debtSvc.RepayDebt(f.userID, 11, domain.Transaction{AmountInCents: 50000}); err != nil {
+		t.Fatalf("repaying in full failed: %v", err)
+	}
+
+	debts, err := debtSvc.GetDebts(f.userID)
+	if err != nil || len(debts) != 1 || debts[0].BalanceCents != 0 || debts[0].Debts[0].OpenCents != 0 {
+		t.Errorf("expected the debt with Carla to be settled, got %+v (%v)", debts, err)
+	}
+}
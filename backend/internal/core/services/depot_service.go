@@ -10,15 +10,15 @@ import (
 )
 
 type depotService struct {
-	depotRepo    ports.DepotRepository
-	walletRepo   ports.WalletRepository
-	budgetRepo   ports.BudgetRepository
-	tradeRepo    ports.TradeRepository
-	stockService ports.StockService
+	depotRepo      ports.DepotRepository
+	tradeRepo      ports.TradeRepository
+	membershipRepo ports.MembershipRepository
+	auth           ports.Authorizer
+	stockService   ports.StockService
 }
 
-func NewDepotService(depotRepo ports.DepotRepository, walletRepo ports.WalletRepository, budgetRepo ports.BudgetRepository, tradeRepo ports.TradeRepository, stockService ports.StockService) ports.DepotService {
-	return &depotService{depotRepo: depotRepo, walletRepo: walletRepo, budgetRepo: budgetRepo, tradeRepo: tradeRepo, stockService: stockService}
+func NewDepotService(depotRepo ports.DepotRepository, tradeRepo ports.TradeRepository, membershipRepo ports.MembershipRepository, auth ports.Authorizer, stockService ports.StockService) ports.DepotService {
+	return &depotService{depotRepo: depotRepo, tradeRepo: tradeRepo, membershipRepo: membershipRepo, auth: auth, stockService: stockService}
 }
 
 func (s *depotService) CreateDepot(userID int, d domain.Depot) error {
@@ -28,24 +28,37 @@ func (s *depotService) CreateDepot(userID int, d domain.Depot) error {
 		return errors.New("depot name is required")
 	}
 
-	wallet, err := s.walletRepo.GetWalletByID(d.WalletID)
-	if err != nil || wallet.UserID != userID {
-		return errors.New("invalid wallet for depot")
-	}
-
-	budget, err := s.budgetRepo.GetBudgetByID(d.BudgetID)
-	if err != nil || budget.UserID != userID {
-		return domain.ErrBudgetNotFound
+	if err := s.checkAccounts(d); err != nil {
+		return err
 	}
 
 	return s.depotRepo.SaveDepot(d)
 }
 
+// GetDepots returns the user's depots and those others shared with them.
 func (s *depotService) GetDepots(userID int) ([]domain.DepotDTO, error) {
 	depots, err := s.depotRepo.FindDepotsByUser(userID)
 	if err != nil {
 		return nil, err
 	}
+	for i := range depots {
+		depots[i].Permission = domain.PermissionOwner
+	}
+	memberships, err := s.membershipRepo.FindMembershipsByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range memberships {
+		if m.ResourceType != domain.ResourceDepot {
+			continue
+		}
+		depot, err := s.depotRepo.GetDepotByID(m.ResourceID)
+		if err != nil {
+			return nil, err
+		}
+		depot.Permission = m.Permission
+		depots = append(depots, depot)
+	}
 
 	stocks, err := s.stockService.GetStocks()
 	if err != nil {
@@ -70,6 +83,7 @@ func (s *depotService) GetDepots(userID int) ([]domain.DepotDTO, error) {
 			BudgetID:            depot.BudgetID,
 			InvestedInCents:     investedInCents(trades),
 			CurrentValueInCents: currentValueInCents(positions, priceByStockID),
+			Permission:          depot.Permission,
 		})
 	}
 
@@ -79,45 +93,36 @@ func (s *depotService) GetDepots(userID int) ([]domain.DepotDTO, error) {
 }
 
 func (s *depotService) GetDepotByID(userID int, id int) (domain.Depot, error) {
-	depot, err := s.depotRepo.GetDepotByID(id)
+	depot, err := s.auth.Depot(userID, id, domain.PermissionRead)
+	if errors.Is(err, domain.ErrUnauthorized) {
+		return domain.Depot{}, err
+	}
 	if err != nil {
 		return domain.Depot{}, domain.ErrDepotNotFound
 	}
-	if depot.UserID != userID {
-		return domain.Depot{}, domain.ErrUnauthorized
-	}
 	return depot, nil
 }
 
 func (s *depotService) UpdateDepot(userID int, d domain.Depot) error {
-	existing, err := s.depotRepo.GetDepotByID(d.ID)
+	existing, err := s.auth.Depot(userID, d.ID, domain.PermissionWrite)
 	if err != nil {
 		return err
 	}
-	if existing.UserID != userID {
-		return domain.ErrUnauthorized
-	}
 
 	if strings.TrimSpace(d.Name) == "" {
 		return errors.New("depot name is required")
 	}
 
-	wallet, err := s.walletRepo.GetWalletByID(d.WalletID)
-	if err != nil || wallet.UserID != userID {
-		return errors.New("invalid wallet for depot")
-	}
-
-	budget, err := s.budgetRepo.GetBudgetByID(d.BudgetID)
-	if err != nil || budget.UserID != userID {
-		return domain.ErrBudgetNotFound
+	d.UserID = existing.UserID
+	if err := s.checkAccounts(d); err != nil {
+		return err
 	}
 
-	d.UserID = userID
 	return s.depotRepo.UpdateDepot(d)
 }
 
 func (s *depotService) DeleteDepot(userID int, id int) error {
-	if _, err := s.GetDepotByID(userID, id); err != nil {
+	if _, err := s.auth.Depot(userID, id, domain.PermissionOwner); err != nil {
 		return err
 	}
 
@@ -131,3 +136,15 @@ func (s *depotService) DeleteDepot(userID int, id int) error {
 
 	return s.depotRepo.DeleteDepot(id)
 }
+
+// checkAccounts makes sure the depot's trades are booked on a wallet and a
+// budget of the depot's owner.
+func (s *depotService) checkAccounts(d domain.Depot) error {
+	if _, err := s.auth.Wallet(d.UserID, d.WalletID, domain.PermissionOwner); err != nil {
+		return errors.New("invalid wallet for depot")
+	}
+	if _, err := s.auth.Budget(d.UserID, d.BudgetID, domain.PermissionOwner); err != nil {
+		return domain.ErrBudgetNotFound
+	}
+	return nil
+}
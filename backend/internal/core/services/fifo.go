@@ -48,8 +48,8 @@ func buildPortfolio(trades []domain.Trade) PortfolioSnapshot {
 				DateOfPurchase:       t.Timestamp,
 				Quantity:             t.Quantity,
 				Remaining:            t.Quantity,
-				TotalInCents:         t.TotalInCents,
-				RemainingCostInCents: t.TotalInCents,
+				TotalInCents:         t.NetInCents(),
+				RemainingCostInCents: t.NetInCents(),
 			}
 			lots = append(lots, lot)
 			lotsByStockID[t.StockID] = append(lotsByStockID[t.StockID], lot)
@@ -76,7 +76,7 @@ func buildPortfolio(trades []domain.Trade) PortfolioSnapshot {
 
 func (s *PortfolioSnapshot) applySell(sell domain.Trade, lots []*domain.Lot) {
 	toSell := sell.Quantity
-	remainingProceeds := sell.TotalInCents
+	remainingProceeds := sell.NetInCents()
 
 	for _, lot := range lots {
 		if toSell <= 0 {
@@ -100,7 +100,7 @@ func (s *PortfolioSnapshot) applySell(sell domain.Trade, lots []*domain.Lot) {
 
 		proceeds := remainingProceeds
 		if !completesSell {
-			proceeds = int(math.Round(float64(sell.TotalInCents) * take / sell.Quantity))
+			proceeds = int(math.Round(float64(sell.NetInCents()) * take / sell.Quantity))
 			if proceeds > remainingProceeds {
 				proceeds = remainingProceeds
 			}
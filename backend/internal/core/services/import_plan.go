@@ -0,0 +1,435 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/fim-lab/expense-tracker/internal/core/domain"
+	"github.com/fim-lab/expense-tracker/internal/core/ports"
+)
+
+// importPlan is everything an import writes, worked out without writing
+// anything. Wallets and budgets the import creates have no ID yet, so rows
+// refer to them by name.
+type importPlan struct {
+	preview domain.ImportPreview
+	salary  int
+	wallets []string
+	budgets []domain.ImportBudget
+	depots  []string
+	rows    []plannedRow
+}
+
+type plannedRow struct {
+	create      bool
+	wallet      string
+	budget      string
+	transaction domain.Transaction
+	existing    *domain.Transaction // The transaction a re-imported row updates
+	trade       *domain.Trade
+}
+
+func planImport(repos ports.Repositories, userID int, data domain.FullImportData) (importPlan, error) {
+	plan := importPlan{
+		preview: domain.ImportPreview{
+			NewWallets: []string{},
+			NewBudgets: []string{},
+			NewDepots:  []string{},
+			Wallets:    []domain.ImportWalletCounts{},
+			Trades:     []domain.ImportTradePreview{},
+			Dropped:    []domain.ImportDroppedRow{},
+		},
+	}
+	if data.Settings.Gehalt > 0 {
+		plan.salary = data.Settings.Gehalt
+		plan.preview.Salary = data.Settings.Gehalt
+	}
+
+	existingWallets, err := repos.WalletRepository().FindWalletsByUser(userID)
+	if err != nil {
+		return importPlan{}, fmt.Errorf("failed to fetch existing wallets: %w", err)
+	}
+	walletIDs := make(map[string]int)
+	walletNames := make(map[int]string)
+	for _, w := range existingWallets {
+		walletIDs[w.Name] = w.ID
+		walletNames[w.ID] = w.Name
+	}
+	for _, importWallet := range data.Settings.Wallets {
+		if importWallet.IsDepot {
+			continue
+		}
+		if _, exists := walletIDs[importWallet.Name]; !exists {
+			walletIDs[importWallet.Name] = 0
+			plan.wallets = append(plan.wallets, importWallet.Name)
+		}
+	}
+	// Depots are created on the wallet with the lowest ID, which is the first
+	// existing wallet or else the first one the import creates.
+	var firstWallet string
+	if len(existingWallets) > 0 {
+		firstWallet = existingWallets[0].Name
+	} else if len(plan.wallets) > 0 {
+		firstWallet = plan.wallets[0]
+	}
+
+	existingBudgets, err := repos.BudgetRepository().FindBudgetsByUser(userID)
+	if err != nil {
+		return importPlan{}, fmt.Errorf("failed to fetch existing budgets: %w", err)
+	}
+	budgetIDs := make(map[string]int)
+	for _, b := range existingBudgets {
+		budgetIDs[b.Name] = b.ID
+	}
+	for _, importBudget := range data.Settings.Budgets {
+		if strings.ToLower(importBudget.Account) != "private" {
+			continue
+		}
+		if _, exists := budgetIDs[importBudget.Name]; !exists {
+			budgetIDs[importBudget.Name] = 0
+			plan.budgets = append(plan.budgets, importBudget)
+		}
+	}
+	hasBudget := len(budgetIDs) > 0
+
+	existingDepots, err := repos.DepotRepository().FindDepotsByUser(userID)
+	if err != nil {
+		return importPlan{}, fmt.Errorf("failed to fetch existing depots: %w", err)
+	}
+	depotNames := make(map[string]bool)
+	depotWallets := make(map[string]bool)
+	for _, d := range existingDepots {
+		depotNames[d.Name] = true
+		depotWallets[walletNames[d.WalletID]] = true
+	}
+	if firstWallet != "" && hasBudget {
+		for _, importWallet := range data.Settings.Wallets {
+			if !importWallet.IsDepot || depotNames[importWallet.Name] {
+				continue
+			}
+			depotNames[importWallet.Name] = true
+			depotWallets[firstWallet] = true
+			plan.depots = append(plan.depots, importWallet.Name)
+		}
+	}
+
+	walletCounts := make(map[string]*domain.ImportResult)
+	var walletOrder []string
+	seen := make(map[string]int)
+	planned := make(map[string]bool)
+	for i := len(data.Transactions) - 1; i >= 0; i-- {
+		importTx := data.Transactions[i]
+		walletID, ok := walletIDs[importTx.Wallet]
+		if !ok {
+			plan.preview.Dropped = append(plan.preview.Dropped, domain.ImportDroppedRow{
+				Row:         i + 1,
+				Wallet:      importTx.Wallet,
+				Description: importTx.Description,
+			})
+			continue
+		}
+
+		txType := domain.TransactionType(strings.ToUpper(importTx.Type))
+		amount := importTx.AmountInCents
+		if amount < 0 {
+			amount = -amount
+		}
+
+		isDebt := importTx.IsDebt
+		isPending := importTx.IsPending
+		row := plannedRow{
+			wallet: importTx.Wallet,
+			transaction: domain.Transaction{
+				UserID:        userID,
+				Date:          importTx.Date,
+				Description:   importTx.Description,
+				AmountInCents: amount,
+				Type:          txType,
+				IsPending:     &isPending,
+				IsDebt:        &isDebt,
+				WalletID:      walletID,
+				ExternalID:    importExternalID(importTx, walletID, seen),
+			},
+		}
+
+		if importTx.Budget != "" && importTx.Budget != importTransferBudget {
+			if budgetID, ok := budgetIDs[importTx.Budget]; ok {
+				row.budget = importTx.Budget
+				row.transaction.BudgetID = &budgetID
+			}
+		}
+
+		counts, ok := walletCounts[importTx.Wallet]
+		if !ok {
+			counts = &domain.ImportResult{}
+			walletCounts[importTx.Wallet] = counts
+			walletOrder = append(walletOrder, importTx.Wallet)
+		}
+
+		tradeType, quantity, wkn, isTrade := parseTradeDescription(importTx.Description)
+
+		if planned[row.transaction.ExternalID] {
+			// The same row twice in one file.
+			counts.Skipped++
+			continue
+		}
+		planned[row.transaction.ExternalID] = true
+
+		existing, err := repos.TransactionRepository().FindTransactionByExternalID(userID, row.transaction.ExternalID)
+		if err == nil {
+			// Trades hang off their wallet transaction, and reconciled
+			// transactions are locked, so those are never rewritten by a
+			// re-import.
+			if _, changed := mergeImportedTransaction(existing, row.transaction); !changed || isTrade || existing.ReconciliationID != nil {
+				counts.Skipped++
+				continue
+			}
+			row.existing = &existing
+			plan.rows = append(plan.rows, row)
+			counts.Updated++
+			continue
+		}
+		if err != domain.ErrTransactionNotFound {
+			return importPlan{}, fmt.Errorf("failed to look up transaction %q: %w", importTx.Description, err)
+		}
+
+		row.create = true
+		if isTrade {
+			if !depotWallets[importTx.Wallet] {
+				return importPlan{}, fmt.Errorf("transaction %q looks like a trade but wallet %q has no depot", importTx.Description, importTx.Wallet)
+			}
+			row.trade = &domain.Trade{
+				WKN:          wkn,
+				Type:         tradeType,
+				Quantity:     quantity,
+				TotalInCents: amount,
+				Timestamp:    normalizeTradeTimestamp(importTx.Date),
+			}
+			plan.preview.Trades = append(plan.preview.Trades, domain.ImportTradePreview{
+				Row:         i + 1,
+				Wallet:      importTx.Wallet,
+				Description: importTx.Description,
+				Type:        tradeType,
+				Quantity:    quantity,
+				WKN:         wkn,
+			})
+		}
+		plan.rows = append(plan.rows, row)
+		counts.Created++
+	}
+
+	plan.preview.NewWallets = append(plan.preview.NewWallets, plan.wallets...)
+	for _, b := range plan.budgets {
+		plan.preview.NewBudgets = append(plan.preview.NewBudgets, b.Name)
+	}
+	plan.preview.NewDepots = append(plan.preview.NewDepots, plan.depots...)
+	for _, wallet := range walletOrder {
+		counts := *walletCounts[wallet]
+		plan.preview.Wallets = append(plan.preview.Wallets, domain.ImportWalletCounts{Wallet: wallet, ImportResult: counts})
+		plan.preview.Result.Created += counts.Created
+		plan.preview.Result.Skipped += counts.Skipped
+		plan.preview.Result.Updated += counts.Updated
+	}
+
+	token, err := importPlanToken(userID, plan.preview)
+	if err != nil {
+		return importPlan{}, err
+	}
+	plan.preview.Token = token
+	return plan, nil
+}
+
+// importPlanToken fingerprints a preview, so a confirmation can tell whether
+// the import still does what was previewed.
+func importPlanToken(userID int, preview domain.ImportPreview) (string, error) {
+	preview.Token = ""
+	encoded, err := json.Marshal(preview)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode import preview: %w", err)
+	}
+	sum := sha256.Sum256(append([]byte(fmt.Sprintf("%d|", userID)), encoded...))
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// applyImportPlan writes the plan and notes the transactions it books in
+// changes.
+func applyImportPlan(repos ports.Repositories, userID int, plan importPlan, changes *bookedChanges) error {
+	if plan.salary > 0 {
+		if err := repos.UserRepository().UpdateUserSalary(userID, plan.salary); err != nil {
+			return fmt.Errorf("failed to update salary: %w", err)
+		}
+	}
+
+	for _, name := range plan.wallets {
+		if err := repos.WalletRepository().SaveWallet(domain.Wallet{UserID: userID, Name: name, Type: domain.WalletChecking}); err != nil {
+			return fmt.Errorf("failed to save wallet %s: %w", name, err)
+		}
+	}
+	for _, importBudget := range plan.budgets {
+		b := domain.Budget{
+			UserID:     userID,
+			Name:       importBudget.Name,
+			LimitCents: importBudget.ValueInCents,
+		}
+		if err := repos.BudgetRepository().SaveBudget(b); err != nil {
+			return fmt.Errorf("failed to save budget %s: %w", importBudget.Name, err)
+		}
+	}
+
+	// Refetch to get the IDs of what was just created
+	wallets, err := repos.WalletRepository().FindWalletsByUser(userID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch wallets: %w", err)
+	}
+	walletIDs := make(map[string]int)
+	for _, w := range wallets {
+		walletIDs[w.Name] = w.ID
+	}
+	budgets, err := repos.BudgetRepository().FindBudgetsByUser(userID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch budgets: %w", err)
+	}
+	budgetIDs := make(map[string]int)
+	for _, b := range budgets {
+		budgetIDs[b.Name] = b.ID
+	}
+
+	for _, name := range plan.depots {
+		d := domain.Depot{
+			UserID:   userID,
+			Name:     name,
+			WalletID: wallets[0].ID,
+			BudgetID: budgets[0].ID,
+		}
+		if err := repos.DepotRepository().SaveDepot(d); err != nil {
+			return fmt.Errorf("failed to save depot %s: %w", name, err)
+		}
+	}
+	depots, err := repos.DepotRepository().FindDepotsByUser(userID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch depots: %w", err)
+	}
+	depotByWallet := make(map[int]int)
+	for _, d := range depots {
+		depotByWallet[d.WalletID] = d.ID
+	}
+	stockService := NewStockService(repos.StockRepository(), repos.TradeRepository())
+
+	for _, row := range plan.rows {
+		t := row.transaction
+		t.WalletID = walletIDs[row.wallet]
+		if row.budget != "" {
+			budgetID := budgetIDs[row.budget]
+			t.BudgetID = &budgetID
+		}
+
+		if !row.create {
+			merged, _ := mergeImportedTransaction(*row.existing, t)
+			if err := repos.TransactionRepository().UpdateTransaction(merged); err != nil {
+				return fmt.Errorf("failed to update transaction %q: %w", t.Description, err)
+			}
+			changes.updated(*row.existing, merged)
+			continue
+		}
+
+		transactionID, err := repos.TransactionRepository().SaveTransaction(t)
+		if err != nil {
+			return fmt.Errorf("failed to save transaction: %w", err)
+		}
+		changes.created(t)
+		if row.trade == nil {
+			continue
+		}
+
+		trade := *row.trade
+		depotID, ok := depotByWallet[t.WalletID]
+		if !ok {
+			return fmt.Errorf("transaction %q looks like a trade but wallet %q has no depot", t.Description, row.wallet)
+		}
+		fallback := int(math.Round(float64(trade.TotalInCents) / trade.Quantity))
+		stock, err := stockService.GetOrCreateByWKN(trade.WKN, fallback)
+		if err != nil {
+			return fmt.Errorf("failed to resolve stock %q: %w", trade.WKN, err)
+		}
+		trade.DepotID = depotID
+		trade.StockID = stock.ID
+		trade.WalletTransactionID = &transactionID
+		if _, err := repos.TradeRepository().SaveTrade(trade); err != nil {
+			return fmt.Errorf("failed to save trade for transaction %q: %w", t.Description, err)
+		}
+	}
+
+	return nil
+}
+
+// importExternalID identifies an imported row across imports: by the ID its
+// source gave it or, lacking one, by a hash of its content. The hash takes the
+// wallet's ID rather than its name, so renaming the wallet does not make its
+// rows look new. Identical rows in one file, like two coffees on the same day,
+// are told apart by the order in which they occur.
+func importExternalID(t domain.ImportTransaction, walletID int, seen map[string]int) string {
+	if id := strings.TrimSpace(t.ID); id != "" {
+		return id
+	}
+
+	amount := t.AmountInCents
+	if amount < 0 {
+		amount = -amount
+	}
+	content := fmt.Sprintf("%s|%d|%d|%s|%s", t.Date.Format("2006-01-02"), walletID, amount, strings.ToUpper(t.Type), strings.TrimSpace(t.Description))
+	seen[content]++
+
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%d", content, seen[content])))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// mergeImportedTransaction applies an imported row to the transaction it was
+// imported as before. The import decides date, wallet, amount, type,
+// description and whether it is pending; a budget or debt flag is only ever
+// added, so assignments made in the app survive a re-import.
+func mergeImportedTransaction(existing, imported domain.Transaction) (domain.Transaction, bool) {
+	merged := existing
+	merged.Date = imported.Date
+	merged.WalletID = imported.WalletID
+	merged.AmountInCents = imported.AmountInCents
+	merged.Type = imported.Type
+	merged.Description = imported.Description
+	merged.IsPending = imported.IsPending
+	// A split no longer adds up once the bank reports another amount, so the
+	// transaction falls back to the imported budget; otherwise it stays split.
+	if len(existing.Splits) > 0 && imported.AmountInCents != existing.AmountInCents {
+		merged.Splits = nil
+	}
+	if imported.BudgetID != nil && len(merged.Splits) == 0 {
+		merged.BudgetID = imported.BudgetID
+	}
+	if imported.IsDebt != nil && *imported.IsDebt {
+		merged.IsDebt = imported.IsDebt
+		merged.Splits = nil
+	}
+
+	changed := !merged.Date.Equal(existing.Date) ||
+		merged.WalletID != existing.WalletID ||
+		merged.AmountInCents != existing.AmountInCents ||
+		merged.Type != existing.Type ||
+		merged.Description != existing.Description ||
+		isSet(merged.IsPending) != isSet(existing.IsPending) ||
+		isSet(merged.IsDebt) != isSet(existing.IsDebt) ||
+		!sameBudget(merged.BudgetID, existing.BudgetID)
+	return merged, changed
+}
+
+func isSet(flag *bool) bool {
+	return flag != nil && *flag
+}
+
+func sameBudget(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
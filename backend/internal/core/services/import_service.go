@@ -3,7 +3,6 @@ package services
 import (
 	"encoding/json"
 	"fmt"
-	"math"
 	"os"
 	"regexp"
 	"strconv"
@@ -46,260 +45,133 @@ func parseTradeDescription(description string) (tradeType domain.TradeType, quan
 }
 
 type importService struct {
-	userRepo                ports.UserRepository
-	budgetRepo              ports.BudgetRepository
-	walletRepo              ports.WalletRepository
-	depotRepo               ports.DepotRepository
-	transactionRepo         ports.TransactionRepository
-	tradeRepo               ports.TradeRepository
-	transactionTemplateRepo ports.TransactionTemplateRepository
-	stockService            ports.StockService
+	repos  ports.Repositories
+	alerts ports.BudgetAlertService
 }
 
-func NewImportService(
-	userRepo ports.UserRepository,
-	budgetRepo ports.BudgetRepository,
-	walletRepo ports.WalletRepository,
-	depotRepo ports.DepotRepository,
-	transactionRepo ports.TransactionRepository,
-	tradeRepo ports.TradeRepository,
-	transactionTemplateRepo ports.TransactionTemplateRepository,
-	stockService ports.StockService,
-) ports.ImportService {
-	return &importService{
-		userRepo:                userRepo,
-		budgetRepo:              budgetRepo,
-		walletRepo:              walletRepo,
-		depotRepo:               depotRepo,
-		transactionRepo:         transactionRepo,
-		tradeRepo:               tradeRepo,
-		transactionTemplateRepo: transactionTemplateRepo,
-		stockService:            stockService,
-	}
+// NewImportService takes the repositories as a whole, as an import writes to
+// most of them in one unit of work.
+func NewImportService(repos ports.Repositories, alerts ports.BudgetAlertService) ports.ImportService {
+	return &importService{repos: repos, alerts: alerts}
 }
 
 func (s *importService) DeleteAllUserData(userID int) error {
-	if err := s.transactionRepo.DeleteAllByUser(userID); err != nil {
-		return fmt.Errorf("failed to delete transactions: %w", err)
-	}
-
-	if err := s.transactionTemplateRepo.DeleteAllByUser(userID); err != nil {
-		return fmt.Errorf("failed to delete templates: %w", err)
+	if err := s.repos.SharedExpenseRepository().DeleteAllByUser(userID); err != nil {
+		return fmt.Errorf("failed to delete shared expenses: %w", err)
 	}
 
-	if err := s.tradeRepo.DeleteAllByUser(userID); err != nil {
-		return fmt.Errorf("failed to delete trades: %w", err)
+	if err := s.repos.MembershipRepository().DeleteAllByUser(userID); err != nil {
+		return fmt.Errorf("failed to delete memberships: %w", err)
 	}
 
-	if err := s.depotRepo.DeleteAllByUser(userID); err != nil {
-		return fmt.Errorf("failed to delete depots: %w", err)
+	if err := s.repos.TransactionRepository().DeleteAllByUser(userID); err != nil {
+		return fmt.Errorf("failed to delete transactions: %w", err)
 	}
 
-	if err := s.budgetRepo.DeleteAllByUser(userID); err != nil {
-		return fmt.Errorf("failed to delete budgets: %w", err)
+	if err := s.repos.CounterpartyRepository().DeleteAllByUser(userID); err != nil {
+		return fmt.Errorf("failed to delete counterparties: %w", err)
 	}
 
-	if err := s.walletRepo.DeleteAllByUser(userID); err != nil {
-		return fmt.Errorf("failed to delete wallets: %w", err)
+	if err := s.repos.SavingsGoalRepository().DeleteAllByUser(userID); err != nil {
+		return fmt.Errorf("failed to delete savings goals: %w", err)
 	}
 
-	if err := s.userRepo.UpdateUserSalary(userID, 0); err != nil {
-		return fmt.Errorf("failed to reset salary: %w", err)
+	if err := s.repos.ReconciliationRepository().DeleteAllByUser(userID); err != nil {
+		return fmt.Errorf("failed to delete reconciliations: %w", err)
 	}
 
-	return nil
-}
-
-func (s *importService) ImportData(userID int, data domain.FullImportData) error {
-	if data.Settings.Gehalt > 0 {
-		if err := s.userRepo.UpdateUserSalary(userID, data.Settings.Gehalt); err != nil {
-			return fmt.Errorf("failed to update salary: %w", err)
-		}
+	if err := s.repos.TransactionTemplateRepository().DeleteAllByUser(userID); err != nil {
+		return fmt.Errorf("failed to delete templates: %w", err)
 	}
 
-	existingWallets, err := s.walletRepo.FindWalletsByUser(userID)
-	if err != nil {
-		return fmt.Errorf("failed to fetch existing wallets: %w", err)
-	}
-	walletMap := make(map[string]int)
-	for _, w := range existingWallets {
-		walletMap[w.Name] = w.ID
+	if err := s.repos.TradeRepository().DeleteAllByUser(userID); err != nil {
+		return fmt.Errorf("failed to delete trades: %w", err)
 	}
 
-	for _, importWallet := range data.Settings.Wallets {
-		if importWallet.IsDepot {
-			continue
-		}
-		if _, exists := walletMap[importWallet.Name]; !exists {
-			w := domain.Wallet{
-				UserID: userID,
-				Name:   importWallet.Name,
-			}
-			if err := s.walletRepo.SaveWallet(w); err != nil {
-				return fmt.Errorf("failed to save wallet %s: %w", importWallet.Name, err)
-			}
-		}
+	if err := s.repos.DepotRepository().DeleteAllByUser(userID); err != nil {
+		return fmt.Errorf("failed to delete depots: %w", err)
 	}
 
-	// Refetch to get newly created Ids
-	existingWallets, err = s.walletRepo.FindWalletsByUser(userID)
-	if err != nil {
-		return err
-	}
-	for _, w := range existingWallets {
-		walletMap[w.Name] = w.ID
+	if err := s.repos.BudgetRepository().DeleteAllByUser(userID); err != nil {
+		return fmt.Errorf("failed to delete budgets: %w", err)
 	}
 
-	var firstWalletID int
-	if len(existingWallets) > 0 {
-		firstWalletID = existingWallets[0].ID
+	if err := s.repos.WalletRepository().DeleteAllByUser(userID); err != nil {
+		return fmt.Errorf("failed to delete wallets: %w", err)
 	}
 
-	existingBudgets, err := s.budgetRepo.FindBudgetsByUser(userID)
-	if err != nil {
-		return fmt.Errorf("failed to fetch existing budgets: %w", err)
-	}
-	budgetMap := make(map[string]int)
-	for _, b := range existingBudgets {
-		budgetMap[b.Name] = b.ID
+	if err := s.repos.CSVProfileRepository().DeleteAllByUser(userID); err != nil {
+		return fmt.Errorf("failed to delete csv profiles: %w", err)
 	}
 
-	for _, importBudget := range data.Settings.Budgets {
-		if strings.ToLower(importBudget.Account) != "private" {
-			continue
-		}
-		if _, exists := budgetMap[importBudget.Name]; !exists {
-			b := domain.Budget{
-				UserID:     userID,
-				Name:       importBudget.Name,
-				LimitCents: importBudget.ValueInCents,
-			}
-			if err := s.budgetRepo.SaveBudget(b); err != nil {
-				return fmt.Errorf("failed to save budget %s: %w", importBudget.Name, err)
-			}
-		}
+	if err := s.repos.NotificationRepository().DeleteAllByUser(userID); err != nil {
+		return fmt.Errorf("failed to delete notifications: %w", err)
 	}
 
-	// Refetch budgets to get ids
-	existingBudgets, err = s.budgetRepo.FindBudgetsByUser(userID)
-	if err != nil {
-		return err
-	}
-	for _, b := range existingBudgets {
-		budgetMap[b.Name] = b.ID
+	if err := s.repos.UserRepository().UpdateUserSalary(userID, 0); err != nil {
+		return fmt.Errorf("failed to reset salary: %w", err)
 	}
 
-	var firstBudgetID int
-	if len(existingBudgets) > 0 {
-		firstBudgetID = existingBudgets[0].ID
-	}
+	return nil
+}
 
-	if firstWalletID != 0 && firstBudgetID != 0 {
-		existingDepots, err := s.depotRepo.FindDepotsByUser(userID)
+// ImportData imports everything or, if any part of the import fails,
+// nothing.
+func (s *importService) ImportData(userID int, data domain.FullImportData) (domain.ImportResult, error) {
+	var result domain.ImportResult
+	var changes bookedChanges
+	err := s.repos.WithinTransaction(func(repos ports.Repositories) error {
+		plan, err := planImport(repos, userID, data)
 		if err != nil {
-			return fmt.Errorf("failed to fetch existing depots: %w", err)
-		}
-		depotMap := make(map[string]bool)
-		for _, d := range existingDepots {
-			depotMap[d.Name] = true
+			return err
 		}
-
-		for _, importWallet := range data.Settings.Wallets {
-			if !importWallet.IsDepot {
-				continue
-			}
-			if !depotMap[importWallet.Name] {
-				d := domain.Depot{
-					UserID:   userID,
-					Name:     importWallet.Name,
-					WalletID: firstWalletID,
-					BudgetID: firstBudgetID,
-				}
-				if err := s.depotRepo.SaveDepot(d); err != nil {
-					return fmt.Errorf("failed to save depot %s: %w", importWallet.Name, err)
-				}
-			}
+		if err := applyImportPlan(repos, userID, plan, &changes); err != nil {
+			return err
 		}
+		result = plan.preview.Result
+		return nil
+	})
+	if err != nil {
+		return domain.ImportResult{}, err
 	}
+	s.alerts.TransactionsChanged(changes.before, changes.after)
+	return result, nil
+}
 
-	existingDepots, err := s.depotRepo.FindDepotsByUser(userID)
+func (s *importService) PreviewImport(userID int, data domain.FullImportData) (domain.ImportPreview, error) {
+	plan, err := planImport(s.repos, userID, data)
 	if err != nil {
-		return fmt.Errorf("failed to fetch existing depots: %w", err)
-	}
-	depotByWallet := make(map[int]int)
-	for _, d := range existingDepots {
-		depotByWallet[d.WalletID] = d.ID
+		return domain.ImportPreview{}, err
 	}
+	return plan.preview, nil
+}
 
-	for i := len(data.Transactions) - 1; i >= 0; i-- {
-		importTx := data.Transactions[i]
-		walletID, ok := walletMap[importTx.Wallet]
-		if !ok {
-			continue
-		}
-
-		txType := domain.TransactionType(strings.ToUpper(importTx.Type))
-		amount := importTx.AmountInCents
-		if amount < 0 {
-			amount = -amount
-		}
-
-		isDebt := importTx.IsDebt
-		isPending := importTx.IsPending
-		t := domain.Transaction{
-			UserID:        userID,
-			Date:          importTx.Date,
-			Description:   importTx.Description,
-			AmountInCents: amount,
-			Type:          txType,
-			IsPending:     &isPending,
-			IsDebt:        &isDebt,
-			WalletID:      walletID,
-		}
-
-		if importTx.Budget != "" && importTx.Budget != importTransferBudget {
-			if budgetId, ok := budgetMap[importTx.Budget]; ok {
-				t.BudgetID = &budgetId
-			}
-		}
-
-		transactionID, err := s.transactionRepo.SaveTransaction(t)
-		if err != nil {
-			return fmt.Errorf("failed to save transaction: %w", err)
-		}
-
-		tradeType, quantity, wkn, isTrade := parseTradeDescription(importTx.Description)
-		if !isTrade {
-			continue
-		}
-
-		depotID, ok := depotByWallet[walletID]
-		if !ok {
-			return fmt.Errorf("transaction %q looks like a trade but wallet %q has no depot", importTx.Description, importTx.Wallet)
-		}
-
-		fallback := int(math.Round(float64(amount) / quantity))
-		stock, err := s.stockService.GetOrCreateByWKN(wkn, fallback)
+// ConfirmImport applies an import previewed before. The plan is worked out
+// again and only applied if it still is the one the token was issued for, so
+// nothing that changed in between, like a wallet created or renamed, makes
+// the import do something other than what was previewed.
+func (s *importService) ConfirmImport(userID int, data domain.FullImportData, token string) (domain.ImportResult, error) {
+	var result domain.ImportResult
+	var changes bookedChanges
+	err := s.repos.WithinTransaction(func(repos ports.Repositories) error {
+		plan, err := planImport(repos, userID, data)
 		if err != nil {
-			return fmt.Errorf("failed to resolve stock %q: %w", wkn, err)
+			return err
 		}
-
-		trade := domain.Trade{
-			DepotID:             depotID,
-			WalletTransactionID: &transactionID,
-			WKN:                 wkn,
-			StockID:             stock.ID,
-			Type:                tradeType,
-			Quantity:            quantity,
-			TotalInCents:        amount,
-			Timestamp:           normalizeTradeTimestamp(t.Date),
+		if plan.preview.Token != token {
+			return domain.ErrImportPlanChanged
 		}
-		if _, err := s.tradeRepo.SaveTrade(trade); err != nil {
-			return fmt.Errorf("failed to save trade for transaction %q: %w", importTx.Description, err)
+		if err := applyImportPlan(repos, userID, plan, &changes); err != nil {
+			return err
 		}
+		result = plan.preview.Result
+		return nil
+	})
+	if err != nil {
+		return domain.ImportResult{}, err
 	}
-
-	return nil
+	s.alerts.TransactionsChanged(changes.before, changes.after)
+	return result, nil
 }
 
 func (s *importService) ImportTestData(userID int) error {
@@ -314,5 +186,6 @@ func (s *importService) ImportTestData(userID int) error {
 		return fmt.Errorf("could not decode testdata: %w", err)
 	}
 
-	return s.ImportData(userID, data)
+	_, err = s.ImportData(userID, data)
+	return err
 }
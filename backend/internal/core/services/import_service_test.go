@@ -1,18 +1,20 @@
 package services
 
 import (
+	"errors"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/fim-lab/expense-tracker/adapters/repository/memory"
 	"github.com/fim-lab/expense-tracker/internal/core/domain"
+	"github.com/fim-lab/expense-tracker/internal/core/ports"
 )
 
 func TestImportTransactions(t *testing.T) {
 	repos := memory.NewCleanRepositories()
-	svc := NewTransactionService(repos.TransactionRepository(), repos.BudgetRepository(), repos.WalletRepository())
-	stockSvc := NewStockService(repos.StockRepository(), repos.TradeRepository())
-	importSvc := NewImportService(repos.UserRepository(), repos.BudgetRepository(), repos.WalletRepository(), repos.DepotRepository(), repos.TransactionRepository(), repos.TradeRepository(), repos.TransactionTemplateRepository(), stockSvc)
+	svc := NewTransactionService(repos.TransactionRepository(), NewAuthorizer(repos))
+	importSvc := NewImportService(repos, noBudgetAlerts{})
 
 	userID := 1
 	repos.UserRepository().SaveUser(domain.User{ID: userID, Username: "test"})
@@ -56,7 +58,7 @@ func TestImportTransactions(t *testing.T) {
 		},
 	}
 
-	err := importSvc.ImportData(userID, importData)
+	_, err := importSvc.ImportData(userID, importData)
 	if err != nil {
 		t.Fatalf("ImportData failed: %v", err)
 	}
@@ -95,16 +97,7 @@ func TestImportTransactions(t *testing.T) {
 
 func TestImportTransactions_TradesAndSpecialCases(t *testing.T) {
 	f := newStockFixture(t)
-	importSvc := NewImportService(
-		f.repos.UserRepository(),
-		f.repos.BudgetRepository(),
-		f.repos.WalletRepository(),
-		f.repos.DepotRepository(),
-		f.repos.TransactionRepository(),
-		f.repos.TradeRepository(),
-		f.repos.TransactionTemplateRepository(),
-		f.stockSvc,
-	)
+	importSvc := NewImportService(f.repos, noBudgetAlerts{})
 	if err := f.repos.UserRepository().SaveUser(domain.User{ID: f.userID, Username: "test"}); err != nil {
 		t.Fatalf("could not seed the user: %v", err)
 	}
@@ -139,7 +132,7 @@ func TestImportTransactions_TradesAndSpecialCases(t *testing.T) {
 		},
 	}
 
-	if err := importSvc.ImportData(f.userID, importData); err != nil {
+	if _, err := importSvc.ImportData(f.userID, importData); err != nil {
 		t.Fatalf("ImportData failed: %v", err)
 	}
 
@@ -198,16 +191,7 @@ func TestImportTransactions_TradesAndSpecialCases(t *testing.T) {
 
 func TestDeleteAllUserDataRemovesTrades(t *testing.T) {
 	f := newStockFixture(t)
-	importSvc := NewImportService(
-		f.repos.UserRepository(),
-		f.repos.BudgetRepository(),
-		f.repos.WalletRepository(),
-		f.repos.DepotRepository(),
-		f.repos.TransactionRepository(),
-		f.repos.TradeRepository(),
-		f.repos.TransactionTemplateRepository(),
-		f.stockSvc,
-	)
+	importSvc := NewImportService(f.repos, noBudgetAlerts{})
 	if err := f.repos.UserRepository().SaveUser(domain.User{ID: f.userID, Username: "test"}); err != nil {
 		t.Fatalf("could not seed the user: %v", err)
 	}
@@ -228,3 +212,242 @@ func TestDeleteAllUserDataRemovesTrades(t *testing.T) {
 		t.Errorf("expected no depots left, got %v (err %v)", depots, err)
 	}
 }
+
+func TestImportData_ReimportIsIdempotent(t *testing.T) {
+	f := newStockFixture(t)
+	importSvc := NewImportService(f.repos, noBudgetAlerts{})
+
+	day := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	importData := domain.FullImportData{
+		Transactions: []domain.ImportTransaction{
+			{ID: "REF-1", Date: day, Wallet: "Main Wallet", Description: "Miete", AmountInCents: -85000, Type: "expense"},
+			// Two identical rows without ID are both kept.
+			{Date: day, Wallet: "Main Wallet", Description: "Kaffee", AmountInCents: -350, Type: "expense"},
+			{Date: day, Wallet: "Main Wallet", Description: "Kaffee", AmountInCents: -350, Type: "expense"},
+			{Date: day, Wallet: "Main Wallet", Description: "Buy 2Stk. TEST", AmountInCents: -20000, Type: "expense"},
+		},
+	}
+
+	first, err := importSvc.ImportData(f.userID, importData)
+	if err != nil {
+		t.Fatalf("first import failed: %v", err)
+	}
+	if first != (domain.ImportResult{Created: 4}) {
+		t.Errorf("expected 4 created rows, got %+v", first)
+	}
+	walletAfterFirst, _ := f.repos.WalletRepository().GetWalletByID(f.walletID)
+
+	second, err := importSvc.ImportData(f.userID, importData)
+	if err != nil {
+		t.Fatalf("second import failed: %v", err)
+	}
+	if second != (domain.ImportResult{Skipped: 4}) {
+		t.Errorf("expected every row to be skipped, got %+v", second)
+	}
+	if count := f.transactionCount(t); count != 4 {
+		t.Errorf("expected 4 transactions, got %d", count)
+	}
+	if count := f.tradeCount(t); count != 1 {
+		t.Errorf("expected 1 trade, got %d", count)
+	}
+	wallet, _ := f.repos.WalletRepository().GetWalletByID(f.walletID)
+	if wallet.BalanceCents != walletAfterFirst.BalanceCents {
+		t.Errorf("expected the balance to stay at %d, got %d", walletAfterFirst.BalanceCents, wallet.BalanceCents)
+	}
+
+	// The bank corrected the rent; the row with the same ID is updated.
+	importData.Transactions[0].AmountInCents = -90000
+	third, err := importSvc.ImportData(f.userID, importData)
+	if err != nil {
+		t.Fatalf("third import failed: %v", err)
+	}
+	if third != (domain.ImportResult{Skipped: 3, Updated: 1}) {
+		t.Errorf("expected the rent to be updated, got %+v", third)
+	}
+	wallet, _ = f.repos.WalletRepository().GetWalletByID(f.walletID)
+	if wallet.BalanceCents != walletAfterFirst.BalanceCents-5000 {
+		t.Errorf("expected the balance to follow the correction, got %d", wallet.BalanceCents)
+	}
+
+	// Rows without ID are still recognized once the wallet is renamed.
+	wallet.Name = "Girokonto"
+	if err := f.repos.WalletRepository().UpdateWallet(wallet); err != nil {
+		t.Fatalf("renaming the wallet failed: %v", err)
+	}
+	for i := range importData.Transactions {
+		importData.Transactions[i].Wallet = "Girokonto"
+	}
+	if fourth, err := importSvc.ImportData(f.userID, importData); err != nil || fourth != (domain.ImportResult{Skipped: 4}) {
+		t.Errorf("expected every row to be skipped after renaming the wallet, got %+v (%v)", fourth, err)
+	}
+}
+
+func TestPreviewImport_WritesNothingAndConfirmAppliesThePlan(t *testing.T) {
+	repos := memory.NewCleanRepositories()
+	importSvc := NewImportService(repos, noBudgetAlerts{})
+
+	userID := 1
+	repos.UserRepository().SaveUser(domain.User{ID: userID, Username: "test"})
+
+	day := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	importData := domain.FullImportData{
+		Settings: domain.ImportSettings{
+			Budgets: []domain.ImportBudget{{Name: "Food", ValueInCents: 50000, Account: "Private"}},
+			Wallets: []domain.ImportWallet{
+				{Name: "Girokonto"},
+				{Name: "Depot", IsDepot: true},
+			},
+		},
+		Transactions: []domain.ImportTransaction{
+			{Date: day, Wallet: "Girokonto", Description: "Buy 2Stk. TEST", AmountInCents: -20000, Type: "expense"},
+			{Date: day, Wallet: "Girokonto", Budget: "Food", Description: "Einkauf", AmountInCents: -4500, Type: "expense"},
+			{Date: day, Wallet: "Unbekannt", Description: "Kaffee", AmountInCents: -350, Type: "expense"},
+		},
+	}
+
+	preview, err := importSvc.PreviewImport(userID, importData)
+	if err != nil {
+		t.Fatalf("preview failed: %v", err)
+	}
+	if preview.Token == "" {
+		t.Error("expected the preview to carry a token")
+	}
+	if len(preview.NewWallets) != 1 || preview.NewWallets[0] != "Girokonto" {
+		t.Errorf("expected Girokonto to be created, got %v", preview.NewWallets)
+	}
+	if len(preview.NewBudgets) != 1 || len(preview.NewDepots) != 1 {
+		t.Errorf("expected one budget and one depot to be created, got %v and %v", preview.NewBudgets, preview.NewDepots)
+	}
+	if len(preview.Dropped) != 1 || preview.Dropped[0].Row != 3 || preview.Dropped[0].Wallet != "Unbekannt" {
+		t.Errorf("expected row 3 to be dropped for its unknown wallet, got %+v", preview.Dropped)
+	}
+	if len(preview.Trades) != 1 || preview.Trades[0].WKN != "TEST" || preview.Trades[0].Quantity != 2 {
+		t.Errorf("expected the trade to be detected, got %+v", preview.Trades)
+	}
+	if preview.Result != (domain.ImportResult{Created: 2}) {
+		t.Errorf("expected 2 rows to be created, got %+v", preview.Result)
+	}
+	if wallets, _ := repos.WalletRepository().FindWalletsByUser(userID); len(wallets) != 0 {
+		t.Errorf("expected the preview to create no wallet, got %d", len(wallets))
+	}
+	if txs, _ := repos.TransactionRepository().FindTransactionsByUser(userID, 0, 0); len(txs) != 0 {
+		t.Errorf("expected the preview to write no transactions, got %d", len(txs))
+	}
+
+	again, _ := importSvc.PreviewImport(userID, importData)
+	if again.Token != preview.Token {
+		t.Error("expected the same import to be previewed with the same token")
+	}
+
+	result, err := importSvc.ConfirmImport(userID, importData, preview.Token)
+	if err != nil {
+		t.Fatalf("confirm failed: %v", err)
+	}
+	if result != preview.Result {
+		t.Errorf("expected the confirmed import to do what was previewed, got %+v", result)
+	}
+	if txs, _ := repos.TransactionRepository().FindTransactionsByUser(userID, 0, 0); len(txs) != 2 {
+		t.Errorf("expected 2 transactions, got %d", len(txs))
+	}
+	depots, _ := repos.DepotRepository().FindDepotsByUser(userID)
+	if len(depots) != 1 {
+		t.Fatalf("expected the depot to be created, got %d depots", len(depots))
+	}
+	if trades, _ := repos.TradeRepository().FindTradesByDepot(depots[0].ID); len(trades) != 1 {
+		t.Errorf("expected 1 trade, got %d", len(trades))
+	}
+}
+
+func TestConfirmImport_RejectsAPlanThatChanged(t *testing.T) {
+	f := newStockFixture(t)
+	importSvc := NewImportService(f.repos, noBudgetAlerts{})
+
+	day := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	importData := domain.FullImportData{
+		Transactions: []domain.ImportTransaction{
+			{Date: day, Wallet: "Tagesgeld", Description: "Zinsen", AmountInCents: 120, Type: "income"},
+		},
+	}
+
+	preview, err := importSvc.PreviewImport(f.userID, importData)
+	if err != nil {
+		t.Fatalf("preview failed: %v", err)
+	}
+	if len(preview.Dropped) != 1 {
+		t.Fatalf("expected the row to be dropped while Tagesgeld does not exist, got %+v", preview.Dropped)
+	}
+
+	// The wallet is created between preview and confirmation, so the row
+	// would now be imported instead of dropped.
+	if err := f.repos.WalletRepository().SaveWallet(domain.Wallet{ID: 10, UserID: f.userID, Name: "Tagesgeld"}); err != nil {
+		t.Fatalf("could not save the wallet: %v", err)
+	}
+
+	_, err = importSvc.ConfirmImport(f.userID, importData, preview.Token)
+	if !errors.Is(err, domain.ErrImportPlanChanged) {
+		t.Fatalf("expected ErrImportPlanChanged, got %v", err)
+	}
+	if count := f.transactionCount(t); count != 0 {
+		t.Errorf("expected nothing to be imported, got %d transactions", count)
+	}
+}
+
+// failingTrades are repositories whose trade repository refuses every trade,
+// so an import fails after it already wrote wallets and transactions.
+type failingTrades struct {
+	ports.Repositories
+}
+
+func (r failingTrades) TradeRepository() ports.TradeRepository {
+	return failingTradeRepository{r.Repositories.TradeRepository()}
+}
+
+func (r failingTrades) WithinTransaction(fn func(repos ports.Repositories) error) error {
+	return r.Repositories.WithinTransaction(func(repos ports.Repositories) error {
+		return fn(failingTrades{repos})
+	})
+}
+
+type failingTradeRepository struct {
+	ports.TradeRepository
+}
+
+func (failingTradeRepository) SaveTrade(domain.Trade) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestImportData_FailedImportLeavesNoTrace(t *testing.T) {
+	f := newStockFixture(t)
+	f.repos.UserRepository().SaveUser(domain.User{ID: f.userID, Username: "test"})
+	importSvc := NewImportService(failingTrades{f.repos}, noBudgetAlerts{})
+
+	day := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	importData := domain.FullImportData{
+		Settings: domain.ImportSettings{
+			Gehalt:  300000,
+			Budgets: []domain.ImportBudget{{Name: "Food", ValueInCents: 50000, Account: "Private"}},
+		},
+		Transactions: []domain.ImportTransaction{
+			{Date: day, Wallet: "Main Wallet", Budget: "Food", Description: "Einkauf", AmountInCents: -4500, Type: "expense"},
+			{Date: day, Wallet: "Main Wallet", Description: "Buy 2Stk. TEST", AmountInCents: -20000, Type: "expense"},
+		},
+	}
+
+	_, err := importSvc.ImportData(f.userID, importData)
+	if err == nil || !strings.Contains(err.Error(), "disk full") {
+		t.Fatalf("expected the import to fail saving the trade, got %v", err)
+	}
+
+	if count := f.transactionCount(t); count != 0 {
+		t.Errorf("expected no transactions, got %d", count)
+	}
+	if budgets, _ := f.repos.BudgetRepository().FindBudgetsByUser(f.userID); len(budgets) != 1 {
+		t.Errorf("expected only the seeded budget, got %d budgets", len(budgets))
+	}
+	if wallet, _ := f.repos.WalletRepository().GetWalletByID(f.walletID); wallet.BalanceCents != 0 {
+		t.Errorf("expected the wallet balance to be untouched, got %d", wallet.BalanceCents)
+	}
+	if user, _ := f.repos.UserRepository().GetUserByID(f.userID); user.SalaryCents != 0 {
+		t.Errorf("expected the salary to be untouched, got %d", user.SalaryCents)
+	}
+}
@@ -0,0 +1,120 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fim-lab/expense-tracker/internal/core/domain"
+	"github.com/fim-lab/expense-tracker/internal/core/ports"
+)
+
+type membershipService struct {
+	membershipRepo ports.MembershipRepository
+	userRepo       ports.UserRepository
+	auth           ports.Authorizer
+}
+
+func NewMembershipService(membershipRepo ports.MembershipRepository, userRepo ports.UserRepository, auth ports.Authorizer) ports.MembershipService {
+	return &membershipService{membershipRepo: membershipRepo, userRepo: userRepo, auth: auth}
+}
+
+func (s *membershipService) Invite(userID int, m domain.Membership) (domain.Membership, error) {
+	if err := s.authorize(userID, m.ResourceType, m.ResourceID, domain.PermissionOwner); err != nil {
+		return domain.Membership{}, err
+	}
+	name := strings.TrimSpace(m.Username)
+	member, err := s.userRepo.GetUserByUsername(name)
+	if err != nil {
+		return domain.Membership{}, fmt.Errorf("%w: %s", domain.ErrUserNotFound, name)
+	}
+	m.OwnerID, m.UserID = userID, member.ID
+	if err := m.Validate(); err != nil {
+		return domain.Membership{}, err
+	}
+
+	m.ID, m.CreatedAt = 0, time.Now()
+	if existing, err := s.membershipRepo.GetMembership(m.ResourceType, m.ResourceID, m.UserID); err == nil {
+		m.ID, m.CreatedAt = existing.ID, existing.CreatedAt
+	}
+	if m.ID, err = s.membershipRepo.SaveMembership(m); err != nil {
+		return domain.Membership{}, err
+	}
+
+	names, err := s.usernames()
+	if err != nil {
+		return domain.Membership{}, err
+	}
+	nameMembership(&m, names)
+	return m, nil
+}
+
+func (s *membershipService) GetMembers(userID int, resourceType domain.ResourceType, resourceID int) ([]domain.Membership, error) {
+	if err := s.authorize(userID, resourceType, resourceID, domain.PermissionRead); err != nil {
+		return nil, err
+	}
+	members, err := s.membershipRepo.FindMembersOf(resourceType, resourceID)
+	if err != nil {
+		return nil, err
+	}
+	return s.named(members)
+}
+
+func (s *membershipService) RemoveMember(userID int, membershipID int) error {
+	m, err := s.membershipRepo.GetMembershipByID(membershipID)
+	if err != nil || (m.OwnerID != userID && m.UserID != userID) {
+		return domain.ErrMembershipNotFound
+	}
+	return s.membershipRepo.DeleteMembership(membershipID)
+}
+
+func (s *membershipService) GetSharedWithMe(userID int) ([]domain.Membership, error) {
+	memberships, err := s.membershipRepo.FindMembershipsByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.named(memberships)
+}
+
+func (s *membershipService) authorize(userID int, resourceType domain.ResourceType, id int, need domain.Permission) error {
+	var err error
+	switch resourceType {
+	case domain.ResourceWallet:
+		_, err = s.auth.Wallet(userID, id, need)
+	case domain.ResourceBudget:
+		_, err = s.auth.Budget(userID, id, need)
+	case domain.ResourceDepot:
+		_, err = s.auth.Depot(userID, id, need)
+	default:
+		err = fmt.Errorf("%w: %q", domain.ErrInvalidResourceType, resourceType)
+	}
+	return err
+}
+
+func (s *membershipService) named(memberships []domain.Membership) ([]domain.Membership, error) {
+	names, err := s.usernames()
+	if err != nil {
+		return nil, err
+	}
+	for i := range memberships {
+		nameMembership(&memberships[i], names)
+	}
+	return memberships, nil
+}
+
+func (s *membershipService) usernames() (map[int]string, error) {
+	users, err := s.userRepo.FindAllUsers()
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[int]string, len(users))
+	for _, u := range users {
+		names[u.ID] = u.Username
+	}
+	return names, nil
+}
+
+func nameMembership(m *domain.Membership, names map[int]string) {
+	m.OwnerName = names[m.OwnerID]
+	m.Username = names[m.UserID]
+}
@@ -0,0 +1,100 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/fim-lab/expense-tracker/adapters/repository/memory"
+	"github.com/fim-lab/expense-tracker/internal/core/domain"
+)
+
+func TestMemberships_MembersBookOnTheOwnersWallet(t *testing.T) {
+	repos := memory.NewCleanRepositories()
+	seedFlat(t, repos)
+	auth := NewAuthorizer(repos)
+	svc := NewMembershipService(repos.MembershipRepository(), repos.UserRepository(), auth)
+	txSvc := NewTransactionService(repos.TransactionRepository(), auth)
+	walletSvc := NewWalletService(repos.WalletRepository(), repos.TransactionRepository(), repos.MembershipRepository(), auth)
+
+	if _, err := svc.Invite(2, domain.Membership{ResourceType: domain.ResourceWallet, ResourceID: 1, Username: "carla", Permission: domain.PermissionRead}); !errors.Is(err, domain.ErrUnauthorized) {
+		t.Errorf("expected only the owner to invite, got %v", err)
+	}
+	if _, err := svc.Invite(1, domain.Membership{ResourceType: domain.ResourceWallet, ResourceID: 1, Username: "ben", Permission: domain.PermissionWrite}); err != nil {
+		t.Fatalf("inviting Ben failed: %v", err)
+	}
+	if _, err := svc.Invite(1, domain.Membership{ResourceType: domain.ResourceWallet, ResourceID: 1, Username: "carla", Permission: domain.PermissionRead}); err != nil {
+		t.Fatalf("inviting Carla failed: %v", err)
+	}
+
+	id, err := txSvc.CreateTransaction(2, domain.Transaction{WalletID: 1, Date: onDate(2026, 5, 1), Description: "Miete", AmountInCents: 80000, Type: domain.Expense})
+	if err != nil {
+		t.Fatalf("booking as a member failed: %v", err)
+	}
+	booked, _ := repos.TransactionRepository().GetTransactionByID(id)
+	if booked.UserID != 1 || booked.CreatedBy != 2 {
+		t.Errorf("expected the transaction to be Anna's, booked by Ben, got owner %d and creator %d", booked.UserID, booked.CreatedBy)
+	}
+	if wallet, _ := walletSvc.GetWallet(3, 1); wallet.BalanceCents != -(9001+10000+80000) || wallet.Permission != domain.PermissionRead || wallet.CanDelete {
+		t.Errorf("expected Carla to see the shared wallet, got %+v", wallet)
+	}
+
+	if _, err := txSvc.CreateTransaction(3, domain.Transaction{WalletID: 1, Date: onDate(2026, 5, 2), Description: "Kino", AmountInCents: 2000, Type: domain.Expense}); !errors.Is(err, domain.ErrWalletNotFound) {
+		t.Errorf("expected a reader not to book on the wallet, got %v", err)
+	}
+	booked.Description = "Kino"
+	if err := txSvc.UpdateTransaction(3, booked); !errors.Is(err, domain.ErrUnauthorized) {
+		t.Errorf("expected a reader not to change a transaction, got %v", err)
+	}
+	if err := walletSvc.DeleteWallet(2, 1); !errors.Is(err, domain.ErrUnauthorized) {
+		t.Errorf("expected a member not to delete the wallet, got %v", err)
+	}
+	if _, err := walletSvc.GetWallet(2, 2); err != nil {
+		t.Errorf("expected Ben to keep his own wallets, got %v", err)
+	}
+}
+
+func TestMemberships_SharedWalletsAreListedUntilRemoved(t *testing.T) {
+	repos := memory.NewCleanRepositories()
+	seedFlat(t, repos)
+	auth := NewAuthorizer(repos)
+	svc := NewMembershipService(repos.MembershipRepository(), repos.UserRepository(), auth)
+	walletSvc := NewWalletService(repos.WalletRepository(), repos.TransactionRepository(), repos.MembershipRepository(), auth)
+
+	if _, err := svc.Invite(1, domain.Membership{ResourceType: domain.ResourceWallet, ResourceID: 1, Username: "anna", Permission: domain.PermissionRead}); !errors.Is(err, domain.ErrInvalidPermission) {
+		t.Errorf("expected the owner not to become a member, got %v", err)
+	}
+	if _, err := svc.Invite(1, domain.Membership{ResourceType: domain.ResourceWallet, ResourceID: 1, Username: "ben", Permission: domain.PermissionOwner}); !errors.Is(err, domain.ErrInvalidPermission) {
+		t.Errorf("expected ownership not to be granted, got %v", err)
+	}
+	if _, err := svc.Invite(1, domain.Membership{ResourceType: domain.ResourceWallet, ResourceID: 1, Username: "ben", Permission: domain.PermissionRead}); err != nil {
+		t.Fatalf("inviting Ben failed: %v", err)
+	}
+	m, err := svc.Invite(1, domain.Membership{ResourceType: domain.ResourceWallet, ResourceID: 1, Username: "ben", Permission: domain.PermissionWrite})
+	if err != nil {
+		t.Fatalf("inviting Ben again failed: %v", err)
+	}
+	if members, _ := svc.GetMembers(2, domain.ResourceWallet, 1); len(members) != 1 || members[0].Permission != domain.PermissionWrite || members[0].OwnerName != "anna" {
+		t.Errorf("expected inviting again to change the permission, got %+v", members)
+	}
+
+	wallets, err := walletSvc.GetWallets(2)
+	if err != nil || len(wallets) != 3 {
+		t.Fatalf("expected Ben's two wallets and Anna's, got %+v (%v)", wallets, err)
+	}
+	if wallets[2].ID != 1 || wallets[2].Permission != domain.PermissionWrite || wallets[0].Permission != domain.PermissionOwner {
+		t.Errorf("expected the shared wallet last, got %+v", wallets)
+	}
+
+	if err := svc.RemoveMember(3, m.ID); !errors.Is(err, domain.ErrMembershipNotFound) {
+		t.Errorf("expected an outsider not to remove the member, got %v", err)
+	}
+	if err := svc.RemoveMember(1, m.ID); err != nil {
+		t.Fatalf("removing Ben failed: %v", err)
+	}
+	if shared, _ := svc.GetSharedWithMe(2); len(shared) != 0 {
+		t.Errorf("expected nothing shared with Ben any more, got %+v", shared)
+	}
+	if _, err := walletSvc.GetWallet(2, 1); !errors.Is(err, domain.ErrUnauthorized) {
+		t.Errorf("expected Ben to lose access, got %v", err)
+	}
+}
@@ -0,0 +1,67 @@
+package services
+
+import (
+	"log"
+	"time"
+
+	"github.com/fim-lab/expense-tracker/internal/core/domain"
+	"github.com/fim-lab/expense-tracker/internal/core/ports"
+)
+
+type notificationService struct {
+	notificationRepo ports.NotificationRepository
+	userRepo         ports.UserRepository
+	notifiers        []ports.Notifier
+}
+
+func NewNotificationService(notificationRepo ports.NotificationRepository, userRepo ports.UserRepository, notifiers ...ports.Notifier) ports.NotificationService {
+	return &notificationService{notificationRepo: notificationRepo, userRepo: userRepo, notifiers: notifiers}
+}
+
+func (s *notificationService) Notify(n domain.Notification) error {
+	if n.CreatedAt.IsZero() {
+		n.CreatedAt = time.Now()
+	}
+	id, err := s.notificationRepo.SaveNotification(n)
+	if err != nil {
+		return err
+	}
+	n.ID = id
+
+	if len(s.notifiers) == 0 {
+		return nil
+	}
+	user, err := s.userRepo.GetUserByID(n.UserID)
+	if err != nil {
+		log.Printf("Could not deliver notification %d, user %d not found: %v", n.ID, n.UserID, err)
+		return nil
+	}
+	for _, notifier := range s.notifiers {
+		if err := notifier.Deliver(user, n); err != nil {
+			log.Printf("Delivering notification %d to user %d failed: %v", n.ID, n.UserID, err)
+		}
+	}
+	return nil
+}
+
+func (s *notificationService) GetNotifications(userID int, unreadOnly bool) ([]domain.Notification, error) {
+	notifications, err := s.notificationRepo.FindNotificationsByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	res := []domain.Notification{}
+	for _, n := range notifications {
+		if !unreadOnly || !n.Read {
+			res = append(res, n)
+		}
+	}
+	return res, nil
+}
+
+func (s *notificationService) MarkRead(userID int, id int) error {
+	n, err := s.notificationRepo.GetNotificationByID(id)
+	if err != nil || n.UserID != userID {
+		return domain.ErrNotificationNotFound
+	}
+	return s.notificationRepo.MarkNotificationRead(id)
+}
@@ -0,0 +1,67 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/fim-lab/expense-tracker/internal/core/domain"
+	"github.com/fim-lab/expense-tracker/internal/core/ports"
+)
+
+type pendingTransactionMonitor struct {
+	transactionRepo  ports.TransactionRepository
+	notifications    ports.NotificationService
+	overdueAfterDays int
+}
+
+func NewPendingTransactionMonitor(
+	transactionRepo ports.TransactionRepository,
+	notifications ports.NotificationService,
+	overdueAfterDays int,
+) ports.PendingTransactionMonitor {
+	return &pendingTransactionMonitor{
+		transactionRepo:  transactionRepo,
+		notifications:    notifications,
+		overdueAfterDays: overdueAfterDays,
+	}
+}
+
+// FlagOverduePending flags every transaction that is still pending more than
+// the configured number of days after its date and returns how many were
+// flagged. Each user with newly flagged transactions is notified once; a
+// transaction stays flagged until it is settled, so it is reported only once.
+func (m *pendingTransactionMonitor) FlagOverduePending(now time.Time) (int, error) {
+	before := domain.DateOf(now).AddDate(0, 0, -m.overdueAfterDays)
+	flagged, err := m.transactionRepo.FlagOverduePending(before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to flag overdue pending transactions: %w", err)
+	}
+
+	perUser := map[int][]domain.Transaction{}
+	var users []int
+	for _, t := range flagged {
+		if _, ok := perUser[t.UserID]; !ok {
+			users = append(users, t.UserID)
+		}
+		perUser[t.UserID] = append(perUser[t.UserID], t)
+	}
+	for _, userID := range users {
+		transactions := perUser[userID]
+		lines := make([]string, len(transactions))
+		for i, t := range transactions {
+			lines[i] = fmt.Sprintf("%s %s %s", t.Date.Format("2006-01-02"), t.Description, formatCents(t.SignedAmount()))
+		}
+		err := m.notifications.Notify(domain.Notification{
+			UserID:  userID,
+			Type:    domain.NotificationPendingOverdue,
+			Subject: fmt.Sprintf("%d transactions pending for more than %d days", len(transactions), m.overdueAfterDays),
+			Message: strings.Join(lines, "\n"),
+		})
+		if err != nil {
+			log.Printf("Could not notify user %d of overdue pending transactions: %v", userID, err)
+		}
+	}
+	return len(flagged), nil
+}
@@ -0,0 +1,135 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fim-lab/expense-tracker/adapters/repository/memory"
+	"github.com/fim-lab/expense-tracker/internal/core/domain"
+)
+
+func TestPendingTransactions_SplitBalancesAndSettleInBulk(t *testing.T) {
+	f := newWalletFixture(t)
+	budgetID := 2
+	if err := f.repos.BudgetRepository().SaveBudget(domain.Budget{ID: budgetID, UserID: f.userID, Name: "Lebensmittel"}); err != nil {
+		t.Fatalf("could not seed the budget: %v", err)
+	}
+	pending := true
+	today := domain.DateOf(time.Now())
+	for _, tx := range []domain.Transaction{
+		{ID: 11, Date: today, WalletID: f.walletID, Description: "Gehalt", AmountInCents: 250000, Type: domain.Income},
+		{ID: 12, Date: today, WalletID: f.walletID, BudgetID: &budgetID, Description: "Supermarkt", AmountInCents: 4000, Type: domain.Expense, IsPending: &pending},
+		{ID: 13, Date: today, WalletID: f.walletID, BudgetID: &budgetID, Description: "Bäcker", AmountInCents: 500, Type: domain.Expense, IsPending: &pending},
+		{ID: 14, Date: today, WalletID: f.walletID, BudgetID: &budgetID, Description: "Markt", AmountInCents: 1500, Type: domain.Expense},
+	} {
+		if _, err := f.txSvc.CreateTransaction(f.userID, tx); err != nil {
+			t.Fatalf("could not book %q: %v", tx.Description, err)
+		}
+	}
+
+	walletSvc := NewWalletService(f.repos.WalletRepository(), f.repos.TransactionRepository(), f.repos.MembershipRepository(), NewAuthorizer(f.repos))
+	wallet, err := walletSvc.GetWallet(f.userID, f.walletID)
+	if err != nil {
+		t.Fatalf("could not read the wallet: %v", err)
+	}
+	if wallet.BalanceCents != 244000 || wallet.PendingCents != -4500 || wallet.BookedCents != 248500 {
+		t.Errorf("expected 45.00 of the balance to be pending, got %+v", wallet)
+	}
+	budgetSvc := NewBudgetService(f.repos, noBudgetAlerts{})
+	budgets, err := budgetSvc.GetBudgets(f.userID)
+	if err != nil || len(budgets) != 1 {
+		t.Fatalf("could not read the budget: %v", err)
+	}
+	if b := budgets[0]; b.PendingCents != -4500 || b.BookedCents != b.BalanceCents+4500 {
+		t.Errorf("expected 45.00 of the budget to be pending, got %+v", b)
+	}
+
+	// Someone else's and already booked transactions are left alone.
+	settled, err := f.txSvc.SettleTransactions(f.userID, []int{12, 14, 99})
+	if err != nil || settled != 1 {
+		t.Fatalf("expected one transaction to be settled, got %d (%v)", settled, err)
+	}
+	if settled, err := f.txSvc.SettleTransactions(2, []int{13}); err != nil || settled != 0 {
+		t.Errorf("expected another user's transaction to stay pending, got %d (%v)", settled, err)
+	}
+	wallet, _ = walletSvc.GetWallet(f.userID, f.walletID)
+	if wallet.BalanceCents != 244000 || wallet.PendingCents != -500 {
+		t.Errorf("expected only the bakery to be pending, got %+v", wallet)
+	}
+}
+
+func TestPendingTransactionMonitor_FlagsOverdueOnceAndNotifies(t *testing.T) {
+	f := newWalletFixture(t)
+	notificationSvc := NewNotificationService(f.repos.NotificationRepository(), f.repos.UserRepository())
+	monitor := NewPendingTransactionMonitor(f.repos.TransactionRepository(), notificationSvc, 5)
+	pending := true
+	today := domain.DateOf(time.Now())
+	for _, tx := range []domain.Transaction{
+		{ID: 11, Date: today.AddDate(0, 0, -10), WalletID: f.walletID, Description: "Hotel", AmountInCents: 20000, Type: domain.Expense, IsPending: &pending},
+		{ID: 12, Date: today.AddDate(0, 0, -6), WalletID: f.walletID, Description: "Tanken", AmountInCents: 6000, Type: domain.Expense, IsPending: &pending},
+		{ID: 13, Date: today.AddDate(0, 0, -2), WalletID: f.walletID, Description: "Kino", AmountInCents: 1500, Type: domain.Expense, IsPending: &pending},
+		{ID: 14, Date: today.AddDate(0, 0, -20), WalletID: f.walletID, Description: "Miete", AmountInCents: 85000, Type: domain.Expense},
+	} {
+		if _, err := f.txSvc.CreateTransaction(f.userID, tx); err != nil {
+			t.Fatalf("could not book %q: %v", tx.Description, err)
+		}
+	}
+
+	flagged, err := monitor.FlagOverduePending(time.Now())
+	if err != nil || flagged != 2 {
+		t.Fatalf("expected the hotel and the gas station to be flagged, got %d (%v)", flagged, err)
+	}
+	if flagged, err := monitor.FlagOverduePending(time.Now()); err != nil || flagged != 0 {
+		t.Errorf("expected flagged transactions to be reported only once, got %d (%v)", flagged, err)
+	}
+	notifications, err := notificationSvc.GetNotifications(f.userID, false)
+	if err != nil || len(notifications) != 1 || notifications[0].Type != domain.NotificationPendingOverdue {
+		t.Fatalf("expected one overdue notification, got %+v (%v)", notifications, err)
+	}
+
+	if _, err := f.txSvc.SettleTransactions(f.userID, []int{11}); err != nil {
+		t.Fatalf("settling failed: %v", err)
+	}
+	if tx, _ := f.repos.TransactionRepository().GetTransactionByID(11); tx.PendingOverdue {
+		t.Errorf("expected settling to clear the overdue flag, got %+v", tx)
+	}
+	if tx, _ := f.repos.TransactionRepository().GetTransactionByID(12); !tx.PendingOverdue {
+		t.Errorf("expected the gas station to stay flagged, got %+v", tx)
+	}
+}
+
+func TestPendingTransactions_MembersSettleOnSharedWallets(t *testing.T) {
+	repos := memory.NewCleanRepositories()
+	seedFlat(t, repos)
+	txSvc := NewTransactionService(repos.TransactionRepository(), NewAuthorizer(repos))
+	for _, m := range []domain.Membership{
+		{OwnerID: 1, UserID: 2, ResourceType: domain.ResourceWallet, ResourceID: 1, Permission: domain.PermissionWrite},
+		{OwnerID: 1, UserID: 3, ResourceType: domain.ResourceWallet, ResourceID: 1, Permission: domain.PermissionRead},
+	} {
+		if _, err := repos.MembershipRepository().SaveMembership(m); err != nil {
+			t.Fatalf("could not seed the membership: %v", err)
+		}
+	}
+	pending := true
+	for _, tx := range []domain.Transaction{
+		{ID: 21, WalletID: 1, Date: onDate(2026, 4, 5), Description: "Strom", AmountInCents: 6000, Type: domain.Expense, IsPending: &pending},
+		{ID: 22, WalletID: 1, Date: onDate(2026, 4, 6), Description: "Wasser", AmountInCents: 3000, Type: domain.Expense, IsPending: &pending},
+	} {
+		if _, err := txSvc.CreateTransaction(1, tx); err != nil {
+			t.Fatalf("could not book %q: %v", tx.Description, err)
+		}
+	}
+
+	if settled, err := txSvc.SettleTransactions(3, []int{21, 22}); err != nil || settled != 0 {
+		t.Errorf("expected a reader not to settle anything, got %d (%v)", settled, err)
+	}
+	if settled, err := txSvc.SettleTransactions(2, []int{21}); err != nil || settled != 1 {
+		t.Fatalf("expected Ben to settle on Anna's wallet, got %d (%v)", settled, err)
+	}
+	if tx, _ := repos.TransactionRepository().GetTransactionByID(21); *tx.IsPending {
+		t.Errorf("expected the electricity bill to be booked, got %+v", tx)
+	}
+	if tx, _ := repos.TransactionRepository().GetTransactionByID(22); !*tx.IsPending {
+		t.Errorf("expected the water bill to stay pending, got %+v", tx)
+	}
+}
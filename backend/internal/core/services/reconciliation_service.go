@@ -0,0 +1,173 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/fim-lab/expense-tracker/internal/core/domain"
+	"github.com/fim-lab/expense-tracker/internal/core/ports"
+)
+
+type reconciliationService struct {
+	repos ports.Repositories
+}
+
+func NewReconciliationService(repos ports.Repositories) ports.ReconciliationService {
+	return &reconciliationService{repos: repos}
+}
+
+func (s *reconciliationService) StartReconciliation(userID int, walletID int, statementDate time.Time, statementBalanceCents int) (domain.Reconciliation, error) {
+	if statementDate.IsZero() {
+		return domain.Reconciliation{}, fmt.Errorf("%w: the statement date is required", domain.ErrInvalidDateRange)
+	}
+	var rec domain.Reconciliation
+	err := s.repos.WithinTransaction(func(repos ports.Repositories) error {
+		if err := checkWalletOwner(repos, userID, walletID); err != nil {
+			return err
+		}
+		existing, err := repos.ReconciliationRepository().FindReconciliationsByWallet(walletID)
+		if err != nil {
+			return err
+		}
+		for _, other := range existing {
+			if other.Status == domain.ReconciliationOpen {
+				return domain.ErrReconciliationOpen
+			}
+		}
+
+		rec = domain.Reconciliation{
+			UserID:                userID,
+			WalletID:              walletID,
+			StatementDate:         domain.DateOf(statementDate),
+			StatementBalanceCents: statementBalanceCents,
+			Status:                domain.ReconciliationOpen,
+			CreatedAt:             time.Now(),
+		}
+		if rec.ID, err = repos.ReconciliationRepository().SaveReconciliation(rec); err != nil {
+			return err
+		}
+		return balanceReconciliation(repos, &rec)
+	})
+	return rec, err
+}
+
+func (s *reconciliationService) GetReconciliations(userID int, walletID int) ([]domain.Reconciliation, error) {
+	if err := checkWalletOwner(s.repos, userID, walletID); err != nil {
+		return nil, err
+	}
+	reconciliations, err := s.repos.ReconciliationRepository().FindReconciliationsByWallet(walletID)
+	if err != nil {
+		return nil, err
+	}
+	transactions, err := s.repos.TransactionRepository().FindTransactionsByWallet(walletID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range reconciliations {
+		reconciliations[i].Balance(transactions)
+		reconciliations[i].Transactions = nil
+	}
+	if reconciliations == nil {
+		reconciliations = []domain.Reconciliation{}
+	}
+	return reconciliations, nil
+}
+
+func (s *reconciliationService) GetReconciliation(userID int, id int) (domain.Reconciliation, error) {
+	rec, err := ownReconciliation(s.repos, userID, id)
+	if err != nil {
+		return domain.Reconciliation{}, err
+	}
+	return rec, balanceReconciliation(s.repos, &rec)
+}
+
+func (s *reconciliationService) SetCleared(userID int, id int, transactionIDs []int, cleared bool) (domain.Reconciliation, error) {
+	var rec domain.Reconciliation
+	err := s.repos.WithinTransaction(func(repos ports.Repositories) error {
+		var err error
+		if rec, err = ownReconciliation(repos, userID, id); err != nil {
+			return err
+		}
+		if rec.Status != domain.ReconciliationOpen {
+			return domain.ErrReconciliationCompleted
+		}
+		for _, transactionID := range transactionIDs {
+			t, err := NewAuthorizer(repos).Transaction(userID, transactionID, domain.PermissionOwner)
+			if err != nil {
+				return domain.ErrTransactionNotFound
+			}
+			if t.ReconciliationID != nil {
+				return domain.ErrTransactionReconciled
+			}
+			if !rec.CanClear(t) {
+				return fmt.Errorf("%w: transaction %d", domain.ErrNotOnStatement, transactionID)
+			}
+		}
+		if err := repos.TransactionRepository().SetTransactionsCleared(transactionIDs, cleared); err != nil {
+			return err
+		}
+		return balanceReconciliation(repos, &rec)
+	})
+	return rec, err
+}
+
+func (s *reconciliationService) CompleteReconciliation(userID int, id int) (domain.Reconciliation, error) {
+	var rec domain.Reconciliation
+	err := s.repos.WithinTransaction(func(repos ports.Repositories) error {
+		var err error
+		if rec, err = ownReconciliation(repos, userID, id); err != nil {
+			return err
+		}
+		if rec.Status != domain.ReconciliationOpen {
+			return domain.ErrReconciliationCompleted
+		}
+		if err := balanceReconciliation(repos, &rec); err != nil {
+			return err
+		}
+		if rec.DifferenceCents != 0 {
+			return fmt.Errorf("%w: %d cents apart", domain.ErrReconciliationUnbalanced, rec.DifferenceCents)
+		}
+
+		if err := repos.TransactionRepository().MarkReconciled(rec.WalletID, rec.StatementDate, rec.ID); err != nil {
+			return err
+		}
+		completedAt := time.Now()
+		if err := repos.ReconciliationRepository().CompleteReconciliation(rec.ID, completedAt); err != nil {
+			return err
+		}
+		rec.Status, rec.CompletedAt, rec.Transactions = domain.ReconciliationCompleted, &completedAt, nil
+		return nil
+	})
+	return rec, err
+}
+
+func (s *reconciliationService) DeleteReconciliation(userID int, id int) error {
+	if _, err := ownReconciliation(s.repos, userID, id); err != nil {
+		return err
+	}
+	return s.repos.ReconciliationRepository().DeleteReconciliation(id)
+}
+
+func checkWalletOwner(repos ports.Repositories, userID int, walletID int) error {
+	if _, err := NewAuthorizer(repos).Wallet(userID, walletID, domain.PermissionOwner); err != nil {
+		return domain.ErrWalletNotFound
+	}
+	return nil
+}
+
+func ownReconciliation(repos ports.Repositories, userID int, id int) (domain.Reconciliation, error) {
+	rec, err := repos.ReconciliationRepository().GetReconciliationByID(id)
+	if err != nil || rec.UserID != userID {
+		return domain.Reconciliation{}, domain.ErrReconciliationNotFound
+	}
+	return rec, nil
+}
+
+func balanceReconciliation(repos ports.Repositories, rec *domain.Reconciliation) error {
+	transactions, err := repos.TransactionRepository().FindTransactionsByWallet(rec.WalletID)
+	if err != nil {
+		return err
+	}
+	rec.Balance(transactions)
+	return nil
+}
@@ -0,0 +1,74 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/fim-lab/expense-tracker/internal/core/domain"
+)
+
+func TestReconciliation_CompletingLocksTheClearedTransactions(t *testing.T) {
+	f := newWalletFixture(t)
+	for _, tx := range []domain.Transaction{
+		{ID: 11, Date: onDate(2026, 1, 5), WalletID: f.walletID, Description: "Gehalt", AmountInCents: 1000, Type: domain.Income},
+		{ID: 12, Date: onDate(2026, 1, 10), WalletID: f.walletID, Description: "Miete", AmountInCents: 300, Type: domain.Expense},
+		{ID: 13, Date: onDate(2026, 1, 20), WalletID: f.walletID, Description: "Scheck", AmountInCents: 50, Type: domain.Expense},
+		{ID: 14, Date: onDate(2026, 2, 5), WalletID: f.walletID, Description: "Kino", AmountInCents: 100, Type: domain.Expense},
+	} {
+		if _, err := f.txSvc.CreateTransaction(f.userID, tx); err != nil {
+			t.Fatalf("could not book %q: %v", tx.Description, err)
+		}
+	}
+	svc := NewReconciliationService(f.repos)
+
+	rec, err := svc.StartReconciliation(f.userID, f.walletID, onDate(2026, 1, 31), 700)
+	if err != nil {
+		t.Fatalf("starting failed: %v", err)
+	}
+	if rec.DifferenceCents != 700 || len(rec.Transactions) != 3 {
+		t.Fatalf("expected the three January transactions to tick and 7.00 apart, got %+v", rec)
+	}
+	if _, err := svc.StartReconciliation(f.userID, f.walletID, onDate(2026, 2, 28), 0); !errors.Is(err, domain.ErrReconciliationOpen) {
+		t.Errorf("expected a second open reconciliation to be refused, got %v", err)
+	}
+	if _, err := svc.SetCleared(f.userID, rec.ID, []int{14}, true); !errors.Is(err, domain.ErrNotOnStatement) {
+		t.Errorf("expected a transaction after the statement date to be refused, got %v", err)
+	}
+
+	if _, err := svc.SetCleared(f.userID, rec.ID, []int{11, 12, 13}, true); err != nil {
+		t.Fatalf("clearing failed: %v", err)
+	}
+	if _, err := svc.CompleteReconciliation(f.userID, rec.ID); !errors.Is(err, domain.ErrReconciliationUnbalanced) {
+		t.Errorf("expected a reconciliation 0.50 apart not to complete, got %v", err)
+	}
+	rec, err = svc.SetCleared(f.userID, rec.ID, []int{13}, false)
+	if err != nil || rec.ClearedBalanceCents != 700 || rec.DifferenceCents != 0 {
+		t.Fatalf("expected the cleared balance to match after unticking the check, got %+v (%v)", rec, err)
+	}
+
+	rec, err = svc.CompleteReconciliation(f.userID, rec.ID)
+	if err != nil || rec.Status != domain.ReconciliationCompleted {
+		t.Fatalf("completing failed: %+v (%v)", rec, err)
+	}
+	if err := f.txSvc.UpdateTransaction(f.userID, domain.Transaction{ID: 11, Date: onDate(2026, 1, 5), WalletID: f.walletID, Description: "Gehalt", AmountInCents: 2000, Type: domain.Income}); !errors.Is(err, domain.ErrTransactionReconciled) {
+		t.Errorf("expected a reconciled transaction to be locked against edits, got %v", err)
+	}
+	if err := f.txSvc.DeleteTransaction(f.userID, 12); !errors.Is(err, domain.ErrTransactionReconciled) {
+		t.Errorf("expected a reconciled transaction to be locked against deletion, got %v", err)
+	}
+	if err := f.txSvc.DeleteTransaction(f.userID, 13); err != nil {
+		t.Errorf("expected the uncleared check to stay editable, got %v", err)
+	}
+
+	next, err := svc.StartReconciliation(f.userID, f.walletID, onDate(2026, 2, 28), 600)
+	if err != nil || len(next.Transactions) != 1 || next.DifferenceCents != 600-700 {
+		t.Fatalf("expected only February's transaction left to tick, got %+v (%v)", next, err)
+	}
+
+	if err := svc.DeleteReconciliation(f.userID, rec.ID); err != nil {
+		t.Fatalf("undoing the reconciliation failed: %v", err)
+	}
+	if err := f.txSvc.DeleteTransaction(f.userID, 12); err != nil {
+		t.Errorf("expected undoing the reconciliation to unlock its transactions, got %v", err)
+	}
+}
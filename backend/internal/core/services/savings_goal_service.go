@@ -0,0 +1,239 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/fim-lab/expense-tracker/internal/core/domain"
+	"github.com/fim-lab/expense-tracker/internal/core/ports"
+)
+
+type savingsGoalService struct {
+	repos ports.Repositories
+}
+
+func NewSavingsGoalService(repos ports.Repositories) ports.SavingsGoalService {
+	return &savingsGoalService{repos: repos}
+}
+
+func (s *savingsGoalService) CreateSavingsGoal(userID int, goal domain.SavingsGoal) (domain.SavingsGoal, error) {
+	now := time.Now()
+	goal.ID = 0
+	goal.UserID = userID
+	goal.TargetDate = domain.DateOf(goal.TargetDate)
+	goal.ContributionTemplateID = nil
+	goal.CreatedAt = now
+	if err := goal.Validate(); err != nil {
+		return domain.SavingsGoal{}, err
+	}
+
+	err := s.repos.WithinTransaction(func(repos ports.Repositories) error {
+		if err := checkGoalAccounts(repos, goal); err != nil {
+			return err
+		}
+		if goal.FromWalletID != nil {
+			id, err := saveContributionTemplate(repos, goal, now)
+			if err != nil {
+				return err
+			}
+			goal.ContributionTemplateID = &id
+		}
+		id, err := repos.SavingsGoalRepository().SaveSavingsGoal(goal)
+		if err != nil {
+			return err
+		}
+		goal.ID = id
+		return nil
+	})
+	if err != nil {
+		return domain.SavingsGoal{}, err
+	}
+
+	goals, err := s.withProgress([]domain.SavingsGoal{goal}, now)
+	if err != nil {
+		return domain.SavingsGoal{}, err
+	}
+	return goals[0], nil
+}
+
+func (s *savingsGoalService) GetSavingsGoals(userID int) ([]domain.SavingsGoal, error) {
+	goals, err := s.repos.SavingsGoalRepository().FindSavingsGoalsByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.withProgress(goals, time.Now())
+}
+
+func (s *savingsGoalService) GetSavingsGoal(userID int, id int) (domain.SavingsGoal, error) {
+	goal, err := s.repos.SavingsGoalRepository().GetSavingsGoalByID(id)
+	if err != nil {
+		return domain.SavingsGoal{}, err
+	}
+	if goal.UserID != userID {
+		return domain.SavingsGoal{}, domain.ErrSavingsGoalNotFound
+	}
+	goals, err := s.withProgress([]domain.SavingsGoal{goal}, time.Now())
+	if err != nil {
+		return domain.SavingsGoal{}, err
+	}
+	return goals[0], nil
+}
+
+// UpdateSavingsGoal keeps the goal's contribution template in line with it:
+// the template follows the new amount and target date, is set up if the goal
+// now asks for an automatic contribution and deleted if it no longer does.
+func (s *savingsGoalService) UpdateSavingsGoal(userID int, goal domain.SavingsGoal) error {
+	now := time.Now()
+	return s.repos.WithinTransaction(func(repos ports.Repositories) error {
+		existing, err := repos.SavingsGoalRepository().GetSavingsGoalByID(goal.ID)
+		if err != nil {
+			return err
+		}
+		if existing.UserID != userID {
+			return domain.ErrSavingsGoalNotFound
+		}
+		goal.UserID = userID
+		goal.TargetDate = domain.DateOf(goal.TargetDate)
+		goal.CreatedAt = existing.CreatedAt
+		goal.ContributionTemplateID = existing.ContributionTemplateID
+		if err := goal.Validate(); err != nil {
+			return err
+		}
+		if err := checkGoalAccounts(repos, goal); err != nil {
+			return err
+		}
+
+		templates := repos.TransactionTemplateRepository()
+		var template *domain.TransactionTemplate
+		if goal.ContributionTemplateID != nil {
+			tt, err := templates.GetTransactionTemplateByID(*goal.ContributionTemplateID)
+			switch {
+			case err == nil:
+				template = &tt
+			case !errors.Is(err, domain.ErrTransactionTemplateNotFound):
+				return err
+			}
+		}
+
+		switch {
+		case goal.FromWalletID == nil && template != nil:
+			if err := templates.DeleteTransactionTemplate(template.ID); err != nil {
+				return fmt.Errorf("failed to delete the contribution template: %w", err)
+			}
+			goal.ContributionTemplateID = nil
+		case goal.FromWalletID != nil && template != nil:
+			updated := goal.ContributionTemplate()
+			updated.ID = template.ID
+			updated.Tags = template.Tags
+			updated.Recurrence.StartDate = template.Recurrence.StartDate
+			if err := updated.Validate(); err != nil {
+				return err
+			}
+			if err := templates.UpdateTransactionTemplate(updated); err != nil {
+				return fmt.Errorf("failed to update the contribution template: %w", err)
+			}
+		case goal.FromWalletID != nil:
+			id, err := saveContributionTemplate(repos, goal, now)
+			if err != nil {
+				return err
+			}
+			goal.ContributionTemplateID = &id
+		default:
+			goal.ContributionTemplateID = nil
+		}
+
+		return repos.SavingsGoalRepository().UpdateSavingsGoal(goal)
+	})
+}
+
+// DeleteSavingsGoal also stops the automatic contribution. What was saved
+// stays where it is.
+func (s *savingsGoalService) DeleteSavingsGoal(userID int, id int) error {
+	return s.repos.WithinTransaction(func(repos ports.Repositories) error {
+		goal, err := repos.SavingsGoalRepository().GetSavingsGoalByID(id)
+		if err != nil {
+			return err
+		}
+		if goal.UserID != userID {
+			return domain.ErrSavingsGoalNotFound
+		}
+		if goal.ContributionTemplateID != nil {
+			if err := repos.TransactionTemplateRepository().DeleteTransactionTemplate(*goal.ContributionTemplateID); err != nil {
+				return fmt.Errorf("failed to delete the contribution template: %w", err)
+			}
+		}
+		return repos.SavingsGoalRepository().DeleteSavingsGoal(id)
+	})
+}
+
+func checkGoalAccounts(repos ports.Repositories, goal domain.SavingsGoal) error {
+	auth := NewAuthorizer(repos)
+	for _, walletID := range []*int{goal.WalletID, goal.FromWalletID} {
+		if walletID == nil {
+			continue
+		}
+		if _, err := auth.Wallet(goal.UserID, *walletID, domain.PermissionOwner); err != nil {
+			return domain.ErrWalletNotFound
+		}
+	}
+	if goal.BudgetID != nil {
+		if _, err := auth.Budget(goal.UserID, *goal.BudgetID, domain.PermissionOwner); err != nil {
+			return domain.ErrBudgetNotFound
+		}
+	}
+	return nil
+}
+
+func saveContributionTemplate(repos ports.Repositories, goal domain.SavingsGoal, now time.Time) (int, error) {
+	tt := goal.ContributionTemplate()
+	tt.CreatedAt = now
+	tt.ApplyRecurrenceDefaults(now)
+	if err := tt.Validate(); err != nil {
+		return 0, err
+	}
+	id, err := repos.TransactionTemplateRepository().SaveTransactionTemplate(tt)
+	if err != nil {
+		return 0, fmt.Errorf("failed to save the contribution template: %w", err)
+	}
+	return id, nil
+}
+
+// withProgress measures every goal against the bookings on its wallet or
+// budget.
+func (s *savingsGoalService) withProgress(goals []domain.SavingsGoal, now time.Time) ([]domain.SavingsGoal, error) {
+	var transactions []domain.Transaction
+	for i, goal := range goals {
+		var bookings []domain.BudgetBooking
+		if goal.WalletID != nil {
+			if transactions == nil {
+				all, err := s.repos.TransactionRepository().FindAllTransactionsByUser(goal.UserID)
+				if err != nil {
+					return nil, fmt.Errorf("failed to fetch transactions: %w", err)
+				}
+				transactions = append([]domain.Transaction{}, all...)
+			}
+			for _, t := range transactions {
+				if t.WalletID == *goal.WalletID {
+					bookings = append(bookings, domain.BudgetBooking{Date: t.Date, AmountInCents: t.SignedAmount()})
+				}
+			}
+		} else {
+			var err error
+			bookings, err = s.repos.TransactionRepository().FindBudgetBookings(*goal.BudgetID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to fetch budget bookings: %w", err)
+			}
+			movements, err := s.repos.BudgetRepository().FindMovementsByBudget(*goal.BudgetID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to fetch budget movements: %w", err)
+			}
+			for _, m := range movements {
+				bookings = append(bookings, domain.BudgetBooking{Date: m.Date, AmountInCents: m.AmountOnBudget(*goal.BudgetID), Movement: true})
+			}
+		}
+		progress := domain.SavingsProgress(goal, bookings, now)
+		goals[i].Progress = &progress
+	}
+	return goals, nil
+}
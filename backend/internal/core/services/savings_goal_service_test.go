@@ -0,0 +1,153 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/fim-lab/expense-tracker/internal/core/domain"
+)
+
+func TestSavingsGoal_ProgressFollowsTheWalletTransactions(t *testing.T) {
+	f := newWalletFixture(t)
+	savings := 2
+	if err := f.repos.WalletRepository().SaveWallet(domain.Wallet{ID: savings, UserID: f.userID, Name: "Tagesgeld"}); err != nil {
+		t.Fatalf("could not seed the savings wallet: %v", err)
+	}
+	today := domain.DateOf(time.Now())
+	for i, tx := range []domain.Transaction{
+		{ID: 11, Date: today.AddDate(0, -2, 0), WalletID: savings, Description: "Sparen", AmountInCents: 30000, Type: domain.Income},
+		{ID: 12, Date: today.AddDate(0, -1, 0), WalletID: savings, Description: "Sparen", AmountInCents: 30000, Type: domain.Income},
+		{ID: 13, Date: today.AddDate(0, -1, 0), WalletID: savings, Description: "Reparatur", AmountInCents: 10000, Type: domain.Expense},
+		{ID: 14, Date: today, WalletID: savings, Description: "Sparen", AmountInCents: 30000, Type: domain.Income},
+		{ID: 15, Date: today, WalletID: f.walletID, Description: "Gehalt", AmountInCents: 250000, Type: domain.Income},
+	} {
+		if _, err := f.txSvc.CreateTransaction(f.userID, tx); err != nil {
+			t.Fatalf("could not seed transaction %d: %v", i, err)
+		}
+	}
+
+	goalSvc := NewSavingsGoalService(f.repos)
+	goal, err := goalSvc.CreateSavingsGoal(f.userID, domain.SavingsGoal{
+		Name:        "Fahrrad",
+		WalletID:    &savings,
+		TargetCents: 200000,
+		TargetDate:  today.AddDate(0, 6, 0),
+	})
+	if err != nil {
+		t.Fatalf("creating the goal failed: %v", err)
+	}
+
+	p := goal.Progress
+	if p == nil || p.SavedCents != 80000 || p.RemainingCents != 120000 || p.Percent != 40 {
+		t.Fatalf("expected 800.00 of 2000.00 saved, got %+v", p)
+	}
+	if p.RequiredMonthlyCents != 20000 {
+		t.Errorf("expected 200.00 a month to be required over six months, got %d", p.RequiredMonthlyCents)
+	}
+	if p.MonthlySavingCents < 38000 || p.MonthlySavingCents > 42000 {
+		t.Errorf("expected about 400.00 a month saved lately, got %d", p.MonthlySavingCents)
+	}
+	// Depending on the length of the last months the rate is a little above
+	// or below 400.00, which takes three or four months for the rest.
+	if p.ProjectedCompletion == nil || p.ProjectedCompletion.Before(today.AddDate(0, 3, 0)) || p.ProjectedCompletion.After(today.AddDate(0, 4, 0)) || !p.OnTrack {
+		t.Errorf("expected the goal to be reached on track in three to four months, got %+v", p)
+	}
+
+	goal.TargetCents = 60000
+	if err := goalSvc.UpdateSavingsGoal(f.userID, goal); err != nil {
+		t.Fatalf("updating the goal failed: %v", err)
+	}
+	reached, err := goalSvc.GetSavingsGoal(f.userID, goal.ID)
+	if err != nil {
+		t.Fatalf("reading the goal failed: %v", err)
+	}
+	// The repair took the savings below the target again, so it counts as
+	// reached with today's deposit.
+	if reached.Progress.Percent != 100 || reached.Progress.ProjectedCompletion == nil || !reached.Progress.ProjectedCompletion.Equal(today) {
+		t.Errorf("expected the lowered target to count as reached today, got %+v", reached.Progress)
+	}
+}
+
+func TestSavingsGoal_AutomaticContributionIsBookedAsTransfer(t *testing.T) {
+	f := newWalletFixture(t)
+	savings := 2
+	if err := f.repos.WalletRepository().SaveWallet(domain.Wallet{ID: savings, UserID: f.userID, Name: "Tagesgeld"}); err != nil {
+		t.Fatalf("could not seed the savings wallet: %v", err)
+	}
+	goalSvc := NewSavingsGoalService(f.repos)
+	today := domain.DateOf(time.Now())
+
+	goal, err := goalSvc.CreateSavingsGoal(f.userID, domain.SavingsGoal{
+		Name:                     "Urlaub",
+		WalletID:                 &savings,
+		TargetCents:              100000,
+		TargetDate:               today.AddDate(1, 0, 0),
+		MonthlyContributionCents: 25000,
+		FromWalletID:             &f.walletID,
+	})
+	if err != nil {
+		t.Fatalf("creating the goal failed: %v", err)
+	}
+	if goal.ContributionTemplateID == nil {
+		t.Fatalf("expected a contribution template, got %+v", goal)
+	}
+
+	scheduler := NewTransactionTemplateScheduler(f.repos.TransactionTemplateRepository(), f.txSvc)
+	booked, err := scheduler.BookDueTransactions(today.AddDate(0, 2, 0))
+	if err != nil || booked != 2 {
+		t.Fatalf("expected two contributions to be booked, got %d (%v)", booked, err)
+	}
+	to, _ := f.repos.WalletRepository().GetWalletByID(savings)
+	from, _ := f.repos.WalletRepository().GetWalletByID(f.walletID)
+	if to.BalanceCents != 50000 || from.BalanceCents != -50000 {
+		t.Errorf("expected 500.00 to be moved to the savings wallet, got %d and %d", to.BalanceCents, from.BalanceCents)
+	}
+
+	goal.MonthlyContributionCents = 10000
+	if err := goalSvc.UpdateSavingsGoal(f.userID, goal); err != nil {
+		t.Fatalf("updating the goal failed: %v", err)
+	}
+	template, err := f.repos.TransactionTemplateRepository().GetTransactionTemplateByID(*goal.ContributionTemplateID)
+	if err != nil || template.AmountInCents != 10000 || template.ToWalletID == nil || *template.ToWalletID != savings {
+		t.Errorf("expected the template to follow the goal, got %+v (%v)", template, err)
+	}
+
+	if err := goalSvc.DeleteSavingsGoal(f.userID, goal.ID); err != nil {
+		t.Fatalf("deleting the goal failed: %v", err)
+	}
+	if _, err := f.repos.TransactionTemplateRepository().GetTransactionTemplateByID(*goal.ContributionTemplateID); !errors.Is(err, domain.ErrTransactionTemplateNotFound) {
+		t.Errorf("expected the contribution template to be deleted with the goal, got %v", err)
+	}
+}
+
+func TestSavingsGoal_RejectsInvalidGoals(t *testing.T) {
+	f := newWalletFixture(t)
+	if err := f.repos.BudgetRepository().SaveBudget(domain.Budget{ID: 2, UserID: f.userID, Name: "Urlaub"}); err != nil {
+		t.Fatalf("could not seed the budget: %v", err)
+	}
+	if err := f.repos.WalletRepository().SaveWallet(domain.Wallet{ID: 3, UserID: 99, Name: "Fremd"}); err != nil {
+		t.Fatalf("could not seed the foreign wallet: %v", err)
+	}
+	goalSvc := NewSavingsGoalService(f.repos)
+	budgetID, foreign := 2, 3
+	target := time.Now().AddDate(1, 0, 0)
+
+	for name, tc := range map[string]struct {
+		goal domain.SavingsGoal
+		want error
+	}{
+		"wallet and budget":        {domain.SavingsGoal{Name: "Urlaub", WalletID: &f.walletID, BudgetID: &budgetID, TargetCents: 100, TargetDate: target}, domain.ErrInvalidSavingsGoal},
+		"no target date":           {domain.SavingsGoal{Name: "Urlaub", BudgetID: &budgetID, TargetCents: 100}, domain.ErrInvalidSavingsGoal},
+		"no target amount":         {domain.SavingsGoal{Name: "Urlaub", BudgetID: &budgetID, TargetDate: target}, domain.ErrInvalidAmount},
+		"contribution to a budget": {domain.SavingsGoal{Name: "Urlaub", BudgetID: &budgetID, TargetCents: 100, TargetDate: target, MonthlyContributionCents: 10, FromWalletID: &f.walletID}, domain.ErrInvalidSavingsGoal},
+		"foreign wallet":           {domain.SavingsGoal{Name: "Urlaub", WalletID: &foreign, TargetCents: 100, TargetDate: target}, domain.ErrWalletNotFound},
+	} {
+		if _, err := goalSvc.CreateSavingsGoal(f.userID, tc.goal); !errors.Is(err, tc.want) {
+			t.Errorf("%s: expected %v, got %v", name, tc.want, err)
+		}
+	}
+	if goals, _ := goalSvc.GetSavingsGoals(f.userID); len(goals) != 0 {
+		t.Errorf("expected no goal to be saved, got %+v", goals)
+	}
+}
@@ -0,0 +1,274 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fim-lab/expense-tracker/internal/core/domain"
+	"github.com/fim-lab/expense-tracker/internal/core/ports"
+)
+
+type sharedExpenseService struct {
+	repos ports.Repositories
+}
+
+func NewSharedExpenseService(repos ports.Repositories) ports.SharedExpenseService {
+	return &sharedExpenseService{repos: repos}
+}
+
+// ShareExpense is only open to the owner of the transaction, who becomes the
+// payer. The users it is shared with only get to see it.
+func (s *sharedExpenseService) ShareExpense(userID int, transactionID int, e domain.SharedExpense) (domain.SharedExpense, error) {
+	err := s.repos.WithinTransaction(func(repos ports.Repositories) error {
+		t, err := NewAuthorizer(repos).Transaction(userID, transactionID, domain.PermissionOwner)
+		if err != nil {
+			return domain.ErrTransactionNotFound
+		}
+		if t.Type != domain.Expense {
+			return fmt.Errorf("%w: only expenses can be shared", domain.ErrInvalidShare)
+		}
+		for i, share := range e.Shares {
+			if e.Shares[i].UserID, err = resolveUser(repos, share); err != nil {
+				return err
+			}
+		}
+
+		e.ID, e.TransactionID, e.PayerID, e.CreatedAt = 0, transactionID, userID, time.Now()
+		if err := e.Allocate(t.AmountInCents); err != nil {
+			return err
+		}
+		if existing, err := repos.SharedExpenseRepository().GetSharedExpenseByTransaction(transactionID); err == nil {
+			if err := repos.SharedExpenseRepository().DeleteSharedExpense(existing.ID); err != nil {
+				return err
+			}
+		}
+		if e.ID, err = repos.SharedExpenseRepository().SaveSharedExpense(e); err != nil {
+			return err
+		}
+		e.Date, e.Description, e.AmountInCents = t.Date, t.Description, t.AmountInCents
+		return nil
+	})
+	if err != nil {
+		return domain.SharedExpense{}, err
+	}
+	names, err := s.usernames()
+	if err != nil {
+		return domain.SharedExpense{}, err
+	}
+	nameSharedExpense(&e, names)
+	return e, nil
+}
+
+func (s *sharedExpenseService) UnshareExpense(userID int, transactionID int) error {
+	return s.repos.WithinTransaction(func(repos ports.Repositories) error {
+		e, err := repos.SharedExpenseRepository().GetSharedExpenseByTransaction(transactionID)
+		if err != nil || e.PayerID != userID {
+			return domain.ErrSharedExpenseNotFound
+		}
+		return repos.SharedExpenseRepository().DeleteSharedExpense(e.ID)
+	})
+}
+
+func (s *sharedExpenseService) GetSharedExpenses(userID int) ([]domain.SharedExpense, error) {
+	expenses, err := s.repos.SharedExpenseRepository().FindSharedExpensesByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	names, err := s.usernames()
+	if err != nil {
+		return nil, err
+	}
+	for i := range expenses {
+		nameSharedExpense(&expenses[i], names)
+	}
+	if expenses == nil {
+		expenses = []domain.SharedExpense{}
+	}
+	return expenses, nil
+}
+
+func (s *sharedExpenseService) GetLedger(userID int) ([]domain.SharedBalance, error) {
+	balances, err := ledger(s.repos, userID)
+	if err != nil {
+		return nil, err
+	}
+	names, err := s.usernames()
+	if err != nil {
+		return nil, err
+	}
+	for i := range balances {
+		balances[i].Username = names[balances[i].UserID]
+	}
+	return balances, nil
+}
+
+// SettleUp lets whichever side owes pay: the user pays from the wallet if
+// they owe the other user, and gets paid into it if they are owed. Only the
+// user's side is booked; the other user books theirs by confirming.
+func (s *sharedExpenseService) SettleUp(userID int, otherUserID int, walletID int, amountInCents int) (domain.Settlement, error) {
+	if amountInCents < 0 {
+		return domain.Settlement{}, domain.ErrInvalidAmount
+	}
+	var settlement domain.Settlement
+	err := s.repos.WithinTransaction(func(repos ports.Repositories) error {
+		other, err := repos.UserRepository().GetUserByID(otherUserID)
+		if err != nil || otherUserID == userID {
+			return domain.ErrUserNotFound
+		}
+		balances, err := ledger(repos, userID)
+		if err != nil {
+			return err
+		}
+		// Pending settlements count here, so the same debt is not settled
+		// twice while the other user has yet to confirm.
+		balance := 0
+		for _, b := range balances {
+			if b.UserID != otherUserID {
+				continue
+			}
+			balance = b.BalanceCents
+			for _, e := range b.Entries {
+				if e.Pending {
+					balance += e.AmountInCents
+				}
+			}
+		}
+		owed := max(balance, -balance)
+		if amountInCents == 0 {
+			amountInCents = owed
+		}
+		if amountInCents == 0 {
+			return fmt.Errorf("%w: nothing to settle", domain.ErrInvalidAmount)
+		}
+		if amountInCents > owed {
+			return fmt.Errorf("%w: %s open", domain.ErrOverRepaid, formatCents(owed))
+		}
+
+		wallet, err := NewAuthorizer(repos).Wallet(userID, walletID, domain.PermissionWrite)
+		if err != nil {
+			return domain.ErrWalletNotFound
+		}
+		settlement = domain.Settlement{FromUserID: userID, ToUserID: otherUserID, AmountInCents: amountInCents, Date: domain.DateOf(time.Now()), CreatedAt: time.Now()}
+		if balance > 0 {
+			settlement.FromUserID, settlement.ToUserID = otherUserID, userID
+		}
+		if err := bookSettlement(repos, &settlement, userID, wallet, other.Username); err != nil {
+			return err
+		}
+		settlement.ID, err = repos.SharedExpenseRepository().SaveSettlement(settlement)
+		return err
+	})
+	if err != nil {
+		return domain.Settlement{}, err
+	}
+	return settlement, nil
+}
+
+// ConfirmSettlement is only open to the side of the settlement that is not
+// booked yet.
+func (s *sharedExpenseService) ConfirmSettlement(userID int, settlementID int, walletID int) (domain.Settlement, error) {
+	var settlement domain.Settlement
+	err := s.repos.WithinTransaction(func(repos ports.Repositories) error {
+		var err error
+		settlement, err = repos.SharedExpenseRepository().GetSettlementByID(settlementID)
+		if err != nil || !settlement.Pending() {
+			return domain.ErrSettlementNotFound
+		}
+		var otherUserID int
+		switch {
+		case userID == settlement.FromUserID && settlement.FromTransactionID == nil:
+			otherUserID = settlement.ToUserID
+		case userID == settlement.ToUserID && settlement.ToTransactionID == nil:
+			otherUserID = settlement.FromUserID
+		default:
+			return domain.ErrSettlementNotFound
+		}
+		other, err := repos.UserRepository().GetUserByID(otherUserID)
+		if err != nil {
+			return err
+		}
+
+		wallet, err := NewAuthorizer(repos).Wallet(userID, walletID, domain.PermissionWrite)
+		if err != nil {
+			return domain.ErrWalletNotFound
+		}
+		if err := bookSettlement(repos, &settlement, userID, wallet, other.Username); err != nil {
+			return err
+		}
+		confirmedAt := time.Now()
+		settlement.ConfirmedAt = &confirmedAt
+		return repos.SharedExpenseRepository().UpdateSettlement(settlement)
+	})
+	if err != nil {
+		return domain.Settlement{}, err
+	}
+	return settlement, nil
+}
+
+// bookSettlement books the user's side of the settlement on the wallet: an
+// expense if they pay, an income if they are paid.
+// Like debts, it is booked on no budget and never reaches an alert threshold.
+func bookSettlement(repos ports.Repositories, settlement *domain.Settlement, userID int, wallet domain.Wallet, otherName string) error {
+	isDebt := true
+	t := domain.Transaction{UserID: wallet.UserID, CreatedBy: userID, WalletID: wallet.ID, Date: settlement.Date, Description: "Settled up with " + otherName, AmountInCents: settlement.AmountInCents, Type: domain.Income, IsDebt: &isDebt}
+	if userID == settlement.FromUserID {
+		t.Type = domain.Expense
+	}
+	id, err := repos.TransactionRepository().SaveTransaction(t)
+	if err != nil {
+		return err
+	}
+	if userID == settlement.FromUserID {
+		settlement.FromTransactionID = &id
+	} else {
+		settlement.ToTransactionID = &id
+	}
+	return nil
+}
+
+func ledger(repos ports.Repositories, userID int) ([]domain.SharedBalance, error) {
+	expenses, err := repos.SharedExpenseRepository().FindSharedExpensesByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	settlements, err := repos.SharedExpenseRepository().FindSettlementsByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	return domain.SharedLedger(userID, expenses, settlements), nil
+}
+
+// resolveUser finds the user a share names, by username if it has one.
+func resolveUser(repos ports.Repositories, share domain.ExpenseShare) (int, error) {
+	if name := strings.TrimSpace(share.Username); name != "" {
+		u, err := repos.UserRepository().GetUserByUsername(name)
+		if err != nil {
+			return 0, fmt.Errorf("%w: %s", domain.ErrUserNotFound, name)
+		}
+		return u.ID, nil
+	}
+	if _, err := repos.UserRepository().GetUserByID(share.UserID); err != nil {
+		return 0, fmt.Errorf("%w: %d", domain.ErrUserNotFound, share.UserID)
+	}
+	return share.UserID, nil
+}
+
+func (s *sharedExpenseService) usernames() (map[int]string, error) {
+	users, err := s.repos.UserRepository().FindAllUsers()
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[int]string, len(users))
+	for _, u := range users {
+		names[u.ID] = u.Username
+	}
+	return names, nil
+}
+
+func nameSharedExpense(e *domain.SharedExpense, names map[int]string) {
+	e.PayerName = names[e.PayerID]
+	for i := range e.Shares {
+		e.Shares[i].Username = names[e.Shares[i].UserID]
+	}
+}
@@ -0,0 +1,217 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/fim-lab/expense-tracker/internal/core/domain"
+	"github.com/fim-lab/expense-tracker/internal/core/ports"
+)
+
+// seedFlat seeds three flat-mates: Anna (1) with wallet 1, Ben (2) with a
+// cash wallet 2 and a checking account 3, and Carla (3) with wallet 4.
+func seedFlat(t *testing.T, repos ports.Repositories) {
+	t.Helper()
+	for id, name := range map[int]string{1: "anna", 2: "ben", 3: "carla"} {
+		if err := repos.UserRepository().SaveUser(domain.User{ID: id, Username: name}); err != nil {
+			t.Fatalf("could not seed %s: %v", name, err)
+		}
+	}
+	for _, w := range []domain.Wallet{
+		{ID: 1, UserID: 1, Name: "Girokonto", Type: domain.WalletChecking},
+		{ID: 2, UserID: 2, Name: "Bargeld", Type: domain.WalletCash},
+		{ID: 3, UserID: 2, Name: "Girokonto", Type: domain.WalletChecking},
+		{ID: 4, UserID: 3, Name: "Girokonto", Type: domain.WalletChecking},
+	} {
+		if err := repos.WalletRepository().SaveWallet(w); err != nil {
+			t.Fatalf("could not seed wallet %d: %v", w.ID, err)
+		}
+	}
+	for _, tx := range []domain.Transaction{
+		{ID: 11, UserID: 1, WalletID: 1, Date: onDate(2026, 4, 1), Description: "Wocheneinkauf", AmountInCents: 9001, Type: domain.Expense},
+		{ID: 12, UserID: 1, WalletID: 1, Date: onDate(2026, 4, 2), Description: "Internet", AmountInCents: 10000, Type: domain.Expense},
+		{ID: 13, UserID: 2, WalletID: 3, Date: onDate(2026, 4, 3), Description: "Putzmittel", AmountInCents: 2000, Type: domain.Expense},
+	} {
+		if _, err := repos.TransactionRepository().SaveTransaction(tx); err != nil {
+			t.Fatalf("could not seed transaction %d: %v", tx.ID, err)
+		}
+	}
+}
+
+func TestSharedExpenses_SplitsAddUpToTheLedger(t *testing.T) {
+	f := newWalletFixture(t)
+	seedFlat(t, f.repos)
+	svc := NewSharedExpenseService(f.repos)
+
+	equal, err := svc.ShareExpense(1, 11, domain.SharedExpense{Method: domain.SplitEqual, Shares: []domain.ExpenseShare{{Username: "carla"}, {UserID: 2}}})
+	if err != nil {
+		t.Fatalf("sharing equally failed: %v", err)
+	}
+	if len(equal.Shares) != 3 || equal.Shares[0].Username != "ben" || equal.Shares[0].AmountInCents != 3000 || equal.Shares[2].UserID != 1 || equal.Shares[2].AmountInCents != 3001 {
+		t.Errorf("expected the payer to keep the odd cent, got %+v", equal.Shares)
+	}
+	if _, err := svc.ShareExpense(1, 12, domain.SharedExpense{Method: domain.SplitPercentage, Shares: []domain.ExpenseShare{{UserID: 2, Percent: 60}, {UserID: 1, Percent: 50}}}); !errors.Is(err, domain.ErrInvalidShare) {
+		t.Errorf("expected percentages above 100 to be refused, got %v", err)
+	}
+	if _, err := svc.ShareExpense(1, 12, domain.SharedExpense{Method: domain.SplitPercentage, Shares: []domain.ExpenseShare{{UserID: 2, Percent: 25}}}); err != nil {
+		t.Fatalf("sharing by percentage failed: %v", err)
+	}
+	if _, err := svc.ShareExpense(2, 13, domain.SharedExpense{Method: domain.SplitExact, Shares: []domain.ExpenseShare{{Username: "anna", AmountInCents: 1200}}}); err != nil {
+		t.Fatalf("sharing exact amounts failed: %v", err)
+	}
+	if _, err := svc.ShareExpense(1, 12, domain.SharedExpense{Method: domain.SplitEqual, Shares: []domain.ExpenseShare{{Username: "dora"}}}); !errors.Is(err, domain.ErrUserNotFound) {
+		t.Errorf("expected an unknown user to be refused, got %v", err)
+	}
+
+	ledger, err := svc.GetLedger(1)
+	if err != nil || len(ledger) != 2 {
+		t.Fatalf("expected balances with Ben and Carla, got %+v (%v)", ledger, err)
+	}
+	if ledger[0].Username != "ben" || ledger[0].BalanceCents != 3000+2500-1200 || len(ledger[0].Entries) != 3 {
+		t.Errorf("expected Ben to owe 43.00, got %+v", ledger[0])
+	}
+	if ledger[1].Username != "carla" || ledger[1].BalanceCents != 3000 {
+		t.Errorf("expected Carla to owe 30.00, got %+v", ledger[1])
+	}
+	if carla, _ := svc.GetLedger(3); len(carla) != 1 || carla[0].UserID != 1 || carla[0].BalanceCents != -3000 {
+		t.Errorf("expected Carla to see what she owes Anna, got %+v", carla)
+	}
+	if shared, _ := svc.GetSharedExpenses(3); len(shared) != 1 || shared[0].PayerName != "anna" {
+		t.Errorf("expected Carla to see the expense Anna paid, got %+v", shared)
+	}
+}
+
+func TestSharedExpenses_OnlyThePayerMayChangeIt(t *testing.T) {
+	f := newWalletFixture(t)
+	seedFlat(t, f.repos)
+	svc := NewSharedExpenseService(f.repos)
+	if _, err := svc.ShareExpense(1, 11, domain.SharedExpense{Method: domain.SplitEqual, Shares: []domain.ExpenseShare{{UserID: 2}}}); err != nil {
+		t.Fatalf("sharing failed: %v", err)
+	}
+
+	if _, err := svc.ShareExpense(2, 11, domain.SharedExpense{Method: domain.SplitEqual, Shares: []domain.ExpenseShare{{UserID: 1}}}); !errors.Is(err, domain.ErrTransactionNotFound) {
+		t.Errorf("expected a participant not to reshare the expense, got %v", err)
+	}
+	if err := svc.UnshareExpense(2, 11); !errors.Is(err, domain.ErrSharedExpenseNotFound) {
+		t.Errorf("expected a participant not to unshare the expense, got %v", err)
+	}
+	if err := svc.UnshareExpense(1, 11); err != nil {
+		t.Fatalf("unsharing failed: %v", err)
+	}
+	if ledger, _ := svc.GetLedger(2); len(ledger) != 0 {
+		t.Errorf("expected nothing owed once unshared, got %+v", ledger)
+	}
+}
+
+func TestSharedExpenses_SettleUpWaitsForTheOtherSide(t *testing.T) {
+	f := newWalletFixture(t)
+	seedFlat(t, f.repos)
+	svc := NewSharedExpenseService(f.repos)
+	if _, err := svc.ShareExpense(1, 12, domain.SharedExpense{Method: domain.SplitEqual, Shares: []domain.ExpenseShare{{UserID: 2}}}); err != nil {
+		t.Fatalf("sharing failed: %v", err)
+	}
+
+	if _, err := svc.SettleUp(2, 1, 2, 6000); !errors.Is(err, domain.ErrOverRepaid) {
+		t.Errorf("expected paying more than is owed to be refused, got %v", err)
+	}
+	if _, err := svc.SettleUp(2, 1, 1, 1000); !errors.Is(err, domain.ErrWalletNotFound) {
+		t.Errorf("expected another user's wallet to be refused, got %v", err)
+	}
+	paid, err := svc.SettleUp(2, 1, 2, 2000)
+	if err != nil {
+		t.Fatalf("settling in part failed: %v", err)
+	}
+	if paid.FromUserID != 2 || paid.ToUserID != 1 || paid.ToTransactionID != nil || !paid.Pending() {
+		t.Errorf("expected Ben to pay Anna, pending her side, got %+v", paid)
+	}
+	if from, _ := f.repos.TransactionRepository().GetTransactionByID(*paid.FromTransactionID); from.UserID != 2 || from.WalletID != 2 || from.Type != domain.Expense || from.AmountInCents != 2000 {
+		t.Errorf("expected the payment to leave Ben's wallet, got %+v", from)
+	}
+	if anna, _ := f.repos.WalletRepository().GetWalletByID(1); anna.BalanceCents != -19001 {
+		t.Errorf("expected Anna's wallet untouched until she confirms, got %d", anna.BalanceCents)
+	}
+	if ledger, _ := svc.GetLedger(1); len(ledger) != 1 || ledger[0].BalanceCents != 5000 || !ledger[0].Entries[1].Pending {
+		t.Errorf("expected the pending settlement listed but not counted, got %+v", ledger)
+	}
+	if _, err := svc.SettleUp(2, 1, 2, 4000); !errors.Is(err, domain.ErrOverRepaid) {
+		t.Errorf("expected the pending settlement to count against what is open, got %v", err)
+	}
+
+	if _, err := svc.ConfirmSettlement(2, paid.ID, 2); !errors.Is(err, domain.ErrSettlementNotFound) {
+		t.Errorf("expected Ben not to confirm his own payment, got %v", err)
+	}
+	if _, err := svc.ConfirmSettlement(1, paid.ID, 2); !errors.Is(err, domain.ErrWalletNotFound) {
+		t.Errorf("expected Anna not to book on Ben's wallet, got %v", err)
+	}
+	confirmed, err := svc.ConfirmSettlement(1, paid.ID, 1)
+	if err != nil {
+		t.Fatalf("confirming failed: %v", err)
+	}
+	if to, _ := f.repos.TransactionRepository().GetTransactionByID(*confirmed.ToTransactionID); to.UserID != 1 || to.WalletID != 1 || to.Type != domain.Income || to.Description != "Settled up with ben" {
+		t.Errorf("expected the payment to reach Anna's wallet, got %+v", to)
+	}
+	if _, err := svc.ConfirmSettlement(1, paid.ID, 1); !errors.Is(err, domain.ErrSettlementNotFound) {
+		t.Errorf("expected a settlement to be confirmed once, got %v", err)
+	}
+
+	rest, err := svc.SettleUp(1, 2, 1, 0)
+	if err != nil {
+		t.Fatalf("settling the rest failed: %v", err)
+	}
+	if rest.FromUserID != 2 || rest.AmountInCents != 3000 || rest.FromTransactionID != nil {
+		t.Errorf("expected Ben to owe the remaining 30.00, got %+v", rest)
+	}
+	if _, err := svc.ConfirmSettlement(2, rest.ID, 3); err != nil {
+		t.Fatalf("confirming the rest failed: %v", err)
+	}
+	if ben, _ := f.repos.WalletRepository().GetWalletByID(3); ben.BalanceCents != -5000 {
+		t.Errorf("expected Ben's checking account to be charged, got %d", ben.BalanceCents)
+	}
+	if ledger, _ := svc.GetLedger(1); len(ledger) != 1 || ledger[0].BalanceCents != 0 {
+		t.Errorf("expected Anna and Ben to be even, got %+v", ledger)
+	}
+	if _, err := svc.SettleUp(1, 2, 1, 0); !errors.Is(err, domain.ErrInvalidAmount) {
+		t.Errorf("expected nothing left to settle, got %v", err)
+	}
+}
+
+func TestSharedExpenses_DeletingASettlementPayment(t *testing.T) {
+	f := newWalletFixture(t)
+	seedFlat(t, f.repos)
+	svc := NewSharedExpenseService(f.repos)
+	txSvc := NewTransactionService(f.repos.TransactionRepository(), NewAuthorizer(f.repos))
+	if _, err := svc.ShareExpense(1, 12, domain.SharedExpense{Method: domain.SplitEqual, Shares: []domain.ExpenseShare{{UserID: 2}}}); err != nil {
+		t.Fatalf("sharing failed: %v", err)
+	}
+
+	pending, err := svc.SettleUp(2, 1, 2, 2000)
+	if err != nil {
+		t.Fatalf("settling up failed: %v", err)
+	}
+	if err := txSvc.DeleteTransaction(2, *pending.FromTransactionID); err != nil {
+		t.Fatalf("deleting the pending payment failed: %v", err)
+	}
+	if _, err := svc.ConfirmSettlement(1, pending.ID, 1); !errors.Is(err, domain.ErrSettlementNotFound) {
+		t.Errorf("expected the settlement to go with its payment, got %v", err)
+	}
+	if ledger, _ := svc.GetLedger(1); len(ledger) != 1 || len(ledger[0].Entries) != 1 || ledger[0].BalanceCents != 5000 {
+		t.Errorf("expected only the shared expense left, got %+v", ledger)
+	}
+
+	settled, err := svc.SettleUp(2, 1, 2, 2000)
+	if err != nil {
+		t.Fatalf("settling up again failed: %v", err)
+	}
+	if settled, err = svc.ConfirmSettlement(1, settled.ID, 1); err != nil {
+		t.Fatalf("confirming failed: %v", err)
+	}
+	if err := txSvc.DeleteTransaction(2, *settled.FromTransactionID); !errors.Is(err, domain.ErrSettlementConfirmed) {
+		t.Errorf("expected Ben not to take back a confirmed payment, got %v", err)
+	}
+	if err := txSvc.DeleteTransaction(1, *settled.ToTransactionID); !errors.Is(err, domain.ErrSettlementConfirmed) {
+		t.Errorf("expected Anna not to take back a confirmed payment, got %v", err)
+	}
+	if ledger, _ := svc.GetLedger(1); len(ledger) != 1 || ledger[0].BalanceCents != 3000 {
+		t.Errorf("expected the confirmed settlement to still count, got %+v", ledger)
+	}
+}
@@ -0,0 +1,204 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/fim-lab/expense-tracker/internal/core/domain"
+	"github.com/fim-lab/expense-tracker/internal/core/ports"
+)
+
+type statementImportService struct {
+	importService  ports.ImportService
+	auth           ports.Authorizer
+	csvProfileRepo ports.CSVProfileRepository
+	parser         ports.StatementParser
+}
+
+func NewStatementImportService(
+	importService ports.ImportService,
+	auth ports.Authorizer,
+	csvProfileRepo ports.CSVProfileRepository,
+	parser ports.StatementParser,
+) ports.StatementImportService {
+	return &statementImportService{
+		importService:  importService,
+		auth:           auth,
+		csvProfileRepo: csvProfileRepo,
+		parser:         parser,
+	}
+}
+
+// ImportCSV books the rows of a bank's CSV export on the given wallet.
+func (s *statementImportService) ImportCSV(userID int, walletID int, profileName string, data []byte) (domain.StatementImportResult, error) {
+	wallet, err := s.writableWallet(userID, walletID)
+	if err != nil {
+		return domain.StatementImportResult{}, err
+	}
+
+	profile, err := s.findCSVProfile(userID, profileName)
+	if err != nil {
+		return domain.StatementImportResult{}, err
+	}
+
+	statement, err := s.parser.ParseCSV(data, profile)
+	if err != nil {
+		return domain.StatementImportResult{}, err
+	}
+
+	return s.importStatement(userID, wallet, statement)
+}
+
+func (s *statementImportService) ImportCAMT053(userID int, walletID int, data []byte) (domain.StatementImportResult, error) {
+	return s.parseAndImport(userID, walletID, data, s.parser.ParseCAMT053)
+}
+
+func (s *statementImportService) ImportMT940(userID int, walletID int, data []byte) (domain.StatementImportResult, error) {
+	return s.parseAndImport(userID, walletID, data, s.parser.ParseMT940)
+}
+
+func (s *statementImportService) parseAndImport(userID int, walletID int, data []byte, parse func([]byte) (domain.Statement, error)) (domain.StatementImportResult, error) {
+	wallet, err := s.writableWallet(userID, walletID)
+	if err != nil {
+		return domain.StatementImportResult{}, err
+	}
+
+	statement, err := parse(data)
+	if err != nil {
+		return domain.StatementImportResult{}, err
+	}
+
+	return s.importStatement(userID, wallet, statement)
+}
+
+func (s *statementImportService) writableWallet(userID int, walletID int) (domain.Wallet, error) {
+	return s.auth.Wallet(userID, walletID, domain.PermissionWrite)
+}
+
+// importStatement books the statement's rows on the wallet. If the statement
+// carries balances, they are compared with the wallet's balance before and
+// after the import; a mismatch is reported, not treated as an error, as the
+// wallet may hold entries the bank does not know about. If some rows were
+// booked by an earlier import, the wallet held them before this one, so only
+// the closing balance is compared. The rows are imported as the wallet's
+// owner's, who may not be the user importing them.
+func (s *statementImportService) importStatement(userID int, wallet domain.Wallet, statement domain.Statement) (domain.StatementImportResult, error) {
+	rows := statement.Transactions
+	for i := range rows {
+		rows[i].Wallet = wallet.Name
+	}
+
+	imported, err := s.importService.ImportData(wallet.UserID, domain.FullImportData{Transactions: rows})
+	if err != nil {
+		return domain.StatementImportResult{}, err
+	}
+	result := domain.StatementImportResult{ImportResult: imported}
+
+	if statement.OpeningBalanceCents == nil || statement.ClosingBalanceCents == nil {
+		return result, nil
+	}
+
+	after, err := s.auth.Wallet(userID, wallet.ID, domain.PermissionRead)
+	if err != nil {
+		return domain.StatementImportResult{}, fmt.Errorf("failed to fetch wallet balance after import: %w", err)
+	}
+	check := domain.BalanceCheck{
+		OpeningBalanceCents:      *statement.OpeningBalanceCents,
+		ClosingBalanceCents:      *statement.ClosingBalanceCents,
+		WalletBalanceBeforeCents: wallet.BalanceCents,
+		WalletBalanceAfterCents:  after.BalanceCents,
+	}
+	overlaps := imported.Skipped > 0 || imported.Updated > 0
+	switch {
+	case !overlaps && check.WalletBalanceBeforeCents != check.OpeningBalanceCents:
+		check.Mismatch = fmt.Sprintf("wallet %q held %d cents before the import, but the statement opens with %d", wallet.Name, check.WalletBalanceBeforeCents, check.OpeningBalanceCents)
+	case check.WalletBalanceAfterCents != check.ClosingBalanceCents:
+		check.Mismatch = fmt.Sprintf("wallet %q holds %d cents after the import, but the statement closes with %d", wallet.Name, check.WalletBalanceAfterCents, check.ClosingBalanceCents)
+	default:
+		check.Matches = true
+	}
+	if !check.Matches {
+		log.Printf("Balance mismatch importing a statement for user %d: %s", userID, check.Mismatch)
+	}
+
+	result.BalanceCheck = &check
+	return result, nil
+}
+
+// findCSVProfile looks the name up in the user's own profiles first, so a user
+// can replace a built-in profile that no longer matches their bank's export.
+func (s *statementImportService) findCSVProfile(userID int, name string) (domain.CSVProfile, error) {
+	profiles, err := s.GetCSVProfiles(userID)
+	if err != nil {
+		return domain.CSVProfile{}, err
+	}
+
+	var builtIn *domain.CSVProfile
+	for i, p := range profiles {
+		if !strings.EqualFold(p.Name, strings.TrimSpace(name)) {
+			continue
+		}
+		if !p.BuiltIn {
+			return p, nil
+		}
+		if builtIn == nil {
+			builtIn = &profiles[i]
+		}
+	}
+	if builtIn == nil {
+		return domain.CSVProfile{}, domain.ErrCSVProfileNotFound
+	}
+	return *builtIn, nil
+}
+
+func (s *statementImportService) GetCSVProfiles(userID int) ([]domain.CSVProfile, error) {
+	own, err := s.csvProfileRepo.FindCSVProfilesByUser(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch csv profiles: %w", err)
+	}
+	return append(s.parser.BuiltInCSVProfiles(), own...), nil
+}
+
+func (s *statementImportService) CreateCSVProfile(userID int, p domain.CSVProfile) error {
+	p.UserID = userID
+	p.BuiltIn = false
+	p.ApplyDefaults()
+
+	if err := p.Validate(); err != nil {
+		return err
+	}
+
+	return s.csvProfileRepo.SaveCSVProfile(p)
+}
+
+func (s *statementImportService) UpdateCSVProfile(userID int, p domain.CSVProfile) error {
+	existing, err := s.csvProfileRepo.GetCSVProfileByID(p.ID)
+	if err != nil {
+		return err
+	}
+	if existing.UserID != userID {
+		return domain.ErrUnauthorized
+	}
+
+	p.UserID = userID
+	p.BuiltIn = false
+	p.ApplyDefaults()
+	if err := p.Validate(); err != nil {
+		return err
+	}
+
+	return s.csvProfileRepo.UpdateCSVProfile(p)
+}
+
+func (s *statementImportService) DeleteCSVProfile(userID int, id int) error {
+	existing, err := s.csvProfileRepo.GetCSVProfileByID(id)
+	if err != nil {
+		return err
+	}
+	if existing.UserID != userID {
+		return domain.ErrUnauthorized
+	}
+
+	return s.csvProfileRepo.DeleteCSVProfile(id)
+}
@@ -0,0 +1,187 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/fim-lab/expense-tracker/adapters/importer"
+	"github.com/fim-lab/expense-tracker/adapters/repository/memory"
+	"github.com/fim-lab/expense-tracker/internal/core/domain"
+	"github.com/fim-lab/expense-tracker/internal/core/ports"
+)
+
+func newStatementImportFixture(t *testing.T) (ports.Repositories, ports.StatementImportService, int) {
+	t.Helper()
+	repos := memory.NewCleanRepositories()
+	importSvc := NewImportService(repos, noBudgetAlerts{})
+	svc := NewStatementImportService(importSvc, NewAuthorizer(repos), repos.CSVProfileRepository(), importer.NewStatementParser())
+
+	if err := repos.WalletRepository().SaveWallet(domain.Wallet{UserID: 1, Name: "Girokonto"}); err != nil {
+		t.Fatalf("could not seed the wallet: %v", err)
+	}
+	wallets, _ := repos.WalletRepository().FindWalletsByUser(1)
+	return repos, svc, wallets[0].ID
+}
+
+func TestStatementImportService_ImportCSV(t *testing.T) {
+	repos, svc, walletID := newStatementImportFixture(t)
+	data := []byte("Buchung;Valuta;Auftraggeber/Empf\xe4nger;Buchungstext;Verwendungszweck;Saldo;W\xe4hrung;Betrag;W\xe4hrung\n" +
+		"02.03.2026;02.03.2026;Arbeitgeber;Gehalt;M\xe4rz;2.500,00;EUR;2.500,00;EUR\n" +
+		"03.03.2026;03.03.2026;REWE;Lastschrift;Einkauf;2.450,00;EUR;-50,00;EUR\n")
+
+	result, err := svc.ImportCSV(1, walletID, "ing", data)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if result.Created != 2 || result.BalanceCheck != nil {
+		t.Errorf("expected 2 imported rows without balance check, got %+v", result)
+	}
+
+	wallet, _ := repos.WalletRepository().GetWalletByID(walletID)
+	if wallet.BalanceCents != 245000 {
+		t.Errorf("expected the wallet balance to be 245000, got %d", wallet.BalanceCents)
+	}
+
+	if _, err := svc.ImportCSV(2, walletID, "ING", data); !errors.Is(err, domain.ErrUnauthorized) {
+		t.Errorf("expected importing onto another user's wallet to be unauthorized, got %v", err)
+	}
+	for userID, permission := range map[int]domain.Permission{2: domain.PermissionWrite, 3: domain.PermissionRead} {
+		if _, err := repos.MembershipRepository().SaveMembership(domain.Membership{OwnerID: 1, UserID: userID, ResourceType: domain.ResourceWallet, ResourceID: walletID, Permission: permission}); err != nil {
+			t.Fatalf("could not seed the membership: %v", err)
+		}
+	}
+	if _, err := svc.ImportCSV(3, walletID, "ING", data); !errors.Is(err, domain.ErrUnauthorized) {
+		t.Errorf("expected a reader not to import onto the wallet, got %v", err)
+	}
+	more := []byte("Buchung;Valuta;Auftraggeber/Empf\xe4nger;Buchungstext;Verwendungszweck;Saldo;W\xe4hrung;Betrag;W\xe4hrung\n" +
+		"04.03.2026;04.03.2026;EDEKA;Lastschrift;Einkauf;2.445,00;EUR;-5,00;EUR\n")
+	if result, err := svc.ImportCSV(2, walletID, "ING", more); err != nil || result.Created != 1 {
+		t.Fatalf("expected a member with write access to import onto the wallet, got %+v (%v)", result, err)
+	}
+	if wallet, _ := repos.WalletRepository().GetWalletByID(walletID); wallet.BalanceCents != 244500 {
+		t.Errorf("expected the member's import on the owner's wallet, got a balance of %d", wallet.BalanceCents)
+	}
+	if _, err := svc.ImportCSV(1, walletID, "Unbekannte Bank", data); !errors.Is(err, domain.ErrCSVProfileNotFound) {
+		t.Errorf("expected an unknown profile to be rejected, got %v", err)
+	}
+}
+
+func TestStatementImportService_CustomProfiles(t *testing.T) {
+	repos, svc, walletID := newStatementImportFixture(t)
+
+	if err := svc.CreateCSVProfile(1, domain.CSVProfile{Name: "Broken"}); !errors.Is(err, domain.ErrInvalidCSVProfile) {
+		t.Errorf("expected an incomplete profile to be rejected, got %v", err)
+	}
+
+	// A user's own profile takes precedence over the built-in one of the same name.
+	err := svc.CreateCSVProfile(1, domain.CSVProfile{
+		Name:               "ING",
+		Delimiter:          ",",
+		DateColumn:         "Date",
+		DateFormat:         "YYYY-MM-DD",
+		DescriptionColumns: []string{"Payee"},
+		AmountColumn:       "Amount",
+		DecimalSeparator:   ".",
+		SignConvention:     domain.PositiveIsExpense,
+	})
+	if err != nil {
+		t.Fatalf("expected no error creating the profile, got %v", err)
+	}
+
+	profiles, _ := svc.GetCSVProfiles(1)
+	if len(profiles) != 5 || profiles[4].BuiltIn || profiles[4].Encoding != domain.EncodingUTF8 {
+		t.Fatalf("expected four built-in profiles and the user's own with defaults, got %+v", profiles)
+	}
+
+	if _, err := svc.ImportCSV(1, walletID, "ING", []byte("Date,Payee,Amount\n2026-03-01,Card,19.99\n")); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	wallet, _ := repos.WalletRepository().GetWalletByID(walletID)
+	if wallet.BalanceCents != -1999 {
+		t.Errorf("expected a positive amount to be booked as expense, got %d", wallet.BalanceCents)
+	}
+
+	if err := svc.DeleteCSVProfile(2, profiles[4].ID); !errors.Is(err, domain.ErrUnauthorized) {
+		t.Errorf("expected deleting another user's profile to be unauthorized, got %v", err)
+	}
+	if err := svc.DeleteCSVProfile(1, profiles[4].ID); err != nil {
+		t.Errorf("expected no error deleting the profile, got %v", err)
+	}
+}
+
+const testMT940 = `{1:F01BANKDEFFXXXX0000000000}{2:I940BANKDEFFXXXXN}{4:
+:20:STARTUMSE
+:25:10020030/1234567
+:28C:00001/001
+:60F:C260301EUR100,00
+:61:2603020302DR25,50NMSCNONREF//REF-1
+:86:105?00Lastschrift?20Rechnung 4711?32Stadtwerke
+:62F:C260302EUR74,50
+-}`
+
+func TestStatementImportService_BalanceCheck(t *testing.T) {
+	t.Run("Mismatch Is Reported", func(t *testing.T) {
+		_, svc, walletID := newStatementImportFixture(t)
+
+		result, err := svc.ImportMT940(1, walletID, []byte(testMT940))
+		if err != nil {
+			t.Fatalf("expected the import to succeed despite the mismatch, got %v", err)
+		}
+		check := result.BalanceCheck
+		if result.Created != 1 || check == nil || check.Matches || check.Mismatch == "" {
+			t.Errorf("expected a mismatch against the empty wallet to be reported, got %+v", result)
+		}
+	})
+
+	t.Run("Matching Balances", func(t *testing.T) {
+		repos, svc, walletID := newStatementImportFixture(t)
+		repos.TransactionRepository().SaveTransaction(domain.Transaction{
+			UserID: 1, WalletID: walletID, Description: "Anfangsbestand", AmountInCents: 10000, Type: domain.Income,
+		})
+
+		result, err := svc.ImportMT940(1, walletID, []byte(testMT940))
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		check := result.BalanceCheck
+		if check == nil || !check.Matches || check.WalletBalanceBeforeCents != 10000 || check.WalletBalanceAfterCents != 7450 {
+			t.Errorf("expected the balances to match, got %+v", check)
+		}
+	})
+
+	t.Run("Reimport Compares The Closing Balance", func(t *testing.T) {
+		repos, svc, walletID := newStatementImportFixture(t)
+		repos.TransactionRepository().SaveTransaction(domain.Transaction{
+			UserID: 1, WalletID: walletID, Description: "Anfangsbestand", AmountInCents: 10000, Type: domain.Income,
+		})
+		if _, err := svc.ImportMT940(1, walletID, []byte(testMT940)); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		result, err := svc.ImportMT940(1, walletID, []byte(testMT940))
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if check := result.BalanceCheck; result.Skipped != 1 || check == nil || !check.Matches {
+			t.Errorf("expected the statement booked before to match, got %+v", result)
+		}
+	})
+}
+
+func TestStatementImportService_RowsSharingACustomerReferenceAreAllBooked(t *testing.T) {
+	_, svc, walletID := newStatementImportFixture(t)
+	data := ":20:STARTUMSE\n" +
+		":25:10020030/1234567\n" +
+		":61:2601010101DR850,00NDDTMIETE\n" +
+		":86:Miete\n" +
+		":61:2602010201DR850,00NDDTMIETE\n" +
+		":86:Miete\n"
+
+	result, err := svc.ImportMT940(1, walletID, []byte(data))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if result.Created != 2 || result.Skipped != 0 {
+		t.Errorf("expected both rent payments to be booked, got %+v", result)
+	}
+}
@@ -38,14 +38,14 @@ func newStockFixture(t *testing.T) stockFixture {
 	}
 
 	stockSvc := NewStockService(repos.StockRepository(), repos.TradeRepository())
-	depotSvc := NewDepotService(repos.DepotRepository(), repos.WalletRepository(), repos.BudgetRepository(), repos.TradeRepository(), stockSvc)
-	txSvc := NewTransactionService(repos.TransactionRepository(), repos.BudgetRepository(), repos.WalletRepository())
+	depotSvc := NewDepotService(repos.DepotRepository(), repos.TradeRepository(), repos.MembershipRepository(), NewAuthorizer(repos), stockSvc)
+	txSvc := NewTransactionService(repos.TransactionRepository(), NewAuthorizer(repos))
 
 	return stockFixture{
 		repos:        repos,
 		depotSvc:     depotSvc,
 		txSvc:        txSvc,
-		tradeSvc:     NewTradeService(repos.TradeRepository(), depotSvc, txSvc, stockSvc),
+		tradeSvc:     NewTradeService(repos.TradeRepository(), repos.UserRepository(), depotSvc, txSvc, stockSvc),
 		portfolioSvc: NewPortfolioService(repos.TradeRepository(), depotSvc, stockSvc),
 		stockSvc:     stockSvc,
 		userID:       userID,
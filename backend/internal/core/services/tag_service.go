@@ -0,0 +1,150 @@
+package services
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/fim-lab/expense-tracker/internal/core/domain"
+	"github.com/fim-lab/expense-tracker/internal/core/ports"
+)
+
+type tagService struct {
+	repos ports.Repositories
+}
+
+func NewTagService(repos ports.Repositories) ports.TagService {
+	return &tagService{repos: repos}
+}
+
+func (s *tagService) GetTags(userID int) ([]domain.TagUsage, error) {
+	return tagUsage(s.repos, userID)
+}
+
+// RenameTag gives a tag a new name. Renaming it to a tag that is already in
+// use would merge the two, which has to be asked for with MergeTags.
+func (s *tagService) RenameTag(userID int, from, to string) (domain.TagChange, error) {
+	from, to = strings.TrimSpace(from), strings.TrimSpace(to)
+	if from == "" || to == "" {
+		return domain.TagChange{}, domain.ErrMissingTag
+	}
+	if from == to {
+		return domain.TagChange{}, nil
+	}
+
+	var change domain.TagChange
+	err := s.repos.WithinTransaction(func(repos ports.Repositories) error {
+		usage, err := tagUsage(repos, userID)
+		if err != nil {
+			return err
+		}
+		if !usesTag(usage, from) {
+			return domain.ErrTagNotFound
+		}
+		if usesTag(usage, to) {
+			return domain.ErrTagExists
+		}
+		change, err = replaceTags(repos, userID, []string{from}, to)
+		return err
+	})
+	return change, err
+}
+
+// MergeTags replaces all of the tags with into, which may be one of them, a
+// tag already in use or a new one.
+func (s *tagService) MergeTags(userID int, tags []string, into string) (domain.TagChange, error) {
+	into = strings.TrimSpace(into)
+	if into == "" {
+		return domain.TagChange{}, domain.ErrMissingTag
+	}
+	var sources []string
+	for _, tag := range tags {
+		if tag = strings.TrimSpace(tag); tag != "" && tag != into && !slices.Contains(sources, tag) {
+			sources = append(sources, tag)
+		}
+	}
+	if len(sources) == 0 {
+		return domain.TagChange{}, domain.ErrMissingTag
+	}
+
+	var change domain.TagChange
+	err := s.repos.WithinTransaction(func(repos ports.Repositories) error {
+		usage, err := tagUsage(repos, userID)
+		if err != nil {
+			return err
+		}
+		for _, tag := range sources {
+			if !usesTag(usage, tag) {
+				return fmt.Errorf("%w: %q", domain.ErrTagNotFound, tag)
+			}
+		}
+		change, err = replaceTags(repos, userID, sources, into)
+		return err
+	})
+	return change, err
+}
+
+func replaceTags(repos ports.Repositories, userID int, tags []string, replacement string) (domain.TagChange, error) {
+	transactions, err := repos.TransactionRepository().ReplaceTags(userID, tags, replacement)
+	if err != nil {
+		return domain.TagChange{}, err
+	}
+	templates, err := repos.TransactionTemplateRepository().ReplaceTags(userID, tags, replacement)
+	if err != nil {
+		return domain.TagChange{}, err
+	}
+	return domain.TagChange{Transactions: transactions, Templates: templates}, nil
+}
+
+// tagUsage counts every tag of the user's transactions, their split lines and
+// templates. A transaction counts once per tag, however many of its lines
+// carry it.
+func tagUsage(repos ports.Repositories, userID int) ([]domain.TagUsage, error) {
+	transactions, err := repos.TransactionRepository().FindAllTransactionsByUser(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch transactions: %w", err)
+	}
+	templates, err := repos.TransactionTemplateRepository().FindTransactionTemplatesByUser(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch transaction templates: %w", err)
+	}
+
+	usage := make(map[string]*domain.TagUsage)
+	use := func(tag string) *domain.TagUsage {
+		u, ok := usage[tag]
+		if !ok {
+			u = &domain.TagUsage{Name: tag}
+			usage[tag] = u
+		}
+		return u
+	}
+	for _, t := range transactions {
+		tags := slices.Clone(t.Tags)
+		for _, split := range t.Splits {
+			tags = append(tags, split.Tags...)
+		}
+		slices.Sort(tags)
+		for _, tag := range slices.Compact(tags) {
+			use(tag).TransactionCount++
+		}
+	}
+	for _, tt := range templates {
+		for _, tag := range tt.Tags {
+			use(tag).TemplateCount++
+		}
+	}
+
+	tags := make([]domain.TagUsage, 0, len(usage))
+	for _, u := range usage {
+		tags = append(tags, *u)
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		return tags[i].Name < tags[j].Name
+	})
+	return tags, nil
+}
+
+func usesTag(usage []domain.TagUsage, tag string) bool {
+	return slices.ContainsFunc(usage, func(u domain.TagUsage) bool { return u.Name == tag })
+}
@@ -0,0 +1,129 @@
+package services
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/fim-lab/expense-tracker/internal/core/domain"
+)
+
+// seedTags books three tagged transactions, one of them split, and a tagged
+// template on the split fixture.
+func seedTags(t *testing.T, f splitFixture) {
+	t.Helper()
+
+	receipt := f.receipt()
+	receipt.Tags = []string{"supermarkt"}
+	receipt.Splits[1].Tags = []string{"putzen", "urlaub"}
+	for _, tx := range []domain.Transaction{
+		receipt,
+		{ID: 11, Date: onDate(2026, 4, 4), WalletID: f.walletID, Description: "Fähre", AmountInCents: 9000, Type: domain.Expense, Tags: []string{"urlaub", "reise"}},
+		{ID: 12, Date: onDate(2026, 4, 5), WalletID: f.walletID, Description: "Kino", AmountInCents: 1200, Type: domain.Expense},
+	} {
+		if _, err := f.txSvc.CreateTransaction(f.userID, tx); err != nil {
+			t.Fatalf("could not seed %q: %v", tx.Description, err)
+		}
+	}
+	if _, err := f.repos.TransactionTemplateRepository().SaveTransactionTemplate(domain.TransactionTemplate{
+		ID: 20, UserID: f.userID, Day: 1, WalletID: f.walletID, Description: "Bahncard", AmountInCents: 2000, Type: domain.Expense,
+		Tags:       []string{"reise"},
+		Recurrence: domain.RecurrenceRule{Frequency: domain.Monthly, Interval: 1, StartDate: onDate(2026, 1, 1)},
+	}); err != nil {
+		t.Fatalf("could not seed the template: %v", err)
+	}
+}
+
+func searchTags(t *testing.T, f splitFixture, all bool, tags ...string) []string {
+	t.Helper()
+	page, err := f.txSvc.Search(f.userID, domain.TransactionSearchCriteria{Tags: tags, AllTags: all})
+	if err != nil {
+		t.Fatalf("search failed: %v", err)
+	}
+	var found []string
+	for _, tx := range page.Transactions {
+		found = append(found, tx.Description)
+	}
+	return found
+}
+
+func TestSearch_FiltersByTags(t *testing.T) {
+	f := newSplitFixture(t)
+	seedTags(t, f)
+
+	if got := searchTags(t, f, false, "urlaub"); !reflect.DeepEqual(got, []string{"Fähre", "Supermarkt"}) {
+		t.Errorf("expected the tag to match on transactions and split lines, got %v", got)
+	}
+	if got := searchTags(t, f, false, "putzen", "reise"); !reflect.DeepEqual(got, []string{"Fähre", "Supermarkt"}) {
+		t.Errorf("expected any of the tags to match, got %v", got)
+	}
+	if got := searchTags(t, f, true, "urlaub", "supermarkt"); !reflect.DeepEqual(got, []string{"Supermarkt"}) {
+		t.Errorf("expected only the transaction carrying all tags, got %v", got)
+	}
+	if got := searchTags(t, f, true, "urlaub", "kino"); len(got) != 0 {
+		t.Errorf("expected no transaction to carry both tags, got %v", got)
+	}
+
+	page, _ := f.txSvc.Search(f.userID, domain.TransactionSearchCriteria{Tags: []string{"reise"}})
+	if len(page.Transactions) != 1 || !reflect.DeepEqual(page.Transactions[0].Tags, []string{"urlaub", "reise"}) {
+		t.Errorf("expected the tags in the result, got %+v", page.Transactions)
+	}
+}
+
+func TestTagService_ListsRenamesAndMerges(t *testing.T) {
+	f := newSplitFixture(t)
+	seedTags(t, f)
+	tagSvc := NewTagService(f.repos)
+
+	tags, err := tagSvc.GetTags(f.userID)
+	if err != nil {
+		t.Fatalf("listing tags failed: %v", err)
+	}
+	want := []domain.TagUsage{
+		{Name: "putzen", TransactionCount: 1},
+		{Name: "reise", TransactionCount: 1, TemplateCount: 1},
+		{Name: "supermarkt", TransactionCount: 1},
+		{Name: "urlaub", TransactionCount: 2},
+	}
+	if !reflect.DeepEqual(tags, want) {
+		t.Errorf("expected %+v, got %+v", want, tags)
+	}
+
+	if _, err := tagSvc.RenameTag(f.userID, "reise", "urlaub"); !errors.Is(err, domain.ErrTagExists) {
+		t.Errorf("expected renaming onto a used tag to be refused, got %v", err)
+	}
+	if _, err := tagSvc.RenameTag(f.userID, "kino", "film"); !errors.Is(err, domain.ErrTagNotFound) {
+		t.Errorf("expected ErrTagNotFound for an unused tag, got %v", err)
+	}
+
+	change, err := tagSvc.RenameTag(f.userID, "reise", "reisen")
+	if err != nil {
+		t.Fatalf("renaming failed: %v", err)
+	}
+	if change != (domain.TagChange{Transactions: 1, Templates: 1}) {
+		t.Errorf("expected one transaction and one template to change, got %+v", change)
+	}
+	template, _ := f.repos.TransactionTemplateRepository().GetTransactionTemplateByID(20)
+	if !reflect.DeepEqual(template.Tags, []string{"reisen"}) {
+		t.Errorf("expected the template to be renamed, got %v", template.Tags)
+	}
+
+	change, err = tagSvc.MergeTags(f.userID, []string{"urlaub", "reisen"}, "ferien")
+	if err != nil {
+		t.Fatalf("merging failed: %v", err)
+	}
+	if change != (domain.TagChange{Transactions: 2, Templates: 1}) {
+		t.Errorf("expected two transactions and one template to change, got %+v", change)
+	}
+	ferry, _ := f.txSvc.GetTransactionByID(f.userID, 11)
+	if !reflect.DeepEqual(ferry.Tags, []string{"ferien"}) {
+		t.Errorf("expected the merged tags to collapse into one, got %v", ferry.Tags)
+	}
+	receipt, _ := f.txSvc.GetTransactionByID(f.userID, 10)
+	if !reflect.DeepEqual(receipt.Splits[1].Tags, []string{"putzen", "ferien"}) {
+		t.Errorf("expected the split line to be merged in place, got %v", receipt.Splits[1].Tags)
+	}
+	if got := searchTags(t, f, false, "urlaub"); len(got) != 0 {
+		t.Errorf("expected the old tag to be gone, got %v", got)
+	}
+}
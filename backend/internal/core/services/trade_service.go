@@ -13,6 +13,7 @@ import (
 
 type tradeService struct {
 	tradeRepo          ports.TradeRepository
+	userRepo           ports.UserRepository
 	depotService       ports.DepotService
 	transactionService ports.TransactionService
 	stockService       ports.StockService
@@ -20,12 +21,14 @@ type tradeService struct {
 
 func NewTradeService(
 	tradeRepo ports.TradeRepository,
+	userRepo ports.UserRepository,
 	depotService ports.DepotService,
 	transactionService ports.TransactionService,
 	stockService ports.StockService,
 ) ports.TradeService {
 	return &tradeService{
 		tradeRepo:          tradeRepo,
+		userRepo:           userRepo,
 		depotService:       depotService,
 		transactionService: transactionService,
 		stockService:       stockService,
@@ -46,6 +49,9 @@ func (s *tradeService) CreateTrade(userID int, t domain.Trade) (domain.Trade, er
 	if err != nil {
 		return domain.Trade{}, err
 	}
+	if !depot.Permission.Allows(domain.PermissionWrite) {
+		return domain.Trade{}, domain.ErrUnauthorized
+	}
 
 	t, err = normalizeTrade(t)
 	if err != nil {
@@ -96,7 +102,7 @@ func (s *tradeService) UpdateTrade(userID int, t domain.Trade) error {
 	}
 
 	depot, err := s.depotService.GetDepotByID(userID, existingTrade.DepotID)
-	if err != nil {
+	if err != nil || !depot.Permission.Allows(domain.PermissionWrite) {
 		return domain.ErrUnauthorized
 	}
 
@@ -144,7 +150,8 @@ func (s *tradeService) DeleteTrade(userID int, id int) error {
 		return err
 	}
 
-	if _, err := s.depotService.GetDepotByID(userID, existingTrade.DepotID); err != nil {
+	depot, err := s.depotService.GetDepotByID(userID, existingTrade.DepotID)
+	if err != nil || !depot.Permission.Allows(domain.PermissionWrite) {
 		return domain.ErrUnauthorized
 	}
 
@@ -175,6 +182,55 @@ func (s *tradeService) DeleteTrade(userID int, id int) error {
 	return nil
 }
 
+// SyncCashFlows books the wallet transaction of every trade at the trade's
+// cash flow again, as trades saved before fees and taxes were part of it were
+// booked without them. The transactions are changed through the transaction
+// service on behalf of the depot's owner. Trades booked right are left alone,
+// so running it again changes nothing. Reconciled transactions are locked and
+// only reported.
+func (s *tradeService) SyncCashFlows() (int, error) {
+	users, err := s.userRepo.FindAllUsers()
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch users: %w", err)
+	}
+	synced := 0
+	for _, u := range users {
+		depots, err := s.depotService.GetDepots(u.ID)
+		if err != nil {
+			return synced, fmt.Errorf("failed to fetch the depots of user %d: %w", u.ID, err)
+		}
+		for _, d := range depots {
+			if d.Permission != domain.PermissionOwner {
+				// Synced with the owner's depots.
+				continue
+			}
+			trades, err := s.tradeRepo.FindTradesByDepot(d.ID)
+			if err != nil {
+				return synced, fmt.Errorf("failed to fetch the trades of depot %d: %w", d.ID, err)
+			}
+			for _, trade := range trades {
+				if trade.WalletTransactionID == nil {
+					continue
+				}
+				t, err := s.transactionService.GetTransactionByID(u.ID, *trade.WalletTransactionID)
+				if err != nil || t.AmountInCents == trade.CashFlowInCents() {
+					continue
+				}
+				if t.ReconciliationID != nil {
+					log.Printf("wallet transaction %d of trade %d is reconciled, leaving it at %d instead of %d", t.ID, trade.ID, t.AmountInCents, trade.CashFlowInCents())
+					continue
+				}
+				t.AmountInCents = trade.CashFlowInCents()
+				if err := s.transactionService.UpdateTransaction(u.ID, t); err != nil {
+					return synced, fmt.Errorf("failed to sync wallet transaction %d of trade %d: %w", t.ID, trade.ID, err)
+				}
+				synced++
+			}
+		}
+	}
+	return synced, nil
+}
+
 func (s *tradeService) syncCashTransaction(userID int, depot domain.Depot, t *domain.Trade) error {
 	transactionType := domain.Expense
 	verb := "Buy"
@@ -224,9 +280,13 @@ func normalizeTrade(t domain.Trade) (domain.Trade, error) {
 	if math.IsNaN(t.Quantity) || math.IsInf(t.Quantity, 0) || t.Quantity <= quantityEpsilon {
 		return t, domain.ErrInvalidQuantity
 	}
-	if t.TotalInCents <= 0 {
+	if t.TotalInCents <= 0 || t.FeesInCents < 0 || t.TaxesInCents < 0 {
 		return t, domain.ErrInvalidAmount
 	}
+	// A sell has to leave something over to book on the wallet.
+	if t.CashFlowInCents() <= 0 {
+		return t, fmt.Errorf("%w: fees and taxes exceed the proceeds", domain.ErrInvalidAmount)
+	}
 	if t.Timestamp.IsZero() {
 		t.Timestamp = time.Now()
 	}
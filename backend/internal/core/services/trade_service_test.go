@@ -1,6 +1,7 @@
 package services
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/fim-lab/expense-tracker/internal/core/domain"
@@ -97,6 +98,78 @@ func TestTradeService_SellDrainsLotsFIFOAndCreatesIncome(t *testing.T) {
 	}
 }
 
+func TestTradeService_FeesAndTaxesMoveCashAndCostBasis(t *testing.T) {
+	f := newStockFixture(t)
+	buy := f.trade(domain.TradeTypeBuy, 1, 10, 10000)
+	buy.FeesInCents, buy.TaxesInCents = 500, 100
+	bought, err := f.tradeSvc.CreateTrade(f.userID, buy)
+	if err != nil {
+		t.Fatalf("buying failed: %v", err)
+	}
+	sell := f.trade(domain.TradeTypeSell, 2, 4, 6000)
+	sell.FeesInCents, sell.TaxesInCents = 300, 400
+	sold, err := f.tradeSvc.CreateTrade(f.userID, sell)
+	if err != nil {
+		t.Fatalf("selling failed: %v", err)
+	}
+
+	if transaction := f.linkedTransaction(t, bought.ID); transaction.Type != domain.Expense || transaction.AmountInCents != 10600 {
+		t.Errorf("expected the buy to cost 10000 + 500 fees + 100 taxes, got %s of %d", transaction.Type, transaction.AmountInCents)
+	}
+	if transaction := f.linkedTransaction(t, sold.ID); transaction.Type != domain.Income || transaction.AmountInCents != 5300 {
+		t.Errorf("expected the sell to bring 6000 - 300 fees - 400 taxes, got %s of %d", transaction.Type, transaction.AmountInCents)
+	}
+	if balance := f.walletBalance(t); balance != -10600+5300 {
+		t.Errorf("expected the wallet to match the broker, got %d", balance)
+	}
+
+	portfolio := f.mustGetPortfolio(t)
+	if len(portfolio.Positions) != 1 || portfolio.Positions[0].InvestedInCents != 6300 {
+		t.Errorf("expected the 6 shares left to cost 6/10 of 10500, got %+v", portfolio.Positions)
+	}
+	if portfolio.RealizedGainInCents != 5700-4200 {
+		t.Errorf("expected a gain of 5700 proceeds less 4200 cost, got %d", portfolio.RealizedGainInCents)
+	}
+
+	tooExpensive := f.trade(domain.TradeTypeSell, 3, 1, 1000)
+	tooExpensive.FeesInCents, tooExpensive.TaxesInCents = 600, 400
+	if _, err := f.tradeSvc.CreateTrade(f.userID, tooExpensive); !errors.Is(err, domain.ErrInvalidAmount) {
+		t.Errorf("expected a sell eaten up by fees and taxes to be refused, got %v", err)
+	}
+}
+
+func TestTradeService_SyncCashFlowsBooksFeesOfOlderTradesOnce(t *testing.T) {
+	f := newStockFixture(t)
+	if err := f.repos.UserRepository().SaveUser(domain.User{ID: f.userID, Username: "anna"}); err != nil {
+		t.Fatalf("could not seed the user: %v", err)
+	}
+	buy := f.trade(domain.TradeTypeBuy, 1, 10, 10000)
+	buy.FeesInCents, buy.TaxesInCents = 500, 100
+	bought, err := f.tradeSvc.CreateTrade(f.userID, buy)
+	if err != nil {
+		t.Fatalf("buying failed: %v", err)
+	}
+	// Booked the way trades were before fees and taxes counted.
+	old := f.linkedTransaction(t, bought.ID)
+	old.AmountInCents = 10000
+	if err := f.repos.TransactionRepository().UpdateTransaction(old); err != nil {
+		t.Fatalf("could not book the trade the old way: %v", err)
+	}
+
+	if synced, err := f.tradeSvc.SyncCashFlows(); err != nil || synced != 1 {
+		t.Fatalf("expected the buy to be synced, got %d (%v)", synced, err)
+	}
+	if transaction := f.linkedTransaction(t, bought.ID); transaction.AmountInCents != 10600 {
+		t.Errorf("expected the buy to cost 10600 with fees and taxes, got %d", transaction.AmountInCents)
+	}
+	if balance := f.walletBalance(t); balance != -10600 {
+		t.Errorf("expected the wallet to be debited by the fees and taxes too, got %d", balance)
+	}
+	if synced, err := f.tradeSvc.SyncCashFlows(); err != nil || synced != 0 {
+		t.Errorf("expected nothing left to sync, got %d (%v)", synced, err)
+	}
+}
+
 func TestTradeService_DeleteTradeRemovesCashTransactionAndRestoresBalance(t *testing.T) {
 	f := newStockFixture(t)
 	balanceBefore := f.walletBalance(t)
@@ -309,6 +382,7 @@ func TestTradeService_InvalidInputRejected(t *testing.T) {
 		"quantity zero": {func(tr *domain.Trade) { tr.Quantity = 0 }, domain.ErrInvalidQuantity},
 		"quantity dust": {func(tr *domain.Trade) { tr.Quantity = 1e-12 }, domain.ErrInvalidQuantity},
 		"total zero":    {func(tr *domain.Trade) { tr.TotalInCents = 0 }, domain.ErrInvalidAmount},
+		"negative fees": {func(tr *domain.Trade) { tr.FeesInCents = -100 }, domain.ErrInvalidAmount},
 		"missing wkn":   {func(tr *domain.Trade) { tr.WKN = "   " }, domain.ErrMissingWKN},
 		"unknown type":  {func(tr *domain.Trade) { tr.Type = "TRANSFER_IN" }, domain.ErrInvalidTradeType},
 		"unknown depot": {func(tr *domain.Trade) { tr.DepotID = 999 }, domain.ErrDepotNotFound},
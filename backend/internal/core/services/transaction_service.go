@@ -10,47 +10,69 @@ import (
 
 type transactionService struct {
 	transactionRepo ports.TransactionRepository
-	budgetRepo      ports.BudgetRepository
-	walletRepo      ports.WalletRepository
+	auth            ports.Authorizer
 }
 
-func NewTransactionService(transactionRepo ports.TransactionRepository, budgetRepo ports.BudgetRepository, walletRepo ports.WalletRepository) ports.TransactionService {
-	return &transactionService{transactionRepo: transactionRepo, budgetRepo: budgetRepo, walletRepo: walletRepo}
+func NewTransactionService(transactionRepo ports.TransactionRepository, auth ports.Authorizer) ports.TransactionService {
+	return &transactionService{transactionRepo: transactionRepo, auth: auth}
 }
 
+// CreateTransaction books on a wallet of the user or one they may write to.
+// The transaction belongs to the owner of the wallet either way.
 func (s *transactionService) CreateTransaction(userID int, t domain.Transaction) (int, error) {
-	t.UserID = userID
+	wallet, err := s.auth.Wallet(userID, t.WalletID, domain.PermissionWrite)
+	if err != nil {
+		return 0, domain.ErrWalletNotFound
+	}
+	t.UserID, t.CreatedBy = wallet.UserID, userID
+	t.ReconciliationID = nil
+	// Debts get their counterparty and repayments through the debt service.
+	t.CounterpartyID, t.RepaymentOfID = nil, nil
 
 	if t.IsDebt != nil && *t.IsDebt {
 		t.BudgetID = nil
-	}
-
-	if t.BudgetID != nil {
-		budget, err := s.budgetRepo.GetBudgetByID(*t.BudgetID)
-		if err != nil || budget.UserID != userID {
-			return 0, domain.ErrBudgetNotFound
-		}
+		t.Splits = nil
 	}
 
 	if t.AmountInCents <= 0 {
 		return 0, domain.ErrInvalidAmount
 	}
 
+	if err := s.checkBudgets(userID, t); err != nil {
+		return 0, err
+	}
+
 	return s.transactionRepo.SaveTransaction(t)
 }
 
-func (s *transactionService) CreateTransfer(userID, fromWalletID, toWalletID, amount int) error {
+// checkBudgets makes sure the split lines add up and the transaction is only
+// booked on budgets the user may write to. These have to belong to the owner
+// of the transaction, as bookings on a wallet go to its owner's budgets.
+func (s *transactionService) checkBudgets(userID int, t domain.Transaction) error {
+	if err := t.ValidateSplits(); err != nil {
+		return err
+	}
+	for budgetID := range t.BudgetAmounts() {
+		budget, err := s.auth.Budget(userID, budgetID, domain.PermissionWrite)
+		if err != nil || budget.UserID != t.UserID {
+			return domain.ErrBudgetNotFound
+		}
+	}
+	return nil
+}
+
+func (s *transactionService) CreateTransfer(userID, fromWalletID, toWalletID, amount int, date time.Time) error {
 	if fromWalletID == toWalletID {
 		return domain.ErrSameWalletTransfer
 	}
 
-	fromWallet, err := s.walletRepo.GetWalletByID(fromWalletID)
-	if err != nil || fromWallet.UserID != userID {
+	fromWallet, err := s.auth.Wallet(userID, fromWalletID, domain.PermissionWrite)
+	if err != nil {
 		return domain.ErrWalletNotFound
 	}
 
-	toWallet, err := s.walletRepo.GetWalletByID(toWalletID)
-	if err != nil || toWallet.UserID != userID {
+	toWallet, err := s.auth.Wallet(userID, toWalletID, domain.PermissionWrite)
+	if err != nil {
 		return domain.ErrWalletNotFound
 	}
 
@@ -59,8 +81,9 @@ func (s *transactionService) CreateTransfer(userID, fromWalletID, toWalletID, am
 	}
 
 	fromTransaction := domain.Transaction{
-		UserID:        userID,
-		Date:          time.Now(),
+		UserID:        fromWallet.UserID,
+		CreatedBy:     userID,
+		Date:          date,
 		WalletID:      fromWalletID,
 		Description:   fmt.Sprintf("Transfer to %s", toWallet.Name),
 		AmountInCents: amount,
@@ -68,8 +91,9 @@ func (s *transactionService) CreateTransfer(userID, fromWalletID, toWalletID, am
 	}
 
 	toTransaction := domain.Transaction{
-		UserID:        userID,
-		Date:          time.Now(),
+		UserID:        toWallet.UserID,
+		CreatedBy:     userID,
+		Date:          date,
 		WalletID:      toWalletID,
 		Description:   fmt.Sprintf("Transfer from %s", fromWallet.Name),
 		AmountInCents: amount,
@@ -93,17 +117,18 @@ func (s *transactionService) Search(userID int, criteria domain.TransactionSearc
 		criteria.PageSize = 100
 	}
 
-	transactions, err := s.transactionRepo.SearchTransactions(userID, criteria)
+	ownerID := s.searchedOwner(userID, criteria)
+	transactions, err := s.transactionRepo.SearchTransactions(ownerID, criteria)
 	if err != nil {
 		return nil, err
 	}
 
-	total, err := s.transactionRepo.CountSearchedTransactions(userID, criteria)
+	total, err := s.transactionRepo.CountSearchedTransactions(ownerID, criteria)
 	if err != nil {
 		return nil, err
 	}
 
-	sum, err := s.transactionRepo.SumSearchedTransactionAmounts(userID, criteria)
+	sum, err := s.transactionRepo.SumSearchedTransactionAmounts(ownerID, criteria)
 	if err != nil {
 		return nil, err
 	}
@@ -117,16 +142,41 @@ func (s *transactionService) Search(userID int, criteria domain.TransactionSearc
 	}, nil
 }
 
+// searchedOwner is whose transactions a search looks through: the user's,
+// unless it is narrowed to a wallet or budget someone shared with them.
+func (s *transactionService) searchedOwner(userID int, criteria domain.TransactionSearchCriteria) int {
+	if criteria.WalletID != nil {
+		if wallet, err := s.auth.Wallet(userID, *criteria.WalletID, domain.PermissionRead); err == nil {
+			return wallet.UserID
+		}
+	}
+	if criteria.BudgetID != nil {
+		if budget, err := s.auth.Budget(userID, *criteria.BudgetID, domain.PermissionRead); err == nil {
+			return budget.UserID
+		}
+	}
+	return userID
+}
+
 func (s *transactionService) GetTransactionCount(userID int) (int, error) {
 	return s.transactionRepo.GetTransactionCount(userID)
 }
 
 func (s *transactionService) UpdateTransaction(userID int, t domain.Transaction) error {
-	existing, err := s.transactionRepo.GetTransactionByID(t.ID)
-	if err != nil || existing.UserID != userID {
+	existing, err := s.auth.Transaction(userID, t.ID, domain.PermissionWrite)
+	if err != nil {
 		return domain.ErrUnauthorized
 	}
-	t.UserID = userID
+	if existing.ReconciliationID != nil {
+		return domain.ErrTransactionReconciled
+	}
+	if t.WalletID != existing.WalletID {
+		wallet, err := s.auth.Wallet(userID, t.WalletID, domain.PermissionWrite)
+		if err != nil || wallet.UserID != existing.UserID {
+			return domain.ErrWalletNotFound
+		}
+	}
+	t.UserID, t.CreatedBy = existing.UserID, existing.CreatedBy
 	if t.IsDebt == nil {
 		t.IsDebt = existing.IsDebt
 	}
@@ -136,27 +186,45 @@ func (s *transactionService) UpdateTransaction(userID int, t domain.Transaction)
 	if t.Tags == nil {
 		t.Tags = existing.Tags
 	}
+	if t.Splits == nil && t.BudgetID == nil {
+		t.Splits = existing.Splits
+	}
 	if t.IsDebt != nil && *t.IsDebt {
 		t.BudgetID = nil
+		t.Splits = nil
+	}
+	if err := s.checkBudgets(userID, t); err != nil {
+		return err
 	}
 	return s.transactionRepo.UpdateTransaction(t)
 }
 
 func (s *transactionService) DeleteTransaction(userID int, id int) error {
-	existing, err := s.transactionRepo.GetTransactionByID(id)
-	if err != nil || existing.UserID != userID {
+	existing, err := s.auth.Transaction(userID, id, domain.PermissionWrite)
+	if err != nil {
 		return domain.ErrUnauthorized
 	}
+	if existing.ReconciliationID != nil {
+		return domain.ErrTransactionReconciled
+	}
 	return s.transactionRepo.DeleteTransaction(id)
 }
 
-func (s *transactionService) GetTransactionByID(userID int, id int) (domain.Transaction, error) {
-	transaction, err := s.transactionRepo.GetTransactionByID(id)
-	if err != nil {
-		return domain.Transaction{}, err
+// SettleTransactions leaves the transactions the user may not change alone,
+// like those that are not pending.
+func (s *transactionService) SettleTransactions(userID int, ids []int) (int, error) {
+	var writable []int
+	for _, id := range ids {
+		if _, err := s.auth.Transaction(userID, id, domain.PermissionWrite); err == nil {
+			writable = append(writable, id)
+		}
 	}
-	if transaction.UserID != userID {
-		return domain.Transaction{}, domain.ErrUnauthorized
+	if len(writable) == 0 {
+		return 0, nil
 	}
-	return transaction, nil
+	return s.transactionRepo.SettleTransactions(writable)
+}
+
+func (s *transactionService) GetTransactionByID(userID int, id int) (domain.Transaction, error) {
+	return s.auth.Transaction(userID, id, domain.PermissionRead)
 }
@@ -12,7 +12,7 @@ import (
 
 func TestTransactionOwnership(t *testing.T) {
 	repos := memory.NewSeededRepositories()
-	svc := NewTransactionService(repos.TransactionRepository(), repos.BudgetRepository(), repos.WalletRepository())
+	svc := NewTransactionService(repos.TransactionRepository(), NewAuthorizer(repos))
 
 	tx := domain.Transaction{
 		UserID:        2,
@@ -49,7 +49,7 @@ func TestTransactionOwnership(t *testing.T) {
 
 func TestGetTransactions_PaginationAndMapping(t *testing.T) {
 	repos := memory.NewSeededRepositories()
-	svc := NewTransactionService(repos.TransactionRepository(), repos.BudgetRepository(), repos.WalletRepository())
+	svc := NewTransactionService(repos.TransactionRepository(), NewAuthorizer(repos))
 
 	testUsername := "testuser"
 	repos.UserRepository().SaveUser(domain.User{Username: testUsername, PasswordHash: "#"})
@@ -0,0 +1,159 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/fim-lab/expense-tracker/adapters/repository/memory"
+	"github.com/fim-lab/expense-tracker/internal/core/domain"
+	"github.com/fim-lab/expense-tracker/internal/core/ports"
+)
+
+type splitFixture struct {
+	repos     ports.Repositories
+	txSvc     ports.TransactionService
+	userID    int
+	walletID  int
+	groceries int
+	household int
+}
+
+func newSplitFixture(t *testing.T) splitFixture {
+	t.Helper()
+
+	repos := memory.NewCleanRepositories()
+	f := splitFixture{repos: repos, userID: 1, walletID: 1, groceries: 2, household: 3}
+
+	if err := repos.WalletRepository().SaveWallet(domain.Wallet{ID: f.walletID, UserID: f.userID, Name: "Girokonto"}); err != nil {
+		t.Fatalf("could not seed the wallet: %v", err)
+	}
+	for id, name := range map[int]string{f.groceries: "Lebensmittel", f.household: "Haushalt"} {
+		if err := repos.BudgetRepository().SaveBudget(domain.Budget{ID: id, UserID: f.userID, Name: name, LimitCents: 50000}); err != nil {
+			t.Fatalf("could not seed the budget: %v", err)
+		}
+	}
+	if err := repos.BudgetRepository().SaveBudget(domain.Budget{ID: 4, UserID: 99, Name: "Fremd"}); err != nil {
+		t.Fatalf("could not seed the foreign budget: %v", err)
+	}
+	f.txSvc = NewTransactionService(repos.TransactionRepository(), NewAuthorizer(repos))
+	return f
+}
+
+func (f splitFixture) receipt() domain.Transaction {
+	return domain.Transaction{
+		ID:            10,
+		Date:          onDate(2026, 4, 3),
+		WalletID:      f.walletID,
+		Description:   "Supermarkt",
+		AmountInCents: 4500,
+		Type:          domain.Expense,
+		Splits: []domain.Split{
+			{BudgetID: f.groceries, AmountInCents: 3000},
+			{BudgetID: f.household, AmountInCents: 1500, Note: "Spülmittel"},
+		},
+	}
+}
+
+func (f splitFixture) budgetBalance(t *testing.T, id int) int {
+	t.Helper()
+	budget, err := f.repos.BudgetRepository().GetBudgetByID(id)
+	if err != nil {
+		t.Fatalf("could not read budget %d: %v", id, err)
+	}
+	return budget.BalanceCents
+}
+
+func TestSplitTransaction_BooksEachLineOnItsBudget(t *testing.T) {
+	f := newSplitFixture(t)
+
+	if _, err := f.txSvc.CreateTransaction(f.userID, f.receipt()); err != nil {
+		t.Fatalf("creating the split transaction failed: %v", err)
+	}
+	if got := f.budgetBalance(t, f.groceries); got != -3000 {
+		t.Errorf("expected groceries at -3000, got %d", got)
+	}
+	if got := f.budgetBalance(t, f.household); got != -1500 {
+		t.Errorf("expected household at -1500, got %d", got)
+	}
+
+	budgetID := f.household
+	page, err := f.txSvc.Search(f.userID, domain.TransactionSearchCriteria{BudgetID: &budgetID})
+	if err != nil {
+		t.Fatalf("search failed: %v", err)
+	}
+	if page.Total != 1 || len(page.Transactions[0].Splits) != 2 {
+		t.Fatalf("expected the receipt with both lines, got %+v", page)
+	}
+	if page.SumInCents != -1500 {
+		t.Errorf("expected the sum to count only the household line, got %d", page.SumInCents)
+	}
+
+	if count, _ := f.repos.TransactionRepository().CountTransactionsByBudgetID(f.household); count != 1 {
+		t.Errorf("expected the household budget to be in use, got %d transactions", count)
+	}
+}
+
+func TestSplitTransaction_UpdateAndDeleteRevertTheLines(t *testing.T) {
+	f := newSplitFixture(t)
+	if _, err := f.txSvc.CreateTransaction(f.userID, f.receipt()); err != nil {
+		t.Fatalf("creating the split transaction failed: %v", err)
+	}
+
+	updated := f.receipt()
+	updated.Splits = []domain.Split{
+		{BudgetID: f.groceries, AmountInCents: 4000},
+		{BudgetID: f.household, AmountInCents: 500},
+	}
+	if err := f.txSvc.UpdateTransaction(f.userID, updated); err != nil {
+		t.Fatalf("updating the split failed: %v", err)
+	}
+	if f.budgetBalance(t, f.groceries) != -4000 || f.budgetBalance(t, f.household) != -500 {
+		t.Errorf("expected the balances to follow the new lines, got %d and %d", f.budgetBalance(t, f.groceries), f.budgetBalance(t, f.household))
+	}
+
+	unsplit := f.receipt()
+	unsplit.Splits = nil
+	if err := f.txSvc.UpdateTransaction(f.userID, unsplit); err != nil {
+		t.Fatalf("updating without splits failed: %v", err)
+	}
+	stored, _ := f.txSvc.GetTransactionByID(f.userID, 10)
+	if len(stored.Splits) != 2 {
+		t.Errorf("expected an update without budget or splits to keep the lines, got %+v", stored.Splits)
+	}
+
+	if err := f.txSvc.DeleteTransaction(f.userID, 10); err != nil {
+		t.Fatalf("deleting failed: %v", err)
+	}
+	if f.budgetBalance(t, f.groceries) != 0 || f.budgetBalance(t, f.household) != 0 {
+		t.Errorf("expected the balances to be back at zero, got %d and %d", f.budgetBalance(t, f.groceries), f.budgetBalance(t, f.household))
+	}
+	if wallet, _ := f.repos.WalletRepository().GetWalletByID(f.walletID); wallet.BalanceCents != 0 {
+		t.Errorf("expected the wallet to be back at zero, got %d", wallet.BalanceCents)
+	}
+}
+
+func TestSplitTransaction_RejectsInvalidLines(t *testing.T) {
+	f := newSplitFixture(t)
+
+	mismatch := f.receipt()
+	mismatch.Splits[1].AmountInCents = 1000
+	if _, err := f.txSvc.CreateTransaction(f.userID, mismatch); !errors.Is(err, domain.ErrInvalidSplit) {
+		t.Errorf("expected ErrInvalidSplit for lines that do not add up, got %v", err)
+	}
+
+	withBudget := f.receipt()
+	withBudget.BudgetID = &f.groceries
+	if _, err := f.txSvc.CreateTransaction(f.userID, withBudget); !errors.Is(err, domain.ErrInvalidSplit) {
+		t.Errorf("expected ErrInvalidSplit for a split with its own budget, got %v", err)
+	}
+
+	foreign := f.receipt()
+	foreign.Splits[1].BudgetID = 4
+	if _, err := f.txSvc.CreateTransaction(f.userID, foreign); !errors.Is(err, domain.ErrBudgetNotFound) {
+		t.Errorf("expected ErrBudgetNotFound for another user's budget, got %v", err)
+	}
+
+	if count, _ := f.txSvc.GetTransactionCount(f.userID); count != 0 {
+		t.Errorf("expected nothing to be saved, got %d transactions", count)
+	}
+}
@@ -0,0 +1,156 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/fim-lab/expense-tracker/adapters/repository/memory"
+	"github.com/fim-lab/expense-tracker/internal/core/domain"
+)
+
+func occurrenceDates(tt domain.TransactionTemplate, from, until time.Time) []string {
+	var dates []string
+	for _, occurrence := range tt.Occurrences(from, until) {
+		dates = append(dates, occurrence.Format("2006-01-02"))
+	}
+	return dates
+}
+
+func expectDates(t *testing.T, want, got []string) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+	}
+}
+
+func TestTransactionTemplateRecurrence(t *testing.T) {
+	t.Run("Every Two Weeks", func(t *testing.T) {
+		tt := domain.TransactionTemplate{Recurrence: domain.RecurrenceRule{
+			Frequency: domain.Weekly, Interval: 2, StartDate: onDate(2026, 1, 2),
+		}}
+		expectDates(t,
+			[]string{"2026-01-02", "2026-01-16", "2026-01-30", "2026-02-13"},
+			occurrenceDates(tt, onDate(2026, 1, 1), onDate(2026, 2, 20)))
+	})
+
+	t.Run("Quarterly On The Last Day", func(t *testing.T) {
+		tt := domain.TransactionTemplate{Day: 31, Recurrence: domain.RecurrenceRule{
+			Frequency: domain.Monthly, Interval: 3, StartDate: onDate(2025, 11, 15),
+		}}
+		expectDates(t,
+			[]string{"2025-11-30", "2026-02-28", "2026-05-31", "2026-08-31"},
+			occurrenceDates(tt, onDate(2025, 1, 1), onDate(2026, 9, 30)))
+	})
+
+	t.Run("Yearly In The Start Month", func(t *testing.T) {
+		tt := domain.TransactionTemplate{Day: 29, Recurrence: domain.RecurrenceRule{
+			Frequency: domain.Yearly, Interval: 1, StartDate: onDate(2024, 2, 1),
+		}}
+		expectDates(t,
+			[]string{"2024-02-29", "2025-02-28", "2026-02-28", "2027-02-28", "2028-02-29"},
+			occurrenceDates(tt, onDate(2024, 1, 1), onDate(2028, 12, 31)))
+	})
+
+	t.Run("Day Before The Start Date Is Skipped", func(t *testing.T) {
+		tt := domain.TransactionTemplate{Day: 1, Recurrence: domain.RecurrenceRule{
+			Frequency: domain.Monthly, Interval: 1, StartDate: onDate(2026, 3, 10),
+		}}
+		expectDates(t,
+			[]string{"2026-04-01", "2026-05-01"},
+			occurrenceDates(tt, onDate(2026, 1, 1), onDate(2026, 5, 15)))
+	})
+
+	t.Run("End Date", func(t *testing.T) {
+		end := onDate(2026, 3, 15)
+		tt := domain.TransactionTemplate{Day: 15, Recurrence: domain.RecurrenceRule{
+			Frequency: domain.Monthly, Interval: 1, StartDate: onDate(2026, 1, 1), EndDate: &end,
+		}}
+		expectDates(t,
+			[]string{"2026-01-15", "2026-02-15", "2026-03-15"},
+			occurrenceDates(tt, onDate(2026, 1, 1), onDate(2026, 12, 31)))
+	})
+
+	t.Run("Occurrence Count", func(t *testing.T) {
+		count := 3
+		tt := domain.TransactionTemplate{Day: 1, Recurrence: domain.RecurrenceRule{
+			Frequency: domain.Monthly, Interval: 1, StartDate: onDate(2026, 1, 1), Count: &count,
+		}}
+		expectDates(t,
+			[]string{"2026-02-01", "2026-03-01"},
+			occurrenceDates(tt, onDate(2026, 2, 1), onDate(2026, 12, 31)))
+	})
+
+	t.Run("Invalid Rules", func(t *testing.T) {
+		end := onDate(2025, 12, 31)
+		zero := 0
+		rules := []domain.RecurrenceRule{
+			{Frequency: "DAILY", Interval: 1, StartDate: onDate(2026, 1, 1)},
+			{Frequency: domain.Monthly, Interval: 0, StartDate: onDate(2026, 1, 1)},
+			{Frequency: domain.Monthly, Interval: 1, StartDate: onDate(2026, 1, 1), EndDate: &end},
+			{Frequency: domain.Monthly, Interval: 1, StartDate: onDate(2026, 1, 1), Count: &zero},
+		}
+		for _, rule := range rules {
+			if err := rule.Validate(); err == nil {
+				t.Errorf("expected %+v to be rejected", rule)
+			}
+		}
+	})
+}
+
+func TestTransactionTemplateService_GetUpcomingTransactions(t *testing.T) {
+	repos := memory.NewCleanRepositories()
+	svc := NewTransactionTemplateService(
+		repos.TransactionTemplateRepository(),
+		NewAuthorizer(repos),
+	)
+	userID := 1
+	repos.WalletRepository().SaveWallet(domain.Wallet{UserID: userID, Name: "Girokonto"})
+	wallets, _ := repos.WalletRepository().FindWalletsByUser(userID)
+	walletID := wallets[0].ID
+
+	today := domain.DateOf(time.Now())
+	templates := []domain.TransactionTemplate{
+		{
+			UserID: userID, WalletID: walletID, Description: "Gehalt", AmountInCents: 120000, Type: domain.Income,
+			Recurrence: domain.RecurrenceRule{Frequency: domain.Weekly, Interval: 2, StartDate: today},
+		},
+		{
+			UserID: userID, WalletID: walletID, Description: "Kfz-Versicherung", AmountInCents: 30000, Type: domain.Expense,
+			Day:        today.Day(),
+			Recurrence: domain.RecurrenceRule{Frequency: domain.Yearly, Interval: 1, StartDate: today},
+		},
+	}
+	for _, tt := range templates {
+		if err := svc.CreateTransactionTemplate(userID, tt); err != nil {
+			t.Fatalf("could not create template %q: %v", tt.Description, err)
+		}
+	}
+
+	upcoming, err := svc.GetUpcomingTransactions(userID, today.AddDate(0, 0, 29))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(upcoming) != 4 {
+		t.Fatalf("expected three paychecks and one insurance fee, got %+v", upcoming)
+	}
+	for i := 1; i < len(upcoming); i++ {
+		if upcoming[i].Date.Before(upcoming[i-1].Date) {
+			t.Errorf("expected upcoming transactions sorted by date, got %+v", upcoming)
+		}
+	}
+
+	none, err := svc.GetUpcomingTransactions(2, today.AddDate(1, 0, 0))
+	if err != nil || none == nil || len(none) != 0 {
+		t.Errorf("expected an empty list for a user without templates, got %v (err %v)", none, err)
+	}
+
+	if _, err := svc.GetUpcomingTransactions(userID, today.AddDate(domain.UpcomingHorizonYears, 0, 1)); !errors.Is(err, domain.ErrInvalidDateRange) {
+		t.Errorf("expected projecting past the horizon to be refused, got %v", err)
+	}
+}
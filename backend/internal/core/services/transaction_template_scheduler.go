@@ -0,0 +1,120 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/fim-lab/expense-tracker/internal/core/domain"
+	"github.com/fim-lab/expense-tracker/internal/core/ports"
+)
+
+type transactionTemplateScheduler struct {
+	transactionTemplateRepo ports.TransactionTemplateRepository
+	transactionService      ports.TransactionService
+}
+
+func NewTransactionTemplateScheduler(
+	transactionTemplateRepo ports.TransactionTemplateRepository,
+	transactionService ports.TransactionService,
+) ports.TransactionTemplateScheduler {
+	return &transactionTemplateScheduler{
+		transactionTemplateRepo: transactionTemplateRepo,
+		transactionService:      transactionService,
+	}
+}
+
+// BookDueTransactions creates a transaction for every template occurrence that
+// is due up to now, including the ones missed while the server was down, and
+// returns how many were booked.
+//
+// Each occurrence is claimed on the template before its transaction is created,
+// so a restart or a second instance never books it twice. If creating the
+// transaction fails, the claim is handed back and the next run retries it.
+//
+// Templates stored before recurrence rules existed have neither a start date
+// nor a booked occurrence. They start on the day the scheduler first sees them
+// instead of catching up on every month since they were created.
+func (s *transactionTemplateScheduler) BookDueTransactions(now time.Time) (int, error) {
+	templates, err := s.transactionTemplateRepo.FindAllTransactionTemplates()
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch transaction templates: %w", err)
+	}
+
+	booked := 0
+	for _, tt := range templates {
+		if tt.Recurrence.StartDate.IsZero() && tt.LastOccurrence == nil {
+			started, err := s.startLegacyTemplate(tt.ID, now)
+			if err != nil {
+				log.Printf("could not start legacy template %d: %v", tt.ID, err)
+				continue
+			}
+			if started == nil {
+				// Another run started it and books its occurrences.
+				continue
+			}
+			tt.LastOccurrence = started
+		}
+
+		last := tt.LastOccurrence
+		for _, occurrence := range tt.DueOccurrences(now) {
+			claimed, err := s.transactionTemplateRepo.AdvanceLastOccurrence(tt.ID, last, &occurrence)
+			if err != nil {
+				log.Printf("could not claim occurrence %s of template %d: %v", occurrence.Format("2006-01-02"), tt.ID, err)
+				break
+			}
+			if !claimed {
+				// Someone else booked it in the meantime and continues from there.
+				break
+			}
+
+			if err := s.book(tt, occurrence); err != nil {
+				log.Printf("could not book occurrence %s of template %d: %v", occurrence.Format("2006-01-02"), tt.ID, err)
+				if _, err := s.transactionTemplateRepo.AdvanceLastOccurrence(tt.ID, &occurrence, last); err != nil {
+					log.Printf("occurrence %s of template %d stays claimed without a transaction: %v", occurrence.Format("2006-01-02"), tt.ID, err)
+				}
+				break
+			}
+
+			booked++
+			last = &occurrence
+		}
+	}
+
+	return booked, nil
+}
+
+// startLegacyTemplate marks everything before the day of now as booked, so the
+// template's first occurrence is the next one from today on. It returns the new
+// last occurrence, or nil if another run has started the template already.
+func (s *transactionTemplateScheduler) startLegacyTemplate(id int, now time.Time) (*time.Time, error) {
+	yesterday := domain.DateOf(now).AddDate(0, 0, -1)
+	claimed, err := s.transactionTemplateRepo.AdvanceLastOccurrence(id, nil, &yesterday)
+	if err != nil || !claimed {
+		return nil, err
+	}
+	return &yesterday, nil
+}
+
+// book creates the transaction of one occurrence, or both sides of the
+// transfer if the template moves money to another wallet.
+func (s *transactionTemplateScheduler) book(tt domain.TransactionTemplate, date time.Time) error {
+	if tt.ToWalletID != nil {
+		return s.transactionService.CreateTransfer(tt.UserID, tt.WalletID, *tt.ToWalletID, tt.AmountInCents, date)
+	}
+	_, err := s.transactionService.CreateTransaction(tt.UserID, transactionFromTemplate(tt, date))
+	return err
+}
+
+func transactionFromTemplate(tt domain.TransactionTemplate, date time.Time) domain.Transaction {
+	return domain.Transaction{
+		UserID:        tt.UserID,
+		Date:          date,
+		BudgetID:      tt.BudgetID,
+		WalletID:      tt.WalletID,
+		Description:   tt.Description,
+		AmountInCents: tt.AmountInCents,
+		Type:          tt.Type,
+		Tags:          tt.Tags,
+	}
+}
@@ -0,0 +1,205 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fim-lab/expense-tracker/internal/core/domain"
+	"github.com/fim-lab/expense-tracker/internal/core/ports"
+)
+
+type schedulerFixture struct {
+	walletFixture
+	scheduler ports.TransactionTemplateScheduler
+}
+
+func newSchedulerFixture(t *testing.T) schedulerFixture {
+	t.Helper()
+
+	f := newWalletFixture(t)
+	return schedulerFixture{
+		walletFixture: f,
+		scheduler:     NewTransactionTemplateScheduler(f.repos.TransactionTemplateRepository(), f.txSvc),
+	}
+}
+
+func (f schedulerFixture) mustSaveTemplate(t *testing.T, day int, createdAt time.Time) int {
+	t.Helper()
+	_, err := f.repos.TransactionTemplateRepository().SaveTransactionTemplate(domain.TransactionTemplate{
+		UserID:        f.userID,
+		Day:           day,
+		WalletID:      f.walletID,
+		Description:   "Miete",
+		AmountInCents: 85000,
+		Type:          domain.Expense,
+		Recurrence:    domain.RecurrenceRule{StartDate: createdAt},
+		CreatedAt:     createdAt,
+	})
+	if err != nil {
+		t.Fatalf("could not save the template: %v", err)
+	}
+	templates, err := f.repos.TransactionTemplateRepository().FindTransactionTemplatesByUser(f.userID)
+	if err != nil || len(templates) == 0 {
+		t.Fatalf("could not read the template back: %v", err)
+	}
+	return templates[len(templates)-1].ID
+}
+
+func (f schedulerFixture) mustBook(t *testing.T, now time.Time) int {
+	t.Helper()
+	booked, err := f.scheduler.BookDueTransactions(now)
+	if err != nil {
+		t.Fatalf("booking due transactions failed: %v", err)
+	}
+	return booked
+}
+
+func (f schedulerFixture) bookedDates(t *testing.T) []string {
+	t.Helper()
+	txs, err := f.txSvc.GetTransactions(f.userID, 0, 0)
+	if err != nil {
+		t.Fatalf("could not read the transactions: %v", err)
+	}
+	dates := make([]string, 0, len(txs))
+	for i := len(txs) - 1; i >= 0; i-- {
+		dates = append(dates, txs[i].Date.Format("2006-01-02"))
+	}
+	return dates
+}
+
+func onDate(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestTransactionTemplateScheduler_BooksOccurrenceOnItsDay(t *testing.T) {
+	f := newSchedulerFixture(t)
+	f.mustSaveTemplate(t, 15, onDate(2026, 3, 2))
+
+	if booked := f.mustBook(t, onDate(2026, 3, 14)); booked != 0 {
+		t.Errorf("expected nothing to be due before the 15th, got %d", booked)
+	}
+	if booked := f.mustBook(t, onDate(2026, 3, 15).Add(8*time.Hour)); booked != 1 {
+		t.Fatalf("expected one transaction on the 15th, got %d", booked)
+	}
+
+	txs, err := f.txSvc.GetTransactions(f.userID, 0, 0)
+	if err != nil || len(txs) != 1 {
+		t.Fatalf("expected one transaction, got %v (err %v)", txs, err)
+	}
+	if txs[0].Description != "Miete" || txs[0].AmountInCents != 85000 || txs[0].Type != domain.Expense {
+		t.Errorf("expected the transaction to be copied from the template, got %+v", txs[0])
+	}
+
+	wallet, _ := f.repos.WalletRepository().GetWalletByID(f.walletID)
+	if wallet.BalanceCents != -85000 {
+		t.Errorf("expected the wallet to be debited by 85000, got %d", wallet.BalanceCents)
+	}
+}
+
+func TestTransactionTemplateScheduler_NeverBooksTwice(t *testing.T) {
+	f := newSchedulerFixture(t)
+	f.mustSaveTemplate(t, 1, onDate(2026, 1, 1))
+
+	f.mustBook(t, onDate(2026, 1, 10))
+	if booked := f.mustBook(t, onDate(2026, 1, 10)); booked != 0 {
+		t.Errorf("expected a second run to book nothing, got %d", booked)
+	}
+
+	// A second scheduler stands in for another instance sharing the repository.
+	other := NewTransactionTemplateScheduler(f.repos.TransactionTemplateRepository(), f.txSvc)
+	if booked, err := other.BookDueTransactions(onDate(2026, 1, 10)); err != nil || booked != 0 {
+		t.Errorf("expected another instance to book nothing, got %d (err %v)", booked, err)
+	}
+
+	if dates := f.bookedDates(t); len(dates) != 1 {
+		t.Errorf("expected exactly one transaction, got %v", dates)
+	}
+}
+
+func TestTransactionTemplateScheduler_CatchesUpMissedMonths(t *testing.T) {
+	f := newSchedulerFixture(t)
+	id := f.mustSaveTemplate(t, 31, onDate(2025, 12, 1))
+
+	if booked := f.mustBook(t, onDate(2026, 4, 5)); booked != 4 {
+		t.Fatalf("expected December to March to be booked, got %d", booked)
+	}
+
+	want := []string{"2025-12-31", "2026-01-31", "2026-02-28", "2026-03-31"}
+	got := f.bookedDates(t)
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("expected %v, got %v", want, got)
+			break
+		}
+	}
+
+	tt, _ := f.repos.TransactionTemplateRepository().GetTransactionTemplateByID(id)
+	if tt.LastOccurrence == nil || !tt.LastOccurrence.Equal(onDate(2026, 3, 31)) {
+		t.Errorf("expected the last occurrence to be remembered as 2026-03-31, got %v", tt.LastOccurrence)
+	}
+}
+
+func TestTransactionTemplateScheduler_FailedBookingIsRetried(t *testing.T) {
+	f := newSchedulerFixture(t)
+	missingBudget := 999
+	_, err := f.repos.TransactionTemplateRepository().SaveTransactionTemplate(domain.TransactionTemplate{
+		UserID:        f.userID,
+		Day:           1,
+		BudgetID:      &missingBudget,
+		WalletID:      f.walletID,
+		Description:   "Abo",
+		AmountInCents: 999,
+		Type:          domain.Expense,
+		Recurrence:    domain.RecurrenceRule{StartDate: onDate(2026, 1, 1)},
+		CreatedAt:     onDate(2026, 1, 1),
+	})
+	if err != nil {
+		t.Fatalf("could not save the template: %v", err)
+	}
+
+	if booked := f.mustBook(t, onDate(2026, 1, 2)); booked != 0 {
+		t.Errorf("expected nothing to be booked for a template with a missing budget, got %d", booked)
+	}
+
+	templates, _ := f.repos.TransactionTemplateRepository().FindTransactionTemplatesByUser(f.userID)
+	if templates[0].LastOccurrence != nil {
+		t.Errorf("expected the failed occurrence to be released again, got %v", templates[0].LastOccurrence)
+	}
+}
+
+func TestTransactionTemplateScheduler_LegacyTemplatesStartAtDeploy(t *testing.T) {
+	f := newSchedulerFixture(t)
+	_, err := f.repos.TransactionTemplateRepository().SaveTransactionTemplate(domain.TransactionTemplate{
+		UserID:        f.userID,
+		Day:           15,
+		WalletID:      f.walletID,
+		Description:   "Miete",
+		AmountInCents: 85000,
+		Type:          domain.Expense,
+		CreatedAt:     onDate(2024, 6, 1),
+	})
+	if err != nil {
+		t.Fatalf("could not save the template: %v", err)
+	}
+
+	if booked := f.mustBook(t, onDate(2026, 3, 10)); booked != 0 {
+		t.Errorf("expected no back-dated bookings for a template without a start date, got %d", booked)
+	}
+	if booked := f.mustBook(t, onDate(2026, 4, 20)); booked != 2 {
+		t.Errorf("expected the occurrences after the first run to be booked, got %d", booked)
+	}
+
+	got := f.bookedDates(t)
+	want := []string{"2026-03-15", "2026-04-15"}
+	if len(got) != len(want) {
+		t.Fatalf("expected bookings on %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("booking %d: expected %s, got %s", i, want[i], got[i])
+		}
+	}
+}
@@ -2,6 +2,8 @@ package services
 
 import (
 	"fmt"
+	"sort"
+	"time"
 
 	"github.com/fim-lab/expense-tracker/internal/core/domain"
 	"github.com/fim-lab/expense-tracker/internal/core/ports"
@@ -9,42 +11,47 @@ import (
 
 type transactionTemplateService struct {
 	transactionTemplateRepo ports.TransactionTemplateRepository
-	walletRepo              ports.WalletRepository
-	budgetRepo              ports.BudgetRepository
+	auth                    ports.Authorizer
 }
 
 func NewTransactionTemplateService(
 	transactionTemplateRepo ports.TransactionTemplateRepository,
-	walletRepo ports.WalletRepository,
-	budgetRepo ports.BudgetRepository,
+	auth ports.Authorizer,
 ) ports.TransactionTemplateService {
 	return &transactionTemplateService{
 		transactionTemplateRepo: transactionTemplateRepo,
-		walletRepo:              walletRepo,
-		budgetRepo:              budgetRepo,
+		auth:                    auth,
 	}
 }
 
 func (s *transactionTemplateService) CreateTransactionTemplate(userID int, tt domain.TransactionTemplate) error {
 	tt.UserID = userID
+	tt.ApplyRecurrenceDefaults(time.Now())
 
 	if err := tt.Validate(); err != nil {
 		return err
 	}
 
-	_, err := s.walletRepo.GetWalletByID(tt.WalletID)
+	_, err := s.auth.Wallet(userID, tt.WalletID, domain.PermissionWrite)
 	if err != nil {
 		return fmt.Errorf("wallet validation failed: %w", err)
 	}
 
 	if tt.BudgetID != nil {
-		_, err := s.budgetRepo.GetBudgetByID(*tt.BudgetID)
+		_, err := s.auth.Budget(userID, *tt.BudgetID, domain.PermissionWrite)
 		if err != nil {
 			return fmt.Errorf("budget validation failed: %w", err)
 		}
 	}
 
-	return s.transactionTemplateRepo.SaveTransactionTemplate(tt)
+	if tt.ToWalletID != nil {
+		if _, err := s.auth.Wallet(userID, *tt.ToWalletID, domain.PermissionWrite); err != nil {
+			return domain.ErrWalletNotFound
+		}
+	}
+
+	_, err = s.transactionTemplateRepo.SaveTransactionTemplate(tt)
+	return err
 }
 
 func (s *transactionTemplateService) GetTransactionTemplate(userID int, id int) (domain.TransactionTemplate, error) {
@@ -74,22 +81,33 @@ func (s *transactionTemplateService) UpdateTransactionTemplate(userID int, tt do
 		return domain.ErrUnauthorized
 	}
 
+	if tt.Recurrence.Frequency == "" {
+		tt.Recurrence = existingTT.Recurrence
+	}
+	tt.ApplyRecurrenceDefaults(existingTT.CreatedAt)
+
 	if err := tt.Validate(); err != nil {
 		return err
 	}
 
-	_, err = s.walletRepo.GetWalletByID(tt.WalletID)
+	_, err = s.auth.Wallet(userID, tt.WalletID, domain.PermissionWrite)
 	if err != nil {
 		return fmt.Errorf("wallet validation failed: %w", err)
 	}
 
 	if tt.BudgetID != nil {
-		_, err := s.budgetRepo.GetBudgetByID(*tt.BudgetID)
+		_, err := s.auth.Budget(userID, *tt.BudgetID, domain.PermissionWrite)
 		if err != nil {
 			return fmt.Errorf("budget validation failed: %w", err)
 		}
 	}
 
+	if tt.ToWalletID != nil {
+		if _, err := s.auth.Wallet(userID, *tt.ToWalletID, domain.PermissionWrite); err != nil {
+			return domain.ErrWalletNotFound
+		}
+	}
+
 	return s.transactionTemplateRepo.UpdateTransactionTemplate(tt)
 }
 
@@ -103,3 +121,26 @@ func (s *transactionTemplateService) DeleteTransactionTemplate(userID int, id in
 	}
 	return s.transactionTemplateRepo.DeleteTransactionTemplate(id)
 }
+
+func (s *transactionTemplateService) GetUpcomingTransactions(userID int, until time.Time) ([]domain.UpcomingTransaction, error) {
+	if horizon := domain.DateOf(time.Now()).AddDate(domain.UpcomingHorizonYears, 0, 0); until.After(horizon) {
+		return nil, fmt.Errorf("%w: upcoming transactions reach %d years ahead at most", domain.ErrInvalidDateRange, domain.UpcomingHorizonYears)
+	}
+
+	templates, err := s.transactionTemplateRepo.FindTransactionTemplatesByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	upcoming := []domain.UpcomingTransaction{}
+	for _, tt := range templates {
+		upcoming = append(upcoming, tt.UpcomingUntil(until)...)
+	}
+	sort.SliceStable(upcoming, func(i, j int) bool {
+		if !upcoming[i].Date.Equal(upcoming[j].Date) {
+			return upcoming[i].Date.Before(upcoming[j].Date)
+		}
+		return upcoming[i].TemplateID < upcoming[j].TemplateID
+	})
+	return upcoming, nil
+}
@@ -12,8 +12,7 @@ func TestTransactionTemplateService(t *testing.T) {
 	repos := memory.NewCleanRepositories()
 	svc := NewTransactionTemplateService(
 		repos.TransactionTemplateRepository(),
-		repos.WalletRepository(),
-		repos.BudgetRepository(),
+		NewAuthorizer(repos),
 	)
 
 	testUser := domain.User{Username: "test", PasswordHash: "hash"}
@@ -1,6 +1,9 @@
 package services
 
 import (
+	"net/mail"
+	"strings"
+
 	"github.com/fim-lab/expense-tracker/internal/core/domain"
 	"github.com/fim-lab/expense-tracker/internal/core/ports"
 	"golang.org/x/crypto/bcrypt"
@@ -47,3 +50,14 @@ func (s *userService) GetUserByID(userID int) (domain.User, error) {
 func (s *userService) UpdateSalary(userID int, salary int) error {
 	return s.repo.UpdateUserSalary(userID, salary)
 }
+
+func (s *userService) UpdateEmail(userID int, email string) error {
+	email = strings.TrimSpace(email)
+	if email != "" {
+		address, err := mail.ParseAddress(email)
+		if err != nil || address.Address != email {
+			return domain.ErrInvalidEmail
+		}
+	}
+	return s.repo.UpdateUserEmail(userID, email)
+}
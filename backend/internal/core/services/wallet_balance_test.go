@@ -0,0 +1,111 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/fim-lab/expense-tracker/adapters/repository/memory"
+	"github.com/fim-lab/expense-tracker/internal/core/domain"
+	"github.com/fim-lab/expense-tracker/internal/core/ports"
+)
+
+func newBalanceFixture(t *testing.T) (ports.WalletService, int, int) {
+	t.Helper()
+	repos := memory.NewCleanRepositories()
+	svc := NewWalletService(repos.WalletRepository(), repos.TransactionRepository(), repos.MembershipRepository(), NewAuthorizer(repos))
+	txSvc := NewTransactionService(repos.TransactionRepository(), NewAuthorizer(repos))
+	userID, checking, cash := 1, 1, 2
+
+	for _, w := range []domain.Wallet{{ID: checking, Name: "Girokonto"}, {ID: cash, Name: "Bargeld", Type: domain.WalletCash}} {
+		if err := svc.CreateWallet(userID, w); err != nil {
+			t.Fatalf("could not create %q: %v", w.Name, err)
+		}
+	}
+	if err := svc.CreateWallet(99, domain.Wallet{ID: 3, Name: "Fremd"}); err != nil {
+		t.Fatalf("could not create the foreign wallet: %v", err)
+	}
+	for _, tx := range []domain.Transaction{
+		{ID: 11, Date: onDate(2025, 12, 20), WalletID: checking, Description: "Rest", AmountInCents: 100, Type: domain.Income},
+		{ID: 12, Date: onDate(2026, 1, 2), WalletID: checking, Description: "Gehalt", AmountInCents: 1000, Type: domain.Income},
+		{ID: 13, Date: onDate(2026, 1, 10), WalletID: checking, Description: "Miete", AmountInCents: 300, Type: domain.Expense},
+		{ID: 14, Date: onDate(2026, 1, 20), WalletID: cash, Description: "Abheben", AmountInCents: 200, Type: domain.Income},
+		{ID: 15, Date: onDate(2026, 2, 3), WalletID: checking, Description: "Gehalt", AmountInCents: 500, Type: domain.Income},
+	} {
+		if _, err := txSvc.CreateTransaction(userID, tx); err != nil {
+			t.Fatalf("could not book %q: %v", tx.Description, err)
+		}
+	}
+	return svc, userID, checking
+}
+
+func TestBalanceHistory_EndsEveryIntervalAndUntil(t *testing.T) {
+	svc, userID, checking := newBalanceFixture(t)
+
+	for name, tc := range map[string]struct {
+		interval    domain.BalanceInterval
+		from, until time.Time
+		want        []domain.BalancePoint
+	}{
+		"weekly ending on sundays": {domain.BalanceWeekly, onDate(2026, 1, 1), onDate(2026, 1, 18), []domain.BalancePoint{
+			{Date: onDate(2026, 1, 4), BalanceCents: 1100},
+			{Date: onDate(2026, 1, 11), BalanceCents: 800},
+			{Date: onDate(2026, 1, 18), BalanceCents: 800},
+		}},
+		"monthly up to the middle of february": {domain.BalanceMonthly, onDate(2026, 1, 1), onDate(2026, 2, 15), []domain.BalancePoint{
+			{Date: onDate(2026, 1, 31), BalanceCents: 800},
+			{Date: onDate(2026, 2, 15), BalanceCents: 1300},
+		}},
+		"a single day": {domain.BalanceDaily, onDate(2026, 1, 10), onDate(2026, 1, 10), []domain.BalancePoint{
+			{Date: onDate(2026, 1, 10), BalanceCents: 800},
+		}},
+	} {
+		points, err := svc.GetBalanceHistory(userID, checking, tc.from, tc.until, tc.interval)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if len(points) != len(tc.want) {
+			t.Fatalf("%s: expected %v, got %v", name, tc.want, points)
+		}
+		if counted := domain.CountBalancePoints(tc.from, tc.until, tc.interval); counted != len(points) {
+			t.Errorf("%s: expected %d points to be counted, got %d", name, len(points), counted)
+		}
+		for i := range points {
+			if !points[i].Date.Equal(tc.want[i].Date) || points[i].BalanceCents != tc.want[i].BalanceCents {
+				t.Errorf("%s: expected %v, got %v", name, tc.want, points)
+				break
+			}
+		}
+	}
+
+	if _, err := svc.GetBalanceHistory(userID, checking, onDate(2026, 2, 1), onDate(2026, 1, 1), domain.BalanceDaily); !errors.Is(err, domain.ErrInvalidDateRange) {
+		t.Errorf("expected a reversed range to be refused, got %v", err)
+	}
+	if _, err := svc.GetBalanceHistory(userID, checking, onDate(2026, 1, 1), onDate(2026, 2, 1), "year"); !errors.Is(err, domain.ErrInvalidDateRange) {
+		t.Errorf("expected an unknown interval to be refused, got %v", err)
+	}
+	if _, err := svc.GetBalanceHistory(userID, checking, onDate(2020, 1, 1), onDate(2026, 1, 1), domain.BalanceDaily); !errors.Is(err, domain.ErrInvalidDateRange) {
+		t.Errorf("expected six years of days to be refused, got %v", err)
+	}
+	if _, err := svc.GetBalanceHistory(userID, checking, onDate(2020, 1, 1), onDate(2026, 1, 1), domain.BalanceMonthly); err != nil {
+		t.Errorf("expected six years of months to be fine, got %v", err)
+	}
+	if _, err := svc.GetBalanceHistory(userID, 3, onDate(2026, 1, 1), onDate(2026, 2, 1), domain.BalanceDaily); !errors.Is(err, domain.ErrUnauthorized) {
+		t.Errorf("expected another user's wallet to be refused, got %v", err)
+	}
+}
+
+func TestBalancesAt_SumsEveryWalletAtTheEndOfTheDay(t *testing.T) {
+	svc, userID, _ := newBalanceFixture(t)
+
+	balances, err := svc.GetBalancesAt(userID, onDate(2026, 1, 31))
+	if err != nil {
+		t.Fatalf("reading the balances failed: %v", err)
+	}
+	if len(balances.Wallets) != 2 || balances.Wallets[0].BalanceCents != 800 || balances.Wallets[1].BalanceCents != 200 || balances.TotalCents != 1000 {
+		t.Errorf("expected 8.00 and 2.00 at the end of January, got %+v", balances)
+	}
+	if balances.Wallets[1].Type != domain.WalletCash {
+		t.Errorf("expected the wallet type to be reported, got %+v", balances.Wallets[1])
+	}
+}
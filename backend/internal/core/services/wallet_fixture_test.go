@@ -0,0 +1,35 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/fim-lab/expense-tracker/adapters/repository/memory"
+	"github.com/fim-lab/expense-tracker/internal/core/domain"
+	"github.com/fim-lab/expense-tracker/internal/core/ports"
+)
+
+// walletFixture is a user with one wallet and the transaction service, which
+// is what most service tests start from.
+type walletFixture struct {
+	repos    ports.Repositories
+	txSvc    ports.TransactionService
+	userID   int
+	walletID int
+}
+
+func newWalletFixture(t *testing.T) walletFixture {
+	t.Helper()
+
+	repos := memory.NewCleanRepositories()
+	userID, walletID := 1, 1
+	if err := repos.WalletRepository().SaveWallet(domain.Wallet{ID: walletID, UserID: userID, Name: "Girokonto"}); err != nil {
+		t.Fatalf("could not seed the wallet: %v", err)
+	}
+
+	return walletFixture{
+		repos:    repos,
+		txSvc:    NewTransactionService(repos.TransactionRepository(), NewAuthorizer(repos)),
+		userID:   userID,
+		walletID: walletID,
+	}
+}
@@ -1,7 +1,9 @@
 package services
 
 import (
+	"fmt"
 	"strings"
+	"time"
 
 	"github.com/fim-lab/expense-tracker/internal/core/domain"
 	"github.com/fim-lab/expense-tracker/internal/core/ports"
@@ -10,72 +12,102 @@ import (
 type walletService struct {
 	walletRepo      ports.WalletRepository
 	transactionRepo ports.TransactionRepository
+	membershipRepo  ports.MembershipRepository
+	auth            ports.Authorizer
 }
 
-func NewWalletService(walletRepo ports.WalletRepository, transactionRepo ports.TransactionRepository) ports.WalletService {
-	return &walletService{walletRepo: walletRepo, transactionRepo: transactionRepo}
+func NewWalletService(walletRepo ports.WalletRepository, transactionRepo ports.TransactionRepository, membershipRepo ports.MembershipRepository, auth ports.Authorizer) ports.WalletService {
+	return &walletService{walletRepo: walletRepo, transactionRepo: transactionRepo, membershipRepo: membershipRepo, auth: auth}
 }
 
 func (s *walletService) CreateWallet(userID int, b domain.Wallet) error {
 	b.UserID = userID
+	b.ApplyDefaults()
 
-	if strings.TrimSpace(b.Name) == "" {
-		return domain.ErrMissingWallet
+	if err := b.Validate(); err != nil {
+		return err
+	}
+	if err := s.checkPaymentWallet(userID, userID, b); err != nil {
+		return err
 	}
 
 	return s.walletRepo.SaveWallet(b)
 }
 
 func (s *walletService) GetWallet(userID int, id int) (domain.Wallet, error) {
-	wallet, err := s.walletRepo.GetWalletByID(id)
+	wallet, err := s.auth.Wallet(userID, id, domain.PermissionRead)
 	if err != nil {
 		return domain.Wallet{}, err
 	}
 
-	if wallet.UserID != userID {
-		return domain.Wallet{}, domain.ErrUnauthorized
+	pending, _, err := s.transactionRepo.SumPendingAmounts(wallet.UserID)
+	if err != nil {
+		return domain.Wallet{}, err
 	}
-
-	wallet.CanDelete = true
-	if wallet.BalanceCents != 0 {
-		wallet.CanDelete = false
-	} else {
-		count, err := s.transactionRepo.CountTransactionsByWalletID(wallet.ID)
-		if err != nil {
-			return domain.Wallet{}, err
-		}
-		if count > 0 {
-			wallet.CanDelete = false
-		}
+	if err := s.describe(&wallet, pending); err != nil {
+		return domain.Wallet{}, err
 	}
-
 	return wallet, nil
 }
 
+// GetWallets returns the user's wallets followed by those others shared with
+// them.
 func (s *walletService) GetWallets(userID int) ([]domain.Wallet, error) {
 	wallets, err := s.walletRepo.FindWalletsByUser(userID)
 	if err != nil {
 		return nil, err
 	}
+	for i := range wallets {
+		wallets[i].Permission = domain.PermissionOwner
+	}
+	memberships, err := s.membershipRepo.FindMembershipsByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range memberships {
+		if m.ResourceType != domain.ResourceWallet {
+			continue
+		}
+		wallet, err := s.walletRepo.GetWalletByID(m.ResourceID)
+		if err != nil {
+			return nil, err
+		}
+		wallet.Permission = m.Permission
+		wallets = append(wallets, wallet)
+	}
 
+	pendingByOwner := make(map[int]map[int]int)
 	for i := range wallets {
-		wallets[i].CanDelete = true
-		if wallets[i].BalanceCents != 0 {
-			wallets[i].CanDelete = false
-		} else {
-			count, err := s.transactionRepo.CountTransactionsByWalletID(wallets[i].ID)
-			if err != nil {
+		pending, ok := pendingByOwner[wallets[i].UserID]
+		if !ok {
+			if pending, _, err = s.transactionRepo.SumPendingAmounts(wallets[i].UserID); err != nil {
 				return nil, err
 			}
-			if count > 0 {
-				wallets[i].CanDelete = false
-			}
+			pendingByOwner[wallets[i].UserID] = pending
+		}
+		if err := s.describe(&wallets[i], pending); err != nil {
+			return nil, err
 		}
 	}
 
 	return wallets, nil
 }
 
+// describe fills in what the wallet's owner's pending transactions add to
+// it, whether the user may delete it and a credit card's statement.
+func (s *walletService) describe(wallet *domain.Wallet, pending map[int]int) error {
+	wallet.SplitPending(pending[wallet.ID])
+	wallet.CanDelete = wallet.Permission == domain.PermissionOwner && wallet.BalanceCents == 0
+	if wallet.CanDelete {
+		count, err := s.transactionRepo.CountTransactionsByWalletID(wallet.ID)
+		if err != nil {
+			return err
+		}
+		wallet.CanDelete = count == 0
+	}
+	return s.withStatement(wallet, time.Now())
+}
+
 func (s *walletService) GetTotalOfWallets(userID int) (int, error) {
 	wallets, err := s.walletRepo.FindWalletsByUser(userID)
 	if err != nil {
@@ -91,30 +123,30 @@ func (s *walletService) GetTotalOfWallets(userID int) (int, error) {
 }
 
 func (s *walletService) UpdateWallet(userID int, wallet domain.Wallet) error {
-	existingWallet, err := s.walletRepo.GetWalletByID(wallet.ID)
+	existingWallet, err := s.auth.Wallet(userID, wallet.ID, domain.PermissionWrite)
 	if err != nil {
 		return err
 	}
 
-	if existingWallet.UserID != userID {
-		return domain.ErrUnauthorized
-	}
-
 	if strings.TrimSpace(wallet.Name) == "" {
 		return domain.ErrMissingDescription
 	}
+	wallet.ApplyDefaults()
+	if err := wallet.Validate(); err != nil {
+		return err
+	}
+	if err := s.checkPaymentWallet(userID, existingWallet.UserID, wallet); err != nil {
+		return err
+	}
 
+	wallet.UserID = existingWallet.UserID
 	return s.walletRepo.UpdateWallet(wallet)
 }
 
 func (s *walletService) DeleteWallet(userID int, id int) error {
-	existing, err := s.walletRepo.GetWalletByID(id)
-	if err != nil {
+	if _, err := s.auth.Wallet(userID, id, domain.PermissionOwner); err != nil {
 		return err
 	}
-	if existing.UserID != userID {
-		return domain.ErrUnauthorized
-	}
 	transactionCount, err := s.transactionRepo.CountTransactionsByWalletID(id)
 	if err != nil {
 		return err
@@ -125,3 +157,135 @@ func (s *walletService) DeleteWallet(userID int, id int) error {
 
 	return s.walletRepo.DeleteWallet(id)
 }
+
+// SettleStatement transfers what is left to pay of the card's last statement
+// from its payment wallet onto the card.
+func (s *walletService) SettleStatement(userID int, id int) (domain.Wallet, error) {
+	card, err := s.GetWallet(userID, id)
+	if err != nil {
+		return domain.Wallet{}, err
+	}
+	if !card.Permission.Allows(domain.PermissionWrite) {
+		return domain.Wallet{}, domain.ErrUnauthorized
+	}
+	if card.Type != domain.WalletCreditCard {
+		return domain.Wallet{}, fmt.Errorf("%w: only credit cards have statements", domain.ErrInvalidStatementCycle)
+	}
+	if card.PaymentWalletID == nil {
+		return domain.Wallet{}, fmt.Errorf("%w: the card has no payment wallet", domain.ErrInvalidStatementCycle)
+	}
+	if card.CurrentStatement.OutstandingCents == 0 {
+		return domain.Wallet{}, domain.ErrNothingToSettle
+	}
+	payer, err := s.auth.Wallet(userID, *card.PaymentWalletID, domain.PermissionWrite)
+	if err != nil || payer.UserID != card.UserID {
+		return domain.Wallet{}, domain.ErrWalletNotFound
+	}
+
+	amount := card.CurrentStatement.OutstandingCents
+	now := time.Now()
+	from := domain.Transaction{
+		UserID:        card.UserID,
+		CreatedBy:     userID,
+		Date:          now,
+		WalletID:      payer.ID,
+		Description:   fmt.Sprintf("Statement of %s", card.Name),
+		AmountInCents: amount,
+		Type:          domain.Expense,
+	}
+	to := domain.Transaction{
+		UserID:        card.UserID,
+		CreatedBy:     userID,
+		Date:          now,
+		WalletID:      card.ID,
+		Description:   fmt.Sprintf("Statement paid from %s", payer.Name),
+		AmountInCents: amount,
+		Type:          domain.Income,
+	}
+	if err := s.transactionRepo.CreateTransfer(from, to); err != nil {
+		return domain.Wallet{}, err
+	}
+	return s.GetWallet(userID, id)
+}
+
+// checkPaymentWallet makes sure a card is paid from another wallet of its
+// owner the user may see.
+func (s *walletService) checkPaymentWallet(userID int, ownerID int, w domain.Wallet) error {
+	if w.PaymentWalletID == nil {
+		return nil
+	}
+	payer, err := s.auth.Wallet(userID, *w.PaymentWalletID, domain.PermissionRead)
+	if err != nil || payer.UserID != ownerID {
+		return domain.ErrWalletNotFound
+	}
+	if payer.Type == domain.WalletCreditCard {
+		return fmt.Errorf("%w: a card cannot be paid from another card", domain.ErrInvalidStatementCycle)
+	}
+	return nil
+}
+
+// withStatement fills in the type of wallets stored before there were types
+// and a credit card's current statement.
+func (s *walletService) withStatement(w *domain.Wallet, now time.Time) error {
+	w.ApplyDefaults()
+	if w.Type != domain.WalletCreditCard {
+		return nil
+	}
+	transactions, err := s.transactionRepo.FindTransactionsByWallet(w.ID)
+	if err != nil {
+		return err
+	}
+	statement := w.Statement(transactions, now)
+	w.CurrentStatement = &statement
+	return nil
+}
+
+// GetBalanceHistory rebuilds the wallet's balance at the end of every day,
+// week or month from from to until, refusing ranges with more than
+// domain.MaxBalancePoints points.
+func (s *walletService) GetBalanceHistory(userID int, id int, from, until time.Time, interval domain.BalanceInterval) ([]domain.BalancePoint, error) {
+	if err := interval.Validate(); err != nil {
+		return nil, err
+	}
+	if from.After(until) {
+		return nil, fmt.Errorf("%w: from is after until", domain.ErrInvalidDateRange)
+	}
+	if domain.CountBalancePoints(from, until, interval) > domain.MaxBalancePoints {
+		return nil, fmt.Errorf("%w: at most %d points per history, pick a shorter range or a longer interval", domain.ErrInvalidDateRange, domain.MaxBalancePoints)
+	}
+	wallet, err := s.auth.Wallet(userID, id, domain.PermissionRead)
+	if err != nil {
+		return nil, err
+	}
+
+	opening, err := s.transactionRepo.SumWalletBalancesAt(wallet.UserID, domain.DateOf(from).AddDate(0, 0, -1))
+	if err != nil {
+		return nil, err
+	}
+	changes, err := s.transactionRepo.FindWalletBalanceChanges(id, from, until)
+	if err != nil {
+		return nil, err
+	}
+	return domain.BalanceSeries(opening[id], changes, from, until, interval), nil
+}
+
+// GetBalancesAt rebuilds the balances of all of the user's wallets at the end
+// of the day.
+func (s *walletService) GetBalancesAt(userID int, at time.Time) (domain.WalletBalances, error) {
+	wallets, err := s.walletRepo.FindWalletsByUser(userID)
+	if err != nil {
+		return domain.WalletBalances{}, err
+	}
+	sums, err := s.transactionRepo.SumWalletBalancesAt(userID, at)
+	if err != nil {
+		return domain.WalletBalances{}, err
+	}
+
+	balances := domain.WalletBalances{At: domain.DateOf(at), Wallets: []domain.WalletBalance{}}
+	for _, w := range wallets {
+		w.ApplyDefaults()
+		balances.Wallets = append(balances.Wallets, domain.WalletBalance{WalletID: w.ID, Name: w.Name, Type: w.Type, BalanceCents: sums[w.ID]})
+		balances.TotalCents += sums[w.ID]
+	}
+	return balances, nil
+}
@@ -10,7 +10,7 @@ import (
 
 func TestCreateWallet(t *testing.T) {
 	repos := memory.NewSeededRepositories()
-	svc := NewWalletService(repos.WalletRepository(), repos.TransactionRepository())
+	svc := NewWalletService(repos.WalletRepository(), repos.TransactionRepository(), repos.MembershipRepository(), NewAuthorizer(repos))
 
 	t.Run("Valid wallet creation", func(t *testing.T) {
 		wallet := domain.Wallet{
@@ -72,7 +72,7 @@ func TestGetWalletCanDelete(t *testing.T) {
 
 	t.Run("CanDelete is false when BalanceCents is not zero", func(t *testing.T) {
 		repos := memory.NewCleanRepositories()
-		svc := NewWalletService(repos.WalletRepository(), repos.TransactionRepository())
+		svc := NewWalletService(repos.WalletRepository(), repos.TransactionRepository(), repos.MembershipRepository(), NewAuthorizer(repos))
 		wallet := domain.Wallet{UserID: userID, Name: "Non-Zero Balance", BalanceCents: 500}
 		svc.CreateWallet(userID, wallet)
 		wallets, _ := repos.WalletRepository().FindWalletsByUser(userID)
@@ -89,7 +89,7 @@ func TestGetWalletCanDelete(t *testing.T) {
 
 	t.Run("CanDelete is false when BalanceCents is zero but transactions exist", func(t *testing.T) {
 		repos := memory.NewCleanRepositories()
-		svc := NewWalletService(repos.WalletRepository(), repos.TransactionRepository())
+		svc := NewWalletService(repos.WalletRepository(), repos.TransactionRepository(), repos.MembershipRepository(), NewAuthorizer(repos))
 		wallet := domain.Wallet{UserID: userID, Name: "Zero Balance, Has Transactions", BalanceCents: -100}
 		svc.CreateWallet(userID, wallet)
 		wallets, _ := repos.WalletRepository().FindWalletsByUser(userID)
@@ -113,7 +113,7 @@ func TestGetWalletCanDelete(t *testing.T) {
 
 	t.Run("CanDelete is true when BalanceCents is zero and no transactions exist", func(t *testing.T) {
 		repos := memory.NewCleanRepositories()
-		svc := NewWalletService(repos.WalletRepository(), repos.TransactionRepository())
+		svc := NewWalletService(repos.WalletRepository(), repos.TransactionRepository(), repos.MembershipRepository(), NewAuthorizer(repos))
 		wallet := domain.Wallet{UserID: userID, Name: "Zero Balance, No Transactions", BalanceCents: 0}
 		svc.CreateWallet(userID, wallet)
 		wallets, _ := repos.WalletRepository().FindWalletsByUser(userID)
@@ -132,7 +132,7 @@ func TestGetWalletCanDelete(t *testing.T) {
 func TestGetWalletsCanDelete(t *testing.T) {
 	userID := 1
 	repos := memory.NewCleanRepositories()
-	svc := NewWalletService(repos.WalletRepository(), repos.TransactionRepository())
+	svc := NewWalletService(repos.WalletRepository(), repos.TransactionRepository(), repos.MembershipRepository(), NewAuthorizer(repos))
 
 	wallet1 := domain.Wallet{UserID: userID, Name: "Wallet 1", BalanceCents: 500}
 	svc.CreateWallet(userID, wallet1)
@@ -180,7 +180,7 @@ func TestDeleteWallet(t *testing.T) {
 
 	t.Run("Successfully delete empty wallet", func(t *testing.T) {
 		repos := memory.NewCleanRepositories()
-		svc := NewWalletService(repos.WalletRepository(), repos.TransactionRepository())
+		svc := NewWalletService(repos.WalletRepository(), repos.TransactionRepository(), repos.MembershipRepository(), NewAuthorizer(repos))
 		wallet := domain.Wallet{UserID: userID, Name: "Test Wallet"}
 		svc.CreateWallet(userID, wallet)
 		wallets, _ := repos.WalletRepository().FindWalletsByUser(userID)
@@ -199,7 +199,7 @@ func TestDeleteWallet(t *testing.T) {
 
 	t.Run("Fail to delete wallet with transactions", func(t *testing.T) {
 		repos := memory.NewCleanRepositories()
-		svc := NewWalletService(repos.WalletRepository(), repos.TransactionRepository())
+		svc := NewWalletService(repos.WalletRepository(), repos.TransactionRepository(), repos.MembershipRepository(), NewAuthorizer(repos))
 		wallet := domain.Wallet{UserID: userID, Name: "Test Wallet"}
 		svc.CreateWallet(userID, wallet)
 		wallets, _ := repos.WalletRepository().FindWalletsByUser(userID)
@@ -225,7 +225,7 @@ func TestDeleteWallet(t *testing.T) {
 
 	t.Run("Unauthorized deletion", func(t *testing.T) {
 		repos := memory.NewCleanRepositories()
-		svc := NewWalletService(repos.WalletRepository(), repos.TransactionRepository())
+		svc := NewWalletService(repos.WalletRepository(), repos.TransactionRepository(), repos.MembershipRepository(), NewAuthorizer(repos))
 		wallet := domain.Wallet{UserID: userID, Name: "Test Wallet"}
 		svc.CreateWallet(userID, wallet)
 		wallets, _ := repos.WalletRepository().FindWalletsByUser(userID)
@@ -0,0 +1,93 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/fim-lab/expense-tracker/adapters/repository/memory"
+	"github.com/fim-lab/expense-tracker/internal/core/domain"
+)
+
+func TestCreditCardStatement_CycleFallsOnShortMonths(t *testing.T) {
+	card := domain.Wallet{Type: domain.WalletCreditCard, StatementClosingDay: 31, PaymentDueDay: 15}
+	s := card.Statement(nil, onDate(2026, 3, 10))
+
+	if !s.ClosingDate.Equal(onDate(2026, 2, 28)) || !s.PeriodStart.Equal(onDate(2026, 2, 1)) || !s.DueDate.Equal(onDate(2026, 3, 15)) {
+		t.Errorf("expected the statement of February due in the middle of March, got %+v", s)
+	}
+}
+
+func TestCreditCard_SettleStatementPaysFromTheLinkedWallet(t *testing.T) {
+	repos := memory.NewCleanRepositories()
+	svc := NewWalletService(repos.WalletRepository(), repos.TransactionRepository(), repos.MembershipRepository(), NewAuthorizer(repos))
+	txSvc := NewTransactionService(repos.TransactionRepository(), NewAuthorizer(repos))
+	userID, checking, card := 1, 1, 2
+	today := domain.DateOf(time.Now())
+
+	if err := svc.CreateWallet(userID, domain.Wallet{ID: checking, Name: "Girokonto"}); err != nil {
+		t.Fatalf("could not create the checking wallet: %v", err)
+	}
+	if err := svc.CreateWallet(userID, domain.Wallet{ID: card, Name: "Kreditkarte", Type: domain.WalletCreditCard, StatementClosingDay: today.Day(), PaymentDueDay: 28, PaymentWalletID: &checking}); err != nil {
+		t.Fatalf("could not create the card: %v", err)
+	}
+	for _, tx := range []domain.Transaction{
+		{ID: 11, Date: today.AddDate(0, 0, -40), WalletID: card, Description: "Hotel", AmountInCents: 5000, Type: domain.Expense},
+		{ID: 12, Date: today.AddDate(0, 0, -5), WalletID: card, Description: "Tanken", AmountInCents: 3000, Type: domain.Expense},
+		{ID: 13, Date: today.AddDate(0, 0, -3), WalletID: card, Description: "Erstattung", AmountInCents: 2000, Type: domain.Income},
+		{ID: 14, Date: today.AddDate(0, 0, 1), WalletID: card, Description: "Kino", AmountInCents: 1500, Type: domain.Expense},
+	} {
+		if _, err := txSvc.CreateTransaction(userID, tx); err != nil {
+			t.Fatalf("could not book %q: %v", tx.Description, err)
+		}
+	}
+
+	wallet, err := svc.GetWallet(userID, card)
+	if err != nil {
+		t.Fatalf("could not read the card: %v", err)
+	}
+	s := wallet.CurrentStatement
+	if s == nil || !s.ClosingDate.Equal(today) || s.BalanceCents != 6000 || s.OutstandingCents != 6000 || s.UnbilledCents != 1500 {
+		t.Fatalf("expected 60.00 on the statement closing today and 15.00 unbilled, got %+v", s)
+	}
+
+	settled, err := svc.SettleStatement(userID, card)
+	if err != nil {
+		t.Fatalf("settling failed: %v", err)
+	}
+	if settled.CurrentStatement.OutstandingCents != 0 || settled.BalanceCents != -1500 {
+		t.Errorf("expected only the unbilled charge to be left on the card, got %+v", settled)
+	}
+	if payer, _ := svc.GetWallet(userID, checking); payer.BalanceCents != -6000 {
+		t.Errorf("expected the statement to be paid from the checking wallet, got %d", payer.BalanceCents)
+	}
+	if _, err := svc.SettleStatement(userID, card); !errors.Is(err, domain.ErrNothingToSettle) {
+		t.Errorf("expected a settled statement to be refused, got %v", err)
+	}
+	if _, err := svc.SettleStatement(userID, checking); !errors.Is(err, domain.ErrInvalidStatementCycle) {
+		t.Errorf("expected a checking wallet to have no statement, got %v", err)
+	}
+}
+
+func TestCreateWallet_ChecksTheStatementCycle(t *testing.T) {
+	repos := memory.NewCleanRepositories()
+	svc := NewWalletService(repos.WalletRepository(), repos.TransactionRepository(), repos.MembershipRepository(), NewAuthorizer(repos))
+	userID, card := 1, 1
+	if err := svc.CreateWallet(userID, domain.Wallet{ID: card, Name: "Kreditkarte", Type: domain.WalletCreditCard, StatementClosingDay: 20, PaymentDueDay: 5}); err != nil {
+		t.Fatalf("could not create the card: %v", err)
+	}
+
+	for name, tc := range map[string]struct {
+		wallet domain.Wallet
+		want   error
+	}{
+		"unknown type":          {domain.Wallet{Name: "Depot", Type: "STOCKS"}, domain.ErrInvalidWalletType},
+		"card without cycle":    {domain.Wallet{Name: "Karte", Type: domain.WalletCreditCard}, domain.ErrInvalidStatementCycle},
+		"checking with a cycle": {domain.Wallet{Name: "Giro", StatementClosingDay: 20}, domain.ErrInvalidStatementCycle},
+		"paid from a card":      {domain.Wallet{Name: "Karte", Type: domain.WalletCreditCard, StatementClosingDay: 1, PaymentDueDay: 20, PaymentWalletID: &card}, domain.ErrInvalidStatementCycle},
+	} {
+		if err := svc.CreateWallet(userID, tc.wallet); !errors.Is(err, tc.want) {
+			t.Errorf("%s: expected %v, got %v", name, tc.want, err)
+		}
+	}
+}
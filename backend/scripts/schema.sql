@@ -3,6 +3,8 @@ CREATE TABLE IF NOT EXISTS users (
     username TEXT NOT NULL UNIQUE,
     password_hash TEXT NOT NULL,
     salary_cents BIGINT NOT NULL DEFAULT 0,
+    email TEXT NOT NULL DEFAULT '',
+    is_admin BOOLEAN NOT NULL DEFAULT false,
     created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
 );
 
@@ -15,17 +17,61 @@ CREATE TABLE IF NOT EXISTS sessions (
 CREATE TABLE IF NOT EXISTS budgets (
     id SERIAL PRIMARY KEY,
     user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    parent_id INT REFERENCES budgets(id) ON DELETE SET NULL,
     name TEXT NOT NULL,
     limit_cents BIGINT NOT NULL,
     balance_cents BIGINT NOT NULL DEFAULT 0,
+    period TEXT NOT NULL DEFAULT 'MONTHLY',
+    rollover TEXT NOT NULL DEFAULT 'RESET',
+    alert_thresholds INTEGER[] NOT NULL DEFAULT '{}',
     UNIQUE(user_id, name)
 );
 
+CREATE TABLE IF NOT EXISTS budget_allocations (
+    budget_id INT NOT NULL REFERENCES budgets(id) ON DELETE CASCADE,
+    period_start DATE NOT NULL,
+    amount_in_cents BIGINT NOT NULL,
+    PRIMARY KEY (budget_id, period_start)
+);
+
+CREATE TABLE IF NOT EXISTS budget_movements (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    date TIMESTAMP WITH TIME ZONE NOT NULL,
+    from_budget_id INT REFERENCES budgets(id) ON DELETE RESTRICT,
+    to_budget_id INT REFERENCES budgets(id) ON DELETE RESTRICT,
+    amount_in_cents BIGINT NOT NULL,
+    note TEXT NOT NULL DEFAULT ''
+);
+
 CREATE TABLE IF NOT EXISTS wallets (
     id SERIAL PRIMARY KEY,
     user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
     name TEXT NOT NULL,
+    type TEXT NOT NULL DEFAULT 'CHECKING',
     balance_cents BIGINT NOT NULL DEFAULT 0,
+    statement_closing_day INT NOT NULL DEFAULT 0,
+    payment_due_day INT NOT NULL DEFAULT 0,
+    payment_wallet_id INT REFERENCES wallets(id) ON DELETE SET NULL,
+    UNIQUE(user_id, name)
+);
+
+CREATE TABLE IF NOT EXISTS reconciliations (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    wallet_id INT NOT NULL REFERENCES wallets(id) ON DELETE CASCADE,
+    statement_date DATE NOT NULL,
+    statement_balance_cents BIGINT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'OPEN',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE TABLE IF NOT EXISTS counterparties (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
     UNIQUE(user_id, name)
 );
 
@@ -41,7 +87,15 @@ CREATE TABLE IF NOT EXISTS transactions (
     is_pending BOOLEAN NOT NULL DEFAULT FALSE,
     is_debt BOOLEAN DEFAULT FALSE,
     tags JSONB,
-    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+    external_id TEXT,
+    cleared BOOLEAN NOT NULL DEFAULT FALSE,
+    reconciliation_id INT REFERENCES reconciliations(id) ON DELETE SET NULL,
+    pending_overdue BOOLEAN NOT NULL DEFAULT FALSE,
+    counterparty_id INT REFERENCES counterparties(id),
+    repayment_of_id INT REFERENCES transactions(id) ON DELETE SET NULL,
+    created_by INT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(user_id, external_id)
 );
 
 CREATE TABLE IF NOT EXISTS depots (
@@ -81,19 +135,146 @@ CREATE TABLE IF NOT EXISTS transaction_templates (
     day INT NOT NULL,
     budget_id INT REFERENCES budgets(id) ON DELETE SET NULL,
     wallet_id INT NOT NULL REFERENCES wallets(id) ON DELETE CASCADE,
+    to_wallet_id INT REFERENCES wallets(id) ON DELETE CASCADE,
     description TEXT NOT NULL,
     amount_in_cents BIGINT NOT NULL,
     type TEXT NOT NULL,
     tags TEXT[],
+    frequency TEXT NOT NULL DEFAULT 'MONTHLY',
+    recurrence_interval INT NOT NULL DEFAULT 1,
+    start_date DATE,
+    end_date DATE,
+    occurrence_count INT,
+    last_occurrence DATE,
     created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
 );
 
+-- Templates stored before recurrence rules existed start now instead of
+-- catching up on every month since they were created.
+UPDATE transaction_templates SET last_occurrence = CURRENT_DATE - 1
+WHERE start_date IS NULL AND last_occurrence IS NULL;
+
+CREATE TABLE IF NOT EXISTS csv_profiles (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    encoding TEXT NOT NULL,
+    delimiter TEXT NOT NULL,
+    date_column TEXT NOT NULL,
+    date_format TEXT NOT NULL,
+    description_columns TEXT[] NOT NULL,
+    amount_column TEXT NOT NULL,
+    decimal_separator TEXT NOT NULL,
+    sign_convention TEXT NOT NULL,
+    indicator_column TEXT NOT NULL DEFAULT '',
+    debit_indicator TEXT NOT NULL DEFAULT '',
+    reference_column TEXT NOT NULL DEFAULT '',
+    UNIQUE(user_id, name)
+);
+
+CREATE TABLE IF NOT EXISTS transaction_splits (
+    id SERIAL PRIMARY KEY,
+    transaction_id INT NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+    budget_id INT NOT NULL REFERENCES budgets(id) ON DELETE CASCADE,
+    amount_in_cents BIGINT NOT NULL,
+    tags JSONB,
+    note TEXT NOT NULL DEFAULT ''
+);
+
+CREATE TABLE IF NOT EXISTS notifications (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type TEXT NOT NULL,
+    subject TEXT NOT NULL,
+    message TEXT NOT NULL,
+    budget_id INT REFERENCES budgets(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    read BOOLEAN NOT NULL DEFAULT FALSE
+);
+
+CREATE TABLE IF NOT EXISTS savings_goals (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    wallet_id INT REFERENCES wallets(id) ON DELETE CASCADE,
+    budget_id INT REFERENCES budgets(id) ON DELETE CASCADE,
+    target_cents BIGINT NOT NULL,
+    target_date DATE NOT NULL,
+    monthly_contribution_cents BIGINT NOT NULL DEFAULT 0,
+    from_wallet_id INT REFERENCES wallets(id) ON DELETE SET NULL,
+    contribution_template_id INT REFERENCES transaction_templates(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK ((wallet_id IS NULL) <> (budget_id IS NULL))
+);
+
+CREATE TABLE IF NOT EXISTS shared_expenses (
+    id SERIAL PRIMARY KEY,
+    transaction_id INT NOT NULL UNIQUE REFERENCES transactions(id) ON DELETE CASCADE,
+    payer_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    method TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS expense_shares (
+    shared_expense_id INT NOT NULL REFERENCES shared_expenses(id) ON DELETE CASCADE,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    percent DOUBLE PRECISION NOT NULL DEFAULT 0,
+    amount_in_cents BIGINT NOT NULL,
+    PRIMARY KEY (shared_expense_id, user_id)
+);
+
+CREATE TABLE IF NOT EXISTS settlements (
+    id SERIAL PRIMARY KEY,
+    from_user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    to_user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    amount_in_cents BIGINT NOT NULL,
+    date DATE NOT NULL,
+    from_transaction_id INT REFERENCES transactions(id) ON DELETE CASCADE,
+    to_transaction_id INT REFERENCES transactions(id) ON DELETE CASCADE,
+    confirmed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS memberships (
+    id SERIAL PRIMARY KEY,
+    owner_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    wallet_id INT REFERENCES wallets(id) ON DELETE CASCADE,
+    budget_id INT REFERENCES budgets(id) ON DELETE CASCADE,
+    depot_id INT REFERENCES depots(id) ON DELETE CASCADE,
+    permission TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK (num_nonnulls(wallet_id, budget_id, depot_id) = 1),
+    UNIQUE(wallet_id, user_id),
+    UNIQUE(budget_id, user_id),
+    UNIQUE(depot_id, user_id)
+);
+
 CREATE INDEX IF NOT EXISTS idx_transactions_user_id ON transactions(user_id);
 CREATE INDEX IF NOT EXISTS idx_transactions_wallet_id ON transactions(wallet_id);
+CREATE INDEX IF NOT EXISTS idx_transactions_wallet_date ON transactions(wallet_id, date);
 CREATE INDEX IF NOT EXISTS idx_transactions_budget_id ON transactions(budget_id);
+CREATE INDEX IF NOT EXISTS idx_transactions_pending ON transactions(date) WHERE is_pending;
+CREATE INDEX IF NOT EXISTS idx_transactions_counterparty_id ON transactions(counterparty_id) WHERE counterparty_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_transactions_repayment_of_id ON transactions(repayment_of_id) WHERE repayment_of_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_transactions_tags ON transactions USING GIN (tags);
+CREATE INDEX IF NOT EXISTS idx_transaction_splits_transaction_id ON transaction_splits(transaction_id);
+CREATE INDEX IF NOT EXISTS idx_transaction_splits_budget_id ON transaction_splits(budget_id);
 CREATE INDEX IF NOT EXISTS idx_budgets_user_id ON budgets(user_id);
+CREATE INDEX IF NOT EXISTS idx_budgets_parent_id ON budgets(parent_id);
+CREATE INDEX IF NOT EXISTS idx_budget_movements_user_id ON budget_movements(user_id);
 CREATE INDEX IF NOT EXISTS idx_wallets_user_id ON wallets(user_id);
 CREATE INDEX IF NOT EXISTS idx_depots_user_id ON depots(user_id);
 CREATE INDEX IF NOT EXISTS idx_trades_depot_id ON trades(depot_id);
 CREATE INDEX IF NOT EXISTS idx_trades_stock_id ON trades(stock_id);
 CREATE INDEX IF NOT EXISTS idx_transaction_templates_user_id ON transaction_templates(user_id);
+CREATE INDEX IF NOT EXISTS idx_notifications_user_id ON notifications(user_id);
+CREATE INDEX IF NOT EXISTS idx_savings_goals_user_id ON savings_goals(user_id);
+CREATE INDEX IF NOT EXISTS idx_reconciliations_wallet_id ON reconciliations(wallet_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_reconciliations_open_wallet ON reconciliations(wallet_id) WHERE status = 'OPEN';
+CREATE INDEX IF NOT EXISTS idx_expense_shares_user_id ON expense_shares(user_id);
+CREATE INDEX IF NOT EXISTS idx_shared_expenses_payer_id ON shared_expenses(payer_id);
+CREATE INDEX IF NOT EXISTS idx_settlements_from_user_id ON settlements(from_user_id);
+CREATE INDEX IF NOT EXISTS idx_settlements_to_user_id ON settlements(to_user_id);
+CREATE INDEX IF NOT EXISTS idx_memberships_user_id ON memberships(user_id);
+CREATE INDEX IF NOT EXISTS idx_memberships_owner_id ON memberships(owner_id);